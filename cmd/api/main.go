@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -32,6 +33,74 @@ func main() {
 		}
 	}
 
+	// Soft-deleted projects are purged after PROJECT_RETENTION (default 30 days),
+	// checked every PURGE_INTERVAL (default 1h)
+	retention := 30 * 24 * time.Hour
+	if v := os.Getenv("PROJECT_RETENTION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			retention = d
+		} else {
+			log.Fatalf("invalid PROJECT_RETENTION: %v", err)
+		}
+	}
+
+	// Audit entries are kept for AUDIT_RETENTION (default 365 days); 0 keeps
+	// them forever
+	auditRetention := 365 * 24 * time.Hour
+	if v := os.Getenv("AUDIT_RETENTION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			auditRetention = d
+		} else {
+			log.Fatalf("invalid AUDIT_RETENTION: %q", v)
+		}
+	}
+
+	purgeInterval := time.Hour
+	if v := os.Getenv("PURGE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			purgeInterval = d
+		} else {
+			log.Fatalf("invalid PURGE_INTERVAL: %q", v)
+		}
+	}
+
+	// API keys are required unless AUTH_DISABLED=true; ADMIN_API_KEY is an
+	// admin key that works without being stored, for creating the first keys
+	auth := httpapi.AuthConfig{Enabled: true, AdminKey: os.Getenv("ADMIN_API_KEY")}
+	if v := os.Getenv("AUTH_DISABLED"); v != "" {
+		disabled, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Fatalf("invalid AUTH_DISABLED: %q", v)
+		}
+		auth.Enabled = !disabled
+	}
+	if !auth.Enabled {
+		log.Printf("WARN: AUTH_DISABLED is set; every endpoint is open")
+	}
+
+	// Bearer JWTs are accepted when their keys come from JWT_JWKS_FILE or
+	// JWT_HMAC_SECRET; JWT_ISSUER and JWT_AUDIENCE are checked when set
+	jwksFile, hmacSecret := os.Getenv("JWT_JWKS_FILE"), os.Getenv("JWT_HMAC_SECRET")
+	if jwksFile != "" && hmacSecret != "" {
+		log.Fatalf("set only one of JWT_JWKS_FILE and JWT_HMAC_SECRET")
+	}
+	if jwksFile != "" || hmacSecret != "" {
+		keys := httpapi.HMACKeySet([]byte(hmacSecret))
+		if jwksFile != "" {
+			var err error
+			if keys, err = httpapi.LoadJWKSFile(jwksFile); err != nil {
+				log.Fatalf("invalid JWT_JWKS_FILE: %v", err)
+			}
+		}
+		auth.Bearer = httpapi.NewJWTVerifier(keys, os.Getenv("JWT_ISSUER"), os.Getenv("JWT_AUDIENCE"))
+	}
+
+	// Without ADMIN_API_KEY or JWTs there is no way to create the first key,
+	// so every request would be a 401
+	if auth.Enabled && auth.AdminKey == "" && auth.Bearer == nil {
+		log.Fatalf("auth is enabled but nothing can authenticate: set ADMIN_API_KEY, JWT_JWKS_FILE or JWT_HMAC_SECRET, or AUTH_DISABLED=true")
+	}
+
 	// Store selection
 	var st store.ProjectStore
 	var stCloser closer
@@ -52,7 +121,14 @@ func main() {
 		stCloser = pg // close later, after shutdown
 	}
 
-	app := httpapi.NewApplication(st)
+	app := httpapi.NewApplication(st, auth)
+
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	purgeDone := make(chan struct{})
+	go func() {
+		defer close(purgeDone)
+		runProjectPurge(purgeCtx, st, retention, auditRetention, purgeInterval)
+	}()
 
 	srv := &http.Server{
 		Addr:         addr,
@@ -93,6 +169,10 @@ func main() {
 		log.Printf("ERROR: server returned: %v", err)
 	}
 
+	// Stop background work before the store goes away
+	stopPurge()
+	<-purgeDone
+
 	if stCloser != nil {
 		stCloser.Close()
 	}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+// purgeActor is who the audit log records the purge as.
+const purgeActor = "system:purge"
+
+// runProjectPurge hard-deletes projects, in every workspace, that have been
+// soft-deleted for longer than retention, and audit entries older than
+// auditRetention unless it is 0, once at start and then every interval,
+// until ctx is done.
+func runProjectPurge(ctx context.Context, st store.ProjectStore, retention, auditRetention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purgeDeletedProjects(ctx, st, retention)
+		if auditRetention > 0 {
+			purgeAuditLog(ctx, st, auditRetention)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purgeDeletedProjects(ctx context.Context, st store.ProjectStore, retention time.Duration) {
+	purgeCtx := store.WithActor(store.AllWorkspaces(ctx), purgeActor, "")
+	n, err := st.PurgeDeletedProjects(purgeCtx, time.Now().UTC().Add(-retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("ERROR: purge deleted projects: %v", err)
+		}
+		return
+	}
+	if n > 0 {
+		log.Printf("INFO: purged %d deleted project(s) older than %s", n, retention)
+	}
+}
+
+func purgeAuditLog(ctx context.Context, st store.ProjectStore, retention time.Duration) {
+	n, err := st.PurgeAuditEntries(ctx, time.Now().UTC().Add(-retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("ERROR: purge audit log: %v", err)
+		}
+		return
+	}
+	if n > 0 {
+		log.Printf("INFO: purged %d audit entr(ies) older than %s", n, retention)
+	}
+}
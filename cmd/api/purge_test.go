@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+func TestPurgeDeletedProjects_RespectsRetention(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemoryStore()
+
+	p, err := st.InsertProject(ctx, store.NewProject{Name: "Alpha"})
+	if err != nil {
+		t.Fatalf("InsertProject: %v", err)
+	}
+	if err := st.DeleteProject(ctx, p.ID); err != nil {
+		t.Fatalf("DeleteProject: %v", err)
+	}
+
+	// Still within retention: kept and restorable.
+	purgeDeletedProjects(ctx, st, time.Hour)
+	if _, err := st.RestoreProject(ctx, p.ID); err != nil {
+		t.Fatalf("expected project to survive purge within retention; got %v", err)
+	}
+
+	if err := st.DeleteProject(ctx, p.ID); err != nil {
+		t.Fatalf("DeleteProject: %v", err)
+	}
+	purgeDeletedProjects(ctx, st, 0)
+	if _, err := st.RestoreProject(ctx, p.ID); err != store.ErrNotFound {
+		t.Fatalf("expected purged project to be gone; got %v", err)
+	}
+}
+
+func TestRunProjectPurge_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		runProjectPurge(ctx, store.NewMemoryStore(), time.Hour, time.Hour, time.Hour)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("runProjectPurge did not return after cancel")
+	}
+}
+
+func TestPurgeAuditLog_RespectsRetention(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemoryStore()
+
+	if _, err := st.InsertProject(ctx, store.NewProject{Name: "Alpha"}); err != nil {
+		t.Fatalf("InsertProject: %v", err)
+	}
+
+	purgeAuditLog(ctx, st, time.Hour)
+	if _, total, _ := st.ListAuditEntries(ctx, store.ListAuditParams{Limit: 10}); total != 1 {
+		t.Fatalf("expected the entry to survive purge within retention; got %d entries", total)
+	}
+
+	time.Sleep(time.Millisecond)
+	purgeAuditLog(ctx, st, time.Nanosecond)
+	if _, total, _ := st.ListAuditEntries(ctx, store.ListAuditParams{Limit: 10}); total != 0 {
+		t.Fatalf("expected the entry to be purged; got %d entries", total)
+	}
+}
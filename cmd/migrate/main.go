@@ -2,15 +2,127 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"strconv"
+	"text/tabwriter"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/linus5304/project-manager-api/internal/store/migrations"
 )
 
+const usage = `usage: migrate [--dry-run] [--dir DIR] <command> [args]
+
+commands:
+  up            apply all pending migrations (default)
+  down [N]      roll back the N most recent migrations (default 1)
+  goto V        migrate up or down to version V (0 rolls back everything)
+  force V       record versions <= V as applied without running SQL
+  status        list applied and pending migrations
+  create NAME   scaffold the next numbered up/down pair in DIR
+`
+
+type options struct {
+	command string
+	n       int   // down
+	version int64 // goto, force
+	name    string
+	dryRun  bool
+	dir     string
+}
+
+func parseArgs(args []string) (options, error) {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	dryRun := fs.Bool("dry-run", false, "print the SQL that would run without executing it")
+	dir := fs.String("dir", "internal/store/migrations", "migrations directory for create")
+
+	// Allow flags both before and after the command
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return options{}, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	opts := options{command: "up", dryRun: *dryRun, dir: *dir}
+	if len(positional) > 0 {
+		opts.command = positional[0]
+		positional = positional[1:]
+	}
+
+	wantArgs := func(min, max int) error {
+		if len(positional) < min || len(positional) > max {
+			return fmt.Errorf("%s: wrong number of arguments", opts.command)
+		}
+		return nil
+	}
+
+	switch opts.command {
+	case "up", "status":
+		if err := wantArgs(0, 0); err != nil {
+			return options{}, err
+		}
+	case "down":
+		if err := wantArgs(0, 1); err != nil {
+			return options{}, err
+		}
+		opts.n = 1
+		if len(positional) == 1 {
+			n, err := strconv.Atoi(positional[0])
+			if err != nil || n < 1 {
+				return options{}, errors.New("down: N must be a positive integer")
+			}
+			opts.n = n
+		}
+	case "goto", "force":
+		if err := wantArgs(1, 1); err != nil {
+			return options{}, err
+		}
+		v, err := strconv.ParseInt(positional[0], 10, 64)
+		if err != nil || v < 0 {
+			return options{}, fmt.Errorf("%s: V must be a non-negative version number", opts.command)
+		}
+		opts.version = v
+	case "create":
+		if err := wantArgs(1, 1); err != nil {
+			return options{}, err
+		}
+		opts.name = positional[0]
+	default:
+		return options{}, fmt.Errorf("unknown command %q", opts.command)
+	}
+
+	return opts, nil
+}
+
 func main() {
+	opts, err := parseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprint(os.Stderr, usage)
+		log.Fatalf("%v", err)
+	}
+
+	if opts.command == "create" {
+		up, down, err := migrations.Create(opts.dir, opts.name)
+		if err != nil {
+			log.Fatalf("create migration: %v", err)
+		}
+		log.Printf("created %s", up)
+		log.Printf("created %s", down)
+		return
+	}
+
 	dsn := os.Getenv("DATABASE_URL")
 	if dsn == "" {
 		log.Fatalf("DATABASE_URL is required")
@@ -25,9 +137,48 @@ func main() {
 	}
 	defer pool.Close()
 
-	if err := migrations.Apply(ctx, pool); err != nil {
-		log.Fatalf("apply migrations: %v", err)
+	runner, err := migrations.NewRunner(pool)
+	if err != nil {
+		log.Fatalf("load migrations: %v", err)
+	}
+	runner.Out = os.Stdout
+	runner.DryRun = opts.dryRun
+
+	switch opts.command {
+	case "up":
+		err = runner.Up(ctx)
+	case "down":
+		err = runner.Down(ctx, opts.n)
+	case "goto":
+		err = runner.To(ctx, opts.version)
+	case "force":
+		err = runner.Force(ctx, opts.version)
+	case "status":
+		err = printStatus(ctx, os.Stdout, runner)
+	}
+	if err != nil {
+		log.Fatalf("%s: %v", opts.command, err)
+	}
+
+	if opts.command != "status" && !opts.dryRun {
+		log.Printf("migrate %s: done", opts.command)
+	}
+}
+
+func printStatus(ctx context.Context, out io.Writer, runner *migrations.Runner) error {
+	statuses, err := runner.Status(ctx)
+	if err != nil {
+		return err
 	}
 
-	log.Printf("migrations applied")
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+	for _, st := range statuses {
+		appliedAt := "-"
+		if st.AppliedAt != nil {
+			appliedAt = st.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%06d\t%s\t%s\t%s\n", st.Version, st.Name, st.State, appliedAt)
+	}
+	return tw.Flush()
 }
@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want options
+	}{
+		{nil, options{command: "up", dir: "internal/store/migrations"}},
+		{[]string{"status"}, options{command: "status", dir: "internal/store/migrations"}},
+		{[]string{"down"}, options{command: "down", n: 1, dir: "internal/store/migrations"}},
+		{[]string{"down", "3", "--dry-run"}, options{command: "down", n: 3, dryRun: true, dir: "internal/store/migrations"}},
+		{[]string{"--dry-run", "goto", "2"}, options{command: "goto", version: 2, dryRun: true, dir: "internal/store/migrations"}},
+		{[]string{"force", "0"}, options{command: "force", version: 0, dir: "internal/store/migrations"}},
+		{[]string{"create", "add_labels", "--dir", "/tmp/m"}, options{command: "create", name: "add_labels", dir: "/tmp/m"}},
+	}
+
+	for _, tt := range tests {
+		got, err := parseArgs(tt.args)
+		if err != nil {
+			t.Fatalf("parseArgs(%q): %v", tt.args, err)
+		}
+		if got != tt.want {
+			t.Fatalf("parseArgs(%q) = %+v; want %+v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestParseArgs_Errors(t *testing.T) {
+	for _, args := range [][]string{
+		{"sideways"},
+		{"down", "0"},
+		{"down", "1", "2"},
+		{"goto"},
+		{"goto", "-1"},
+		{"force", "abc"},
+		{"create"},
+		{"status", "extra"},
+		{"--bogus"},
+	} {
+		if _, err := parseArgs(args); err == nil {
+			t.Errorf("parseArgs(%q): expected error", args)
+		}
+	}
+}
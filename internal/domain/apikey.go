@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey is a credential for calling the API. Only a hash of the key is
+// kept; Prefix holds its first characters so people can tell keys apart.
+type APIKey struct {
+	ID     uuid.UUID `json:"id"`
+	Name   string    `json:"name"`
+	Prefix string    `json:"prefix"`
+	Hash   []byte    `json:"-"`
+	// Admin keys may also manage API keys and organizations.
+	Admin bool `json:"admin"`
+	// WorkspaceID pins the key to one workspace; keys without one, which
+	// only admin keys can be, may pick any workspace.
+	WorkspaceID *uuid.UUID `json:"workspaceId,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	RevokedAt   *time.Time `json:"revokedAt,omitempty"`
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit actions.
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// Audited entities. Label attachments and task dependencies are recorded
+// against the task, and workflows and members against the project.
+const (
+	AuditProject        = "project"
+	AuditWorkflow       = "workflow"
+	AuditMember         = "member"
+	AuditTemplate       = "template"
+	AuditTask           = "task"
+	AuditTaskLabel      = "task_label"
+	AuditTaskDependency = "task_dependency"
+	AuditLabel          = "label"
+	AuditComment        = "comment"
+	AuditUser           = "user"
+	AuditAPIKey         = "api_key"
+	AuditOrganization   = "organization"
+)
+
+var auditEntities = map[string]bool{
+	AuditProject:        true,
+	AuditWorkflow:       true,
+	AuditMember:         true,
+	AuditTemplate:       true,
+	AuditTask:           true,
+	AuditTaskLabel:      true,
+	AuditTaskDependency: true,
+	AuditLabel:          true,
+	AuditComment:        true,
+	AuditUser:           true,
+	AuditAPIKey:         true,
+	AuditOrganization:   true,
+}
+
+// ValidAuditEntity reports whether entity is one of the audited entities.
+func ValidAuditEntity(entity string) bool {
+	return auditEntities[entity]
+}
+
+// AuditEntry records one change made through the store. Before holds the
+// fields an update or delete changed as they were, and After those a create
+// or update changed as they became.
+type AuditEntry struct {
+	ID          int64     `json:"id"`
+	WorkspaceID uuid.UUID `json:"workspaceId"`
+	// Actor is the subject the change was made as, or empty when that is
+	// unknown, as when authentication is off.
+	Actor     string         `json:"actor"`
+	RequestID string         `json:"requestId,omitempty"`
+	Action    string         `json:"action"`
+	Entity    string         `json:"entity"`
+	EntityID  string         `json:"entityId"`
+	Before    map[string]any `json:"before,omitempty"`
+	After     map[string]any `json:"after,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Comment is a message on a task. Replies point at the comment they answer
+// through ParentID; UpdatedAt is set once the body has been edited.
+type Comment struct {
+	ID        uuid.UUID  `json:"id"`
+	TaskID    uuid.UUID  `json:"taskId"`
+	ParentID  *uuid.UUID `json:"parentId,omitempty"`
+	AuthorID  uuid.UUID  `json:"authorId"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Project roles, from least to most privileged. Viewers can read a project,
+// editors can also change it and its tasks, and owners can also delete,
+// archive and restore it and manage its members.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleOwner  = "owner"
+)
+
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// ValidRole reports whether role is one of the project roles.
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAllows reports whether a member with role has at least the
+// permissions of want.
+func RoleAllows(role, want string) bool {
+	return roleRanks[role] > 0 && roleRanks[role] >= roleRanks[want]
+}
+
+// ProjectMember gives a caller, identified by the subject they authenticate
+// as, a role on a project.
+type ProjectMember struct {
+	ProjectID uuid.UUID `json:"projectId"`
+	Subject   string    `json:"subject"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultWorkspaceID is the organization that holds everything created
+// before workspaces existed, and everything created by callers not tied to
+// another workspace.
+var DefaultWorkspaceID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// Organization is a tenant of the deployment. Its ID is the workspace ID
+// that projects and templates belong to; nothing in one workspace is visible
+// from another.
+type Organization struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -1,13 +1,34 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Project struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID          uuid.UUID  `json:"id"`
+	WorkspaceID uuid.UUID  `json:"workspaceId"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	OwnerID     *uuid.UUID `json:"ownerId,omitempty"`
+	Color       string     `json:"color,omitempty"`
+	Icon        string     `json:"icon,omitempty"`
+	// Key is the project's short code, such as "OPS". Its tasks are then
+	// known as OPS-1, OPS-2 and so on.
+	Key        string     `json:"key,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+	DeletedAt  *time.Time `json:"deletedAt,omitempty"`
+}
+
+// TaskIdentifier is the human-readable name of a task in a project with a
+// key, such as OPS-42.
+func TaskIdentifier(key string, number int64) string {
+	if key == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s-%d", key, number)
 }
@@ -7,10 +7,33 @@ import (
 )
 
 type Task struct {
-	ID          uuid.UUID `json:"id"`
-	ProjectID   uuid.UUID `json:"projectId"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"createdAt"`
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"projectId"`
+	// Number is the task's sequence number within its project; Identifier
+	// prefixes it with the project key (OPS-42) when the project has one.
+	Number      int64  `json:"number"`
+	Identifier  string `json:"identifier,omitempty"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	// StatusCategory is the workflow category of Status.
+	StatusCategory string     `json:"statusCategory"`
+	AssigneeID     *uuid.UUID `json:"assigneeId,omitempty"`
+	DueDate        *time.Time `json:"dueDate,omitempty"`
+	Priority       string     `json:"priority"`
+	Labels         []Label    `json:"labels"`
+	// ParentTaskID is set on subtasks; Subtasks rolls up a task's own subtasks.
+	ParentTaskID *uuid.UUID    `json:"parentTaskId,omitempty"`
+	Subtasks     SubtaskRollup `json:"subtasks"`
+	// Position orders the task within its status column on the board.
+	Position float64 `json:"position"`
+	// BlockedBy lists the tasks that must be done before this one can start.
+	BlockedBy []uuid.UUID `json:"blockedBy"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// SubtaskRollup counts a task's direct subtasks and how many of them are done.
+type SubtaskRollup struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
 }
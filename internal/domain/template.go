@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Template is a project skeleton: the name of the project it creates and
+// the tasks the project starts with.
+type Template struct {
+	ID          uuid.UUID       `json:"id"`
+	WorkspaceID uuid.UUID       `json:"workspaceId"`
+	Name        string          `json:"name"`
+	Tasks       []TaskBlueprint `json:"tasks"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+// TaskBlueprint is a task a template creates, in the project's first status.
+type TaskBlueprint struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Priority    string `json:"priority"`
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "slices"
+
+// Status categories. Every workflow status belongs to one, and the rest of the
+// system (rollups, blockers, overdue listings) only looks at the category.
+const (
+	StatusCategoryTodo       = "todo"
+	StatusCategoryInProgress = "in_progress"
+	StatusCategoryDone       = "done"
+)
+
+type WorkflowStatus struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+// Workflow is a project's ordered list of task statuses. New tasks start in the
+// first one. Transitions, when set, lists the statuses each status may move
+// to; a status without an entry may move to any other.
+type Workflow struct {
+	Statuses    []WorkflowStatus    `json:"statuses"`
+	Transitions map[string][]string `json:"transitions,omitempty"`
+}
+
+// DefaultWorkflow is used by projects that have not defined their own.
+func DefaultWorkflow() Workflow {
+	return Workflow{
+		Statuses: []WorkflowStatus{
+			{Name: "todo", Category: StatusCategoryTodo},
+			{Name: "doing", Category: StatusCategoryInProgress},
+			{Name: "done", Category: StatusCategoryDone},
+		},
+	}
+}
+
+// Status looks up a status by name.
+func (w Workflow) Status(name string) (WorkflowStatus, bool) {
+	for _, s := range w.Statuses {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return WorkflowStatus{}, false
+}
+
+// Allows reports whether a task may move from one status to another.
+// Staying in the same status is always allowed.
+func (w Workflow) Allows(from, to string) bool {
+	if from == to {
+		return true
+	}
+	next, ok := w.Transitions[from]
+	return !ok || slices.Contains(next, to)
+}
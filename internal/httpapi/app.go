@@ -4,10 +4,22 @@ import "github.com/linus5304/project-manager-api/internal/store"
 
 type Application struct {
 	store store.ProjectStore
+	auth  AuthConfig
 }
 
-func NewApplication(store store.ProjectStore) *Application {
+// AuthConfig says how requests are authenticated. With Enabled unset every
+// endpoint is open. AdminKey, when set, is an admin API key that works
+// without being stored, so the first keys can be created. Bearer, when set,
+// also accepts "Authorization: Bearer" tokens.
+type AuthConfig struct {
+	Enabled  bool
+	AdminKey string
+	Bearer   TokenVerifier
+}
+
+func NewApplication(store store.ProjectStore, auth AuthConfig) *Application {
 	return &Application{
 		store: store,
+		auth:  auth,
 	}
 }
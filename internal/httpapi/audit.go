@@ -0,0 +1,70 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/linus5304/project-manager-api/internal/domain"
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+// actorMiddleware attributes the changes a request makes to its caller and
+// request ID in the audit log.
+func (app *Application) actorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, _ := getIdentity(r)
+		ctx := store.WithActor(r.Context(), id.Subject, getRequestID(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (app *Application) listAuditEntries(w http.ResponseWriter, r *http.Request) {
+	page, err := readIntQuery(r, "page", 1)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	pageSize, err := readIntQuery(r, "page_size", 20)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := validatePageParams(page, pageSize); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	params := store.ListAuditParams{
+		Limit:    pageSize,
+		Offset:   (page - 1) * pageSize,
+		Entity:   strings.TrimSpace(q.Get("entity")),
+		EntityID: strings.TrimSpace(q.Get("id")),
+	}
+	if params.Entity != "" && !domain.ValidAuditEntity(params.Entity) {
+		badRequestResponse(w, r, errors.New("entity is invalid"))
+		return
+	}
+	if params.EntityID != "" && params.Entity == "" {
+		badRequestResponse(w, r, errors.New("id requires entity"))
+		return
+	}
+
+	entries, total, err := app.store.ListAuditEntries(r.Context(), params)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, map[string]any{
+		"entries": entries,
+		"metadata": metadata{
+			Page:         page,
+			PageSize:     pageSize,
+			TotalRecords: total,
+		},
+	}, nil)
+}
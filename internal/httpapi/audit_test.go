@@ -0,0 +1,38 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAudit_RecordsTaskChanges(t *testing.T) {
+	ts := newRBACTestServer(t)
+	admin := http.Header{"X-Api-Key": {testAdminKey}}
+	alice := http.Header{
+		"Authorization": {"Bearer " + signTestJWT(t, testHMACSecret, "", testClaims(map[string]any{"sub": "alice"}))},
+		"X-Request-Id":  {"req-42"},
+	}
+
+	created := doRequest(t, http.MethodPost, ts.URL+"/v1/projects", alice, `{"name": "Alpha"}`, http.StatusCreated)
+	projectURL := ts.URL + "/v1/projects/" + created["id"].(string)
+	task := doRequest(t, http.MethodPost, projectURL+"/tasks", alice, `{"title": "T1"}`, http.StatusCreated)
+	taskID := task["id"].(string)
+	doRequest(t, http.MethodPatch, projectURL+"/tasks/"+taskID, alice, `{"status": "done"}`, http.StatusOK)
+
+	got := doRequest(t, http.MethodGet, ts.URL+"/v1/audit?entity=task&id="+taskID+"&page_size=1", admin, "", http.StatusOK)
+	entries := got["entries"].([]any)
+	if len(entries) != 1 || got["metadata"].(map[string]any)["totalRecords"] != float64(2) {
+		t.Fatalf("expected the newest of 2 entries; got %#v", got)
+	}
+	e := entries[0].(map[string]any)
+	if e["action"] != "update" || e["actor"] != "alice" || e["requestId"] != "req-42" {
+		t.Fatalf("unexpected entry: %#v", e)
+	}
+	if e["before"].(map[string]any)["status"] != "todo" || e["after"].(map[string]any)["status"] != "done" {
+		t.Fatalf("unexpected diff: %#v", e)
+	}
+
+	doRequest(t, http.MethodGet, ts.URL+"/v1/audit?entity=widget", admin, "", http.StatusBadRequest)
+	doRequest(t, http.MethodGet, ts.URL+"/v1/audit?id="+taskID, admin, "", http.StatusBadRequest)
+	doRequest(t, http.MethodGet, ts.URL+"/v1/audit", alice, "", http.StatusForbidden)
+}
@@ -0,0 +1,236 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/domain"
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+// apiKeyHeader carries the API key on every authenticated request.
+const apiKeyHeader = "X-API-Key"
+
+// apiKeyPrefix starts every generated key, so leaked keys are easy to spot.
+const apiKeyPrefix = "pmk_"
+
+// apiKeyDisplayLength is how much of a key is kept in the clear as its prefix.
+const apiKeyDisplayLength = len(apiKeyPrefix) + 8
+
+// openPaths are served without authentication so load balancers and
+// orchestrators can probe them.
+var openPaths = map[string]bool{
+	"/livez":   true,
+	"/readyz":  true,
+	"/healthz": true,
+}
+
+// hashAPIKey is how keys are stored and looked up. Keys are 256 random bits,
+// so a fast unsalted hash is enough.
+func hashAPIKey(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+	return sum[:]
+}
+
+func generateAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// apiKeySubjectPrefix starts the subject of every API key. JWT subjects may
+// not use it, so a token cannot pass for a key.
+const apiKeySubjectPrefix = "api-key:"
+
+// Identity is the authenticated caller of a request.
+type Identity struct {
+	// Subject is the JWT "sub" claim, or "api-key:<id>" for API keys.
+	Subject string
+	// Admin callers may also manage API keys and organizations, and pick any
+	// workspace.
+	Admin bool
+	// WorkspaceID pins the caller to a workspace: the JWT "workspace_id"
+	// claim or the API key's workspace.
+	WorkspaceID *uuid.UUID
+}
+
+// getIdentity returns the caller the request was authenticated as.
+func getIdentity(r *http.Request) (Identity, bool) {
+	id, ok := r.Context().Value(identityKey).(Identity)
+	return id, ok
+}
+
+// authenticateMiddleware rejects requests without a valid bearer token in the
+// Authorization header or a valid, unrevoked API key in the X-API-Key header,
+// except to the health endpoints.
+func (app *Application) authenticateMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !app.auth.Enabled || openPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var id Identity
+		if token, ok := bearerToken(r); ok {
+			if app.auth.Bearer == nil {
+				unauthorizedResponse(w, r, "bearer tokens are not accepted; use the "+apiKeyHeader+" header")
+				return
+			}
+			var err error
+			if id, err = app.auth.Bearer.VerifyToken(token); err != nil {
+				unauthorizedResponse(w, r, "invalid bearer token: "+err.Error())
+				return
+			}
+		} else {
+			key := strings.TrimSpace(r.Header.Get(apiKeyHeader))
+			if key == "" {
+				msg := "an API key is required in the " + apiKeyHeader + " header"
+				if app.auth.Bearer != nil {
+					msg = "a bearer token or an API key in the " + apiKeyHeader + " header is required"
+				}
+				unauthorizedResponse(w, r, msg)
+				return
+			}
+
+			hash := hashAPIKey(key)
+			if app.auth.AdminKey != "" && subtle.ConstantTimeCompare(hash, hashAPIKey(app.auth.AdminKey)) == 1 {
+				id = Identity{Subject: apiKeySubjectPrefix + "admin", Admin: true}
+			} else {
+				k, err := app.store.GetAPIKeyByHash(r.Context(), hash)
+				if err != nil && !errors.Is(err, store.ErrAPIKeyNotFound) {
+					serverErrorResponse(w, r, err)
+					return
+				}
+				if err != nil || k.RevokedAt != nil {
+					unauthorizedResponse(w, r, "invalid or revoked API key")
+					return
+				}
+				id = Identity{Subject: apiKeySubjectPrefix + k.ID.String(), Admin: k.Admin, WorkspaceID: k.WorkspaceID}
+			}
+		}
+
+		// Let logRequestMiddleware, which runs outside us, log the subject
+		if sr, ok := w.(*statusRecorder); ok {
+			sr.subject = id.Subject
+		}
+
+		ctx := context.WithValue(r.Context(), identityKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// requireAdmin only lets admin callers through to next.
+func (app *Application) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if app.auth.Enabled {
+			if id, ok := getIdentity(r); !ok || !id.Admin {
+				forbiddenResponse(w, r, "this endpoint requires an admin API key")
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+type createAPIKeyInput struct {
+	Name  string `json:"name"`
+	Admin bool   `json:"admin"`
+}
+
+// createdAPIKey is the only response that includes the key itself.
+type createdAPIKey struct {
+	domain.APIKey
+	Key string `json:"key"`
+}
+
+func (app *Application) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	var input createAPIKeyInput
+	if err := readJSON(w, r, &input); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		badRequestResponse(w, r, errors.New("name is required"))
+		return
+	}
+
+	key, err := generateAPIKey()
+	if err != nil {
+		serverErrorResponse(w, r, fmt.Errorf("generate api key: %w", err))
+		return
+	}
+
+	// Only admin keys may pick a workspace; the rest stay in the one they
+	// were created in
+	var workspaceID *uuid.UUID
+	if !input.Admin {
+		ws := store.Workspace(r.Context())
+		workspaceID = &ws
+	}
+
+	k, err := app.store.InsertAPIKey(r.Context(), store.NewAPIKey{
+		Name:        input.Name,
+		Prefix:      key[:apiKeyDisplayLength],
+		Hash:        hashAPIKey(key),
+		Admin:       input.Admin,
+		WorkspaceID: workspaceID,
+	})
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusCreated, createdAPIKey{APIKey: k, Key: key}, nil)
+}
+
+func (app *Application) listAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := app.store.ListAPIKeys(r.Context())
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, map[string]any{"apiKeys": keys}, nil)
+}
+
+// revokeAPIKey returns 204 even when the key was already revoked.
+func (app *Application) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid api key id"))
+		return
+	}
+
+	if _, err := app.store.RevokeAPIKey(r.Context(), id); err != nil {
+		if errors.Is(err, store.ErrAPIKeyNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
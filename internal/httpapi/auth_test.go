@@ -0,0 +1,89 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+const testAdminKey = "test-admin-key"
+
+func newAuthTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	app := NewApplication(store.NewMemoryStore(), AuthConfig{Enabled: true, AdminKey: testAdminKey})
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// doAuthJSON is doJSON with an X-API-Key header; an empty key sends none.
+func doAuthJSON(t *testing.T, method, url, key, body string, wantStatus int) map[string]any {
+	t.Helper()
+	var header http.Header
+	if key != "" {
+		header = http.Header{"X-Api-Key": {key}}
+	}
+	return doRequest(t, method, url, header, body, wantStatus)
+}
+
+func TestAuth_RequiresAPIKey(t *testing.T) {
+	ts := newAuthTestServer(t)
+
+	got := doAuthJSON(t, http.MethodGet, ts.URL+"/v1/projects", "", "", http.StatusUnauthorized)
+	if e, _ := got["error"].(map[string]any); e["message"] == "" {
+		t.Fatalf("expected error envelope; got %#v", got)
+	}
+	doAuthJSON(t, http.MethodGet, ts.URL+"/v1/projects", "pmk_wrong", "", http.StatusUnauthorized)
+	doAuthJSON(t, http.MethodGet, ts.URL+"/v1/projects", testAdminKey, "", http.StatusOK)
+
+	for _, path := range []string{"/livez", "/readyz", "/healthz"} {
+		res, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: expected 200 without a key; got %d", path, res.StatusCode)
+		}
+	}
+}
+
+func TestAuth_ManageAPIKeys(t *testing.T) {
+	ts := newAuthTestServer(t)
+
+	created := doAuthJSON(t, http.MethodPost, ts.URL+"/v1/api-keys", testAdminKey, `{"name": "ci"}`, http.StatusCreated)
+	key, _ := created["key"].(string)
+	if !strings.HasPrefix(key, "pmk_") || !strings.HasPrefix(key, created["prefix"].(string)) || created["admin"] != false {
+		t.Fatalf("unexpected created key: %#v", created)
+	}
+	if _, ok := created["hash"]; ok {
+		t.Fatalf("hash must not be returned: %#v", created)
+	}
+
+	// A regular key can use the API but not manage keys
+	doAuthJSON(t, http.MethodPost, ts.URL+"/v1/projects", key, `{"name": "Alpha"}`, http.StatusCreated)
+	doAuthJSON(t, http.MethodGet, ts.URL+"/v1/api-keys", key, "", http.StatusForbidden)
+	doAuthJSON(t, http.MethodPost, ts.URL+"/v1/api-keys", key, `{"name": "mine"}`, http.StatusForbidden)
+
+	admin := doAuthJSON(t, http.MethodPost, ts.URL+"/v1/api-keys", testAdminKey, `{"name": "ops", "admin": true}`, http.StatusCreated)
+	list := doAuthJSON(t, http.MethodGet, ts.URL+"/v1/api-keys", admin["key"].(string), "", http.StatusOK)
+	keys, _ := list["apiKeys"].([]any)
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys; got %#v", list)
+	}
+	if first, _ := keys[0].(map[string]any); first["name"] != "ci" || first["key"] != nil {
+		t.Fatalf("unexpected listed key: %#v", keys[0])
+	}
+
+	doAuthJSON(t, http.MethodPost, ts.URL+"/v1/api-keys", testAdminKey, `{"name": " "}`, http.StatusBadRequest)
+
+	id := created["id"].(string)
+	doAuthJSON(t, http.MethodDelete, ts.URL+"/v1/api-keys/"+id, testAdminKey, "", http.StatusNoContent)
+	doAuthJSON(t, http.MethodDelete, ts.URL+"/v1/api-keys/"+id, testAdminKey, "", http.StatusNoContent)
+	doAuthJSON(t, http.MethodGet, ts.URL+"/v1/projects", key, "", http.StatusUnauthorized)
+	doAuthJSON(t, http.MethodDelete, ts.URL+"/v1/api-keys/00000000-0000-0000-0000-000000000001", testAdminKey, "", http.StatusNotFound)
+	doAuthJSON(t, http.MethodDelete, ts.URL+"/v1/api-keys/nope", testAdminKey, "", http.StatusBadRequest)
+}
@@ -0,0 +1,130 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/domain"
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+type moveTaskInput struct {
+	Status   *string `json:"status"`
+	AfterID  *string `json:"afterId"`
+	BeforeID *string `json:"beforeId"`
+
+	// TargetProjectID moves the task to another project instead.
+	TargetProjectID *string `json:"targetProjectId"`
+}
+
+// boardColumn is one workflow status and its tasks in board order.
+type boardColumn struct {
+	Status   string        `json:"status"`
+	Category string        `json:"category"`
+	Tasks    []domain.Task `json:"tasks"`
+}
+
+// readNeighbourID parses an optional afterId/beforeId.
+func readNeighbourID(field string, s *string) (*uuid.UUID, error) {
+	if s == nil {
+		return nil, nil
+	}
+	id, err := uuid.Parse(strings.TrimSpace(*s))
+	if err != nil {
+		return nil, errors.New(field + " must be a valid UUID")
+	}
+	return &id, nil
+}
+
+func (app *Application) moveTask(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, err := readTaskPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	var input moveTaskInput
+	if err := readJSON(w, r, &input); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.TargetProjectID != nil {
+		if input.Status != nil || input.AfterID != nil || input.BeforeID != nil {
+			badRequestResponse(w, r, errors.New("targetProjectId cannot be combined with status, afterId or beforeId"))
+			return
+		}
+		app.transferTask(w, r, projectID, taskID, *input.TargetProjectID)
+		return
+	}
+
+	var move store.TaskMove
+	if input.Status != nil {
+		s := strings.TrimSpace(*input.Status)
+		if s == "" {
+			badRequestResponse(w, r, errors.New("status cannot be empty"))
+			return
+		}
+		move.Status = &s
+	}
+	if move.AfterID, err = readNeighbourID("afterId", input.AfterID); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+	if move.BeforeID, err = readNeighbourID("beforeId", input.BeforeID); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	moved, err := app.store.MoveTask(r.Context(), projectID, taskID, move)
+	if err != nil {
+		if errors.Is(err, store.ErrMoveTargetInvalid) {
+			badRequestResponse(w, r, err)
+			return
+		}
+		if errors.Is(err, store.ErrMoveConflict) {
+			errorResponse(w, r, http.StatusConflict, err.Error())
+			return
+		}
+		app.statusChangeErrorResponse(w, r, projectID, taskID, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, moved, nil)
+}
+
+func (app *Application) getBoard(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid project id"))
+		return
+	}
+
+	workflow, err := app.store.GetWorkflow(r.Context(), projectID)
+	if err != nil {
+		taskErrorResponse(w, r, err)
+		return
+	}
+	tasks, err := app.store.ListBoardTasks(r.Context(), projectID)
+	if err != nil {
+		taskErrorResponse(w, r, err)
+		return
+	}
+
+	// Columns follow the workflow's status order, empty ones included.
+	columns := make([]boardColumn, len(workflow.Statuses))
+	index := make(map[string]int, len(workflow.Statuses))
+	for i, s := range workflow.Statuses {
+		columns[i] = boardColumn{Status: s.Name, Category: s.Category, Tasks: []domain.Task{}}
+		index[s.Name] = i
+	}
+	for _, t := range tasks {
+		if i, ok := index[t.Status]; ok {
+			columns[i].Tasks = append(columns[i].Tasks, t)
+		}
+	}
+
+	_ = writeJSON(w, http.StatusOK, map[string]any{"columns": columns}, nil)
+}
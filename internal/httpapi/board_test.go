@@ -0,0 +1,79 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBoard_MoveAndList(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	tasksURL := ts.URL + "/v1/projects/" + pid + "/tasks"
+	boardURL := ts.URL + "/v1/projects/" + pid + "/board"
+
+	a := createTask(t, ts, pid, "a", "")["id"].(string)
+	b := createTask(t, ts, pid, "b", "")["id"].(string)
+	c := createTask(t, ts, pid, "c", "")["id"].(string)
+
+	columnTitles := func(status string) []string {
+		t.Helper()
+		board := getJSON(t, boardURL, http.StatusOK)
+		for _, col := range board["columns"].([]any) {
+			col := col.(map[string]any)
+			if col["status"] != status {
+				continue
+			}
+			titles := []string{}
+			for _, task := range col["tasks"].([]any) {
+				titles = append(titles, task.(map[string]any)["title"].(string))
+			}
+			return titles
+		}
+		t.Fatalf("no %q column on the board", status)
+		return nil
+	}
+
+	board := getJSON(t, boardURL, http.StatusOK)
+	if cols := board["columns"].([]any); len(cols) != 3 || cols[1].(map[string]any)["status"] != "doing" {
+		t.Fatalf("expected one column per workflow status; got %#v", board)
+	}
+	if got := columnTitles("todo"); len(got) != 3 || got[0] != "a" || got[2] != "c" {
+		t.Fatalf("expected a, b, c; got %v", got)
+	}
+
+	moved := doJSON(t, http.MethodPost, tasksURL+"/"+c+"/move", `{"afterId": "`+a+`", "beforeId": "`+b+`"}`, http.StatusOK)
+	if moved["status"] != "todo" {
+		t.Fatalf("unexpected move result: %#v", moved)
+	}
+	if got := columnTitles("todo"); got[0] != "a" || got[1] != "c" || got[2] != "b" {
+		t.Fatalf("expected a, c, b; got %v", got)
+	}
+
+	moved = doJSON(t, http.MethodPost, tasksURL+"/"+b+"/move", `{"status": "doing"}`, http.StatusOK)
+	if moved["status"] != "doing" || moved["statusCategory"] != "in_progress" {
+		t.Fatalf("expected b in doing; got %#v", moved)
+	}
+	doJSON(t, http.MethodPost, tasksURL+"/"+a+"/move", `{"status": "doing", "beforeId": "`+b+`"}`, http.StatusOK)
+	if got := columnTitles("doing"); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Fatalf("expected a, b in doing; got %v", got)
+	}
+	if got := columnTitles("todo"); len(got) != 1 || got[0] != "c" {
+		t.Fatalf("expected only c left in todo; got %v", got)
+	}
+
+	// Stale neighbours: a and b are adjacent, but the other way round
+	doJSON(t, http.MethodPost, tasksURL+"/"+c+"/move", `{"status": "doing", "afterId": "`+b+`", "beforeId": "`+a+`"}`, http.StatusConflict)
+	// Neighbour in another column
+	doJSON(t, http.MethodPost, tasksURL+"/"+c+"/move", `{"afterId": "`+a+`"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, tasksURL+"/"+c+"/move", `{"afterId": "nope"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, tasksURL+"/"+c+"/move", `{"status": "blocked"}`, http.StatusBadRequest)
+
+	missing := "00000000-0000-0000-0000-000000000001"
+	doJSON(t, http.MethodPost, tasksURL+"/"+missing+"/move", `{}`, http.StatusNotFound)
+	getJSON(t, ts.URL+"/v1/projects/"+missing+"/board", http.StatusNotFound)
+	getJSON(t, ts.URL+"/v1/projects/nope/board", http.StatusBadRequest)
+}
@@ -0,0 +1,204 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+type createCommentInput struct {
+	AuthorID string  `json:"authorId"`
+	ParentID *string `json:"parentId,omitempty"`
+	Body     string  `json:"body"`
+}
+
+type updateCommentInput struct {
+	Body string `json:"body"`
+}
+
+// readCommentPathIDs parses {projectId}, {taskId} and {commentId}.
+func readCommentPathIDs(r *http.Request) (projectID, taskID, commentID uuid.UUID, err error) {
+	projectID, taskID, err = readTaskPathIDs(r)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, err
+	}
+	commentID, err = uuid.Parse(r.PathValue("commentId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, errors.New("invalid comment id")
+	}
+	return projectID, taskID, commentID, nil
+}
+
+// commentErrorResponse maps store errors from comment lookups: a missing
+// project, task or comment is a 404.
+func commentErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, store.ErrCommentNotFound) {
+		notFoundResponse(w, r)
+		return
+	}
+	taskErrorResponse(w, r, err)
+}
+
+func (app *Application) createComment(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, err := readTaskPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	var input createCommentInput
+	if err := readJSON(w, r, &input); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	input.Body = strings.TrimSpace(input.Body)
+	if input.Body == "" {
+		badRequestResponse(w, r, errors.New("body is required"))
+		return
+	}
+
+	authorID, err := uuid.Parse(strings.TrimSpace(input.AuthorID))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("authorId must be a user id"))
+		return
+	}
+
+	var parentID *uuid.UUID
+	if input.ParentID != nil {
+		id, err := uuid.Parse(strings.TrimSpace(*input.ParentID))
+		if err != nil {
+			badRequestResponse(w, r, errors.New("invalid parentId"))
+			return
+		}
+		parentID = &id
+	}
+
+	c, err := app.store.InsertComment(r.Context(), projectID, taskID, store.NewComment{
+		AuthorID: authorID,
+		ParentID: parentID,
+		Body:     input.Body,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrUserNotFound):
+			badRequestResponse(w, r, errors.New("authorId does not match any user"))
+		case errors.Is(err, store.ErrCommentNotFound):
+			badRequestResponse(w, r, errors.New("parentId does not match a comment on this task"))
+		default:
+			taskErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	_ = writeJSON(w, http.StatusCreated, c, nil)
+}
+
+// listComments returns a task's comments oldest first. Replies carry a
+// parentId, so clients can rebuild the threads from a flat page.
+func (app *Application) listComments(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, err := readTaskPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	page, err := readIntQuery(r, "page", 1)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	pageSize, err := readIntQuery(r, "page_size", 20)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := validatePageParams(page, pageSize); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	comments, total, err := app.store.ListComments(r.Context(), projectID, taskID, store.ListCommentsParams{
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	})
+	if err != nil {
+		taskErrorResponse(w, r, err)
+		return
+	}
+
+	env := map[string]any{
+		"comments": comments,
+		"metadata": metadata{
+			Page:         page,
+			PageSize:     pageSize,
+			TotalRecords: total,
+		},
+	}
+
+	_ = writeJSON(w, http.StatusOK, env, nil)
+}
+
+func (app *Application) getComment(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, commentID, err := readCommentPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	c, err := app.store.GetComment(r.Context(), projectID, taskID, commentID)
+	if err != nil {
+		commentErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, c, nil)
+}
+
+func (app *Application) updateComment(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, commentID, err := readCommentPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	var input updateCommentInput
+	if err := readJSON(w, r, &input); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	input.Body = strings.TrimSpace(input.Body)
+	if input.Body == "" {
+		badRequestResponse(w, r, errors.New("body is required"))
+		return
+	}
+
+	c, err := app.store.UpdateComment(r.Context(), projectID, taskID, commentID, input.Body)
+	if err != nil {
+		commentErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, c, nil)
+}
+
+func (app *Application) deleteComment(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, commentID, err := readCommentPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.DeleteComment(r.Context(), projectID, taskID, commentID); err != nil {
+		commentErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
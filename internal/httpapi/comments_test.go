@@ -0,0 +1,74 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestComments_ThreadLifecycle(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	uid := createUser(t, ts, "Ada", "ada@example.com")
+	pid := createProject(t, ts, "Alpha")
+	task := createTask(t, ts, pid, "T1", "")
+	url := ts.URL + "/v1/projects/" + pid + "/tasks/" + task["id"].(string) + "/comments"
+
+	root := doJSON(t, http.MethodPost, url, `{"authorId": "`+uid+`", "body": " Looks good "}`, http.StatusCreated)
+	if root["body"] != "Looks good" || root["authorId"] != uid {
+		t.Fatalf("unexpected comment: %#v", root)
+	}
+	rootID := root["id"].(string)
+	reply := doJSON(t, http.MethodPost, url, `{"authorId": "`+uid+`", "parentId": "`+rootID+`", "body": "Thanks"}`, http.StatusCreated)
+	if reply["parentId"] != rootID {
+		t.Fatalf("expected reply to root; got %#v", reply)
+	}
+
+	env := getJSON(t, url+"?page_size=1", http.StatusOK)
+	md, _ := env["metadata"].(map[string]any)
+	if md["totalRecords"] != float64(2) || md["pageSize"] != float64(1) || md["page"] != float64(1) {
+		t.Fatalf("unexpected metadata: %#v", md)
+	}
+	items, _ := env["comments"].([]any)
+	if len(items) != 1 || items[0].(map[string]any)["id"] != rootID {
+		t.Fatalf("expected root comment first; got %#v", items)
+	}
+
+	edited := doJSON(t, http.MethodPatch, url+"/"+rootID, `{"body": "Looks great"}`, http.StatusOK)
+	if edited["body"] != "Looks great" || edited["updatedAt"] == nil {
+		t.Fatalf("unexpected edited comment: %#v", edited)
+	}
+
+	doJSON(t, http.MethodDelete, url+"/"+rootID, "", http.StatusNoContent)
+	getJSON(t, url+"/"+reply["id"].(string), http.StatusNotFound)
+	env = getJSON(t, url, http.StatusOK)
+	if md, _ := env["metadata"].(map[string]any); md["totalRecords"] != float64(0) {
+		t.Fatalf("expected thread deleted; got %#v", env)
+	}
+}
+
+func TestComments_Validation(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	uid := createUser(t, ts, "Ada", "ada@example.com")
+	pid := createProject(t, ts, "Alpha")
+	task := createTask(t, ts, pid, "T1", "")
+	url := ts.URL + "/v1/projects/" + pid + "/tasks/" + task["id"].(string) + "/comments"
+	missing := "00000000-0000-0000-0000-000000000001"
+
+	doJSON(t, http.MethodPost, url, `{"authorId": "`+uid+`", "body": "  "}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, url, `{"authorId": "nope", "body": "hi"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, url, `{"authorId": "`+missing+`", "body": "hi"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, url, `{"authorId": "`+uid+`", "parentId": "`+missing+`", "body": "hi"}`, http.StatusBadRequest)
+
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+pid+"/tasks/"+missing+"/comments", `{"authorId": "`+uid+`", "body": "hi"}`, http.StatusNotFound)
+	getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks/"+missing+"/comments", http.StatusNotFound)
+	getJSON(t, url+"/"+missing, http.StatusNotFound)
+	getJSON(t, url+"?page=0", http.StatusBadRequest)
+	doJSON(t, http.MethodPatch, url+"/"+missing, `{"body": "x"}`, http.StatusNotFound)
+	doJSON(t, http.MethodDelete, url+"/invalid-uuid", "", http.StatusBadRequest)
+}
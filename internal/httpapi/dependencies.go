@@ -0,0 +1,151 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/domain"
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+// readTaskBlockerPathIDs parses {projectId}, {taskId} and {blockerId}.
+func readTaskBlockerPathIDs(r *http.Request) (projectID, taskID, blockerID uuid.UUID, err error) {
+	projectID, taskID, err = readTaskPathIDs(r)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, err
+	}
+	blockerID, err = uuid.Parse(r.PathValue("blockerId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, errors.New("invalid blocker id")
+	}
+	return projectID, taskID, blockerID, nil
+}
+
+func (app *Application) listTaskBlockers(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, err := readTaskPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	blockers, err := app.store.ListTaskBlockers(r.Context(), projectID, taskID)
+	if err != nil {
+		taskErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, map[string]any{"blockers": blockers}, nil)
+}
+
+func (app *Application) addTaskBlocker(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, blockerID, err := readTaskBlockerPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	t, err := app.store.AddTaskBlocker(r.Context(), projectID, taskID, blockerID)
+	if err != nil {
+		if errors.Is(err, store.ErrDependencyCycle) {
+			errorResponse(w, r, http.StatusConflict, "blocker already depends on this task, directly or indirectly")
+			return
+		}
+		taskErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, t, nil)
+}
+
+func (app *Application) removeTaskBlocker(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, blockerID, err := readTaskBlockerPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	t, err := app.store.RemoveTaskBlocker(r.Context(), projectID, taskID, blockerID)
+	if err != nil {
+		taskErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, t, nil)
+}
+
+// taskBlockedResponse writes the 409 for a status change held up by
+// blockers, listing the ones that are not done yet.
+func (app *Application) taskBlockedResponse(w http.ResponseWriter, r *http.Request, projectID, taskID uuid.UUID) {
+	blockers, err := app.store.ListTaskBlockers(r.Context(), projectID, taskID)
+	if err != nil {
+		taskErrorResponse(w, r, err)
+		return
+	}
+
+	open := make([]domain.Task, 0, len(blockers))
+	for _, b := range blockers {
+		if b.StatusCategory != domain.StatusCategoryDone {
+			open = append(open, b)
+		}
+	}
+
+	env := map[string]any{
+		"error": map[string]any{
+			"message":  "task cannot start until its blockers are done",
+			"blockers": open,
+		},
+	}
+	_ = writeJSON(w, http.StatusConflict, env, nil)
+}
+
+// listBlockedTasks returns the project's open tasks that are waiting on a
+// blocker, newest first.
+func (app *Application) listBlockedTasks(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid project id"))
+		return
+	}
+
+	page, err := readIntQuery(r, "page", 1)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	pageSize, err := readIntQuery(r, "page_size", 20)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := validatePageParams(page, pageSize); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	tasks, total, err := app.store.ListBlockedTasks(r.Context(), projectID, store.ListBlockedTasksParams{
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrProjectNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	env := map[string]any{
+		"tasks": tasks,
+		"metadata": metadata{
+			Page:         page,
+			PageSize:     pageSize,
+			TotalRecords: total,
+		},
+	}
+
+	_ = writeJSON(w, http.StatusOK, env, nil)
+}
@@ -0,0 +1,76 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDependencies_BlockersHoldUpWork(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	tasksURL := ts.URL + "/v1/projects/" + pid + "/tasks"
+	designID := createTask(t, ts, pid, "Design", "")["id"].(string)
+	buildID := createTask(t, ts, pid, "Build", "")["id"].(string)
+
+	build := doJSON(t, http.MethodPut, tasksURL+"/"+buildID+"/blockers/"+designID, "", http.StatusOK)
+	if blockedBy, _ := build["blockedBy"].([]any); len(blockedBy) != 1 || blockedBy[0] != designID {
+		t.Fatalf("expected build blocked by design; got %#v", build["blockedBy"])
+	}
+
+	env := getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks/blocked", http.StatusOK)
+	if got := taskTitles(t, env); len(got) != 1 || got[0] != "Build" {
+		t.Fatalf("expected Build blocked; got %v", got)
+	}
+
+	env = doJSON(t, http.MethodPatch, tasksURL+"/"+buildID, `{"status": "doing"}`, http.StatusConflict)
+	errBody, _ := env["error"].(map[string]any)
+	blockers, _ := errBody["blockers"].([]any)
+	if len(blockers) != 1 || blockers[0].(map[string]any)["id"] != designID {
+		t.Fatalf("expected design listed as blocker; got %#v", env)
+	}
+
+	doJSON(t, http.MethodPut, tasksURL+"/"+designID+"/blockers/"+buildID, "", http.StatusConflict)
+
+	doJSON(t, http.MethodPatch, tasksURL+"/"+designID, `{"status": "done"}`, http.StatusOK)
+	doJSON(t, http.MethodPatch, tasksURL+"/"+buildID, `{"status": "doing"}`, http.StatusOK)
+	env = getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks/blocked", http.StatusOK)
+	if got := taskTitles(t, env); len(got) != 0 {
+		t.Fatalf("expected nothing blocked; got %v", got)
+	}
+
+	env = getJSON(t, tasksURL+"/"+buildID+"/blockers", http.StatusOK)
+	if items, _ := env["blockers"].([]any); len(items) != 1 {
+		t.Fatalf("expected one blocker; got %#v", env)
+	}
+	build = doJSON(t, http.MethodDelete, tasksURL+"/"+buildID+"/blockers/"+designID, "", http.StatusOK)
+	if blockedBy, _ := build["blockedBy"].([]any); len(blockedBy) != 0 {
+		t.Fatalf("expected blocker removed; got %#v", build["blockedBy"])
+	}
+}
+
+func TestDependencies_Validation(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	otherPID := createProject(t, ts, "Beta")
+	tasksURL := ts.URL + "/v1/projects/" + pid + "/tasks"
+	taskID := createTask(t, ts, pid, "T1", "")["id"].(string)
+	foreignID := createTask(t, ts, otherPID, "Foreign", "")["id"].(string)
+	missing := "00000000-0000-0000-0000-000000000001"
+
+	doJSON(t, http.MethodPut, tasksURL+"/"+taskID+"/blockers/"+taskID, "", http.StatusConflict)
+	doJSON(t, http.MethodPut, tasksURL+"/"+taskID+"/blockers/"+foreignID, "", http.StatusNotFound)
+	doJSON(t, http.MethodPut, tasksURL+"/"+taskID+"/blockers/"+missing, "", http.StatusNotFound)
+	doJSON(t, http.MethodPut, tasksURL+"/"+taskID+"/blockers/nope", "", http.StatusBadRequest)
+	doJSON(t, http.MethodDelete, tasksURL+"/"+missing+"/blockers/"+taskID, "", http.StatusNotFound)
+	getJSON(t, tasksURL+"/"+missing+"/blockers", http.StatusNotFound)
+
+	getJSON(t, ts.URL+"/v1/projects/"+missing+"/tasks/blocked", http.StatusNotFound)
+	getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks/blocked?page=0", http.StatusBadRequest)
+}
@@ -18,7 +18,7 @@ type pingStore struct {
 func (ps pingStore) Ping(ctx context.Context) error { return ps.err }
 
 func TestLivez_200(t *testing.T) {
-	app := NewApplication(store.NewMemoryStore())
+	app := NewApplication(store.NewMemoryStore(), AuthConfig{})
 	ts := httptest.NewServer(app.Routes())
 	t.Cleanup(ts.Close)
 
@@ -34,7 +34,7 @@ func TestLivez_200(t *testing.T) {
 }
 
 func TestReadyz_200_WithMemoryStore(t *testing.T) {
-	app := NewApplication(store.NewMemoryStore())
+	app := NewApplication(store.NewMemoryStore(), AuthConfig{})
 	ts := httptest.NewServer(app.Routes())
 	t.Cleanup(ts.Close)
 
@@ -51,7 +51,7 @@ func TestReadyz_200_WithMemoryStore(t *testing.T) {
 
 func TestReadyz_503_WhenPingFails(t *testing.T) {
 	base := store.NewMemoryStore()
-	app := NewApplication(pingStore{ProjectStore: base, err: errors.New("db down")})
+	app := NewApplication(pingStore{ProjectStore: base, err: errors.New("db down")}, AuthConfig{})
 	ts := httptest.NewServer(app.Routes())
 	t.Cleanup(ts.Close)
 
@@ -1,12 +1,19 @@
 package httpapi
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/store"
 )
 
 func writeJSON(w http.ResponseWriter, status int, data any, headers http.Header) error {
@@ -61,6 +68,48 @@ func readIntQuery(r *http.Request, key string, defaultValue int) (int, error) {
 	return i, nil
 }
 
+// readCSVQuery splits a comma-separated query parameter, dropping blank entries.
+func readCSVQuery(r *http.Request, key string) []string {
+	s := r.URL.Query().Get(key)
+	if s == "" {
+		return nil
+	}
+
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// parseDueDate accepts a bare date (midnight UTC) or an RFC 3339 timestamp.
+func parseDueDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
+
+// readTimeQuery parses a date or timestamp query parameter, or returns nil when absent.
+func readTimeQuery(r *http.Request, key string) (*time.Time, error) {
+	s := r.URL.Query().Get(key)
+	if s == "" {
+		return nil, nil
+	}
+
+	t, err := parseDueDate(s)
+	if err != nil {
+		return nil, fmt.Errorf("query parameter %q must be a date (YYYY-MM-DD) or an RFC 3339 timestamp", key)
+	}
+	return &t, nil
+}
+
 func validatePageParams(page, pageSize int) error {
 	if page < 1 {
 		return errors.New("page must be >= 1")
@@ -73,9 +122,60 @@ func validatePageParams(page, pageSize int) error {
 	if pageSize > 100 {
 		return errors.New("page_size must be <= 100")
 	}
+
+	// The offset, (page-1)*pageSize, must fit in an int32, whatever the
+	// size of int
+	if page-1 > math.MaxInt32/pageSize {
+		return errors.New("page is too large for page_size")
+	}
 	return nil
 }
 
+var errInvalidCursor = errors.New("cursor is invalid")
+
+// encodeCursor turns a keyset position into an opaque, URL-safe token.
+func encodeCursor(c store.Cursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(token string) (store.Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return store.Cursor{}, errInvalidCursor
+	}
+
+	ts, id, ok := strings.Cut(string(b), "|")
+	if !ok {
+		return store.Cursor{}, errInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return store.Cursor{}, errInvalidCursor
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return store.Cursor{}, errInvalidCursor
+	}
+
+	return store.Cursor{CreatedAt: createdAt, ID: parsedID}, nil
+}
+
+// readCursorQuery returns the decoded "cursor" query parameter, or nil when absent.
+func readCursorQuery(r *http.Request) (*store.Cursor, error) {
+	token := r.URL.Query().Get("cursor")
+	if token == "" {
+		return nil, nil
+	}
+
+	c, err := decodeCursor(token)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
 func errorResponse(w http.ResponseWriter, r *http.Request, status int, message string) {
 	env := map[string]any{
 		"error": map[string]string{
@@ -98,4 +198,19 @@ func notFoundResponse(w http.ResponseWriter, r *http.Request) {
 	errorResponse(w, r, http.StatusNotFound, "the requested resource could not be found")
 }
 
+// methodNotAllowedResponse answers a method the resource does not take,
+// listing the ones it does in the Allow header.
+func methodNotAllowedResponse(w http.ResponseWriter, r *http.Request, allow string) {
+	w.Header().Set("Allow", allow)
+	errorResponse(w, r, http.StatusMethodNotAllowed, "the "+r.Method+" method is not supported for this resource")
+}
+
+func unauthorizedResponse(w http.ResponseWriter, r *http.Request, message string) {
+	errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func forbiddenResponse(w http.ResponseWriter, r *http.Request, message string) {
+	errorResponse(w, r, http.StatusForbidden, message)
+}
+
 var errInvalidJson = errors.New("invalid JSON")
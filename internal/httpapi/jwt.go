@@ -0,0 +1,326 @@
+package httpapi
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenVerifier checks a bearer token and returns the caller it identifies.
+// JWTVerifier is the only implementation; tests and other identity providers
+// can plug in their own through AuthConfig.Bearer.
+type TokenVerifier interface {
+	VerifyToken(token string) (Identity, error)
+}
+
+// jwtLeeway allows for clock skew between us and the identity provider.
+const jwtLeeway = 30 * time.Second
+
+var (
+	errTokenMalformed  = errors.New("token is malformed")
+	errTokenSignature  = errors.New("token signature is invalid")
+	errTokenExpired    = errors.New("token has expired")
+	errTokenNotYet     = errors.New("token is not valid yet")
+	errTokenIssuer     = errors.New("token issuer is not accepted")
+	errTokenAudience   = errors.New("token audience is not accepted")
+	errTokenSubject    = errors.New("token has no subject")
+	errTokenKeySubject = errors.New("token subject is reserved for API keys")
+	errTokenWorkspace  = errors.New("token workspace_id is not a UUID")
+)
+
+// jwk is one verification key. key is []byte for HMAC, *rsa.PublicKey or
+// *ecdsa.PublicKey.
+type jwk struct {
+	kid string
+	alg string
+	key any
+}
+
+// JWKSet is the set of keys tokens may be signed with.
+type JWKSet struct {
+	keys []jwk
+}
+
+// HMACKeySet is a key set holding a single shared secret, for HS256/384/512.
+func HMACKeySet(secret []byte) JWKSet {
+	return JWKSet{keys: []jwk{{key: secret}}}
+}
+
+// LoadJWKSFile reads a JSON Web Key Set (RFC 7517) from path. RSA, EC (P-256,
+// P-384, P-521) and oct keys are supported; keys with "use" other than "sig"
+// are skipped.
+func LoadJWKSFile(path string) (JWKSet, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return JWKSet{}, err
+	}
+	return parseJWKS(b)
+}
+
+func parseJWKS(b []byte) (JWKSet, error) {
+	var doc struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return JWKSet{}, fmt.Errorf("parse jwks: %w", err)
+	}
+
+	var set JWKSet
+	for i, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		var key any
+		switch k.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+			if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+				return JWKSet{}, fmt.Errorf("jwks key %d: invalid RSA key", i)
+			}
+			key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				return JWKSet{}, fmt.Errorf("jwks key %d: unsupported curve %q", i, k.Crv)
+			}
+			x, errX := base64.RawURLEncoding.DecodeString(k.X)
+			y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+			if errX != nil || errY != nil {
+				return JWKSet{}, fmt.Errorf("jwks key %d: invalid EC key", i)
+			}
+			pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+			if !curve.IsOnCurve(pub.X, pub.Y) {
+				return JWKSet{}, fmt.Errorf("jwks key %d: point is not on curve %s", i, k.Crv)
+			}
+			key = pub
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil || len(secret) == 0 {
+				return JWKSet{}, fmt.Errorf("jwks key %d: invalid oct key", i)
+			}
+			key = secret
+		default:
+			return JWKSet{}, fmt.Errorf("jwks key %d: unsupported key type %q", i, k.Kty)
+		}
+		set.keys = append(set.keys, jwk{kid: k.Kid, alg: k.Alg, key: key})
+	}
+
+	if len(set.keys) == 0 {
+		return JWKSet{}, errors.New("jwks has no signing keys")
+	}
+	return set, nil
+}
+
+// JWTVerifier checks the signature, expiry, issuer and audience of JWTs.
+type JWTVerifier struct {
+	keys     JWKSet
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// NewJWTVerifier accepts tokens signed by one of keys whose "iss" equals
+// issuer and whose "aud" contains audience. An empty issuer or audience
+// skips that check.
+func NewJWTVerifier(keys JWKSet, issuer, audience string) *JWTVerifier {
+	return &JWTVerifier{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		now:      time.Now,
+	}
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string      `json:"sub"`
+	Issuer    string      `json:"iss"`
+	Audience  jwtAudience `json:"aud"`
+	ExpiresAt *int64      `json:"exp"`
+	NotBefore *int64      `json:"nbf"`
+	// WorkspaceID, when present, pins the caller to that workspace.
+	WorkspaceID string `json:"workspace_id"`
+}
+
+// jwtAudience is "aud", which may be a single string or an array of them.
+type jwtAudience []string
+
+func (a *jwtAudience) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*a = jwtAudience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func (v *JWTVerifier) VerifyToken(token string) (Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, errTokenMalformed
+	}
+
+	var header jwtHeader
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return Identity{}, errTokenMalformed
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, errTokenMalformed
+	}
+	if !v.verifySignature(header, []byte(parts[0]+"."+parts[1]), sig) {
+		return Identity{}, errTokenSignature
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return Identity{}, errTokenMalformed
+	}
+
+	now := v.now()
+	if claims.ExpiresAt == nil || now.After(time.Unix(*claims.ExpiresAt, 0).Add(jwtLeeway)) {
+		return Identity{}, errTokenExpired
+	}
+	if claims.NotBefore != nil && now.Add(jwtLeeway).Before(time.Unix(*claims.NotBefore, 0)) {
+		return Identity{}, errTokenNotYet
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return Identity{}, errTokenIssuer
+	}
+	if v.audience != "" && !slices.Contains(claims.Audience, v.audience) {
+		return Identity{}, errTokenAudience
+	}
+	if claims.Subject == "" {
+		return Identity{}, errTokenSubject
+	}
+	if strings.HasPrefix(claims.Subject, apiKeySubjectPrefix) {
+		return Identity{}, errTokenKeySubject
+	}
+
+	id := Identity{Subject: claims.Subject}
+	if claims.WorkspaceID != "" {
+		workspaceID, err := uuid.Parse(claims.WorkspaceID)
+		if err != nil {
+			return Identity{}, errTokenWorkspace
+		}
+		id.WorkspaceID = &workspaceID
+	}
+	return id, nil
+}
+
+func decodeJWTPart(part string, dst any) error {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+// verifySignature tries every key that fits the token's alg and kid. The alg
+// must match the key type, so an RSA public key can never be used as an HMAC
+// secret.
+func (v *JWTVerifier) verifySignature(header jwtHeader, signed, sig []byte) bool {
+	for _, k := range v.keys.keys {
+		if header.Kid != "" && k.kid != "" && header.Kid != k.kid {
+			continue
+		}
+		if k.alg != "" && k.alg != header.Alg {
+			continue
+		}
+		if verifyJWTSignature(header.Alg, k.key, signed, sig) {
+			return true
+		}
+	}
+	return false
+}
+
+func verifyJWTSignature(alg string, key any, signed, sig []byte) bool {
+	var newHash func() hash.Hash
+	var cryptoHash crypto.Hash
+	switch alg[min(2, len(alg)):] {
+	case "256":
+		newHash, cryptoHash = sha256.New, crypto.SHA256
+	case "384":
+		newHash, cryptoHash = sha512.New384, crypto.SHA384
+	case "512":
+		newHash, cryptoHash = sha512.New, crypto.SHA512
+	default:
+		return false
+	}
+
+	switch k := key.(type) {
+	case []byte:
+		if !strings.HasPrefix(alg, "HS") {
+			return false
+		}
+		mac := hmac.New(newHash, k)
+		mac.Write(signed)
+		return hmac.Equal(sig, mac.Sum(nil))
+	case *rsa.PublicKey:
+		h := newHash()
+		h.Write(signed)
+		switch {
+		case strings.HasPrefix(alg, "RS"):
+			return rsa.VerifyPKCS1v15(k, cryptoHash, h.Sum(nil), sig) == nil
+		case strings.HasPrefix(alg, "PS"):
+			return rsa.VerifyPSS(k, cryptoHash, h.Sum(nil), sig, nil) == nil
+		}
+		return false
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if !strings.HasPrefix(alg, "ES") || len(sig) != 2*size {
+			return false
+		}
+		h := newHash()
+		h.Write(signed)
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(k, h.Sum(nil), r, s)
+	}
+	return false
+}
@@ -0,0 +1,258 @@
+package httpapi
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+var testHMACSecret = []byte("test-hmac-secret")
+
+// lockedBuffer collects log output written from server goroutines.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// signTestJWT signs claims with key: a []byte (HS256), *rsa.PrivateKey
+// (RS256) or *ecdsa.PrivateKey (ES256).
+func signTestJWT(t *testing.T, key any, kid string, claims map[string]any) string {
+	t.Helper()
+
+	header := map[string]any{"typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	switch key.(type) {
+	case []byte:
+		header["alg"] = "HS256"
+	case *rsa.PrivateKey:
+		header["alg"] = "RS256"
+	case *ecdsa.PrivateKey:
+		header["alg"] = "ES256"
+	}
+
+	enc := func(v any) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := enc(header) + "." + enc(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:]); err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func testClaims(overrides map[string]any) map[string]any {
+	claims := map[string]any{
+		"sub": "user-1",
+		"iss": "https://idp.test",
+		"aud": "pm-api",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(claims, k)
+		} else {
+			claims[k] = v
+		}
+	}
+	return claims
+}
+
+func TestJWTVerifier_HMAC(t *testing.T) {
+	v := NewJWTVerifier(HMACKeySet(testHMACSecret), "https://idp.test", "pm-api")
+
+	id, err := v.VerifyToken(signTestJWT(t, testHMACSecret, "", testClaims(nil)))
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if id.Subject != "user-1" || id.Admin {
+		t.Fatalf("unexpected identity: %#v", id)
+	}
+
+	// aud may also be an array
+	if _, err := v.VerifyToken(signTestJWT(t, testHMACSecret, "", testClaims(map[string]any{"aud": []string{"other", "pm-api"}}))); err != nil {
+		t.Fatalf("verify array aud: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"wrong secret", signTestJWT(t, []byte("other"), "", testClaims(nil)), errTokenSignature},
+		{"expired", signTestJWT(t, testHMACSecret, "", testClaims(map[string]any{"exp": time.Now().Add(-time.Hour).Unix()})), errTokenExpired},
+		{"no exp", signTestJWT(t, testHMACSecret, "", testClaims(map[string]any{"exp": nil})), errTokenExpired},
+		{"not yet valid", signTestJWT(t, testHMACSecret, "", testClaims(map[string]any{"nbf": time.Now().Add(time.Hour).Unix()})), errTokenNotYet},
+		{"wrong issuer", signTestJWT(t, testHMACSecret, "", testClaims(map[string]any{"iss": "https://evil.test"})), errTokenIssuer},
+		{"wrong audience", signTestJWT(t, testHMACSecret, "", testClaims(map[string]any{"aud": "other"})), errTokenAudience},
+		{"no subject", signTestJWT(t, testHMACSecret, "", testClaims(map[string]any{"sub": nil})), errTokenSubject},
+		{"API key subject", signTestJWT(t, testHMACSecret, "", testClaims(map[string]any{"sub": "api-key:admin"})), errTokenKeySubject},
+		{"malformed", "not-a-jwt", errTokenMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := v.VerifyToken(tt.token); !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v; got %v", tt.want, err)
+			}
+		})
+	}
+
+	// alg "none" is never accepted
+	payload := strings.Split(signTestJWT(t, testHMACSecret, "", testClaims(nil)), ".")[1]
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + payload + "."
+	if _, err := v.VerifyToken(unsigned); !errors.Is(err, errTokenSignature) {
+		t.Fatalf("expected alg none to be rejected; got %v", err)
+	}
+}
+
+func TestJWTVerifier_JWKSFile(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ec key: %v", err)
+	}
+
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	jwks := fmt.Sprintf(`{"keys": [
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": %q, "e": %q},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": %q, "y": %q},
+		{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": "AQAB", "e": "AQAB"}
+	]}`,
+		b64(rsaKey.N.Bytes()), b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+		b64(ecKey.X.Bytes()), b64(ecKey.Y.Bytes()))
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, []byte(jwks), 0o600); err != nil {
+		t.Fatalf("write jwks: %v", err)
+	}
+	keys, err := LoadJWKSFile(path)
+	if err != nil {
+		t.Fatalf("load jwks: %v", err)
+	}
+	v := NewJWTVerifier(keys, "", "")
+
+	if _, err := v.VerifyToken(signTestJWT(t, rsaKey, "rsa-1", testClaims(nil))); err != nil {
+		t.Fatalf("verify RS256: %v", err)
+	}
+	if _, err := v.VerifyToken(signTestJWT(t, ecKey, "ec-1", testClaims(nil))); err != nil {
+		t.Fatalf("verify ES256: %v", err)
+	}
+	if _, err := v.VerifyToken(signTestJWT(t, ecKey, "", testClaims(nil))); err != nil {
+		t.Fatalf("verify ES256 without kid: %v", err)
+	}
+	if _, err := v.VerifyToken(signTestJWT(t, rsaKey, "ec-1", testClaims(nil))); !errors.Is(err, errTokenSignature) {
+		t.Fatalf("expected a kid mismatch to fail; got %v", err)
+	}
+
+	// The RSA public key must not be usable as an HMAC secret
+	forged := signTestJWT(t, rsaKey.N.Bytes(), "rsa-1", testClaims(nil))
+	if _, err := v.VerifyToken(forged); !errors.Is(err, errTokenSignature) {
+		t.Fatalf("expected alg confusion to fail; got %v", err)
+	}
+
+	if _, err := parseJWKS([]byte(`{"keys": []}`)); err == nil {
+		t.Fatalf("expected an empty key set to be rejected")
+	}
+}
+
+func TestAuth_BearerToken(t *testing.T) {
+	app := NewApplication(store.NewMemoryStore(), AuthConfig{
+		Enabled:  true,
+		AdminKey: testAdminKey,
+		Bearer:   NewJWTVerifier(HMACKeySet(testHMACSecret), "https://idp.test", "pm-api"),
+	})
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	logs := &lockedBuffer{}
+	log.SetOutput(logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	do := func(token string, wantStatus int) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/v1/projects", nil)
+		if err != nil {
+			t.Fatalf("new request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET /v1/projects: %v", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != wantStatus {
+			t.Fatalf("expected status %d; got %d", wantStatus, res.StatusCode)
+		}
+	}
+
+	do(signTestJWT(t, testHMACSecret, "", testClaims(map[string]any{"sub": "alice"})), http.StatusOK)
+	if !strings.Contains(logs.String(), "subject=alice") {
+		t.Fatalf("expected the subject to be logged; got %q", logs.String())
+	}
+	do(signTestJWT(t, testHMACSecret, "", testClaims(map[string]any{"aud": "other"})), http.StatusUnauthorized)
+	do("garbage", http.StatusUnauthorized)
+
+	// API keys keep working alongside bearer tokens
+	doAuthJSON(t, http.MethodGet, ts.URL+"/v1/projects", testAdminKey, "", http.StatusOK)
+	doAuthJSON(t, http.MethodGet, ts.URL+"/v1/projects", "", "", http.StatusUnauthorized)
+}
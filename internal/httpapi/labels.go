@@ -0,0 +1,226 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+var labelColorRX = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+var errInvalidLabelColor = errors.New("color must be a hex color like #1f6feb")
+
+type createLabelInput struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type updateLabelInput struct {
+	Name  *string `json:"name,omitempty"`
+	Color *string `json:"color,omitempty"`
+}
+
+// readLabelPathIDs parses the {projectId} and {labelId} path values.
+func readLabelPathIDs(r *http.Request) (projectID, labelID uuid.UUID, err error) {
+	projectID, err = uuid.Parse(r.PathValue("projectId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("invalid project id")
+	}
+	labelID, err = uuid.Parse(r.PathValue("labelId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("invalid label id")
+	}
+	return projectID, labelID, nil
+}
+
+// labelErrorResponse maps store errors from label lookups: a missing project,
+// task or label is a 404 and a duplicate name a 409.
+func labelErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, store.ErrLabelExists):
+		errorResponse(w, r, http.StatusConflict, "a label with this name already exists in the project")
+	case errors.Is(err, store.ErrLabelNotFound):
+		notFoundResponse(w, r)
+	default:
+		taskErrorResponse(w, r, err)
+	}
+}
+
+func (app *Application) createLabel(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid project id"))
+		return
+	}
+
+	var input createLabelInput
+	if err := readJSON(w, r, &input); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	input.Color = strings.TrimSpace(input.Color)
+
+	if input.Name == "" {
+		badRequestResponse(w, r, errors.New("name is required"))
+		return
+	}
+	if input.Color != "" && !labelColorRX.MatchString(input.Color) {
+		badRequestResponse(w, r, errInvalidLabelColor)
+		return
+	}
+
+	l, err := app.store.InsertLabel(r.Context(), projectID, input.Name, input.Color)
+	if err != nil {
+		labelErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusCreated, l, nil)
+}
+
+func (app *Application) listLabels(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid project id"))
+		return
+	}
+
+	labels, err := app.store.ListLabels(r.Context(), projectID)
+	if err != nil {
+		labelErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, map[string]any{"labels": labels}, nil)
+}
+
+func (app *Application) getLabel(w http.ResponseWriter, r *http.Request) {
+	projectID, labelID, err := readLabelPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	l, err := app.store.GetLabel(r.Context(), projectID, labelID)
+	if err != nil {
+		labelErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, l, nil)
+}
+
+func (app *Application) updateLabel(w http.ResponseWriter, r *http.Request) {
+	projectID, labelID, err := readLabelPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	var input updateLabelInput
+	if err := readJSON(w, r, &input); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Name == nil && input.Color == nil {
+		badRequestResponse(w, r, errors.New("body must contain at least one of name or color"))
+		return
+	}
+
+	if input.Name != nil {
+		n := strings.TrimSpace(*input.Name)
+		if n == "" {
+			badRequestResponse(w, r, errors.New("name cannot be empty"))
+			return
+		}
+		input.Name = &n
+	}
+
+	// An empty color clears it.
+	if input.Color != nil {
+		c := strings.TrimSpace(*input.Color)
+		if c != "" && !labelColorRX.MatchString(c) {
+			badRequestResponse(w, r, errInvalidLabelColor)
+			return
+		}
+		input.Color = &c
+	}
+
+	l, err := app.store.UpdateLabel(r.Context(), projectID, labelID, store.LabelUpdate{
+		Name:  input.Name,
+		Color: input.Color,
+	})
+	if err != nil {
+		labelErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, l, nil)
+}
+
+func (app *Application) deleteLabel(w http.ResponseWriter, r *http.Request) {
+	projectID, labelID, err := readLabelPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.DeleteLabel(r.Context(), projectID, labelID); err != nil {
+		labelErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readTaskLabelPathIDs parses {projectId}, {taskId} and {labelId}.
+func readTaskLabelPathIDs(r *http.Request) (projectID, taskID, labelID uuid.UUID, err error) {
+	projectID, taskID, err = readTaskPathIDs(r)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, err
+	}
+	labelID, err = uuid.Parse(r.PathValue("labelId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, errors.New("invalid label id")
+	}
+	return projectID, taskID, labelID, nil
+}
+
+func (app *Application) attachTaskLabel(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, labelID, err := readTaskLabelPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	t, err := app.store.AttachLabel(r.Context(), projectID, taskID, labelID)
+	if err != nil {
+		labelErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, t, nil)
+}
+
+func (app *Application) detachTaskLabel(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, labelID, err := readTaskLabelPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	t, err := app.store.DetachLabel(r.Context(), projectID, taskID, labelID)
+	if err != nil {
+		labelErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, t, nil)
+}
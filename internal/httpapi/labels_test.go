@@ -0,0 +1,108 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func labelNames(t *testing.T, items any) []string {
+	t.Helper()
+
+	raw, ok := items.([]any)
+	if !ok {
+		t.Fatalf("expected labels array, got %#v", items)
+	}
+	names := make([]string, 0, len(raw))
+	for _, item := range raw {
+		l, _ := item.(map[string]any)
+		name, _ := l["name"].(string)
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestLabels_CRUD(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	url := ts.URL + "/v1/projects/" + pid + "/labels"
+
+	bug := doJSON(t, http.MethodPost, url, `{"name": " bug ", "color": "#FF0000"}`, http.StatusCreated)
+	if bug["name"] != "bug" || bug["color"] != "#FF0000" || bug["projectId"] != pid {
+		t.Fatalf("unexpected label: %#v", bug)
+	}
+	doJSON(t, http.MethodPost, url, `{"name": "backend"}`, http.StatusCreated)
+	doJSON(t, http.MethodPost, url, `{"name": "bug"}`, http.StatusConflict)
+	doJSON(t, http.MethodPost, url, `{"name": ""}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, url, `{"name": "infra", "color": "red"}`, http.StatusBadRequest)
+
+	env := getJSON(t, url, http.StatusOK)
+	if got := labelNames(t, env["labels"]); !slices.Equal(got, []string{"backend", "bug"}) {
+		t.Fatalf("expected [backend bug]; got %v", got)
+	}
+
+	bugURL := url + "/" + bug["id"].(string)
+	got := doJSON(t, http.MethodPatch, bugURL, `{"name": "defect", "color": ""}`, http.StatusOK)
+	if got["name"] != "defect" {
+		t.Fatalf("unexpected label: %#v", got)
+	}
+	if _, ok := got["color"]; ok {
+		t.Fatalf("expected color cleared; got %#v", got["color"])
+	}
+	doJSON(t, http.MethodPatch, bugURL, `{"name": "backend"}`, http.StatusConflict)
+	doJSON(t, http.MethodPatch, bugURL, `{}`, http.StatusBadRequest)
+	getJSON(t, bugURL, http.StatusOK)
+
+	doJSON(t, http.MethodDelete, bugURL, "", http.StatusNoContent)
+	getJSON(t, bugURL, http.StatusNotFound)
+	getJSON(t, ts.URL+"/v1/projects/00000000-0000-0000-0000-000000000000/labels", http.StatusNotFound)
+	getJSON(t, url+"/invalid-uuid", http.StatusBadRequest)
+}
+
+func TestTaskLabels_AttachDetachAndFilter(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	other := createProject(t, ts, "Beta")
+	bug := doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+pid+"/labels", `{"name": "bug"}`, http.StatusCreated)
+	infra := doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+pid+"/labels", `{"name": "infra"}`, http.StatusCreated)
+	foreign := doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+other+"/labels", `{"name": "bug"}`, http.StatusCreated)
+
+	t1 := createTask(t, ts, pid, "T1", "")
+	if got := labelNames(t, t1["labels"]); len(got) != 0 {
+		t.Fatalf("expected no labels on new task; got %v", got)
+	}
+	createTask(t, ts, pid, "T2", "")
+	taskURL := ts.URL + "/v1/projects/" + pid + "/tasks/" + t1["id"].(string)
+
+	doJSON(t, http.MethodPut, taskURL+"/labels/"+bug["id"].(string), "", http.StatusOK)
+	got := doJSON(t, http.MethodPut, taskURL+"/labels/"+infra["id"].(string), "", http.StatusOK)
+	if names := labelNames(t, got["labels"]); !slices.Equal(names, []string{"bug", "infra"}) {
+		t.Fatalf("expected [bug infra]; got %v", names)
+	}
+	doJSON(t, http.MethodPut, taskURL+"/labels/"+foreign["id"].(string), "", http.StatusNotFound)
+
+	env := getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks?label=bug", http.StatusOK)
+	if titles := taskTitles(t, env); !slices.Equal(titles, []string{"T1"}) {
+		t.Fatalf("expected [T1]; got %v", titles)
+	}
+
+	got = doJSON(t, http.MethodDelete, taskURL+"/labels/"+bug["id"].(string), "", http.StatusOK)
+	if names := labelNames(t, got["labels"]); !slices.Equal(names, []string{"infra"}) {
+		t.Fatalf("expected [infra]; got %v", names)
+	}
+	got = getJSON(t, taskURL, http.StatusOK)
+	if names := labelNames(t, got["labels"]); !slices.Equal(names, []string{"infra"}) {
+		t.Fatalf("expected [infra] inline on get; got %v", names)
+	}
+	env = getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks?label=bug", http.StatusOK)
+	if titles := taskTitles(t, env); len(titles) != 0 {
+		t.Fatalf("expected no tasks labelled bug; got %v", titles)
+	}
+}
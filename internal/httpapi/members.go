@@ -0,0 +1,156 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/domain"
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+// callerSubject is who creates a project and so becomes its first owner;
+// empty when authentication is off.
+func (app *Application) callerSubject(r *http.Request) string {
+	if !app.auth.Enabled {
+		return ""
+	}
+	id, _ := getIdentity(r)
+	return id.Subject
+}
+
+// memberFilter limits listings to the caller's projects. Admins, and
+// everyone when authentication is off, see all projects.
+func (app *Application) memberFilter(r *http.Request) string {
+	id, ok := getIdentity(r)
+	if !app.auth.Enabled || !ok || id.Admin {
+		return ""
+	}
+	return id.Subject
+}
+
+// checkProjectRole fails with store.ErrForbidden or store.ErrProjectNotFound
+// unless the caller holds at least the want role on the project.
+func (app *Application) checkProjectRole(r *http.Request, projectID uuid.UUID, want string) error {
+	id, ok := getIdentity(r)
+	if !app.auth.Enabled || !ok || id.Admin {
+		return nil
+	}
+	return store.AuthorizeProject(r.Context(), app.store, projectID, id.Subject, want)
+}
+
+func projectRoleErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, store.ErrProjectNotFound):
+		notFoundResponse(w, r)
+	case errors.Is(err, store.ErrForbidden):
+		forbiddenResponse(w, r, "your role on this project does not allow this")
+	default:
+		serverErrorResponse(w, r, err)
+	}
+}
+
+// requireProjectRole only lets callers holding at least the want role on the
+// project in the path through to next. Invalid project IDs are left for next
+// to reject.
+func (app *Application) requireProjectRole(want string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		raw := r.PathValue("projectId")
+		if raw == "" {
+			raw = r.PathValue("id")
+		}
+		if projectID, err := uuid.Parse(raw); err == nil {
+			if err := app.checkProjectRole(r, projectID, want); err != nil {
+				projectRoleErrorResponse(w, r, err)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+type putMemberInput struct {
+	Role string `json:"role"`
+}
+
+// memberErrorResponse maps store errors from membership changes.
+func memberErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, store.ErrProjectNotFound), errors.Is(err, store.ErrMemberNotFound):
+		notFoundResponse(w, r)
+	case errors.Is(err, store.ErrLastOwner):
+		errorResponse(w, r, http.StatusConflict, err.Error())
+	default:
+		serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *Application) listMembers(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid project ID"))
+		return
+	}
+
+	members, err := app.store.ListProjectMembers(r.Context(), id)
+	if err != nil {
+		memberErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, map[string]any{"members": members}, nil)
+}
+
+// putMember adds the subject in the path to the project or changes their role.
+func (app *Application) putMember(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid project ID"))
+		return
+	}
+	subject := strings.TrimSpace(r.PathValue("subject"))
+	if subject == "" {
+		badRequestResponse(w, r, errors.New("subject is required"))
+		return
+	}
+
+	var input putMemberInput
+	if err := readJSON(w, r, &input); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+	if !domain.ValidRole(input.Role) {
+		badRequestResponse(w, r, errors.New("role must be one of: viewer, editor, owner"))
+		return
+	}
+
+	m, err := app.store.PutProjectMember(r.Context(), id, subject, input.Role)
+	if err != nil {
+		memberErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, m, nil)
+}
+
+func (app *Application) removeMember(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid project ID"))
+		return
+	}
+
+	subject := strings.TrimSpace(r.PathValue("subject"))
+	if subject == "" {
+		badRequestResponse(w, r, errors.New("subject is required"))
+		return
+	}
+
+	if err := app.store.RemoveProjectMember(r.Context(), id, subject); err != nil {
+		memberErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
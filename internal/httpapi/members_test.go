@@ -0,0 +1,96 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+// newRBACTestServer accepts HS256 tokens signed with testHMACSecret, so each
+// test caller is just a JWT subject.
+func newRBACTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	app := NewApplication(store.NewMemoryStore(), AuthConfig{
+		Enabled:  true,
+		AdminKey: testAdminKey,
+		Bearer:   NewJWTVerifier(HMACKeySet(testHMACSecret), "", ""),
+	})
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// doAs is doJSON as the caller with the given JWT subject.
+func doAs(t *testing.T, subject, method, url, body string, wantStatus int) map[string]any {
+	t.Helper()
+	return doRequest(t, method, url, bearerHeader(t, subject), body, wantStatus)
+}
+
+// bearerHeader authenticates as the JWT subject.
+func bearerHeader(t *testing.T, subject string) http.Header {
+	t.Helper()
+	token := signTestJWT(t, testHMACSecret, "", testClaims(map[string]any{"sub": subject}))
+	return http.Header{"Authorization": {"Bearer " + token}}
+}
+
+func TestRBAC_ProjectRoles(t *testing.T) {
+	ts := newRBACTestServer(t)
+
+	created := doAs(t, "alice", http.MethodPost, ts.URL+"/v1/projects", `{"name": "Alpha"}`, http.StatusCreated)
+	projectURL := ts.URL + "/v1/projects/" + created["id"].(string)
+	task := doAs(t, "alice", http.MethodPost, projectURL+"/tasks", `{"title": "T1"}`, http.StatusCreated)
+	taskURL := projectURL + "/tasks/" + task["id"].(string)
+
+	// To non-members the project does not exist
+	doAs(t, "bob", http.MethodGet, projectURL, "", http.StatusNotFound)
+	doAs(t, "bob", http.MethodGet, taskURL, "", http.StatusNotFound)
+	doAs(t, "bob", http.MethodPatch, projectURL, `{"name": "Mine"}`, http.StatusNotFound)
+	doAs(t, "bob", http.MethodGet, ts.URL+"/v1/projects/00000000-0000-0000-0000-000000000001", "", http.StatusNotFound)
+	if got := doAs(t, "bob", http.MethodGet, ts.URL+"/v1/projects", "", http.StatusOK); len(got["projects"].([]any)) != 0 {
+		t.Fatalf("expected bob to see no projects; got %#v", got["projects"])
+	}
+
+	// Viewers can read but not write
+	doAs(t, "alice", http.MethodPut, projectURL+"/members/bob", `{"role": "viewer"}`, http.StatusOK)
+	doAs(t, "bob", http.MethodGet, projectURL, "", http.StatusOK)
+	doAs(t, "bob", http.MethodGet, taskURL, "", http.StatusOK)
+	doAs(t, "bob", http.MethodPatch, projectURL, `{"name": "Mine"}`, http.StatusForbidden)
+	doAs(t, "bob", http.MethodPatch, taskURL, `{"status": "doing"}`, http.StatusForbidden)
+	doAs(t, "bob", http.MethodPost, projectURL+"/tasks", `{"title": "T2"}`, http.StatusForbidden)
+	if got := doAs(t, "bob", http.MethodGet, ts.URL+"/v1/projects", "", http.StatusOK); len(got["projects"].([]any)) != 1 {
+		t.Fatalf("expected bob to see one project; got %#v", got["projects"])
+	}
+
+	// Editors can write but not delete the project or manage members
+	doAs(t, "alice", http.MethodPut, projectURL+"/members/bob", `{"role": "editor"}`, http.StatusOK)
+	doAs(t, "bob", http.MethodPatch, projectURL, `{"name": "Alpha 2"}`, http.StatusOK)
+	doAs(t, "bob", http.MethodPatch, taskURL, `{"status": "doing"}`, http.StatusOK)
+	doAs(t, "bob", http.MethodDelete, projectURL, "", http.StatusForbidden)
+	doAs(t, "bob", http.MethodPut, projectURL+"/members/carol", `{"role": "viewer"}`, http.StatusForbidden)
+
+	// Copying into a project needs editor rights there too
+	other := doAs(t, "carol", http.MethodPost, ts.URL+"/v1/projects", `{"name": "Beta"}`, http.StatusCreated)
+	copyBody := `{"targetProjectId": "` + other["id"].(string) + `"}`
+	doAs(t, "bob", http.MethodPost, taskURL+"/copy", copyBody, http.StatusNotFound)
+	doAs(t, "carol", http.MethodPut, ts.URL+"/v1/projects/"+other["id"].(string)+"/members/bob", `{"role": "viewer"}`, http.StatusOK)
+	doAs(t, "bob", http.MethodPost, taskURL+"/copy", copyBody, http.StatusForbidden)
+
+	members := doAs(t, "bob", http.MethodGet, projectURL+"/members", "", http.StatusOK)
+	if list := members["members"].([]any); len(list) != 2 || list[0].(map[string]any)["role"] != "owner" {
+		t.Fatalf("unexpected members: %#v", members)
+	}
+	doAs(t, "alice", http.MethodPut, projectURL+"/members/bob", `{"role": "admin"}`, http.StatusBadRequest)
+	doAs(t, "alice", http.MethodDelete, projectURL+"/members/alice", "", http.StatusConflict)
+	doAs(t, "alice", http.MethodDelete, projectURL+"/members/%20bob%20", "", http.StatusNoContent)
+	doAs(t, "alice", http.MethodDelete, projectURL+"/members/bob", "", http.StatusNotFound)
+	doAs(t, "alice", http.MethodDelete, projectURL+"/members/%20", "", http.StatusBadRequest)
+	doAs(t, "bob", http.MethodGet, projectURL, "", http.StatusNotFound)
+
+	// Admin keys see and may change every project
+	if got := doAuthJSON(t, http.MethodGet, ts.URL+"/v1/projects", testAdminKey, "", http.StatusOK); len(got["projects"].([]any)) != 2 {
+		t.Fatalf("expected the admin to see every project; got %#v", got["projects"])
+	}
+	doAuthJSON(t, http.MethodDelete, projectURL, testAdminKey, "", http.StatusNoContent)
+}
@@ -13,7 +13,10 @@ import (
 
 type ctxKey int
 
-const requestIDKey ctxKey = iota
+const (
+	requestIDKey ctxKey = iota
+	identityKey
+)
 
 func getRequestID(r *http.Request) string {
 	v, _ := r.Context().Value(requestIDKey).(string)
@@ -58,7 +61,8 @@ func (app *Application) recoverPanicMiddleware(next http.Handler) http.Handler {
 
 type statusRecorder struct {
 	http.ResponseWriter
-	status int
+	status  int
+	subject string
 }
 
 func (sr *statusRecorder) WriteHeader(status int) {
@@ -76,6 +80,6 @@ func (app *Application) logRequestMiddleware(next http.Handler) http.Handler {
 		rid := getRequestID(r)
 		dur := time.Since(start)
 
-		log.Printf("INFO: request_id=%s, method=%s, path=%s, status=%d, duration=%s", rid, r.Method, r.URL.Path, sr.status, dur)
+		log.Printf("INFO: request_id=%s, subject=%s, method=%s, path=%s, status=%d, duration=%s", rid, sr.subject, r.Method, r.URL.Path, sr.status, dur)
 	})
 }
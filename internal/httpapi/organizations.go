@@ -0,0 +1,97 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/domain"
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+// workspaceHeader picks the workspace of a request for callers that are not
+// pinned to one.
+const workspaceHeader = "X-Workspace-ID"
+
+// workspaceMiddleware scopes the store calls of every request to one
+// workspace. Callers pinned to a workspace by their token or API key get
+// that one; admins, and everyone when authentication is off, may pick any
+// with the X-Workspace-ID header. Everyone else gets the default workspace.
+// Asking for a workspace the caller cannot use is a 404, like any other
+// resource of another tenant.
+func (app *Application) workspaceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if openPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		id, _ := getIdentity(r)
+		workspaceID := domain.DefaultWorkspaceID
+		if id.WorkspaceID != nil {
+			workspaceID = *id.WorkspaceID
+		}
+
+		if raw := strings.TrimSpace(r.Header.Get(workspaceHeader)); raw != "" {
+			requested, err := uuid.Parse(raw)
+			if err != nil {
+				badRequestResponse(w, r, errors.New("invalid "+workspaceHeader+" header"))
+				return
+			}
+			if app.auth.Enabled && !id.Admin && requested != workspaceID {
+				notFoundResponse(w, r)
+				return
+			}
+			workspaceID = requested
+		}
+		if workspaceID != domain.DefaultWorkspaceID {
+			if _, err := app.store.GetOrganization(r.Context(), workspaceID); err != nil {
+				if errors.Is(err, store.ErrOrganizationNotFound) {
+					notFoundResponse(w, r)
+					return
+				}
+				serverErrorResponse(w, r, err)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(store.WithWorkspace(r.Context(), workspaceID)))
+	})
+}
+
+type createOrganizationInput struct {
+	Name string `json:"name"`
+}
+
+func (app *Application) createOrganization(w http.ResponseWriter, r *http.Request) {
+	var input createOrganizationInput
+	if err := readJSON(w, r, &input); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		badRequestResponse(w, r, errors.New("name is required"))
+		return
+	}
+
+	o, err := app.store.InsertOrganization(r.Context(), input.Name)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusCreated, o, nil)
+}
+
+func (app *Application) listOrganizations(w http.ResponseWriter, r *http.Request) {
+	orgs, err := app.store.ListOrganizations(r.Context())
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, map[string]any{"organizations": orgs}, nil)
+}
@@ -0,0 +1,82 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+)
+
+// tenantHeader authenticates as subject, pinned to workspaceID by the token.
+func tenantHeader(t *testing.T, subject, workspaceID string) http.Header {
+	t.Helper()
+	token := signTestJWT(t, testHMACSecret, "", testClaims(map[string]any{"sub": subject, "workspace_id": workspaceID}))
+	return http.Header{"Authorization": {"Bearer " + token}}
+}
+
+func TestWorkspaces_Isolation(t *testing.T) {
+	ts := newRBACTestServer(t)
+	admin := http.Header{"X-Api-Key": {testAdminKey}}
+
+	acme := doRequest(t, http.MethodPost, ts.URL+"/v1/organizations", admin, `{"name": "Acme"}`, http.StatusCreated)
+	globex := doRequest(t, http.MethodPost, ts.URL+"/v1/organizations", admin, `{"name": "Globex"}`, http.StatusCreated)
+	acmeID, globexID := acme["id"].(string), globex["id"].(string)
+	alice, bob := tenantHeader(t, "alice", acmeID), tenantHeader(t, "bob", globexID)
+
+	created := doRequest(t, http.MethodPost, ts.URL+"/v1/projects", alice, `{"name": "Alpha", "key": "OPS"}`, http.StatusCreated)
+	if created["workspaceId"] != acmeID {
+		t.Fatalf("expected the project in alice's workspace; got %#v", created)
+	}
+	projectURL := ts.URL + "/v1/projects/" + created["id"].(string)
+	task := doRequest(t, http.MethodPost, projectURL+"/tasks", alice, `{"title": "T1"}`, http.StatusCreated)
+	taskURL := projectURL + "/tasks/" + task["id"].(string)
+
+	// Another tenant gets 404s, not 403s, and can reuse the key
+	doRequest(t, http.MethodGet, projectURL, bob, "", http.StatusNotFound)
+	doRequest(t, http.MethodGet, taskURL, bob, "", http.StatusNotFound)
+	doRequest(t, http.MethodPatch, projectURL, bob, `{"name": "Mine"}`, http.StatusNotFound)
+	beta := doRequest(t, http.MethodPost, ts.URL+"/v1/projects", bob, `{"name": "Beta", "key": "OPS"}`, http.StatusCreated)
+	if got := doRequest(t, http.MethodGet, ts.URL+"/v1/projects", bob, "", http.StatusOK); len(got["projects"].([]any)) != 1 {
+		t.Fatalf("expected bob to see only their project; got %#v", got["projects"])
+	}
+
+	// Users too: another tenant can neither see nor assign them, and can
+	// reuse the email
+	ann := `{"name": "Ann", "email": "ann@example.com"}`
+	user := doRequest(t, http.MethodPost, ts.URL+"/v1/users", alice, ann, http.StatusCreated)
+	userURL := ts.URL + "/v1/users/" + user["id"].(string)
+	doRequest(t, http.MethodGet, userURL, alice, "", http.StatusOK)
+	doRequest(t, http.MethodGet, userURL, bob, "", http.StatusNotFound)
+	doRequest(t, http.MethodGet, userURL+"/tasks", bob, "", http.StatusNotFound)
+	doRequest(t, http.MethodPost, ts.URL+"/v1/projects/"+beta["id"].(string)+"/tasks", bob,
+		`{"title": "T2", "assigneeId": "`+user["id"].(string)+`"}`, http.StatusBadRequest)
+	doRequest(t, http.MethodPost, ts.URL+"/v1/users", alice, ann, http.StatusConflict)
+	doRequest(t, http.MethodPost, ts.URL+"/v1/users", bob, ann, http.StatusCreated)
+
+	// A pinned caller cannot switch workspaces with the header
+	bob.Set(workspaceHeader, acmeID)
+	doRequest(t, http.MethodGet, projectURL, bob, "", http.StatusNotFound)
+	doRequest(t, http.MethodGet, ts.URL+"/v1/projects", bob, "", http.StatusNotFound)
+
+	// Admins pick a workspace with the header and see the default one without
+	if got := doRequest(t, http.MethodGet, ts.URL+"/v1/projects", admin, "", http.StatusOK); len(got["projects"].([]any)) != 0 {
+		t.Fatalf("expected the default workspace to be empty; got %#v", got["projects"])
+	}
+	admin.Set(workspaceHeader, acmeID)
+	doRequest(t, http.MethodGet, projectURL, admin, "", http.StatusOK)
+
+	// Keys created in a workspace are pinned to it
+	key := doRequest(t, http.MethodPost, ts.URL+"/v1/api-keys", admin, `{"name": "ci"}`, http.StatusCreated)
+	if key["workspaceId"] != acmeID {
+		t.Fatalf("expected the key to be pinned to acme; got %#v", key)
+	}
+	doRequest(t, http.MethodGet, projectURL, http.Header{"X-Api-Key": {key["key"].(string)}}, "", http.StatusNotFound)
+
+	admin.Set(workspaceHeader, "00000000-0000-0000-0000-0000000000ff")
+	doRequest(t, http.MethodGet, ts.URL+"/v1/projects", admin, "", http.StatusNotFound)
+	admin.Set(workspaceHeader, "acme")
+	doRequest(t, http.MethodGet, ts.URL+"/v1/projects", admin, "", http.StatusBadRequest)
+
+	if got := doRequest(t, http.MethodGet, ts.URL+"/v1/organizations", http.Header{"X-Api-Key": {testAdminKey}}, "", http.StatusOK); len(got["organizations"].([]any)) != 3 {
+		t.Fatalf("expected the default organization and two more; got %#v", got["organizations"])
+	}
+	doRequest(t, http.MethodGet, ts.URL+"/v1/organizations", alice, "", http.StatusForbidden)
+}
@@ -2,22 +2,100 @@ package httpapi
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
-	"github.com/linus5304/project-manager-api/internal/domain"
 	"github.com/linus5304/project-manager-api/internal/store"
 )
 
 type createProjectInput struct {
-	Name string `json:"name"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	OwnerID     *string `json:"ownerId,omitempty"`
+	Color       string  `json:"color"`
+	Icon        string  `json:"icon"`
+	Key         string  `json:"key"`
 }
 
+// projectKeyRX matches project keys after upper-casing: a letter followed by
+// one to nine letters or digits, as in OPS or WEB2.
+var projectKeyRX = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}$`)
+
+// maxProjectIconLength bounds icon names and emoji, in characters.
+const maxProjectIconLength = 32
+
+var errUnknownOwner = errors.New("ownerId does not match any user")
+
+// readProjectKey upper-cases and checks a project key. An empty key is
+// allowed: on create it means none, on update it removes the key.
+func readProjectKey(key string) (string, error) {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if key != "" && !projectKeyRX.MatchString(key) {
+		return "", errors.New("key must be 2 to 10 letters or digits, starting with a letter")
+	}
+	return key, nil
+}
+
+// readProjectColor trims and checks a project color; an empty color is none.
+func readProjectColor(color string) (string, error) {
+	color = strings.TrimSpace(color)
+	if color != "" && !labelColorRX.MatchString(color) {
+		return "", errInvalidLabelColor
+	}
+	return color, nil
+}
+
+func readProjectIcon(icon string) (string, error) {
+	icon = strings.TrimSpace(icon)
+	if utf8.RuneCountInString(icon) > maxProjectIconLength {
+		return "", fmt.Errorf("icon must not be more than %d characters", maxProjectIconLength)
+	}
+	return icon, nil
+}
+
+// readOwnerID parses an ownerId from a request body. An empty string yields
+// uuid.Nil, which clears the owner on update.
+func readOwnerID(s *string) (*uuid.UUID, error) {
+	if s == nil {
+		return nil, nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return &uuid.Nil, nil
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return nil, errors.New("invalid ownerId")
+	}
+	return &id, nil
+}
+
+// projectErrorResponse maps store errors from project writes: a missing
+// project is a 404, an unknown owner a 400 and a key in use a 409.
+func projectErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		notFoundResponse(w, r)
+	case errors.Is(err, store.ErrUserNotFound):
+		badRequestResponse(w, r, errUnknownOwner)
+	case errors.Is(err, store.ErrProjectKeyTaken):
+		errorResponse(w, r, http.StatusConflict, "another project already uses this key")
+	default:
+		serverErrorResponse(w, r, err)
+	}
+}
+
+// metadata is the pagination envelope shared by list endpoints. Page is
+// omitted for cursor-based requests; NextCursor is set while more rows remain.
 type metadata struct {
-	Page         int `json:"page"`
-	PageSize     int `json:"pageSize"`
-	TotalRecords int `json:"totalRecords"`
+	Page         int    `json:"page,omitempty"`
+	PageSize     int    `json:"pageSize"`
+	TotalRecords int    `json:"totalRecords"`
+	NextCursor   string `json:"nextCursor,omitempty"`
 }
 
 func (app *Application) createProject(w http.ResponseWriter, r *http.Request) {
@@ -28,15 +106,41 @@ func (app *Application) createProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	input.Name = strings.TrimSpace(input.Name)
-	if input.Name == "" {
+	project := store.NewProject{
+		Name:        strings.TrimSpace(input.Name),
+		Description: strings.TrimSpace(input.Description),
+		CreatedBy:   app.callerSubject(r),
+	}
+	if project.Name == "" {
 		badRequestResponse(w, r, errors.New("name is required"))
 		return
 	}
 
-	p, err := app.store.InsertProject(r.Context(), input.Name)
+	var err error
+	if project.Key, err = readProjectKey(input.Key); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+	if project.Color, err = readProjectColor(input.Color); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+	if project.Icon, err = readProjectIcon(input.Icon); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+	ownerID, err := readOwnerID(input.OwnerID)
 	if err != nil {
-		serverErrorResponse(w, r, err)
+		badRequestResponse(w, r, err)
+		return
+	}
+	if ownerID != nil && *ownerID != uuid.Nil {
+		project.OwnerID = ownerID
+	}
+
+	p, err := app.store.InsertProject(r.Context(), project)
+	if err != nil {
+		projectErrorResponse(w, r, err)
 		return
 	}
 
@@ -65,6 +169,211 @@ func (app *Application) getProject(w http.ResponseWriter, r *http.Request) {
 	_ = writeJSON(w, http.StatusOK, p, nil)
 }
 
+type updateProjectInput struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	// OwnerID changes the owner; an empty string clears it.
+	OwnerID *string `json:"ownerId,omitempty"`
+	Color   *string `json:"color,omitempty"`
+	Icon    *string `json:"icon,omitempty"`
+	// Key changes the project key; an empty string removes it.
+	Key *string `json:"key,omitempty"`
+}
+
+func (app *Application) updateProject(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid project ID"))
+		return
+	}
+
+	var input updateProjectInput
+	if err := readJSON(w, r, &input); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	// Must provide at least one field for PATCH
+	if input.Name == nil && input.Description == nil && input.OwnerID == nil &&
+		input.Color == nil && input.Icon == nil && input.Key == nil {
+		badRequestResponse(w, r, errors.New("body must contain at least one of name, description, ownerId, color, icon or key"))
+		return
+	}
+
+	var update store.ProjectUpdate
+	if input.Name != nil {
+		n := strings.TrimSpace(*input.Name)
+		if n == "" {
+			badRequestResponse(w, r, errors.New("name cannot be empty"))
+			return
+		}
+		update.Name = &n
+	}
+	if input.Description != nil {
+		d := strings.TrimSpace(*input.Description)
+		update.Description = &d
+	}
+	if input.Key != nil {
+		k, err := readProjectKey(*input.Key)
+		if err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+		update.Key = &k
+	}
+	if input.Color != nil {
+		c, err := readProjectColor(*input.Color)
+		if err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+		update.Color = &c
+	}
+	if input.Icon != nil {
+		i, err := readProjectIcon(*input.Icon)
+		if err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+		update.Icon = &i
+	}
+	if update.OwnerID, err = readOwnerID(input.OwnerID); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	p, err := app.store.UpdateProject(r.Context(), id, update)
+	if err != nil {
+		projectErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, p, nil)
+}
+
+func (app *Application) deleteProject(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid project ID"))
+		return
+	}
+
+	if err := app.store.DeleteProject(r.Context(), id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *Application) archiveProject(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid project ID"))
+		return
+	}
+
+	p, err := app.store.ArchiveProject(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, p, nil)
+}
+
+// restoreProject undoes both archive and (not yet purged) delete.
+func (app *Application) restoreProject(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid project ID"))
+		return
+	}
+
+	p, err := app.store.RestoreProject(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, p, nil)
+}
+
+type cloneProjectInput struct {
+	Name          *string `json:"name"`
+	IncludeTasks  bool    `json:"includeTasks"`
+	ResetStatuses bool    `json:"resetStatuses"`
+}
+
+func (app *Application) cloneProject(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid project ID"))
+		return
+	}
+
+	// The body is optional; by default only the project's settings are copied
+	var input cloneProjectInput
+	if r.ContentLength != 0 {
+		if err := readJSON(w, r, &input); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+	}
+	if input.ResetStatuses && !input.IncludeTasks {
+		badRequestResponse(w, r, errors.New("resetStatuses requires includeTasks"))
+		return
+	}
+
+	clone := store.ProjectClone{
+		CreatedBy:     app.callerSubject(r),
+		IncludeTasks:  input.IncludeTasks,
+		ResetStatuses: input.ResetStatuses,
+	}
+	if input.Name != nil {
+		clone.Name = strings.TrimSpace(*input.Name)
+		if clone.Name == "" {
+			badRequestResponse(w, r, errors.New("name cannot be empty"))
+			return
+		}
+	} else {
+		src, err := app.store.GetProject(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				notFoundResponse(w, r)
+				return
+			}
+			serverErrorResponse(w, r, err)
+			return
+		}
+		clone.Name = src.Name + " (copy)"
+	}
+
+	p, err := app.store.CloneProject(r.Context(), id, clone)
+	if err != nil {
+		if errors.Is(err, store.ErrProjectNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusCreated, p, nil)
+}
+
 func (app *Application) listProjects(w http.ResponseWriter, r *http.Request) {
 	page, err := readIntQuery(r, "page", 1)
 	if err != nil {
@@ -83,34 +392,58 @@ func (app *Application) listProjects(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	all, err := app.store.ListProjects(r.Context())
+	after, err := readCursorQuery(r)
 	if err != nil {
-		serverErrorResponse(w, r, err)
+		badRequestResponse(w, r, err)
+		return
+	}
+	if after != nil && r.URL.Query().Has("page") {
+		badRequestResponse(w, r, errors.New("page and cursor cannot be used together"))
 		return
 	}
 
-	total := len(all)
-	offset := (page - 1) * pageSize
-
-	// If offset is beyond the end, return an empty page (not an error)
-	var pageItems []domain.Project
-	if offset < total {
-		end := offset + pageSize
-		if end > total {
-			end = total
+	params := store.ListProjectsParams{
+		// Fetch one extra row so we know whether a next page exists.
+		Limit:  pageSize + 1,
+		Offset: (page - 1) * pageSize,
+		After:  after,
+		Member: app.memberFilter(r),
+	}
+	for _, v := range readCSVQuery(r, "include") {
+		switch v {
+		case "archived":
+			params.IncludeArchived = true
+		case "deleted":
+			params.IncludeDeleted = true
+		default:
+			badRequestResponse(w, r, errors.New("include must be a list of: archived, deleted"))
+			return
 		}
-		pageItems = all[offset:end]
-	} else {
-		pageItems = []domain.Project{}
+	}
+
+	projects, total, err := app.store.ListProjects(r.Context(), params)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	md := metadata{
+		Page:         page,
+		PageSize:     pageSize,
+		TotalRecords: total,
+	}
+	if after != nil {
+		md.Page = 0
+	}
+	if len(projects) > pageSize {
+		projects = projects[:pageSize]
+		last := projects[len(projects)-1]
+		md.NextCursor = encodeCursor(store.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
 	}
 
 	env := map[string]any{
-		"projects": pageItems,
-		"metadata": metadata{
-			Page:         page,
-			PageSize:     pageSize,
-			TotalRecords: total,
-		},
+		"projects": projects,
+		"metadata": md,
 	}
 
 	_ = writeJSON(w, http.StatusOK, env, nil)
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -250,3 +251,359 @@ func TestListProjects_400_InvalidPage(t *testing.T) {
 		t.Fatalf("expected status 400 Bad Request; got %d; body=%s", res.StatusCode, string(b))
 	}
 }
+
+func TestListProjects_400_PageTooLarge(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	// Would be an offset past the largest int32
+	res, err := http.Get(ts.URL + "/v1/projects?page=30000000&page_size=100")
+	if err != nil {
+		t.Fatalf("GET /v1/projects failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		b, _ := io.ReadAll(res.Body)
+		t.Fatalf("expected status 400 Bad Request; got %d; body=%s", res.StatusCode, string(b))
+	}
+}
+
+func TestListProjects_200_SecondPage(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	createProject(t, ts, "Alpha")
+	createProject(t, ts, "Beta")
+	createProject(t, ts, "Gamma")
+
+	res, err := http.Get(ts.URL + "/v1/projects?page=2&page_size=2")
+	if err != nil {
+		t.Fatalf("GET /v1/projects failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		t.Fatalf("expected status 200 OK; got %d; body=%s", res.StatusCode, string(b))
+	}
+
+	var env map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
+		t.Fatalf("decode response body: %v", err)
+	}
+
+	raw, ok := env["projects"].([]any)
+	if !ok || len(raw) != 1 {
+		t.Fatalf("expected 1 project on page 2; got %#v", env["projects"])
+	}
+	first, _ := raw[0].(map[string]any)
+	if first["name"] != "Alpha" {
+		t.Fatalf("expected oldest project 'Alpha' on page 2; got %q", first["name"])
+	}
+
+	md, _ := env["metadata"].(map[string]any)
+	if md["totalRecords"] != float64(3) || md["page"] != float64(2) || md["pageSize"] != float64(2) {
+		t.Fatalf("unexpected metadata: %#v", md)
+	}
+}
+
+func TestListProjects_200_PageBeyondEnd(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	createProject(t, ts, "Alpha")
+
+	res, err := http.Get(ts.URL + "/v1/projects?page=5&page_size=10")
+	if err != nil {
+		t.Fatalf("GET /v1/projects failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	var env map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
+		t.Fatalf("decode response body: %v", err)
+	}
+
+	raw, ok := env["projects"].([]any)
+	if !ok || len(raw) != 0 {
+		t.Fatalf("expected empty projects array; got %#v", env["projects"])
+	}
+	md, _ := env["metadata"].(map[string]any)
+	if md["totalRecords"] != float64(1) {
+		t.Fatalf("expected totalRecords 1, got %v", md["totalRecords"])
+	}
+}
+
+func TestListProjects_200_CursorPages(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	createProject(t, ts, "Alpha")
+	createProject(t, ts, "Beta")
+	createProject(t, ts, "Gamma")
+
+	env := getJSON(t, ts.URL+"/v1/projects?page_size=2", http.StatusOK)
+	md, _ := env["metadata"].(map[string]any)
+	next, _ := md["nextCursor"].(string)
+	if next == "" {
+		t.Fatalf("expected nextCursor on first page; got %#v", md)
+	}
+
+	createProject(t, ts, "Delta")
+
+	env = getJSON(t, ts.URL+"/v1/projects?page_size=2&cursor="+next, http.StatusOK)
+	raw, _ := env["projects"].([]any)
+	if len(raw) != 1 {
+		t.Fatalf("expected 1 project after cursor; got %d", len(raw))
+	}
+	first, _ := raw[0].(map[string]any)
+	if first["name"] != "Alpha" {
+		t.Fatalf("expected 'Alpha' after cursor; got %q", first["name"])
+	}
+	md, _ = env["metadata"].(map[string]any)
+	if _, ok := md["page"]; ok {
+		t.Fatalf("expected page to be omitted for cursor requests; got %#v", md)
+	}
+}
+
+func TestListProjects_400_PageWithCursor(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	createProject(t, ts, "Alpha")
+	createProject(t, ts, "Beta")
+
+	env := getJSON(t, ts.URL+"/v1/projects?page_size=1", http.StatusOK)
+	md, _ := env["metadata"].(map[string]any)
+	next, _ := md["nextCursor"].(string)
+
+	getJSON(t, ts.URL+"/v1/projects?page=2&cursor="+next, http.StatusBadRequest)
+}
+
+func TestUpdateProject_200_Rename(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	id := createProject(t, ts, "Alpah")
+
+	got := doJSON(t, http.MethodPatch, ts.URL+"/v1/projects/"+id, `{"name": "  Alpha  "}`, http.StatusOK)
+	if got["name"] != "Alpha" {
+		t.Fatalf("expected renamed project 'Alpha'; got %q", got["name"])
+	}
+
+	got = getJSON(t, ts.URL+"/v1/projects/"+id, http.StatusOK)
+	if got["name"] != "Alpha" {
+		t.Fatalf("expected stored name 'Alpha'; got %q", got["name"])
+	}
+}
+
+func TestUpdateProject_400_InvalidBody(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	id := createProject(t, ts, "Alpha")
+
+	doJSON(t, http.MethodPatch, ts.URL+"/v1/projects/"+id, `{}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPatch, ts.URL+"/v1/projects/"+id, `{"name": "   "}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPatch, ts.URL+"/v1/projects/invalid-uuid", `{"name": "Beta"}`, http.StatusBadRequest)
+}
+
+func TestUpdateProject_404_NotFound(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	doJSON(t, http.MethodPatch, ts.URL+"/v1/projects/123e4567-e89b-12d3-a456-426614174000", `{"name": "Beta"}`, http.StatusNotFound)
+}
+
+func TestDeleteProject_204_HidesProjectAndTasks(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	id := createProject(t, ts, "Alpha")
+	createTask(t, ts, id, "T1", "")
+
+	doJSON(t, http.MethodDelete, ts.URL+"/v1/projects/"+id, "", http.StatusNoContent)
+
+	getJSON(t, ts.URL+"/v1/projects/"+id, http.StatusNotFound)
+	getJSON(t, ts.URL+"/v1/projects/"+id+"/tasks", http.StatusNotFound)
+	doJSON(t, http.MethodDelete, ts.URL+"/v1/projects/"+id, "", http.StatusNotFound)
+}
+
+func projectNames(t *testing.T, env map[string]any) []string {
+	t.Helper()
+
+	raw, ok := env["projects"].([]any)
+	if !ok {
+		t.Fatalf("expected projects array, got %#v", env["projects"])
+	}
+	names := make([]string, 0, len(raw))
+	for _, item := range raw {
+		p, _ := item.(map[string]any)
+		name, _ := p["name"].(string)
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestArchiveProject_HiddenFromDefaultListing(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	createProject(t, ts, "Alpha")
+	beta := createProject(t, ts, "Beta")
+
+	got := doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+beta+"/archive", "", http.StatusOK)
+	if got["archivedAt"] == nil {
+		t.Fatalf("expected archivedAt to be set; got %#v", got)
+	}
+
+	// Archived projects stay readable directly
+	getJSON(t, ts.URL+"/v1/projects/"+beta, http.StatusOK)
+
+	env := getJSON(t, ts.URL+"/v1/projects", http.StatusOK)
+	if names := projectNames(t, env); len(names) != 1 || names[0] != "Alpha" {
+		t.Fatalf("expected only Alpha in default listing; got %v", names)
+	}
+
+	env = getJSON(t, ts.URL+"/v1/projects?include=archived", http.StatusOK)
+	if names := projectNames(t, env); len(names) != 2 {
+		t.Fatalf("expected both projects with include=archived; got %v", names)
+	}
+	md, _ := env["metadata"].(map[string]any)
+	if md["totalRecords"] != float64(2) {
+		t.Fatalf("expected totalRecords 2; got %v", md["totalRecords"])
+	}
+
+	got = doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+beta+"/restore", "", http.StatusOK)
+	if _, ok := got["archivedAt"]; ok {
+		t.Fatalf("expected archivedAt to be cleared; got %#v", got)
+	}
+	env = getJSON(t, ts.URL+"/v1/projects", http.StatusOK)
+	if names := projectNames(t, env); len(names) != 2 {
+		t.Fatalf("expected restored project in default listing; got %v", names)
+	}
+}
+
+func TestRestoreProject_UndoesDelete(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	id := createProject(t, ts, "Alpha")
+	createTask(t, ts, id, "T1", "")
+
+	doJSON(t, http.MethodDelete, ts.URL+"/v1/projects/"+id, "", http.StatusNoContent)
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+id+"/archive", "", http.StatusNotFound)
+
+	env := getJSON(t, ts.URL+"/v1/projects?include=deleted", http.StatusOK)
+	if names := projectNames(t, env); len(names) != 1 || names[0] != "Alpha" {
+		t.Fatalf("expected deleted project with include=deleted; got %v", names)
+	}
+
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+id+"/restore", "", http.StatusOK)
+
+	env = getJSON(t, ts.URL+"/v1/projects/"+id+"/tasks", http.StatusOK)
+	if titles := taskTitles(t, env); len(titles) != 1 || titles[0] != "T1" {
+		t.Fatalf("expected tasks to survive soft delete; got %v", titles)
+	}
+}
+
+func TestListProjects_400_InvalidInclude(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	getJSON(t, ts.URL+"/v1/projects?include=everything", http.StatusBadRequest)
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects/123e4567-e89b-12d3-a456-426614174000/restore", "", http.StatusNotFound)
+}
+
+func TestProjects_Clone(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Sprint 1")
+	task := createTask(t, ts, pid, "Retro", "")
+	doJSON(t, http.MethodPatch, ts.URL+"/v1/projects/"+pid+"/tasks/"+task["id"].(string), `{"status": "done"}`, http.StatusOK)
+	cloneURL := ts.URL + "/v1/projects/" + pid + "/clone"
+
+	bare := doJSON(t, http.MethodPost, cloneURL, ``, http.StatusCreated)
+	if bare["name"] != "Sprint 1 (copy)" || bare["id"] == pid {
+		t.Fatalf("unexpected clone: %#v", bare)
+	}
+	env := getJSON(t, ts.URL+"/v1/projects/"+bare["id"].(string)+"/tasks", http.StatusOK)
+	if got := taskTitles(t, env); len(got) != 0 {
+		t.Fatalf("expected no tasks without includeTasks; got %v", got)
+	}
+
+	full := doJSON(t, http.MethodPost, cloneURL, `{"name": "Sprint 2", "includeTasks": true, "resetStatuses": true}`, http.StatusCreated)
+	if full["name"] != "Sprint 2" {
+		t.Fatalf("unexpected clone: %#v", full)
+	}
+	env = getJSON(t, ts.URL+"/v1/projects/"+full["id"].(string)+"/tasks?status=todo", http.StatusOK)
+	if got := taskTitles(t, env); len(got) != 1 || got[0] != "Retro" {
+		t.Fatalf("expected Retro back in todo; got %v", got)
+	}
+
+	doJSON(t, http.MethodPost, cloneURL, `{"resetStatuses": true}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, cloneURL, `{"name": " "}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects/00000000-0000-0000-0000-000000000001/clone", ``, http.StatusNotFound)
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects/00000000-0000-0000-0000-000000000001/clone", `{"name": "x"}`, http.StatusNotFound)
+}
+
+func TestProjects_Metadata(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	owner := createUser(t, ts, "Ada", "ada@example.com")
+	p := doJSON(t, http.MethodPost, ts.URL+"/v1/projects",
+		`{"name": "Operations", "description": " On call ", "ownerId": "`+owner+`", "color": "#1f6feb", "icon": "wrench", "key": "ops"}`,
+		http.StatusCreated)
+	if p["description"] != "On call" || p["ownerId"] != owner || p["color"] != "#1f6feb" || p["icon"] != "wrench" || p["key"] != "OPS" {
+		t.Fatalf("unexpected project: %#v", p)
+	}
+	if _, ok := p["updatedAt"].(string); !ok {
+		t.Fatalf("expected updatedAt; got %#v", p)
+	}
+	pid := p["id"].(string)
+
+	task := createTask(t, ts, pid, "Rotate certificates", "")
+	if task["number"] != float64(1) || task["identifier"] != "OPS-1" {
+		t.Fatalf("expected OPS-1; got %v %v", task["number"], task["identifier"])
+	}
+
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects", `{"name": "Other", "key": "OPS"}`, http.StatusConflict)
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects", `{"name": "Other", "key": "1X"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects", `{"name": "Other", "key": "TOOLONGKEY1"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects", `{"name": "Other", "color": "blue"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects", `{"name": "Other", "icon": "`+strings.Repeat("x", 33)+`"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects", `{"name": "Other", "ownerId": "nope"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects", `{"name": "Other", "ownerId": "00000000-0000-0000-0000-000000000001"}`, http.StatusBadRequest)
+
+	got := doJSON(t, http.MethodPatch, ts.URL+"/v1/projects/"+pid, `{"ownerId": "", "key": "infra", "color": ""}`, http.StatusOK)
+	if _, ok := got["ownerId"]; ok || got["key"] != "INFRA" || got["color"] != nil || got["name"] != "Operations" {
+		t.Fatalf("unexpected project after update: %#v", got)
+	}
+	task = getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks/"+task["id"].(string), http.StatusOK)
+	if task["identifier"] != "INFRA-1" {
+		t.Fatalf("expected INFRA-1 after rekeying; got %v", task["identifier"])
+	}
+
+	got = doJSON(t, http.MethodPatch, ts.URL+"/v1/projects/"+pid, `{"key": ""}`, http.StatusOK)
+	if _, ok := got["key"]; ok {
+		t.Fatalf("expected key removed; got %#v", got)
+	}
+	doJSON(t, http.MethodPatch, ts.URL+"/v1/projects/"+pid, `{"key": "a"}`, http.StatusBadRequest)
+}
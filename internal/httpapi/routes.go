@@ -1,23 +1,85 @@
 package httpapi
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/linus5304/project-manager-api/internal/domain"
+)
 
 func (app *Application) Routes() http.Handler {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("GET /healthz", app.healthz)
 	mux.HandleFunc("POST /v1/projects", app.createProject)
-	mux.HandleFunc("GET /v1/projects/{id}", app.getProject)
+	mux.HandleFunc("GET /v1/projects/{id}", app.requireProjectRole(domain.RoleViewer, app.getProject))
+	mux.HandleFunc("PATCH /v1/projects/{id}", app.requireProjectRole(domain.RoleEditor, app.updateProject))
+	mux.HandleFunc("DELETE /v1/projects/{id}", app.requireProjectRole(domain.RoleOwner, app.deleteProject))
+	mux.HandleFunc("POST /v1/projects/{id}/archive", app.requireProjectRole(domain.RoleOwner, app.archiveProject))
+	mux.HandleFunc("POST /v1/projects/{id}/restore", app.requireProjectRole(domain.RoleOwner, app.restoreProject))
+	mux.HandleFunc("POST /v1/projects/{id}/clone", app.requireProjectRole(domain.RoleViewer, app.cloneProject))
+	mux.HandleFunc("GET /v1/projects/{id}/workflow", app.requireProjectRole(domain.RoleViewer, app.getWorkflow))
+	mux.HandleFunc("GET /v1/projects/{id}/board", app.requireProjectRole(domain.RoleViewer, app.getBoard))
+	mux.HandleFunc("PUT /v1/projects/{id}/workflow", app.requireProjectRole(domain.RoleEditor, app.updateWorkflow))
 	mux.HandleFunc("GET /v1/projects", app.listProjects)
+	mux.HandleFunc("GET /v1/projects/{id}/members", app.requireProjectRole(domain.RoleViewer, app.listMembers))
+	mux.HandleFunc("PUT /v1/projects/{id}/members/{subject}", app.requireProjectRole(domain.RoleOwner, app.putMember))
+	mux.HandleFunc("DELETE /v1/projects/{id}/members/{subject}", app.requireProjectRole(domain.RoleOwner, app.removeMember))
+
+	mux.HandleFunc("POST /v1/projects/{id}/tasks", app.requireProjectRole(domain.RoleEditor, app.createTask))
+	mux.HandleFunc("GET /v1/projects/{id}/tasks", app.requireProjectRole(domain.RoleViewer, app.listTasks))
+	mux.HandleFunc("GET /v1/projects/{projectId}/tasks/{taskId}", app.requireProjectRole(domain.RoleViewer, app.getTask))
+	mux.HandleFunc("GET /v1/projects/{projectId}/tasks/{taskId}/{number}", app.getTaskByNumber) // tasks/by-number/{number}
+	mux.HandleFunc("PATCH /v1/projects/{projectId}/tasks/{taskId}", app.requireProjectRole(domain.RoleEditor, app.updateTask))
+	mux.HandleFunc("DELETE /v1/projects/{projectId}/tasks/{taskId}", app.requireProjectRole(domain.RoleEditor, app.deleteTask))
+	mux.HandleFunc("GET /v1/projects/{projectId}/tasks/{taskId}/subtasks", app.requireProjectRole(domain.RoleViewer, app.listSubtasks))
+	mux.HandleFunc("POST /v1/projects/{projectId}/tasks/{taskId}/move", app.requireProjectRole(domain.RoleEditor, app.moveTask))
+	mux.HandleFunc("POST /v1/projects/{projectId}/tasks/{taskId}/copy", app.requireProjectRole(domain.RoleViewer, app.copyTask))
+	mux.HandleFunc("GET /v1/projects/{id}/tasks/blocked", app.requireProjectRole(domain.RoleViewer, app.listBlockedTasks))
+	mux.HandleFunc("GET /v1/projects/{projectId}/tasks/{taskId}/blockers", app.requireProjectRole(domain.RoleViewer, app.listTaskBlockers))
+	mux.HandleFunc("PUT /v1/projects/{projectId}/tasks/{taskId}/blockers/{blockerId}", app.requireProjectRole(domain.RoleEditor, app.addTaskBlocker))
+	mux.HandleFunc("DELETE /v1/projects/{projectId}/tasks/{taskId}/blockers/{blockerId}", app.requireProjectRole(domain.RoleEditor, app.removeTaskBlocker))
+	mux.HandleFunc("GET /v1/tasks/overdue", app.listOverdueTasks)
+
+	mux.HandleFunc("POST /v1/projects/{projectId}/tasks/{taskId}/comments", app.requireProjectRole(domain.RoleEditor, app.createComment))
+	mux.HandleFunc("GET /v1/projects/{projectId}/tasks/{taskId}/comments", app.requireProjectRole(domain.RoleViewer, app.listComments))
+	mux.HandleFunc("GET /v1/projects/{projectId}/tasks/{taskId}/comments/{commentId}", app.requireProjectRole(domain.RoleViewer, app.getComment))
+	mux.HandleFunc("PATCH /v1/projects/{projectId}/tasks/{taskId}/comments/{commentId}", app.requireProjectRole(domain.RoleEditor, app.updateComment))
+	mux.HandleFunc("DELETE /v1/projects/{projectId}/tasks/{taskId}/comments/{commentId}", app.requireProjectRole(domain.RoleEditor, app.deleteComment))
+
+	mux.HandleFunc("POST /v1/projects/{id}/labels", app.requireProjectRole(domain.RoleEditor, app.createLabel))
+	mux.HandleFunc("GET /v1/projects/{id}/labels", app.requireProjectRole(domain.RoleViewer, app.listLabels))
+	mux.HandleFunc("GET /v1/projects/{projectId}/labels/{labelId}", app.requireProjectRole(domain.RoleViewer, app.getLabel))
+	mux.HandleFunc("PATCH /v1/projects/{projectId}/labels/{labelId}", app.requireProjectRole(domain.RoleEditor, app.updateLabel))
+	mux.HandleFunc("DELETE /v1/projects/{projectId}/labels/{labelId}", app.requireProjectRole(domain.RoleEditor, app.deleteLabel))
+	mux.HandleFunc("PUT /v1/projects/{projectId}/tasks/{taskId}/labels/{labelId}", app.requireProjectRole(domain.RoleEditor, app.attachTaskLabel))
+	mux.HandleFunc("DELETE /v1/projects/{projectId}/tasks/{taskId}/labels/{labelId}", app.requireProjectRole(domain.RoleEditor, app.detachTaskLabel))
+
+	mux.HandleFunc("POST /v1/templates", app.createTemplate)
+	mux.HandleFunc("GET /v1/templates", app.listTemplates)
+	mux.HandleFunc("GET /v1/templates/{id}", app.getTemplate)
+	mux.HandleFunc("DELETE /v1/templates/{id}", app.deleteTemplate)
+	mux.HandleFunc("POST /v1/templates/{id}/instantiate", app.instantiateTemplate)
+
+	mux.HandleFunc("POST /v1/users", app.createUser)
+	mux.HandleFunc("GET /v1/users/{id}", app.getUser)
+	mux.HandleFunc("GET /v1/users/{id}/tasks", app.listUserTasks)
+
+	mux.HandleFunc("POST /v1/api-keys", app.requireAdmin(app.createAPIKey))
+	mux.HandleFunc("GET /v1/api-keys", app.requireAdmin(app.listAPIKeys))
+	mux.HandleFunc("DELETE /v1/api-keys/{id}", app.requireAdmin(app.revokeAPIKey))
+
+	mux.HandleFunc("POST /v1/organizations", app.requireAdmin(app.createOrganization))
+	mux.HandleFunc("GET /v1/organizations", app.requireAdmin(app.listOrganizations))
 
-	mux.HandleFunc("POST /v1/projects/{id}/tasks", app.createTask)
-	mux.HandleFunc("GET /v1/projects/{id}/tasks", app.listTasks)
-	mux.HandleFunc("PATCH /v1/projects/{projectId}/tasks/{taskId}", app.updateTask)
+	mux.HandleFunc("GET /v1/audit", app.requireAdmin(app.listAuditEntries))
 
 	mux.HandleFunc("GET /livez", app.livez)
 	mux.HandleFunc("GET /readyz", app.readyz)
 
 	h := http.Handler(mux)
+	h = app.actorMiddleware(h)
+	h = app.workspaceMiddleware(h)
+	h = app.authenticateMiddleware(h)
 	h = app.logRequestMiddleware(h)
 	h = app.recoverPanicMiddleware(h)
 	h = app.requestIDMiddleware(h)
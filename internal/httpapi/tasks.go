@@ -3,15 +3,61 @@ package httpapi
 import (
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/domain"
 	"github.com/linus5304/project-manager-api/internal/store"
 )
 
 type createTaskInput struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	AssigneeID  *string `json:"assigneeId,omitempty"`
+	DueDate     *string `json:"dueDate,omitempty"`
+	Priority    string  `json:"priority"`
+	// ParentTaskID makes the new task a subtask of another task in the project.
+	ParentTaskID *string `json:"parentTaskId,omitempty"`
+}
+
+var errUnknownAssignee = errors.New("assigneeId does not match any user")
+
+var errUnknownParentTask = errors.New("parentTaskId does not match a task in this project")
+
+// readParentTaskID parses a parentTaskId from a request body. An empty string
+// yields uuid.Nil, which moves the task back to the top level on update.
+func readParentTaskID(s *string) (*uuid.UUID, error) {
+	if s == nil {
+		return nil, nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return &uuid.Nil, nil
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return nil, errors.New("invalid parentTaskId")
+	}
+	return &id, nil
+}
+
+// readAssigneeID parses an assigneeId from a request body. An empty string
+// yields uuid.Nil, which unassigns the task on update.
+func readAssigneeID(s *string) (*uuid.UUID, error) {
+	if s == nil {
+		return nil, nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return &uuid.Nil, nil
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return nil, errors.New("invalid assigneeId")
+	}
+	return &id, nil
 }
 
 func (app *Application) createTask(w http.ResponseWriter, r *http.Request) {
@@ -36,12 +82,60 @@ func (app *Application) createTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	t, err := app.store.InsertTask(r.Context(), projectID, input.Title, input.Description)
+	assigneeID, err := readAssigneeID(input.AssigneeID)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+	if assigneeID != nil && *assigneeID == uuid.Nil {
+		assigneeID = nil
+	}
+
+	dueDate, err := readDueDate(input.DueDate)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+	if dueDate != nil && dueDate.IsZero() {
+		dueDate = nil
+	}
+
+	input.Priority = strings.TrimSpace(input.Priority)
+	if input.Priority != "" && !isValidTaskPriority(input.Priority) {
+		badRequestResponse(w, r, errInvalidPriority)
+		return
+	}
+
+	parentTaskID, err := readParentTaskID(input.ParentTaskID)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+	if parentTaskID != nil && *parentTaskID == uuid.Nil {
+		parentTaskID = nil
+	}
+
+	t, err := app.store.InsertTask(r.Context(), projectID, store.NewTask{
+		Title:        input.Title,
+		Description:  input.Description,
+		AssigneeID:   assigneeID,
+		DueDate:      dueDate,
+		Priority:     input.Priority,
+		ParentTaskID: parentTaskID,
+	})
 	if err != nil {
 		if errors.Is(err, store.ErrProjectNotFound) {
 			notFoundResponse(w, r)
 			return
 		}
+		if errors.Is(err, store.ErrUserNotFound) {
+			badRequestResponse(w, r, errUnknownAssignee)
+			return
+		}
+		if errors.Is(err, store.ErrParentTaskNotFound) {
+			badRequestResponse(w, r, errUnknownParentTask)
+			return
+		}
 		serverErrorResponse(w, r, err)
 		return
 	}
@@ -56,7 +150,116 @@ func (app *Application) listTasks(w http.ResponseWriter, r *http.Request) {
 		badRequestResponse(w, r, errors.New("invalid project id"))
 		return
 	}
-	tasks, err := app.store.ListTasks(r.Context(), projectID)
+
+	app.writeTaskList(w, r, projectID, nil)
+}
+
+// listSubtasks lists the direct subtasks of a task, taking the same query
+// parameters as listTasks.
+func (app *Application) listSubtasks(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, err := readTaskPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	if _, err := app.store.GetTask(r.Context(), projectID, taskID); err != nil {
+		taskErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeTaskList(w, r, projectID, &taskID)
+}
+
+// writeTaskList reads the task list query parameters and writes one page of
+// the project's tasks, limited to the direct subtasks of parentTaskID if set.
+func (app *Application) writeTaskList(w http.ResponseWriter, r *http.Request, projectID uuid.UUID, parentTaskID *uuid.UUID) {
+	page, err := readIntQuery(r, "page", 1)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	pageSize, err := readIntQuery(r, "page_size", 20)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := validatePageParams(page, pageSize); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	statuses := readCSVQuery(r, "status")
+	if len(statuses) > 0 {
+		workflow, err := app.store.GetWorkflow(r.Context(), projectID)
+		if err != nil {
+			if errors.Is(err, store.ErrProjectNotFound) {
+				notFoundResponse(w, r)
+				return
+			}
+			serverErrorResponse(w, r, err)
+			return
+		}
+		for _, s := range statuses {
+			if _, ok := workflow.Status(s); !ok {
+				badRequestResponse(w, r, unknownStatusError(workflow))
+				return
+			}
+		}
+	}
+
+	sortKey := store.TaskSort(r.URL.Query().Get("sort"))
+	switch sortKey {
+	case "":
+		sortKey = store.TaskSortNewest
+	case store.TaskSortNewest, store.TaskSortOldest, store.TaskSortTitle:
+		// ok
+	default:
+		badRequestResponse(w, r, errors.New("sort must be one of: created_at, -created_at, title"))
+		return
+	}
+
+	dueBefore, err := readTimeQuery(r, "due_before")
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+	dueAfter, err := readTimeQuery(r, "due_after")
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	after, err := readCursorQuery(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+	if after != nil && r.URL.Query().Has("page") {
+		badRequestResponse(w, r, errors.New("page and cursor cannot be used together"))
+		return
+	}
+	if after != nil && sortKey != store.TaskSortNewest {
+		badRequestResponse(w, r, errors.New("cursor can only be used with sort=-created_at"))
+		return
+	}
+
+	// Fetch one extra row so we know whether a next page exists.
+	tasks, total, err := app.store.ListTasks(r.Context(), projectID, store.ListTasksParams{
+		Limit:     pageSize + 1,
+		Offset:    (page - 1) * pageSize,
+		After:     after,
+		Statuses:  statuses,
+		Query:     strings.TrimSpace(r.URL.Query().Get("q")),
+		DueBefore: dueBefore,
+		DueAfter:  dueAfter,
+		Labels:    readCSVQuery(r, "label"),
+		Sort:      sortKey,
+
+		ParentTaskID: parentTaskID,
+	})
 	if err != nil {
 		if errors.Is(err, store.ErrProjectNotFound) {
 			notFoundResponse(w, r)
@@ -66,35 +269,174 @@ func (app *Application) listTasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	md := metadata{
+		Page:         page,
+		PageSize:     pageSize,
+		TotalRecords: total,
+	}
+	if after != nil {
+		md.Page = 0
+	}
+	if len(tasks) > pageSize {
+		tasks = tasks[:pageSize]
+		// Cursors only describe the newest-first order.
+		if sortKey == store.TaskSortNewest {
+			last := tasks[len(tasks)-1]
+			md.NextCursor = encodeCursor(store.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+		}
+	}
+
 	env := map[string]any{
-		"tasks": tasks,
-		"metadata": metadata{
-			Page:         1,
-			PageSize:     len(tasks),
-			TotalRecords: len(tasks),
-		},
+		"tasks":    tasks,
+		"metadata": md,
 	}
 
 	_ = writeJSON(w, http.StatusOK, env, nil)
 }
 
+var errInvalidPriority = errors.New("priority must be one of: low, medium, high, urgent")
+
+func isValidTaskPriority(s string) bool {
+	switch s {
+	case "low", "medium", "high", "urgent":
+		return true
+	default:
+		return false
+	}
+}
+
+// readDueDate parses a dueDate from a request body. An empty string yields
+// the zero time, which clears the due date on update.
+func readDueDate(s *string) (*time.Time, error) {
+	if s == nil {
+		return nil, nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return &time.Time{}, nil
+	}
+	t, err := parseDueDate(v)
+	if err != nil {
+		return nil, errors.New("dueDate must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+	}
+	return &t, nil
+}
+
 type updateTaskInput struct {
 	Title       *string `json:"title,omitempty"`
 	Description *string `json:"description,omitempty"`
 	Status      *string `json:"status,omitempty"`
+	// AssigneeID reassigns the task; an empty string unassigns it.
+	AssigneeID *string `json:"assigneeId,omitempty"`
+	// DueDate reschedules the task; an empty string clears it.
+	DueDate  *string `json:"dueDate,omitempty"`
+	Priority *string `json:"priority,omitempty"`
+	// ParentTaskID moves the task under another task; an empty string moves
+	// it back to the top level.
+	ParentTaskID *string `json:"parentTaskId,omitempty"`
 }
 
-func (app *Application) updateTask(w http.ResponseWriter, r *http.Request) {
-	projectIDStr := r.PathValue("projectId")
-	projectID, err := uuid.Parse(projectIDStr)
+// readTaskPathIDs parses the {projectId} and {taskId} path values.
+func readTaskPathIDs(r *http.Request) (projectID, taskID uuid.UUID, err error) {
+	projectID, err = uuid.Parse(r.PathValue("projectId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("invalid project id")
+	}
+	taskID, err = uuid.Parse(r.PathValue("taskId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("invalid task id")
+	}
+	return projectID, taskID, nil
+}
+
+// taskErrorResponse maps store errors from a project-scoped task lookup:
+// a missing project or task is a 404, anything else a 500.
+func taskErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, store.ErrProjectNotFound) || errors.Is(err, store.ErrTaskNotFound) {
+		notFoundResponse(w, r)
+		return
+	}
+	serverErrorResponse(w, r, err)
+}
+
+// statusChangeErrorResponse extends taskErrorResponse with the ways a status
+// change can be refused: an unknown status is a 400, a transition the workflow
+// does not allow or open blockers a 409.
+func (app *Application) statusChangeErrorResponse(w http.ResponseWriter, r *http.Request, projectID, taskID uuid.UUID, err error) {
+	switch {
+	case errors.Is(err, store.ErrTaskBlocked):
+		app.taskBlockedResponse(w, r, projectID, taskID)
+	case errors.Is(err, store.ErrUnknownStatus):
+		app.unknownStatusResponse(w, r, projectID)
+	case errors.Is(err, store.ErrTransitionNotAllowed):
+		errorResponse(w, r, http.StatusConflict, err.Error())
+	default:
+		taskErrorResponse(w, r, err)
+	}
+}
+
+func (app *Application) getTask(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, err := readTaskPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	t, err := app.store.GetTask(r.Context(), projectID, taskID)
+	if err != nil {
+		taskErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, t, nil)
+}
+
+// postOnlyTaskRoutes are the sub-resources of a task that only take POST.
+var postOnlyTaskRoutes = map[string]bool{"move": true, "copy": true}
+
+// getTaskByNumber serves GET .../tasks/by-number/{number}. The route is
+// registered as .../tasks/{taskId}/{number} because a literal "by-number"
+// segment would conflict with .../tasks/{taskId}/subtasks and its siblings in
+// ServeMux. Any other {taskId} is turned away before the role check, with the
+// 405 ServeMux would give for the sub-resources that only take POST and a 404
+// otherwise.
+func (app *Application) getTaskByNumber(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("taskId") != "by-number" {
+		if postOnlyTaskRoutes[r.PathValue("number")] {
+			methodNotAllowedResponse(w, r, http.MethodPost)
+			return
+		}
+		notFoundResponse(w, r)
+		return
+	}
+	app.requireProjectRole(domain.RoleViewer, app.taskByNumber)(w, r)
+}
+
+func (app *Application) taskByNumber(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(r.PathValue("projectId"))
 	if err != nil {
 		badRequestResponse(w, r, errors.New("invalid project id"))
 		return
 	}
-	taskIDStr := r.PathValue("taskId")
-	taskID, err := uuid.Parse(taskIDStr)
+	number, err := strconv.ParseInt(r.PathValue("number"), 10, 64)
+	if err != nil || number < 1 {
+		badRequestResponse(w, r, errors.New("task number must be a positive integer"))
+		return
+	}
+
+	t, err := app.store.GetTaskByNumber(r.Context(), projectID, number)
 	if err != nil {
-		badRequestResponse(w, r, errors.New("invalid task id"))
+		taskErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, t, nil)
+}
+
+func (app *Application) updateTask(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, err := readTaskPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
 		return
 	}
 
@@ -105,8 +447,10 @@ func (app *Application) updateTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Must provide at least one field for PATCH
-	if input.Title == nil && input.Description == nil && input.Status == nil {
-		badRequestResponse(w, r, errors.New("body must contain at least one of title, description or status"))
+	if input.Title == nil && input.Description == nil && input.Status == nil &&
+		input.AssigneeID == nil && input.DueDate == nil && input.Priority == nil &&
+		input.ParentTaskID == nil {
+		badRequestResponse(w, r, errors.New("body must contain at least one of title, description, status, assigneeId, dueDate, priority or parentTaskId"))
 		return
 	}
 
@@ -125,34 +469,142 @@ func (app *Application) updateTask(w http.ResponseWriter, r *http.Request) {
 		input.Description = &d
 	}
 
+	// Statuses are checked against the project's workflow by the store
 	if input.Status != nil {
 		s := strings.TrimSpace(*input.Status)
-		switch s {
-		case "todo", "doing", "done":
-			// ok
-		default:
-			badRequestResponse(w, r, errors.New("status must be one of: todo, doing, done"))
+		if s == "" {
+			badRequestResponse(w, r, errors.New("status cannot be empty"))
 			return
 		}
 		input.Status = &s
 	}
 
+	if input.Priority != nil {
+		p := strings.TrimSpace(*input.Priority)
+		if !isValidTaskPriority(p) {
+			badRequestResponse(w, r, errInvalidPriority)
+			return
+		}
+		input.Priority = &p
+	}
+
+	assigneeID, err := readAssigneeID(input.AssigneeID)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	dueDate, err := readDueDate(input.DueDate)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	parentTaskID, err := readParentTaskID(input.ParentTaskID)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
 	update := store.TaskUpdate{
-		Title:       input.Title,
-		Description: input.Description,
-		Status:      input.Status,
+		Title:        input.Title,
+		Description:  input.Description,
+		Status:       input.Status,
+		AssigneeID:   assigneeID,
+		DueDate:      dueDate,
+		Priority:     input.Priority,
+		ParentTaskID: parentTaskID,
 	}
 
 	updated, err := app.store.UpdateTask(r.Context(), projectID, taskID, update)
 	if err != nil {
-		if errors.Is(err, store.ErrProjectNotFound) || errors.Is(err, store.ErrTaskNotFound) {
-			notFoundResponse(w, r)
+		if errors.Is(err, store.ErrUserNotFound) {
+			badRequestResponse(w, r, errUnknownAssignee)
 			return
 		}
-		serverErrorResponse(w, r, err)
+		if errors.Is(err, store.ErrParentTaskNotFound) {
+			badRequestResponse(w, r, errUnknownParentTask)
+			return
+		}
+		if errors.Is(err, store.ErrTaskCycle) {
+			badRequestResponse(w, r, err)
+			return
+		}
+		app.statusChangeErrorResponse(w, r, projectID, taskID, err)
 		return
 	}
 
 	_ = writeJSON(w, http.StatusOK, updated, nil)
 
 }
+
+func (app *Application) deleteTask(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, err := readTaskPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	// By default subtasks move up to the deleted task's parent;
+	// ?subtasks=cascade deletes them along with it.
+	policy := store.SubtaskPolicy(r.URL.Query().Get("subtasks"))
+	switch policy {
+	case "":
+		policy = store.SubtasksReparent
+	case store.SubtasksReparent, store.SubtasksCascade:
+		// ok
+	default:
+		badRequestResponse(w, r, errors.New("subtasks must be one of: reparent, cascade"))
+		return
+	}
+
+	if err := app.store.DeleteTask(r.Context(), projectID, taskID, policy); err != nil {
+		taskErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listOverdueTasks returns open tasks past their due date across all live
+// projects, earliest due first.
+func (app *Application) listOverdueTasks(w http.ResponseWriter, r *http.Request) {
+	page, err := readIntQuery(r, "page", 1)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	pageSize, err := readIntQuery(r, "page_size", 20)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := validatePageParams(page, pageSize); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	tasks, total, err := app.store.ListOverdueTasks(r.Context(), store.ListOverdueTasksParams{
+		AsOf:   time.Now().UTC(),
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+		Member: app.memberFilter(r),
+	})
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	env := map[string]any{
+		"tasks": tasks,
+		"metadata": metadata{
+			Page:         page,
+			PageSize:     pageSize,
+			TotalRecords: total,
+		},
+	}
+
+	_ = writeJSON(w, http.StatusOK, env, nil)
+}
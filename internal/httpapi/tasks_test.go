@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/linus5304/project-manager-api/internal/store"
 )
 
 func createProject(t *testing.T, ts *httptest.Server, name string) string {
@@ -59,6 +61,71 @@ func createTask(t *testing.T, ts *httptest.Server, projectID, title, desc string
 	return got
 }
 
+func getJSON(t *testing.T, url string, wantStatus int) map[string]any {
+	t.Helper()
+	return doJSON(t, http.MethodGet, url, "", wantStatus)
+}
+
+// doJSON sends body (if any) as JSON and decodes the response, failing the test
+// unless the status matches. Responses without a body decode to nil.
+func doJSON(t *testing.T, method, url, body string, wantStatus int) map[string]any {
+	t.Helper()
+	return doRequest(t, method, url, nil, body, wantStatus)
+}
+
+// doRequest is doJSON with extra request headers.
+func doRequest(t *testing.T, method, url string, header http.Header, body string, wantStatus int) map[string]any {
+	t.Helper()
+
+	var rdr io.Reader
+	if body != "" {
+		rdr = bytes.NewReader([]byte(body))
+	}
+	req, err := http.NewRequest(method, url, rdr)
+	if err != nil {
+		t.Fatalf("creating request failed: %v", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != wantStatus {
+		b, _ := io.ReadAll(res.Body)
+		t.Fatalf("%s %s: expected status %d; got %d; body=%s", method, url, wantStatus, res.StatusCode, string(b))
+	}
+
+	var got map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&got); err != nil && err != io.EOF {
+		t.Fatalf("decode response body: %v", err)
+	}
+	return got
+}
+
+func taskTitles(t *testing.T, env map[string]any) []string {
+	t.Helper()
+
+	raw, ok := env["tasks"].([]any)
+	if !ok {
+		t.Fatalf("expected tasks array, got %#v", env["tasks"])
+	}
+	titles := make([]string, 0, len(raw))
+	for _, item := range raw {
+		task, _ := item.(map[string]any)
+		title, _ := task["title"].(string)
+		titles = append(titles, title)
+	}
+	return titles
+}
+
 func TestCreateTask_201_DefaultTodo(t *testing.T) {
 	app := newTestApp()
 	ts := httptest.NewServer(app.Routes())
@@ -391,3 +458,370 @@ func TestUpdateTask_404_TaskMissing(t *testing.T) {
 		t.Fatalf("expected status 404 Not Found; got %d; body=%s", res.StatusCode, string(b))
 	}
 }
+
+func TestListTasks_200_CursorPages(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	createTask(t, ts, pid, "T1", "")
+	createTask(t, ts, pid, "T2", "")
+	createTask(t, ts, pid, "T3", "")
+
+	env := getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks?page_size=2", http.StatusOK)
+	tasks, _ := env["tasks"].([]any)
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks on first page; got %d", len(tasks))
+	}
+	md, _ := env["metadata"].(map[string]any)
+	next, _ := md["nextCursor"].(string)
+	if next == "" {
+		t.Fatalf("expected nextCursor on first page; got %#v", md)
+	}
+
+	// Inserting between fetches must not repeat or skip rows.
+	createTask(t, ts, pid, "T4", "")
+
+	env = getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks?page_size=2&cursor="+next, http.StatusOK)
+	tasks, _ = env["tasks"].([]any)
+	if len(tasks) != 1 {
+		t.Fatalf("expected 1 task on second page; got %d", len(tasks))
+	}
+	first, _ := tasks[0].(map[string]any)
+	if first["title"] != "T1" {
+		t.Fatalf("expected 'T1' after cursor; got %q", first["title"])
+	}
+	md, _ = env["metadata"].(map[string]any)
+	if _, ok := md["nextCursor"]; ok {
+		t.Fatalf("expected no nextCursor on last page; got %#v", md)
+	}
+}
+
+func TestListTasks_400_InvalidCursor(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks?cursor=not-a-cursor", http.StatusBadRequest)
+}
+
+func TestListTasks_200_Paginated(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	createTask(t, ts, pid, "T1", "")
+	createTask(t, ts, pid, "T2", "")
+	createTask(t, ts, pid, "T3", "")
+
+	env := getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks?page=2&page_size=2", http.StatusOK)
+	if got := taskTitles(t, env); len(got) != 1 || got[0] != "T1" {
+		t.Fatalf("expected [T1] on page 2; got %v", got)
+	}
+	md, _ := env["metadata"].(map[string]any)
+	if md["page"] != float64(2) || md["pageSize"] != float64(2) || md["totalRecords"] != float64(3) {
+		t.Fatalf("unexpected metadata: %#v", md)
+	}
+}
+
+func TestListTasks_200_FilterAndSort(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	createTask(t, ts, pid, "Write docs", "")
+	doing := createTask(t, ts, pid, "Fix login bug", "")
+	createTask(t, ts, pid, "Deploy", "")
+	done := createTask(t, ts, pid, "Fix signup bug", "")
+
+	doJSON(t, http.MethodPatch, ts.URL+"/v1/projects/"+pid+"/tasks/"+doing["id"].(string), `{"status": "doing"}`, http.StatusOK)
+	doJSON(t, http.MethodPatch, ts.URL+"/v1/projects/"+pid+"/tasks/"+done["id"].(string), `{"status": "done"}`, http.StatusOK)
+
+	env := getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks?status=doing,done&sort=title", http.StatusOK)
+	got := taskTitles(t, env)
+	if len(got) != 2 || got[0] != "Fix login bug" || got[1] != "Fix signup bug" {
+		t.Fatalf("expected doing/done tasks sorted by title; got %v", got)
+	}
+
+	env = getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks?q=FIX&sort=created_at", http.StatusOK)
+	got = taskTitles(t, env)
+	if len(got) != 2 || got[0] != "Fix login bug" || got[1] != "Fix signup bug" {
+		t.Fatalf("expected oldest-first title matches; got %v", got)
+	}
+	md, _ := env["metadata"].(map[string]any)
+	if md["totalRecords"] != float64(2) {
+		t.Fatalf("expected totalRecords 2 for filtered list; got %v", md["totalRecords"])
+	}
+}
+
+func TestListTasks_400_InvalidQuery(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	for _, qs := range []string{
+		"page=0",
+		"page_size=101",
+		"status=todo,blocked",
+		"sort=priority",
+		"sort=title&cursor=" + encodeCursor(store.Cursor{}),
+	} {
+		getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks?"+qs, http.StatusBadRequest)
+	}
+}
+
+func TestGetTask_200(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	task := createTask(t, ts, pid, "T1", "D1")
+	tid, _ := task["id"].(string)
+
+	got := getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks/"+tid, http.StatusOK)
+	if got["id"] != tid || got["title"] != "T1" || got["projectId"] != pid {
+		t.Fatalf("unexpected task: %#v", got)
+	}
+}
+
+func TestGetTask_404_And_400(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	other := createProject(t, ts, "Beta")
+	task := createTask(t, ts, pid, "T1", "D1")
+	tid, _ := task["id"].(string)
+	missing := "00000000-0000-0000-0000-000000000000"
+
+	getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks/"+missing, http.StatusNotFound)
+	getJSON(t, ts.URL+"/v1/projects/"+missing+"/tasks/"+tid, http.StatusNotFound)
+	getJSON(t, ts.URL+"/v1/projects/"+other+"/tasks/"+tid, http.StatusNotFound)
+	getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks/invalid-uuid", http.StatusBadRequest)
+}
+
+func TestDeleteTask_204(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	task := createTask(t, ts, pid, "T1", "D1")
+	tid, _ := task["id"].(string)
+
+	doJSON(t, http.MethodDelete, ts.URL+"/v1/projects/"+pid+"/tasks/"+tid, "", http.StatusNoContent)
+	getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks/"+tid, http.StatusNotFound)
+	doJSON(t, http.MethodDelete, ts.URL+"/v1/projects/"+pid+"/tasks/"+tid, "", http.StatusNotFound)
+}
+
+func TestCreateTask_DueDateAndPriority(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	url := ts.URL + "/v1/projects/" + pid + "/tasks"
+
+	got := doJSON(t, http.MethodPost, url, `{"title": "T1", "dueDate": "2030-01-02", "priority": "high"}`, http.StatusCreated)
+	if got["dueDate"] != "2030-01-02T00:00:00Z" || got["priority"] != "high" {
+		t.Fatalf("unexpected task: %#v", got)
+	}
+
+	got = createTask(t, ts, pid, "T2", "")
+	if got["priority"] != "medium" {
+		t.Fatalf("expected default priority medium; got %#v", got["priority"])
+	}
+	if _, ok := got["dueDate"]; ok {
+		t.Fatalf("expected no dueDate; got %#v", got["dueDate"])
+	}
+
+	doJSON(t, http.MethodPost, url, `{"title": "T3", "priority": "critical"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, url, `{"title": "T3", "dueDate": "next week"}`, http.StatusBadRequest)
+}
+
+func TestUpdateTask_DueDateAndPriority(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	task := createTask(t, ts, pid, "T1", "")
+	url := ts.URL + "/v1/projects/" + pid + "/tasks/" + task["id"].(string)
+
+	got := doJSON(t, http.MethodPatch, url, `{"dueDate": "2030-01-02T15:04:05+02:00", "priority": "urgent"}`, http.StatusOK)
+	if got["dueDate"] != "2030-01-02T13:04:05Z" || got["priority"] != "urgent" {
+		t.Fatalf("unexpected task: %#v", got)
+	}
+
+	got = doJSON(t, http.MethodPatch, url, `{"dueDate": ""}`, http.StatusOK)
+	if _, ok := got["dueDate"]; ok {
+		t.Fatalf("expected dueDate cleared; got %#v", got["dueDate"])
+	}
+
+	doJSON(t, http.MethodPatch, url, `{"priority": ""}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPatch, url, `{"dueDate": "tomorrow"}`, http.StatusBadRequest)
+}
+
+func TestListTasks_200_DueFilters(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	url := ts.URL + "/v1/projects/" + pid + "/tasks"
+	doJSON(t, http.MethodPost, url, `{"title": "Jan", "dueDate": "2030-01-15"}`, http.StatusCreated)
+	doJSON(t, http.MethodPost, url, `{"title": "Feb", "dueDate": "2030-02-15"}`, http.StatusCreated)
+	createTask(t, ts, pid, "Undated", "")
+
+	env := getJSON(t, url+"?due_before=2030-02-01", http.StatusOK)
+	if got := taskTitles(t, env); len(got) != 1 || got[0] != "Jan" {
+		t.Fatalf("expected [Jan]; got %v", got)
+	}
+	env = getJSON(t, url+"?due_after=2030-01-15T00:00:00Z&sort=title", http.StatusOK)
+	if got := taskTitles(t, env); len(got) != 1 || got[0] != "Feb" {
+		t.Fatalf("expected [Feb]; got %v", got)
+	}
+
+	getJSON(t, url+"?due_before=soon", http.StatusBadRequest)
+}
+
+func TestListOverdueTasks_200(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	alpha := createProject(t, ts, "Alpha")
+	beta := createProject(t, ts, "Beta")
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+alpha+"/tasks", `{"title": "Old", "dueDate": "2001-01-01"}`, http.StatusCreated)
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+beta+"/tasks", `{"title": "Older", "dueDate": "2000-01-01"}`, http.StatusCreated)
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+beta+"/tasks", `{"title": "Future", "dueDate": "2999-01-01"}`, http.StatusCreated)
+	done := doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+beta+"/tasks", `{"title": "Done", "dueDate": "2000-06-01"}`, http.StatusCreated)
+	doJSON(t, http.MethodPatch, ts.URL+"/v1/projects/"+beta+"/tasks/"+done["id"].(string), `{"status": "done"}`, http.StatusOK)
+
+	env := getJSON(t, ts.URL+"/v1/tasks/overdue", http.StatusOK)
+	if got := taskTitles(t, env); len(got) != 2 || got[0] != "Older" || got[1] != "Old" {
+		t.Fatalf("expected [Older Old]; got %v", got)
+	}
+
+	getJSON(t, ts.URL+"/v1/tasks/overdue?page=0", http.StatusBadRequest)
+}
+
+func TestSubtasks_HierarchyAndRollup(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	tasksURL := ts.URL + "/v1/projects/" + pid + "/tasks"
+	parentID := createTask(t, ts, pid, "Parent", "")["id"].(string)
+
+	child := doJSON(t, http.MethodPost, tasksURL, `{"title": "Child", "parentTaskId": "`+parentID+`"}`, http.StatusCreated)
+	if child["parentTaskId"] != parentID {
+		t.Fatalf("expected child under parent; got %#v", child)
+	}
+	childID := child["id"].(string)
+	doJSON(t, http.MethodPost, tasksURL, `{"title": "Other child", "parentTaskId": "`+parentID+`"}`, http.StatusCreated)
+	doJSON(t, http.MethodPatch, tasksURL+"/"+childID, `{"status": "done"}`, http.StatusOK)
+
+	parent := getJSON(t, tasksURL+"/"+parentID, http.StatusOK)
+	rollup, _ := parent["subtasks"].(map[string]any)
+	if rollup["done"] != float64(1) || rollup["total"] != float64(2) {
+		t.Fatalf("expected rollup 1/2; got %#v", parent["subtasks"])
+	}
+
+	env := getJSON(t, tasksURL+"/"+parentID+"/subtasks?sort=title", http.StatusOK)
+	if got := taskTitles(t, env); len(got) != 2 || got[0] != "Child" || got[1] != "Other child" {
+		t.Fatalf("unexpected subtasks: %v", got)
+	}
+
+	doJSON(t, http.MethodPatch, tasksURL+"/"+parentID, `{"parentTaskId": "`+childID+`"}`, http.StatusBadRequest)
+	moved := doJSON(t, http.MethodPatch, tasksURL+"/"+childID, `{"parentTaskId": ""}`, http.StatusOK)
+	if _, ok := moved["parentTaskId"]; ok {
+		t.Fatalf("expected child moved to top level; got %#v", moved)
+	}
+
+	doJSON(t, http.MethodDelete, tasksURL+"/"+parentID+"?subtasks=cascade", "", http.StatusNoContent)
+	env = getJSON(t, tasksURL, http.StatusOK)
+	if got := taskTitles(t, env); len(got) != 1 || got[0] != "Child" {
+		t.Fatalf("expected only the detached child to survive; got %v", got)
+	}
+}
+
+func TestSubtasks_Validation(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	otherPID := createProject(t, ts, "Beta")
+	tasksURL := ts.URL + "/v1/projects/" + pid + "/tasks"
+	taskID := createTask(t, ts, pid, "T1", "")["id"].(string)
+	foreignID := createTask(t, ts, otherPID, "Foreign", "")["id"].(string)
+	missing := "00000000-0000-0000-0000-000000000001"
+
+	doJSON(t, http.MethodPost, tasksURL, `{"title": "x", "parentTaskId": "nope"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, tasksURL, `{"title": "x", "parentTaskId": "`+foreignID+`"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPatch, tasksURL+"/"+taskID, `{"parentTaskId": "`+missing+`"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPatch, tasksURL+"/"+taskID, `{"parentTaskId": "`+taskID+`"}`, http.StatusBadRequest)
+
+	getJSON(t, tasksURL+"/"+missing+"/subtasks", http.StatusNotFound)
+	getJSON(t, tasksURL+"/"+taskID+"/subtasks?page=0", http.StatusBadRequest)
+	doJSON(t, http.MethodDelete, tasksURL+"/"+taskID+"?subtasks=orphan", "", http.StatusBadRequest)
+}
+
+func TestGetTaskByNumber(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	createTask(t, ts, pid, "First", "")
+	second := createTask(t, ts, pid, "Second", "")
+	if second["number"] != float64(2) {
+		t.Fatalf("expected number 2; got %v", second["number"])
+	}
+
+	got := getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks/by-number/2", http.StatusOK)
+	if got["id"] != second["id"] || got["title"] != "Second" {
+		t.Fatalf("unexpected task: %#v", got)
+	}
+
+	getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks/by-number/3", http.StatusNotFound)
+	getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks/by-number/0", http.StatusBadRequest)
+	getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks/by-number/two", http.StatusBadRequest)
+	getJSON(t, ts.URL+"/v1/projects/00000000-0000-0000-0000-000000000001/tasks/by-number/1", http.StatusNotFound)
+	getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks/"+second["id"].(string)+"/unknown", http.StatusNotFound)
+
+	// The sub-routes of a task still win over the by-number route
+	getJSON(t, ts.URL+"/v1/projects/"+pid+"/tasks/"+second["id"].(string)+"/subtasks", http.StatusOK)
+
+	// A GET on a sub-resource that only takes POST is a 405, as elsewhere
+	res, err := http.Get(ts.URL + "/v1/projects/" + pid + "/tasks/" + second["id"].(string) + "/move")
+	if err != nil {
+		t.Fatalf("GET move: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed || res.Header.Get("Allow") != http.MethodPost {
+		t.Fatalf("expected 405 with Allow: POST; got %d, %q", res.StatusCode, res.Header.Get("Allow"))
+	}
+}
+
+func TestGetTaskByNumber_UnknownSubResourceSkipsRoleCheck(t *testing.T) {
+	ts := newRBACTestServer(t)
+
+	created := doAs(t, "alice", http.MethodPost, ts.URL+"/v1/projects", `{"name": "Alpha"}`, http.StatusCreated)
+	tasksURL := ts.URL + "/v1/projects/" + created["id"].(string) + "/tasks"
+	task := doAs(t, "alice", http.MethodPost, tasksURL, `{"title": "T1"}`, http.StatusCreated)
+
+	// Turned away before the membership of the caller is checked
+	doAs(t, "mallory", http.MethodGet, tasksURL+"/"+task["id"].(string)+"/unknown", "", http.StatusNotFound)
+	doAs(t, "mallory", http.MethodGet, tasksURL+"/"+task["id"].(string)+"/copy", "", http.StatusMethodNotAllowed)
+	doAs(t, "mallory", http.MethodGet, tasksURL+"/by-number/1", "", http.StatusNotFound)
+}
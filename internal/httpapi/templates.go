@@ -0,0 +1,166 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/domain"
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+type createTemplateInput struct {
+	Name  string                 `json:"name"`
+	Tasks []domain.TaskBlueprint `json:"tasks"`
+}
+
+type instantiateTemplateInput struct {
+	Name *string `json:"name"`
+}
+
+// maxTemplateTasks bounds the number of tasks a template creates.
+const maxTemplateTasks = 500
+
+// readTemplateTasks normalizes and validates task blueprints the way
+// createTask validates a single task.
+func readTemplateTasks(tasks []domain.TaskBlueprint) ([]domain.TaskBlueprint, error) {
+	if len(tasks) > maxTemplateTasks {
+		return nil, fmt.Errorf("tasks must not contain more than %d tasks", maxTemplateTasks)
+	}
+	out := make([]domain.TaskBlueprint, 0, len(tasks))
+	for i, t := range tasks {
+		t.Title = strings.TrimSpace(t.Title)
+		if t.Title == "" {
+			return nil, fmt.Errorf("tasks[%d]: title is required", i)
+		}
+		t.Description = strings.TrimSpace(t.Description)
+		t.Priority = strings.TrimSpace(t.Priority)
+		if t.Priority == "" {
+			t.Priority = store.DefaultTaskPriority
+		}
+		if !isValidTaskPriority(t.Priority) {
+			return nil, fmt.Errorf("tasks[%d]: %w", i, errInvalidPriority)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func readTemplateID(r *http.Request) (uuid.UUID, error) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return uuid.Nil, errors.New("invalid template id")
+	}
+	return id, nil
+}
+
+func (app *Application) createTemplate(w http.ResponseWriter, r *http.Request) {
+	var input createTemplateInput
+	if err := readJSON(w, r, &input); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		badRequestResponse(w, r, errors.New("name is required"))
+		return
+	}
+	tasks, err := readTemplateTasks(input.Tasks)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	t, err := app.store.InsertTemplate(r.Context(), input.Name, tasks)
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusCreated, t, nil)
+}
+
+func (app *Application) listTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := app.store.ListTemplates(r.Context())
+	if err != nil {
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, map[string]any{"templates": templates}, nil)
+}
+
+func (app *Application) getTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := readTemplateID(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	t, err := app.store.GetTemplate(r.Context(), id)
+	if err != nil {
+		templateErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, t, nil)
+}
+
+func (app *Application) deleteTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := readTemplateID(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.DeleteTemplate(r.Context(), id); err != nil {
+		templateErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *Application) instantiateTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := readTemplateID(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	// The body is optional; without a name the project is named after the template
+	var input instantiateTemplateInput
+	if r.ContentLength != 0 {
+		if err := readJSON(w, r, &input); err != nil {
+			badRequestResponse(w, r, err)
+			return
+		}
+	}
+	var name string
+	if input.Name != nil {
+		name = strings.TrimSpace(*input.Name)
+		if name == "" {
+			badRequestResponse(w, r, errors.New("name cannot be empty"))
+			return
+		}
+	}
+
+	p, err := app.store.InstantiateTemplate(r.Context(), id, name, app.callerSubject(r))
+	if err != nil {
+		templateErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusCreated, p, nil)
+}
+
+func templateErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, store.ErrTemplateNotFound) {
+		notFoundResponse(w, r)
+		return
+	}
+	serverErrorResponse(w, r, err)
+}
@@ -0,0 +1,70 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTemplates_CRUDAndInstantiate(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	tpl := doJSON(t, http.MethodPost, ts.URL+"/v1/templates", `{
+		"name": " Onboarding ",
+		"tasks": [
+			{"title": " Kickoff call ", "priority": "high"},
+			{"title": "Provision accounts", "description": "SSO"}
+		]
+	}`, http.StatusCreated)
+	tasks, _ := tpl["tasks"].([]any)
+	if tpl["name"] != "Onboarding" || len(tasks) != 2 || tasks[0].(map[string]any)["title"] != "Kickoff call" ||
+		tasks[1].(map[string]any)["priority"] != "medium" {
+		t.Fatalf("unexpected template: %#v", tpl)
+	}
+	tplURL := ts.URL + "/v1/templates/" + tpl["id"].(string)
+
+	list := getJSON(t, ts.URL+"/v1/templates", http.StatusOK)
+	if items, _ := list["templates"].([]any); len(items) != 1 {
+		t.Fatalf("expected one template; got %#v", list)
+	}
+	getJSON(t, tplURL, http.StatusOK)
+
+	p := doJSON(t, http.MethodPost, tplURL+"/instantiate", ``, http.StatusCreated)
+	if p["name"] != "Onboarding" {
+		t.Fatalf("expected project named after the template; got %#v", p)
+	}
+	env := getJSON(t, ts.URL+"/v1/projects/"+p["id"].(string)+"/tasks?sort=title", http.StatusOK)
+	if got := taskTitles(t, env); len(got) != 2 || got[0] != "Kickoff call" {
+		t.Fatalf("expected the template's tasks; got %v", got)
+	}
+	p = doJSON(t, http.MethodPost, tplURL+"/instantiate", `{"name": "Acme"}`, http.StatusCreated)
+	if p["name"] != "Acme" {
+		t.Fatalf("expected renamed project; got %#v", p)
+	}
+
+	doJSON(t, http.MethodDelete, tplURL, ``, http.StatusNoContent)
+	getJSON(t, tplURL, http.StatusNotFound)
+	doJSON(t, http.MethodPost, tplURL+"/instantiate", ``, http.StatusNotFound)
+	doJSON(t, http.MethodDelete, tplURL, ``, http.StatusNotFound)
+}
+
+func TestTemplates_Validation(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	for _, body := range []string{
+		`{"name": " "}`,
+		`{"name": "x", "tasks": [{"title": " "}]}`,
+		`{"name": "x", "tasks": [{"title": "a", "priority": "asap"}]}`,
+		`{"name": "x", "tasks": [{"title": "a", "status": "done"}]}`,
+	} {
+		doJSON(t, http.MethodPost, ts.URL+"/v1/templates", body, http.StatusBadRequest)
+	}
+
+	tpl := doJSON(t, http.MethodPost, ts.URL+"/v1/templates", `{"name": "x"}`, http.StatusCreated)
+	doJSON(t, http.MethodPost, ts.URL+"/v1/templates/"+tpl["id"].(string)+"/instantiate", `{"name": ""}`, http.StatusBadRequest)
+	getJSON(t, ts.URL+"/v1/templates/nope", http.StatusBadRequest)
+}
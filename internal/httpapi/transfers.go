@@ -0,0 +1,137 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/domain"
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+type copyTaskInput struct {
+	TargetProjectID *string `json:"targetProjectId"`
+}
+
+// transferResponse is a moved or copied task plus what came along with it
+// and what was left behind.
+type transferResponse struct {
+	Task    domain.Task `json:"task"`
+	Carried struct {
+		Subtasks      int      `json:"subtasks"`
+		Comments      int      `json:"comments"`
+		Labels        []string `json:"labels"`
+		CreatedLabels []string `json:"createdLabels"`
+	} `json:"carried"`
+	Dropped struct {
+		Dependencies int `json:"dependencies"`
+		Subtasks     int `json:"subtasks"`
+	} `json:"dropped"`
+}
+
+func newTransferResponse(task domain.Task, report store.TransferReport) transferResponse {
+	var resp transferResponse
+	resp.Task = task
+	resp.Carried.Subtasks = report.Subtasks
+	resp.Carried.Comments = report.Comments
+	resp.Carried.Labels = append([]string{}, report.Labels...)
+	resp.Carried.CreatedLabels = append([]string{}, report.CreatedLabels...)
+	resp.Dropped.Dependencies = report.DroppedDependencies
+	resp.Dropped.Subtasks = report.DroppedSubtasks
+	return resp
+}
+
+func readTargetProjectID(s string) (uuid.UUID, error) {
+	id, err := uuid.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return uuid.Nil, errors.New("targetProjectId must be a valid UUID")
+	}
+	return id, nil
+}
+
+// transferErrorResponse maps store errors from moving or copying a task.
+func transferErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, store.ErrTargetProjectNotFound):
+		errorResponse(w, r, http.StatusNotFound, err.Error())
+	case errors.Is(err, store.ErrTransferSameProject):
+		badRequestResponse(w, r, err)
+	default:
+		taskErrorResponse(w, r, err)
+	}
+}
+
+// authorizeTargetProject checks that the caller may add tasks to the project
+// a task is moved or copied to, and writes the error response when not.
+func (app *Application) authorizeTargetProject(w http.ResponseWriter, r *http.Request, targetProjectID uuid.UUID) bool {
+	err := app.checkProjectRole(r, targetProjectID, domain.RoleEditor)
+	if errors.Is(err, store.ErrProjectNotFound) {
+		err = store.ErrTargetProjectNotFound
+	}
+	if err != nil {
+		if errors.Is(err, store.ErrForbidden) {
+			forbiddenResponse(w, r, "your role on the target project does not allow this")
+		} else {
+			transferErrorResponse(w, r, err)
+		}
+		return false
+	}
+	return true
+}
+
+// transferTask handles a move with targetProjectId.
+func (app *Application) transferTask(w http.ResponseWriter, r *http.Request, projectID, taskID uuid.UUID, target string) {
+	targetProjectID, err := readTargetProjectID(target)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	if !app.authorizeTargetProject(w, r, targetProjectID) {
+		return
+	}
+
+	task, report, err := app.store.TransferTask(r.Context(), projectID, taskID, targetProjectID)
+	if err != nil {
+		transferErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, newTransferResponse(task, report), nil)
+}
+
+func (app *Application) copyTask(w http.ResponseWriter, r *http.Request) {
+	projectID, taskID, err := readTaskPathIDs(r)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	var input copyTaskInput
+	if err := readJSON(w, r, &input); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+	if input.TargetProjectID == nil {
+		badRequestResponse(w, r, errors.New("targetProjectId is required"))
+		return
+	}
+	targetProjectID, err := readTargetProjectID(*input.TargetProjectID)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	if !app.authorizeTargetProject(w, r, targetProjectID) {
+		return
+	}
+
+	task, report, err := app.store.CopyTask(r.Context(), projectID, taskID, targetProjectID)
+	if err != nil {
+		transferErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusCreated, newTransferResponse(task, report), nil)
+}
@@ -0,0 +1,63 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransfers_MoveAndCopy(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	uid := createUser(t, ts, "Ada", "ada@example.com")
+	src := createProject(t, ts, "Alpha")
+	dst := createProject(t, ts, "Beta")
+	tid := createTask(t, ts, src, "Misfiled", "")["id"].(string)
+	taskURL := ts.URL + "/v1/projects/" + src + "/tasks/" + tid
+
+	label := doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+src+"/labels", `{"name": "bug"}`, http.StatusCreated)
+	doJSON(t, http.MethodPut, taskURL+"/labels/"+label["id"].(string), ``, http.StatusOK)
+	doJSON(t, http.MethodPost, taskURL+"/comments", `{"authorId": "`+uid+`", "body": "wrong project"}`, http.StatusCreated)
+
+	cp := doJSON(t, http.MethodPost, taskURL+"/copy", `{"targetProjectId": "`+dst+`"}`, http.StatusCreated)
+	task := cp["task"].(map[string]any)
+	if task["projectId"] != dst || task["id"] == tid || task["title"] != "Misfiled" {
+		t.Fatalf("unexpected copy: %#v", cp)
+	}
+	carried := cp["carried"].(map[string]any)
+	if carried["comments"] != float64(1) || len(carried["createdLabels"].([]any)) != 1 {
+		t.Fatalf("unexpected copy report: %#v", cp)
+	}
+
+	moved := doJSON(t, http.MethodPost, taskURL+"/move", `{"targetProjectId": "`+dst+`"}`, http.StatusOK)
+	if task := moved["task"].(map[string]any); task["projectId"] != dst || task["id"] != tid {
+		t.Fatalf("unexpected move: %#v", moved)
+	}
+	// The copy already created bug in Beta
+	if carried := moved["carried"].(map[string]any); len(carried["labels"].([]any)) != 1 || len(carried["createdLabels"].([]any)) != 0 {
+		t.Fatalf("unexpected move report: %#v", moved)
+	}
+	getJSON(t, taskURL, http.StatusNotFound)
+	comments := getJSON(t, ts.URL+"/v1/projects/"+dst+"/tasks/"+tid+"/comments", http.StatusOK)
+	if items, _ := comments["comments"].([]any); len(items) != 1 {
+		t.Fatalf("expected the comment to follow the task; got %#v", comments)
+	}
+
+	movedURL := ts.URL + "/v1/projects/" + dst + "/tasks/" + tid
+	missing := "00000000-0000-0000-0000-000000000001"
+	doJSON(t, http.MethodPost, movedURL+"/move", `{"targetProjectId": "`+missing+`"}`, http.StatusNotFound)
+	doJSON(t, http.MethodPost, movedURL+"/copy", `{"targetProjectId": "`+missing+`"}`, http.StatusNotFound)
+	doJSON(t, http.MethodPost, movedURL+"/move", `{"targetProjectId": "`+dst+`"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, movedURL+"/move", `{"targetProjectId": "nope"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, movedURL+"/move", `{"targetProjectId": "`+src+`", "status": "doing"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, movedURL+"/copy", `{}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, taskURL+"/copy", `{"targetProjectId": "`+dst+`"}`, http.StatusNotFound)
+
+	// Copying within a project is a duplicate
+	dup := doJSON(t, http.MethodPost, movedURL+"/copy", `{"targetProjectId": "`+dst+`"}`, http.StatusCreated)
+	if dup["task"].(map[string]any)["projectId"] != dst {
+		t.Fatalf("unexpected duplicate: %#v", dup)
+	}
+}
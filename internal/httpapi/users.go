@@ -0,0 +1,124 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+type createUserInput struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func (app *Application) createUser(w http.ResponseWriter, r *http.Request) {
+	var input createUserInput
+	if err := readJSON(w, r, &input); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	input.Email = strings.ToLower(strings.TrimSpace(input.Email))
+
+	if input.Name == "" {
+		badRequestResponse(w, r, errors.New("name is required"))
+		return
+	}
+	if addr, err := mail.ParseAddress(input.Email); err != nil || addr.Address != input.Email {
+		badRequestResponse(w, r, errors.New("email must be a valid address"))
+		return
+	}
+
+	u, err := app.store.InsertUser(r.Context(), input.Name, input.Email)
+	if err != nil {
+		if errors.Is(err, store.ErrEmailTaken) {
+			errorResponse(w, r, http.StatusConflict, "a user with this email already exists")
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusCreated, u, nil)
+}
+
+func (app *Application) getUser(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid user id"))
+		return
+	}
+
+	u, err := app.store.GetUser(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrUserNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, u, nil)
+}
+
+// listUserTasks answers "what am I working on?": the tasks assigned to a user
+// across every live project, newest first.
+func (app *Application) listUserTasks(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid user id"))
+		return
+	}
+
+	page, err := readIntQuery(r, "page", 1)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	pageSize, err := readIntQuery(r, "page_size", 20)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := validatePageParams(page, pageSize); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	// Each project has its own workflow, so any status name is a valid filter
+	statuses := readCSVQuery(r, "status")
+
+	tasks, total, err := app.store.ListUserTasks(r.Context(), id, store.ListUserTasksParams{
+		Limit:    pageSize,
+		Offset:   (page - 1) * pageSize,
+		Statuses: statuses,
+		Member:   app.memberFilter(r),
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrUserNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	env := map[string]any{
+		"tasks": tasks,
+		"metadata": metadata{
+			Page:         page,
+			PageSize:     pageSize,
+			TotalRecords: total,
+		},
+	}
+
+	_ = writeJSON(w, http.StatusOK, env, nil)
+}
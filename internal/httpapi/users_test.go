@@ -0,0 +1,101 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func createUser(t *testing.T, ts *httptest.Server, name, email string) string {
+	t.Helper()
+
+	got := doJSON(t, http.MethodPost, ts.URL+"/v1/users", `{"name": "`+name+`", "email": "`+email+`"}`, http.StatusCreated)
+	id, ok := got["id"].(string)
+	if !ok || id == "" {
+		t.Fatalf("expected non-empty user ID, got %#v", got["id"])
+	}
+	return id
+}
+
+func TestCreateUser_201_And_Conflict(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	uid := createUser(t, ts, "Ada", "Ada@Example.com")
+
+	got := getJSON(t, ts.URL+"/v1/users/"+uid, http.StatusOK)
+	if got["email"] != "ada@example.com" || got["name"] != "Ada" {
+		t.Fatalf("unexpected user: %#v", got)
+	}
+
+	doJSON(t, http.MethodPost, ts.URL+"/v1/users", `{"name": "Ada", "email": "ada@example.com"}`, http.StatusConflict)
+	doJSON(t, http.MethodPost, ts.URL+"/v1/users", `{"name": "", "email": "x@example.com"}`, http.StatusBadRequest)
+	doJSON(t, http.MethodPost, ts.URL+"/v1/users", `{"name": "X", "email": "not-an-email"}`, http.StatusBadRequest)
+	getJSON(t, ts.URL+"/v1/users/00000000-0000-0000-0000-000000000000", http.StatusNotFound)
+	getJSON(t, ts.URL+"/v1/users/invalid-uuid", http.StatusBadRequest)
+}
+
+func TestTaskAssignee_CreateUpdateAndList(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	uid := createUser(t, ts, "Ada", "ada@example.com")
+	alpha := createProject(t, ts, "Alpha")
+	beta := createProject(t, ts, "Beta")
+
+	t1 := doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+alpha+"/tasks", `{"title": "T1", "assigneeId": "`+uid+`"}`, http.StatusCreated)
+	if t1["assigneeId"] != uid {
+		t.Fatalf("expected assigneeId %s; got %#v", uid, t1["assigneeId"])
+	}
+
+	t2 := createTask(t, ts, beta, "T2", "")
+	if _, ok := t2["assigneeId"]; ok {
+		t.Fatalf("expected unassigned task to omit assigneeId; got %#v", t2)
+	}
+	t2URL := ts.URL + "/v1/projects/" + beta + "/tasks/" + t2["id"].(string)
+	doJSON(t, http.MethodPatch, t2URL, `{"assigneeId": "`+uid+`"}`, http.StatusOK)
+
+	env := getJSON(t, ts.URL+"/v1/users/"+uid+"/tasks", http.StatusOK)
+	if got := taskTitles(t, env); !slices.Equal(got, []string{"T2", "T1"}) {
+		t.Fatalf("expected [T2 T1]; got %v", got)
+	}
+
+	unassigned := doJSON(t, http.MethodPatch, t2URL, `{"assigneeId": ""}`, http.StatusOK)
+	if _, ok := unassigned["assigneeId"]; ok {
+		t.Fatalf("expected task to be unassigned; got %#v", unassigned)
+	}
+	env = getJSON(t, ts.URL+"/v1/users/"+uid+"/tasks?status=todo", http.StatusOK)
+	if got := taskTitles(t, env); !slices.Equal(got, []string{"T1"}) {
+		t.Fatalf("expected [T1]; got %v", got)
+	}
+
+	getJSON(t, ts.URL+"/v1/users/00000000-0000-0000-0000-000000000000/tasks", http.StatusNotFound)
+	// Workflows are per project, so an unknown status simply matches nothing
+	env = getJSON(t, ts.URL+"/v1/users/"+uid+"/tasks?status=blocked", http.StatusOK)
+	if got := taskTitles(t, env); len(got) != 0 {
+		t.Fatalf("expected no tasks; got %v", got)
+	}
+}
+
+func TestTaskAssignee_400_UnknownOrInvalidUser(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	missing := "00000000-0000-0000-0000-000000000001"
+
+	got := doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+pid+"/tasks", `{"title": "T1", "assigneeId": "`+missing+`"}`, http.StatusBadRequest)
+	errObj, _ := got["error"].(map[string]any)
+	if errObj["message"] != "assigneeId does not match any user" {
+		t.Fatalf("unexpected error: %#v", got)
+	}
+	doJSON(t, http.MethodPost, ts.URL+"/v1/projects/"+pid+"/tasks", `{"title": "T1", "assigneeId": "nope"}`, http.StatusBadRequest)
+
+	task := createTask(t, ts, pid, "T2", "")
+	taskURL := ts.URL + "/v1/projects/" + pid + "/tasks/" + task["id"].(string)
+	doJSON(t, http.MethodPatch, taskURL, `{"assigneeId": "`+missing+`"}`, http.StatusBadRequest)
+}
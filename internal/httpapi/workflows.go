@@ -0,0 +1,149 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/domain"
+	"github.com/linus5304/project-manager-api/internal/store"
+)
+
+type workflowInput struct {
+	Statuses []struct {
+		Name     string `json:"name"`
+		Category string `json:"category"`
+	} `json:"statuses"`
+	Transitions map[string][]string `json:"transitions"`
+}
+
+// maxWorkflowStatuses bounds the size of a workflow definition.
+const maxWorkflowStatuses = 50
+
+// readWorkflow normalizes and validates a workflow definition: at least one
+// uniquely named status, each in a known category, and transitions that only
+// mention those statuses.
+func readWorkflow(input workflowInput) (domain.Workflow, error) {
+	if len(input.Statuses) == 0 {
+		return domain.Workflow{}, errors.New("statuses must contain at least one status")
+	}
+	if len(input.Statuses) > maxWorkflowStatuses {
+		return domain.Workflow{}, fmt.Errorf("statuses must not contain more than %d statuses", maxWorkflowStatuses)
+	}
+
+	var workflow domain.Workflow
+	for _, in := range input.Statuses {
+		name := strings.TrimSpace(in.Name)
+		if name == "" {
+			return domain.Workflow{}, errors.New("status name is required")
+		}
+		if _, dup := workflow.Status(name); dup {
+			return domain.Workflow{}, fmt.Errorf("status %q is listed twice", name)
+		}
+		switch in.Category {
+		case domain.StatusCategoryTodo, domain.StatusCategoryInProgress, domain.StatusCategoryDone:
+			// ok
+		default:
+			return domain.Workflow{}, errors.New("category must be one of: todo, in_progress, done")
+		}
+		workflow.Statuses = append(workflow.Statuses, domain.WorkflowStatus{Name: name, Category: in.Category})
+	}
+
+	if len(input.Transitions) > 0 {
+		workflow.Transitions = make(map[string][]string, len(input.Transitions))
+	}
+	for from, targets := range input.Transitions {
+		from = strings.TrimSpace(from)
+		if _, ok := workflow.Status(from); !ok {
+			return domain.Workflow{}, fmt.Errorf("transitions mention unknown status %q", from)
+		}
+		next := make([]string, 0, len(targets))
+		for _, to := range targets {
+			to = strings.TrimSpace(to)
+			if _, ok := workflow.Status(to); !ok {
+				return domain.Workflow{}, fmt.Errorf("transitions mention unknown status %q", to)
+			}
+			next = append(next, to)
+		}
+		workflow.Transitions[from] = next
+	}
+
+	return workflow, nil
+}
+
+// unknownStatusError lists the statuses the workflow accepts.
+func unknownStatusError(workflow domain.Workflow) error {
+	names := make([]string, 0, len(workflow.Statuses))
+	for _, s := range workflow.Statuses {
+		names = append(names, s.Name)
+	}
+	return fmt.Errorf("status must be one of: %s", strings.Join(names, ", "))
+}
+
+// unknownStatusResponse writes the 400 for a status outside the project's
+// workflow.
+func (app *Application) unknownStatusResponse(w http.ResponseWriter, r *http.Request, projectID uuid.UUID) {
+	workflow, err := app.store.GetWorkflow(r.Context(), projectID)
+	if err != nil {
+		taskErrorResponse(w, r, err)
+		return
+	}
+	badRequestResponse(w, r, unknownStatusError(workflow))
+}
+
+func (app *Application) getWorkflow(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid project id"))
+		return
+	}
+
+	workflow, err := app.store.GetWorkflow(r.Context(), projectID)
+	if err != nil {
+		if errors.Is(err, store.ErrProjectNotFound) {
+			notFoundResponse(w, r)
+			return
+		}
+		serverErrorResponse(w, r, err)
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, workflow, nil)
+}
+
+func (app *Application) updateWorkflow(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequestResponse(w, r, errors.New("invalid project id"))
+		return
+	}
+
+	var input workflowInput
+	if err := readJSON(w, r, &input); err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	workflow, err := readWorkflow(input)
+	if err != nil {
+		badRequestResponse(w, r, err)
+		return
+	}
+
+	workflow, err = app.store.UpdateWorkflow(r.Context(), projectID, workflow)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrProjectNotFound):
+			notFoundResponse(w, r)
+		case errors.Is(err, store.ErrStatusInUse):
+			errorResponse(w, r, http.StatusConflict, "tasks still use a status the new workflow removes; move them first")
+		default:
+			serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, workflow, nil)
+}
@@ -0,0 +1,84 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWorkflows_CustomStatuses(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Support")
+	workflowURL := ts.URL + "/v1/projects/" + pid + "/workflow"
+	tasksURL := ts.URL + "/v1/projects/" + pid + "/tasks"
+
+	wf := getJSON(t, workflowURL, http.StatusOK)
+	if statuses, _ := wf["statuses"].([]any); len(statuses) != 3 {
+		t.Fatalf("expected default workflow; got %#v", wf)
+	}
+
+	wf = doJSON(t, http.MethodPut, workflowURL, `{
+		"statuses": [
+			{"name": "triage", "category": "todo"},
+			{"name": " waiting on customer ", "category": "in_progress"},
+			{"name": "closed", "category": "done"}
+		],
+		"transitions": {"triage": ["waiting on customer", "closed"], "closed": []}
+	}`, http.StatusOK)
+	if statuses, _ := wf["statuses"].([]any); len(statuses) != 3 || statuses[1].(map[string]any)["name"] != "waiting on customer" {
+		t.Fatalf("unexpected workflow: %#v", wf)
+	}
+
+	task := createTask(t, ts, pid, "Printer on fire", "")
+	if task["status"] != "triage" || task["statusCategory"] != "todo" {
+		t.Fatalf("expected new task in triage; got %#v", task)
+	}
+	taskURL := tasksURL + "/" + task["id"].(string)
+
+	env := doJSON(t, http.MethodPatch, taskURL, `{"status": "doing"}`, http.StatusBadRequest)
+	if msg := env["error"].(map[string]any)["message"]; msg != "status must be one of: triage, waiting on customer, closed" {
+		t.Fatalf("unexpected error: %v", msg)
+	}
+	closed := doJSON(t, http.MethodPatch, taskURL, `{"status": "closed"}`, http.StatusOK)
+	if closed["statusCategory"] != "done" {
+		t.Fatalf("expected closed to be done; got %#v", closed)
+	}
+	doJSON(t, http.MethodPatch, taskURL, `{"status": "triage"}`, http.StatusConflict)
+
+	env = getJSON(t, tasksURL+"?status=closed", http.StatusOK)
+	if got := taskTitles(t, env); len(got) != 1 {
+		t.Fatalf("expected the closed task; got %v", got)
+	}
+	getJSON(t, tasksURL+"?status=done", http.StatusBadRequest)
+
+	// Dropping a status that a task still uses is refused
+	doJSON(t, http.MethodPut, workflowURL, `{"statuses": [{"name": "triage", "category": "todo"}]}`, http.StatusConflict)
+}
+
+func TestWorkflows_Validation(t *testing.T) {
+	app := newTestApp()
+	ts := httptest.NewServer(app.Routes())
+	t.Cleanup(ts.Close)
+
+	pid := createProject(t, ts, "Alpha")
+	workflowURL := ts.URL + "/v1/projects/" + pid + "/workflow"
+
+	for _, body := range []string{
+		`{"statuses": []}`,
+		`{"statuses": [{"name": " ", "category": "todo"}]}`,
+		`{"statuses": [{"name": "a", "category": "todo"}, {"name": "a", "category": "done"}]}`,
+		`{"statuses": [{"name": "a", "category": "blocked"}]}`,
+		`{"statuses": [{"name": "a", "category": "todo"}], "transitions": {"a": ["b"]}}`,
+		`{"statuses": [{"name": "a", "category": "todo"}], "transitions": {"b": ["a"]}}`,
+	} {
+		doJSON(t, http.MethodPut, workflowURL, body, http.StatusBadRequest)
+	}
+
+	missing := "00000000-0000-0000-0000-000000000001"
+	getJSON(t, ts.URL+"/v1/projects/"+missing+"/workflow", http.StatusNotFound)
+	doJSON(t, http.MethodPut, ts.URL+"/v1/projects/"+missing+"/workflow", `{"statuses": [{"name": "a", "category": "todo"}]}`, http.StatusNotFound)
+	getJSON(t, ts.URL+"/v1/projects/invalid-uuid/workflow", http.StatusBadRequest)
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/domain"
+)
+
+type actorKey struct{}
+
+type actor struct {
+	subject   string
+	requestID string
+}
+
+// WithActor attributes the changes made with the returned context to
+// subject, as part of the request with the given ID, in the audit log.
+func WithActor(ctx context.Context, subject, requestID string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor{subject: subject, requestID: requestID})
+}
+
+// auditIdentity names the fields an update entry keeps even when they did
+// not change, for entities whose EntityID alone does not say which one it is.
+var auditIdentity = map[string][]string{
+	domain.AuditMember: {"subject"},
+}
+
+// newAuditEntry describes a change to an entity, attributed to the actor of
+// ctx in the workspace ctx is scoped to. A nil before makes it a create and a
+// nil after a delete. Updates only keep the fields that changed, and ok is
+// false when none did.
+func newAuditEntry(ctx context.Context, entity, entityID string, before, after any) (e domain.AuditEntry, ok bool, err error) {
+	a, _ := ctx.Value(actorKey{}).(actor)
+	e = domain.AuditEntry{
+		WorkspaceID: Workspace(ctx),
+		Actor:       a.subject,
+		RequestID:   a.requestID,
+		Entity:      entity,
+		EntityID:    entityID,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	if before != nil {
+		if e.Before, err = auditSnapshot(before); err != nil {
+			return domain.AuditEntry{}, false, err
+		}
+	}
+	if after != nil {
+		if e.After, err = auditSnapshot(after); err != nil {
+			return domain.AuditEntry{}, false, err
+		}
+	}
+
+	switch {
+	case before == nil:
+		e.Action = domain.AuditCreate
+	case after == nil:
+		e.Action = domain.AuditDelete
+	default:
+		e.Action = domain.AuditUpdate
+		changed := false
+		for k, v := range e.After {
+			old, ok := e.Before[k]
+			if ok && reflect.DeepEqual(old, v) && !slices.Contains(auditIdentity[entity], k) {
+				delete(e.Before, k)
+				delete(e.After, k)
+			} else if !ok || !reflect.DeepEqual(old, v) {
+				changed = true
+			}
+		}
+		for k := range e.Before {
+			if _, ok := e.After[k]; !ok {
+				changed = true
+			}
+		}
+		if !changed {
+			return domain.AuditEntry{}, false, nil
+		}
+	}
+	return e, true, nil
+}
+
+// auditSnapshot is v as its JSON object. Tasks leave out the fields derived
+// from other entities, which are audited on their own.
+func auditSnapshot(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	if _, ok := v.(domain.Task); ok {
+		for _, k := range []string{"identifier", "labels", "subtasks", "blockedBy"} {
+			delete(m, k)
+		}
+	}
+	return m, nil
+}
+
+// labelSnapshot and dependencySnapshot describe what task_label and
+// task_dependency entries record about a task.
+func labelSnapshot(labelID uuid.UUID) map[string]any {
+	return map[string]any{"labelId": labelID}
+}
+
+func dependencySnapshot(blockerID uuid.UUID) map[string]any {
+	return map[string]any{"blockerId": blockerID}
+}
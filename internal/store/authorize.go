@@ -0,0 +1,28 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/domain"
+)
+
+// AuthorizeProject checks that subject holds at least the want role on the
+// project. Both stores share it, so a role means the same thing everywhere.
+// It fails with ErrForbidden when subject is a member whose role is too low,
+// and with ErrProjectNotFound when they are not a member at all, so a
+// project's existence is not revealed to those who cannot see it.
+func AuthorizeProject(ctx context.Context, s ProjectStore, projectID uuid.UUID, subject, want string) error {
+	m, err := s.GetProjectMember(ctx, projectID, subject)
+	if err != nil {
+		if errors.Is(err, ErrMemberNotFound) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+	if !domain.RoleAllows(m.Role, want) {
+		return ErrForbidden
+	}
+	return nil
+}
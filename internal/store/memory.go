@@ -1,9 +1,12 @@
 package store
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,145 +18,2169 @@ var (
 	ErrNotFound        = errors.New("not found")
 	ErrProjectNotFound = errors.New("project not found")
 	ErrTaskNotFound    = errors.New("task not found")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrEmailTaken      = errors.New("email already in use")
+	ErrLabelNotFound   = errors.New("label not found")
+	ErrLabelExists     = errors.New("label already exists")
+	ErrCommentNotFound = errors.New("comment not found")
+
+	ErrParentTaskNotFound = errors.New("parent task not found")
+	ErrTaskCycle          = errors.New("task cannot be nested under itself or its subtasks")
+
+	ErrTaskBlocked     = errors.New("task has unfinished blockers")
+	ErrDependencyCycle = errors.New("dependency would create a cycle")
+
+	ErrUnknownStatus        = errors.New("status is not part of the project's workflow")
+	ErrTransitionNotAllowed = errors.New("the project's workflow does not allow this status change")
+	ErrStatusInUse          = errors.New("status is still used by tasks")
+
+	ErrMoveTargetInvalid = errors.New("beforeId and afterId must be other tasks in the target status")
+	ErrMoveConflict      = errors.New("afterId and beforeId are no longer next to each other")
+
+	ErrTargetProjectNotFound = errors.New("target project not found")
+	ErrTransferSameProject   = errors.New("task is already in that project")
+
+	ErrTemplateNotFound = errors.New("template not found")
+
+	ErrProjectKeyTaken = errors.New("project key already in use")
+
+	ErrAPIKeyNotFound = errors.New("api key not found")
+
+	ErrOrganizationNotFound = errors.New("organization not found")
+
+	ErrMemberNotFound = errors.New("project member not found")
+	ErrLastOwner      = errors.New("a project must keep at least one owner")
+	ErrForbidden      = errors.New("the caller's project role does not allow this")
 )
 
+var _ ProjectStore = (*MemoryStore)(nil)
+
 type MemoryStore struct {
 	mu       sync.RWMutex
 	projects map[uuid.UUID]domain.Project
 	tasks    map[uuid.UUID]map[uuid.UUID]domain.Task
+	users    map[uuid.UUID]domain.User
+	labels   map[uuid.UUID]domain.Label
+	// taskLabels maps a task ID to the IDs of its labels.
+	taskLabels map[uuid.UUID]map[uuid.UUID]struct{}
+	comments   map[uuid.UUID]domain.Comment
+	// taskBlockers maps a task ID to the IDs of its blockers, oldest first.
+	taskBlockers map[uuid.UUID][]uuid.UUID
+	// workflows holds custom workflows by project ID; projects without an
+	// entry use domain.DefaultWorkflow.
+	workflows map[uuid.UUID]domain.Workflow
+	templates map[uuid.UUID]domain.Template
+	// taskCounters holds the last task number handed out in each project.
+	taskCounters map[uuid.UUID]int64
+	apiKeys      map[uuid.UUID]domain.APIKey
+	// members maps a project ID to its members by subject.
+	members       map[uuid.UUID]map[string]domain.ProjectMember
+	organizations map[uuid.UUID]domain.Organization
+	// auditLog holds the audit entries, oldest first.
+	auditLog []domain.AuditEntry
+	auditSeq int64
 }
 
 func NewMemoryStore() *MemoryStore {
 	return &MemoryStore{
 		projects: make(map[uuid.UUID]domain.Project),
 		tasks:    make(map[uuid.UUID]map[uuid.UUID]domain.Task),
+		users:    make(map[uuid.UUID]domain.User),
+		labels:   make(map[uuid.UUID]domain.Label),
+
+		taskLabels: make(map[uuid.UUID]map[uuid.UUID]struct{}),
+		comments:   make(map[uuid.UUID]domain.Comment),
+
+		taskBlockers: make(map[uuid.UUID][]uuid.UUID),
+		workflows:    make(map[uuid.UUID]domain.Workflow),
+		templates:    make(map[uuid.UUID]domain.Template),
+		taskCounters: make(map[uuid.UUID]int64),
+		apiKeys:      make(map[uuid.UUID]domain.APIKey),
+		members:      make(map[uuid.UUID]map[string]domain.ProjectMember),
+		// Like the workspaces migration, start with the default organization
+		organizations: map[uuid.UUID]domain.Organization{
+			domain.DefaultWorkspaceID: {ID: domain.DefaultWorkspaceID, Name: "Default", CreatedAt: time.Now().UTC()},
+		},
 	}
 }
 
-func (s *MemoryStore) InsertProject(ctx context.Context, name string) (domain.Project, error) {
+func (s *MemoryStore) InsertProject(ctx context.Context, project NewProject) (domain.Project, error) {
+	now := time.Now().UTC()
 	p := domain.Project{
-		ID:        uuid.New(),
-		Name:      name,
-		CreatedAt: time.Now().UTC(),
+		ID:          uuid.New(),
+		WorkspaceID: Workspace(ctx),
+		Name:        project.Name,
+		Description: project.Description,
+		OwnerID:     project.OwnerID,
+		Color:       project.Color,
+		Icon:        project.Icon,
+		Key:         project.Key,
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	}
 
 	s.mu.Lock()
-	s.projects[p.ID] = p
-	s.mu.Unlock()
+	defer s.mu.Unlock()
 
+	if p.OwnerID != nil {
+		if !s.userIn(p.WorkspaceID, *p.OwnerID) {
+			return domain.Project{}, ErrUserNotFound
+		}
+	}
+	if s.keyTaken(p.WorkspaceID, p.Key, p.ID) {
+		return domain.Project{}, ErrProjectKeyTaken
+	}
+	if err := s.audit(ctx, domain.AuditProject, p.ID.String(), nil, p); err != nil {
+		return domain.Project{}, err
+	}
+	s.projects[p.ID] = p
+	if err := s.addOwner(ctx, p, project.CreatedBy); err != nil {
+		return domain.Project{}, err
+	}
 	return p, nil
 }
 
+// addOwner makes subject the first owner of a new project; an empty subject
+// leaves it without members. Callers must hold s.mu.
+func (s *MemoryStore) addOwner(ctx context.Context, p domain.Project, subject string) error {
+	if subject == "" {
+		return nil
+	}
+	m := domain.ProjectMember{ProjectID: p.ID, Subject: subject, Role: domain.RoleOwner, CreatedAt: p.CreatedAt}
+	if err := s.audit(ctx, domain.AuditMember, p.ID.String(), nil, m); err != nil {
+		return err
+	}
+	s.members[p.ID] = map[string]domain.ProjectMember{subject: m}
+	return nil
+}
+
+// isMember reports whether subject is a member of the project; an empty
+// subject is a member of every project. Callers must hold s.mu.
+func (s *MemoryStore) isMember(projectID uuid.UUID, subject string) bool {
+	if subject == "" {
+		return true
+	}
+	_, ok := s.members[projectID][subject]
+	return ok
+}
+
+// keyTaken reports whether another project in the workspace, soft-deleted
+// ones included, already uses key. Callers must hold s.mu.
+func (s *MemoryStore) keyTaken(workspaceID uuid.UUID, key string, projectID uuid.UUID) bool {
+	if key == "" {
+		return false
+	}
+	for _, p := range s.projects {
+		if p.WorkspaceID == workspaceID && p.Key == key && p.ID != projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// nextTaskNumber hands out the next task number in the project. Callers must
+// hold s.mu.
+func (s *MemoryStore) nextTaskNumber(projectID uuid.UUID) int64 {
+	s.taskCounters[projectID]++
+	return s.taskCounters[projectID]
+}
+
 func (s *MemoryStore) GetProject(ctx context.Context, id uuid.UUID) (domain.Project, error) {
 	s.mu.RLock()
-	p, ok := s.projects[id]
+	p, ok := s.project(ctx, id)
 	s.mu.RUnlock()
 
+	if !ok || p.DeletedAt != nil {
+		return domain.Project{}, ErrNotFound
+	}
+
+	return p, nil
+}
+
+// project looks up a project, soft-deleted or not, in the workspace ctx is
+// scoped to. Callers must hold s.mu.
+func (s *MemoryStore) project(ctx context.Context, id uuid.UUID) (domain.Project, bool) {
+	p, ok := s.projects[id]
+	if !ok || !inWorkspace(ctx, p.WorkspaceID) {
+		return domain.Project{}, false
+	}
+	return p, true
+}
+
+// liveProject reports whether the project exists in the workspace ctx is
+// scoped to and is not soft-deleted. Callers must hold s.mu.
+func (s *MemoryStore) liveProject(ctx context.Context, id uuid.UUID) bool {
+	p, ok := s.project(ctx, id)
+	return ok && p.DeletedAt == nil
+}
+
+func (s *MemoryStore) UpdateProject(ctx context.Context, id uuid.UUID, update ProjectUpdate) (domain.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.project(ctx, id)
+	if !ok || p.DeletedAt != nil {
+		return domain.Project{}, ErrNotFound
+	}
+	before := p
+
+	if update.OwnerID != nil && *update.OwnerID != uuid.Nil {
+		if !s.userIn(p.WorkspaceID, *update.OwnerID) {
+			return domain.Project{}, ErrUserNotFound
+		}
+	}
+	if update.Key != nil && s.keyTaken(p.WorkspaceID, *update.Key, id) {
+		return domain.Project{}, ErrProjectKeyTaken
+	}
+
+	if update.Name != nil {
+		p.Name = *update.Name
+	}
+	if update.Description != nil {
+		p.Description = *update.Description
+	}
+	if update.OwnerID != nil {
+		if *update.OwnerID == uuid.Nil {
+			p.OwnerID = nil
+		} else {
+			ownerID := *update.OwnerID
+			p.OwnerID = &ownerID
+		}
+	}
+	if update.Color != nil {
+		p.Color = *update.Color
+	}
+	if update.Icon != nil {
+		p.Icon = *update.Icon
+	}
+	if update.Key != nil {
+		p.Key = *update.Key
+	}
+	p.UpdatedAt = time.Now().UTC()
+	if err := s.audit(ctx, domain.AuditProject, id.String(), before, p); err != nil {
+		return domain.Project{}, err
+	}
+	s.projects[id] = p
+	return p, nil
+}
+
+func (s *MemoryStore) DeleteProject(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.project(ctx, id)
+	if !ok || p.DeletedAt != nil {
+		return ErrNotFound
+	}
+
+	before := p
+	now := time.Now().UTC()
+	p.DeletedAt = &now
+	if err := s.audit(ctx, domain.AuditProject, id.String(), before, p); err != nil {
+		return err
+	}
+	s.projects[id] = p
+	return nil
+}
+
+func (s *MemoryStore) ArchiveProject(ctx context.Context, id uuid.UUID) (domain.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.project(ctx, id)
+	if !ok || p.DeletedAt != nil {
+		return domain.Project{}, ErrNotFound
+	}
+
+	if p.ArchivedAt == nil {
+		before := p
+		now := time.Now().UTC()
+		p.ArchivedAt = &now
+		if err := s.audit(ctx, domain.AuditProject, id.String(), before, p); err != nil {
+			return domain.Project{}, err
+		}
+		s.projects[id] = p
+	}
+	return p, nil
+}
+
+func (s *MemoryStore) RestoreProject(ctx context.Context, id uuid.UUID) (domain.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.project(ctx, id)
 	if !ok {
 		return domain.Project{}, ErrNotFound
 	}
 
+	before := p
+	p.ArchivedAt = nil
+	p.DeletedAt = nil
+	if err := s.audit(ctx, domain.AuditProject, id.String(), before, p); err != nil {
+		return domain.Project{}, err
+	}
+	s.projects[id] = p
 	return p, nil
 }
 
-func (s *MemoryStore) ListProjects(ctx context.Context) ([]domain.Project, error) {
+func (s *MemoryStore) PurgeDeletedProjects(ctx context.Context, deletedBefore time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for id, p := range s.projects {
+		if !inWorkspace(ctx, p.WorkspaceID) || p.DeletedAt == nil || !p.DeletedAt.Before(deletedBefore) {
+			continue
+		}
+		e, _, err := newAuditEntry(ctx, domain.AuditProject, id.String(), p, nil)
+		if err != nil {
+			return n, err
+		}
+		e.WorkspaceID = p.WorkspaceID
+		s.appendAudit(e)
+		// Mirror ON DELETE CASCADE on tasks.project_id, labels.project_id,
+		// project_workflows.project_id, project_task_counters.project_id and
+		// project_members.project_id
+		for taskID := range s.tasks[id] {
+			s.deleteTaskChildren(taskID)
+		}
+		delete(s.workflows, id)
+		delete(s.members, id)
+		for labelID, l := range s.labels {
+			if l.ProjectID == id {
+				delete(s.labels, labelID)
+			}
+		}
+		delete(s.tasks, id)
+		delete(s.taskCounters, id)
+		delete(s.projects, id)
+		n++
+	}
+	return n, nil
+}
+
+func (s *MemoryStore) ListProjects(ctx context.Context, params ListProjectsParams) ([]domain.Project, int, error) {
 	s.mu.RLock()
 	projects := make([]domain.Project, 0, len(s.projects))
 	for _, p := range s.projects {
+		if !inWorkspace(ctx, p.WorkspaceID) {
+			continue
+		}
+		if p.ArchivedAt != nil && !params.IncludeArchived {
+			continue
+		}
+		if p.DeletedAt != nil && !params.IncludeDeleted {
+			continue
+		}
+		if !s.isMember(p.ID, params.Member) {
+			continue
+		}
 		projects = append(projects, p)
 	}
 	s.mu.RUnlock()
 
 	sort.Slice(projects, func(i, j int) bool {
-		if projects[i].CreatedAt.Equal(projects[j].CreatedAt) {
-			return projects[i].ID.String() > projects[j].ID.String()
-		}
-		return projects[i].CreatedAt.After(projects[j].CreatedAt)
+		return newerThan(projects[i].CreatedAt, projects[i].ID, projects[j].CreatedAt, projects[j].ID)
 	})
-	return projects, nil
+
+	total := len(projects)
+	if params.After != nil {
+		i := sort.Search(len(projects), func(i int) bool {
+			return newerThan(params.After.CreatedAt, params.After.ID, projects[i].CreatedAt, projects[i].ID)
+		})
+		return paginate(projects[i:], params.Limit, 0), total, nil
+	}
+	return paginate(projects, params.Limit, params.Offset), total, nil
+}
+
+func (s *MemoryStore) GetWorkflow(ctx context.Context, projectID uuid.UUID) (domain.Workflow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.liveProject(ctx, projectID) {
+		return domain.Workflow{}, ErrProjectNotFound
+	}
+	return s.workflow(projectID), nil
 }
 
-func (s *MemoryStore) InsertTask(ctx context.Context, projectID uuid.UUID, title, description string) (domain.Task, error) {
+func (s *MemoryStore) UpdateWorkflow(ctx context.Context, projectID uuid.UUID, workflow domain.Workflow) (domain.Workflow, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Ensure the project exists
-	if _, ok := s.projects[projectID]; !ok {
-		return domain.Task{}, ErrProjectNotFound
+	if !s.liveProject(ctx, projectID) {
+		return domain.Workflow{}, ErrProjectNotFound
 	}
 
-	t := domain.Task{
+	projectTasks := s.tasks[projectID]
+	for _, t := range projectTasks {
+		if _, ok := workflow.Status(t.Status); !ok {
+			return domain.Workflow{}, ErrStatusInUse
+		}
+	}
+	if err := s.audit(ctx, domain.AuditWorkflow, projectID.String(), s.workflow(projectID), workflow); err != nil {
+		return domain.Workflow{}, err
+	}
+	for id, t := range projectTasks {
+		st, _ := workflow.Status(t.Status)
+		t.StatusCategory = st.Category
+		projectTasks[id] = t
+	}
+
+	s.workflows[projectID] = workflow
+	return workflow, nil
+}
+
+func (s *MemoryStore) CloneProject(ctx context.Context, projectID uuid.UUID, clone ProjectClone) (domain.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.liveProject(ctx, projectID) {
+		return domain.Project{}, ErrProjectNotFound
+	}
+
+	// Everything but the key, which must stay unique
+	src := s.projects[projectID]
+	now := time.Now().UTC()
+	p := domain.Project{
 		ID:          uuid.New(),
-		ProjectID:   projectID,
-		Title:       title,
-		Description: description,
-		Status:      "todo",
+		WorkspaceID: src.WorkspaceID,
+		Name:        clone.Name,
+		Description: src.Description,
+		OwnerID:     src.OwnerID,
+		Color:       src.Color,
+		Icon:        src.Icon,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.audit(ctx, domain.AuditProject, p.ID.String(), nil, p); err != nil {
+		return domain.Project{}, err
+	}
+	s.projects[p.ID] = p
+	if err := s.addOwner(ctx, p, clone.CreatedBy); err != nil {
+		return domain.Project{}, err
+	}
+	if w, ok := s.workflows[projectID]; ok {
+		// Recorded as a change from the default every new project starts with
+		if err := s.audit(ctx, domain.AuditWorkflow, p.ID.String(), domain.DefaultWorkflow(), w); err != nil {
+			return domain.Project{}, err
+		}
+		s.workflows[p.ID] = w
+	}
+
+	labels := make(map[uuid.UUID]uuid.UUID)
+	for _, l := range s.labels {
+		if l.ProjectID == projectID {
+			copied := l
+			copied.ID = uuid.New()
+			copied.ProjectID = p.ID
+			copied.CreatedAt = p.CreatedAt
+			if err := s.audit(ctx, domain.AuditLabel, copied.ID.String(), nil, copied); err != nil {
+				return domain.Project{}, err
+			}
+			s.labels[copied.ID] = copied
+			labels[l.ID] = copied.ID
+		}
+	}
+	if !clone.IncludeTasks {
+		return p, nil
+	}
+
+	workflow := s.workflow(projectID)
+	tasks := make([]domain.Task, 0, len(s.tasks[projectID]))
+	copies := make(map[uuid.UUID]uuid.UUID, len(s.tasks[projectID]))
+	for _, t := range s.tasks[projectID] {
+		tasks = append(tasks, t)
+		copies[t.ID] = uuid.New()
+	}
+	sortBoard(workflow, tasks)
+
+	s.tasks[p.ID] = make(map[uuid.UUID]domain.Task, len(tasks))
+	for _, t := range tasks {
+		oldID := t.ID
+		t.ID = copies[oldID]
+		t.ProjectID = p.ID
+		t.CreatedAt = p.CreatedAt
+		if t.ParentTaskID != nil {
+			parentID := copies[*t.ParentTaskID]
+			t.ParentTaskID = &parentID
+		}
+		if clone.ResetStatuses {
+			t.Status = workflow.Statuses[0].Name
+			t.StatusCategory = workflow.Statuses[0].Category
+		}
+		t.Position = s.nextPosition(p.ID, t.Status)
+		t.Number = s.nextTaskNumber(p.ID)
+		if err := s.audit(ctx, domain.AuditTask, t.ID.String(), nil, t); err != nil {
+			return domain.Project{}, err
+		}
+		s.tasks[p.ID][t.ID] = t
+
+		if len(s.taskLabels[oldID]) > 0 {
+			s.taskLabels[t.ID] = make(map[uuid.UUID]struct{}, len(s.taskLabels[oldID]))
+			for labelID := range s.taskLabels[oldID] {
+				if err := s.audit(ctx, domain.AuditTaskLabel, t.ID.String(), nil, labelSnapshot(labels[labelID])); err != nil {
+					return domain.Project{}, err
+				}
+				s.taskLabels[t.ID][labels[labelID]] = struct{}{}
+			}
+		}
+		for _, blockerID := range s.taskBlockers[oldID] {
+			if err := s.audit(ctx, domain.AuditTaskDependency, t.ID.String(), nil, dependencySnapshot(copies[blockerID])); err != nil {
+				return domain.Project{}, err
+			}
+			s.taskBlockers[t.ID] = append(s.taskBlockers[t.ID], copies[blockerID])
+		}
+	}
+	return p, nil
+}
+
+func (s *MemoryStore) InsertTemplate(ctx context.Context, name string, tasks []domain.TaskBlueprint) (domain.Template, error) {
+	t := domain.Template{
+		ID:          uuid.New(),
+		WorkspaceID: Workspace(ctx),
+		Name:        name,
+		Tasks:       append([]domain.TaskBlueprint{}, tasks...),
 		CreatedAt:   time.Now().UTC(),
 	}
 
-	if s.tasks[projectID] == nil {
-		s.tasks[projectID] = make(map[uuid.UUID]domain.Task)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.audit(ctx, domain.AuditTemplate, t.ID.String(), nil, t); err != nil {
+		return domain.Template{}, err
 	}
-	s.tasks[projectID][t.ID] = t
+	s.templates[t.ID] = t
 	return t, nil
 }
 
-func (s *MemoryStore) ListTasks(ctx context.Context, projectID uuid.UUID) ([]domain.Task, error) {
+func (s *MemoryStore) GetTemplate(ctx context.Context, id uuid.UUID) (domain.Template, error) {
 	s.mu.RLock()
-	if _, ok := s.projects[projectID]; !ok {
-		s.mu.RUnlock()
-		return []domain.Task{}, ErrProjectNotFound
+	defer s.mu.RUnlock()
+
+	t, ok := s.template(ctx, id)
+	if !ok {
+		return domain.Template{}, ErrTemplateNotFound
+	}
+	return t, nil
+}
+
+// template looks up a template in the workspace ctx is scoped to. Callers
+// must hold s.mu.
+func (s *MemoryStore) template(ctx context.Context, id uuid.UUID) (domain.Template, bool) {
+	t, ok := s.templates[id]
+	if !ok || !inWorkspace(ctx, t.WorkspaceID) {
+		return domain.Template{}, false
 	}
+	return t, true
+}
 
-	projectTasks, ok := s.tasks[projectID]
+func (s *MemoryStore) ListTemplates(ctx context.Context) ([]domain.Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	if !ok || len(projectTasks) == 0 {
-		s.mu.RUnlock()
-		return []domain.Task{}, nil
+	templates := make([]domain.Template, 0, len(s.templates))
+	for _, t := range s.templates {
+		if !inWorkspace(ctx, t.WorkspaceID) {
+			continue
+		}
+		templates = append(templates, t)
 	}
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Name != templates[j].Name {
+			return templates[i].Name < templates[j].Name
+		}
+		return templates[i].ID.String() < templates[j].ID.String()
+	})
+	return templates, nil
+}
 
-	tasks := make([]domain.Task, 0, len(projectTasks))
-	for _, t := range projectTasks {
-		tasks = append(tasks, t)
+func (s *MemoryStore) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.template(ctx, id)
+	if !ok {
+		return ErrTemplateNotFound
 	}
-	s.mu.RUnlock()
+	if err := s.audit(ctx, domain.AuditTemplate, id.String(), t, nil); err != nil {
+		return err
+	}
+	delete(s.templates, id)
+	return nil
+}
 
-	sort.Slice(tasks, func(i, j int) bool {
-		if tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
-			return tasks[i].ID.String() > tasks[j].ID.String()
+func (s *MemoryStore) InstantiateTemplate(ctx context.Context, templateID uuid.UUID, name, createdBy string) (domain.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tpl, ok := s.template(ctx, templateID)
+	if !ok {
+		return domain.Project{}, ErrTemplateNotFound
+	}
+	if name == "" {
+		name = tpl.Name
+	}
+
+	now := time.Now().UTC()
+	p := domain.Project{
+		ID:          uuid.New(),
+		WorkspaceID: tpl.WorkspaceID,
+		Name:        name,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.audit(ctx, domain.AuditProject, p.ID.String(), nil, p); err != nil {
+		return domain.Project{}, err
+	}
+	s.projects[p.ID] = p
+	if err := s.addOwner(ctx, p, createdBy); err != nil {
+		return domain.Project{}, err
+	}
+
+	initial := s.workflow(p.ID).Statuses[0]
+	s.tasks[p.ID] = make(map[uuid.UUID]domain.Task, len(tpl.Tasks))
+	for _, b := range tpl.Tasks {
+		t := domain.Task{
+			ID:          uuid.New(),
+			ProjectID:   p.ID,
+			Title:       b.Title,
+			Description: b.Description,
+			Status:      initial.Name,
+			Priority:    b.Priority,
+			CreatedAt:   p.CreatedAt,
+
+			StatusCategory: initial.Category,
+			Position:       s.nextPosition(p.ID, initial.Name),
+			Number:         s.nextTaskNumber(p.ID),
 		}
-		return tasks[i].CreatedAt.After(tasks[j].CreatedAt)
-	})
+		if t.Priority == "" {
+			t.Priority = DefaultTaskPriority
+		}
+		if err := s.audit(ctx, domain.AuditTask, t.ID.String(), nil, t); err != nil {
+			return domain.Project{}, err
+		}
+		s.tasks[p.ID][t.ID] = t
+	}
+	return p, nil
+}
 
-	return tasks, nil
+// workflow returns the project's workflow. Callers must hold s.mu.
+func (s *MemoryStore) workflow(projectID uuid.UUID) domain.Workflow {
+	if w, ok := s.workflows[projectID]; ok {
+		return w
+	}
+	return domain.DefaultWorkflow()
 }
 
-func (s *MemoryStore) UpdateTask(ctx context.Context, projectID, taskID uuid.UUID, update TaskUpdate) (domain.Task, error) {
+// newerThan reports whether (at, id) sorts before (otherAt, otherID) in the
+// created_at DESC, id DESC order used by the Postgres indexes.
+func newerThan(at time.Time, id uuid.UUID, otherAt time.Time, otherID uuid.UUID) bool {
+	if at.Equal(otherAt) {
+		return id.String() > otherID.String()
+	}
+	return at.After(otherAt)
+}
+
+// paginate returns the window of items described by limit and offset.
+// A limit of zero means no limit; an offset past the end yields an empty (non-nil) slice.
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := offset + limit
+	if limit <= 0 || end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
+
+func (s *MemoryStore) InsertTask(ctx context.Context, projectID uuid.UUID, task NewTask) (domain.Task, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, ok := s.projects[projectID]; !ok {
+	// Ensure the project exists
+	if !s.liveProject(ctx, projectID) {
 		return domain.Task{}, ErrProjectNotFound
 	}
-
-	taskMap, ok := s.tasks[projectID]
-	if !ok {
-		return domain.Task{}, ErrTaskNotFound
+	if task.AssigneeID != nil {
+		if !s.userIn(s.projects[projectID].WorkspaceID, *task.AssigneeID) {
+			return domain.Task{}, ErrUserNotFound
+		}
+	}
+	if task.ParentTaskID != nil {
+		if _, ok := s.tasks[projectID][*task.ParentTaskID]; !ok {
+			return domain.Task{}, ErrParentTaskNotFound
+		}
 	}
 
-	task, ok := taskMap[taskID]
-	if !ok {
-		return domain.Task{}, ErrTaskNotFound
+	initial := s.workflow(projectID).Statuses[0]
+	t := domain.Task{
+		ID:          uuid.New(),
+		ProjectID:   projectID,
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      initial.Name,
+		AssigneeID:  task.AssigneeID,
+		DueDate:     task.DueDate,
+		Priority:    task.Priority,
+		CreatedAt:   time.Now().UTC(),
+
+		ParentTaskID:   task.ParentTaskID,
+		StatusCategory: initial.Category,
+		Position:       s.nextPosition(projectID, initial.Name),
+		Number:         s.nextTaskNumber(projectID),
 	}
-	if update.Title != nil {
-		task.Title = *update.Title
+	if t.Priority == "" {
+		t.Priority = DefaultTaskPriority
 	}
-	if update.Description != nil {
-		task.Description = *update.Description
+
+	if err := s.audit(ctx, domain.AuditTask, t.ID.String(), nil, t); err != nil {
+		return domain.Task{}, err
 	}
-	if update.Status != nil {
-		task.Status = *update.Status
+	if s.tasks[projectID] == nil {
+		s.tasks[projectID] = make(map[uuid.UUID]domain.Task)
 	}
-	s.tasks[projectID][taskID] = task
-	return task, nil
+	s.tasks[projectID][t.ID] = t
+	return s.withRelations(t), nil
+}
+
+func (s *MemoryStore) GetTask(ctx context.Context, projectID, taskID uuid.UUID) (domain.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.liveProject(ctx, projectID) {
+		return domain.Task{}, ErrProjectNotFound
+	}
+
+	task, ok := s.tasks[projectID][taskID]
+	if !ok {
+		return domain.Task{}, ErrTaskNotFound
+	}
+	return s.withRelations(task), nil
+}
+
+func (s *MemoryStore) GetTaskByNumber(ctx context.Context, projectID uuid.UUID, number int64) (domain.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.liveProject(ctx, projectID) {
+		return domain.Task{}, ErrProjectNotFound
+	}
+
+	for _, task := range s.tasks[projectID] {
+		if task.Number == number {
+			return s.withRelations(task), nil
+		}
+	}
+	return domain.Task{}, ErrTaskNotFound
+}
+
+// withRelations returns t with its identifier, labels (ordered by name),
+// subtask rollup and blockers filled in. Callers must hold s.mu.
+func (s *MemoryStore) withRelations(t domain.Task) domain.Task {
+	t.Identifier = domain.TaskIdentifier(s.projects[t.ProjectID].Key, t.Number)
+
+	t.Labels = make([]domain.Label, 0, len(s.taskLabels[t.ID]))
+	for labelID := range s.taskLabels[t.ID] {
+		t.Labels = append(t.Labels, s.labels[labelID])
+	}
+	sortLabels(t.Labels)
+
+	t.Subtasks = domain.SubtaskRollup{}
+	for _, sub := range s.tasks[t.ProjectID] {
+		if sub.ParentTaskID != nil && *sub.ParentTaskID == t.ID {
+			t.Subtasks.Total++
+			if sub.StatusCategory == domain.StatusCategoryDone {
+				t.Subtasks.Done++
+			}
+		}
+	}
+
+	t.BlockedBy = slices.Clone(s.taskBlockers[t.ID])
+	if t.BlockedBy == nil {
+		t.BlockedBy = []uuid.UUID{}
+	}
+	return t
+}
+
+// sortLabels mirrors ORDER BY name COLLATE "C", id.
+func sortLabels(labels []domain.Label) {
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Name != labels[j].Name {
+			return labels[i].Name < labels[j].Name
+		}
+		return labels[i].ID.String() < labels[j].ID.String()
+	})
+}
+
+func (s *MemoryStore) ListTasks(ctx context.Context, projectID uuid.UUID, params ListTasksParams) ([]domain.Task, int, error) {
+	s.mu.RLock()
+	if !s.liveProject(ctx, projectID) {
+		s.mu.RUnlock()
+		return []domain.Task{}, 0, ErrProjectNotFound
+	}
+
+	projectTasks := s.tasks[projectID]
+	tasks := make([]domain.Task, 0, len(projectTasks))
+	for _, t := range projectTasks {
+		if t = s.withRelations(t); matchesTaskFilters(t, params) {
+			tasks = append(tasks, t)
+		}
+	}
+	s.mu.RUnlock()
+
+	sortTasks(tasks, params.Sort)
+
+	total := len(tasks)
+	if params.After != nil {
+		i := sort.Search(len(tasks), func(i int) bool {
+			return newerThan(params.After.CreatedAt, params.After.ID, tasks[i].CreatedAt, tasks[i].ID)
+		})
+		return paginate(tasks[i:], params.Limit, 0), total, nil
+	}
+	return paginate(tasks, params.Limit, params.Offset), total, nil
+}
+
+func matchesTaskFilters(t domain.Task, params ListTasksParams) bool {
+	if len(params.Statuses) > 0 && !slices.Contains(params.Statuses, t.Status) {
+		return false
+	}
+	if params.Query != "" && !strings.Contains(strings.ToLower(t.Title), strings.ToLower(params.Query)) {
+		return false
+	}
+	if params.DueBefore != nil && (t.DueDate == nil || !t.DueDate.Before(*params.DueBefore)) {
+		return false
+	}
+	if params.DueAfter != nil && (t.DueDate == nil || !t.DueDate.After(*params.DueAfter)) {
+		return false
+	}
+	if params.ParentTaskID != nil && (t.ParentTaskID == nil || *t.ParentTaskID != *params.ParentTaskID) {
+		return false
+	}
+	if len(params.Labels) > 0 && !slices.ContainsFunc(t.Labels, func(l domain.Label) bool {
+		return slices.Contains(params.Labels, l.Name)
+	}) {
+		return false
+	}
+	return true
+}
+
+// sortTasks mirrors the ORDER BY in the ListTasks query: the requested key first,
+// then newest-first as the tie-breaker.
+func sortTasks(tasks []domain.Task, by TaskSort) {
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		switch by {
+		case TaskSortTitle:
+			if a.Title != b.Title {
+				return a.Title < b.Title
+			}
+		case TaskSortOldest:
+			return newerThan(b.CreatedAt, b.ID, a.CreatedAt, a.ID)
+		}
+		return newerThan(a.CreatedAt, a.ID, b.CreatedAt, b.ID)
+	})
+}
+
+func (s *MemoryStore) UpdateTask(ctx context.Context, projectID, taskID uuid.UUID, update TaskUpdate) (domain.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.liveProject(ctx, projectID) {
+		return domain.Task{}, ErrProjectNotFound
+	}
+
+	taskMap, ok := s.tasks[projectID]
+	if !ok {
+		return domain.Task{}, ErrTaskNotFound
+	}
+
+	task, ok := taskMap[taskID]
+	if !ok {
+		return domain.Task{}, ErrTaskNotFound
+	}
+	before := task
+	if update.Title != nil {
+		task.Title = *update.Title
+	}
+	if update.Description != nil {
+		task.Description = *update.Description
+	}
+	if update.Status != nil && *update.Status != task.Status {
+		status, err := s.checkStatusChange(task, *update.Status)
+		if err != nil {
+			return domain.Task{}, err
+		}
+		task.Status = status.Name
+		task.StatusCategory = status.Category
+		task.Position = s.nextPosition(projectID, status.Name)
+	}
+	if update.AssigneeID != nil {
+		if *update.AssigneeID == uuid.Nil {
+			task.AssigneeID = nil
+		} else {
+			if !s.userIn(s.projects[projectID].WorkspaceID, *update.AssigneeID) {
+				return domain.Task{}, ErrUserNotFound
+			}
+			id := *update.AssigneeID
+			task.AssigneeID = &id
+		}
+	}
+	if update.DueDate != nil {
+		if update.DueDate.IsZero() {
+			task.DueDate = nil
+		} else {
+			due := *update.DueDate
+			task.DueDate = &due
+		}
+	}
+	if update.Priority != nil {
+		task.Priority = *update.Priority
+	}
+	if update.ParentTaskID != nil {
+		if *update.ParentTaskID == uuid.Nil {
+			task.ParentTaskID = nil
+		} else {
+			parentID := *update.ParentTaskID
+			if _, ok := taskMap[parentID]; !ok {
+				return domain.Task{}, ErrParentTaskNotFound
+			}
+			// Walk up from the new parent; meeting the task means a cycle.
+			for id := &parentID; id != nil; id = taskMap[*id].ParentTaskID {
+				if *id == taskID {
+					return domain.Task{}, ErrTaskCycle
+				}
+			}
+			task.ParentTaskID = &parentID
+		}
+	}
+	if err := s.audit(ctx, domain.AuditTask, taskID.String(), before, task); err != nil {
+		return domain.Task{}, err
+	}
+	s.tasks[projectID][taskID] = task
+	return s.withRelations(task), nil
+}
+
+// checkStatusChange validates moving task to a different status against the
+// project's workflow and the task's blockers. Callers must hold s.mu.
+func (s *MemoryStore) checkStatusChange(task domain.Task, name string) (domain.WorkflowStatus, error) {
+	workflow := s.workflow(task.ProjectID)
+	status, ok := workflow.Status(name)
+	if !ok {
+		return domain.WorkflowStatus{}, ErrUnknownStatus
+	}
+	if !workflow.Allows(task.Status, status.Name) {
+		return domain.WorkflowStatus{}, ErrTransitionNotAllowed
+	}
+	if status.Category != domain.StatusCategoryTodo && s.hasOpenBlockers(task.ProjectID, task.ID) {
+		return domain.WorkflowStatus{}, ErrTaskBlocked
+	}
+	return status, nil
+}
+
+func (s *MemoryStore) MoveTask(ctx context.Context, projectID, taskID uuid.UUID, move TaskMove) (domain.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.liveTask(ctx, projectID, taskID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	before := task
+	if move.Status != nil && *move.Status != task.Status {
+		status, err := s.checkStatusChange(task, *move.Status)
+		if err != nil {
+			return domain.Task{}, err
+		}
+		task.Status = status.Name
+		task.StatusCategory = status.Category
+	}
+
+	column := s.column(projectID, task.Status, taskID)
+	pos, ok, err := slotPosition(column, move.AfterID, move.BeforeID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if !ok {
+		renumberColumn(column)
+		for _, e := range column {
+			t := s.tasks[projectID][e.ID]
+			t.Position = e.Position
+			s.tasks[projectID][e.ID] = t
+		}
+		if pos, _, err = slotPosition(column, move.AfterID, move.BeforeID); err != nil {
+			return domain.Task{}, err
+		}
+	}
+
+	task.Position = pos
+	if err := s.audit(ctx, domain.AuditTask, taskID.String(), before, task); err != nil {
+		return domain.Task{}, err
+	}
+	s.tasks[projectID][taskID] = task
+	return s.withRelations(task), nil
+}
+
+func (s *MemoryStore) ListBoardTasks(ctx context.Context, projectID uuid.UUID) ([]domain.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.liveProject(ctx, projectID) {
+		return nil, ErrProjectNotFound
+	}
+
+	tasks := make([]domain.Task, 0, len(s.tasks[projectID]))
+	for _, t := range s.tasks[projectID] {
+		tasks = append(tasks, s.withRelations(t))
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].Status != tasks[j].Status {
+			return tasks[i].Status < tasks[j].Status
+		}
+		return boardBefore(tasks[i], tasks[j])
+	})
+	return tasks, nil
+}
+
+// column returns a status column in board order, leaving out excludeID.
+// Callers must hold s.mu.
+func (s *MemoryStore) column(projectID uuid.UUID, status string, excludeID uuid.UUID) []columnEntry {
+	var tasks []domain.Task
+	for _, t := range s.tasks[projectID] {
+		if t.Status == status && t.ID != excludeID {
+			tasks = append(tasks, t)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return boardBefore(tasks[i], tasks[j]) })
+
+	column := make([]columnEntry, 0, len(tasks))
+	for _, t := range tasks {
+		column = append(column, columnEntry{ID: t.ID, Position: t.Position})
+	}
+	return column
+}
+
+// nextPosition is the position just below the bottom card of a status column.
+// Callers must hold s.mu.
+func (s *MemoryStore) nextPosition(projectID uuid.UUID, status string) float64 {
+	var bottom float64
+	for _, t := range s.tasks[projectID] {
+		if t.Status == status && t.Position > bottom {
+			bottom = t.Position
+		}
+	}
+	return bottom + positionGap
+}
+
+// sortBoard orders tasks column by column in workflow order, as the board
+// shows them.
+func sortBoard(workflow domain.Workflow, tasks []domain.Task) {
+	column := make(map[string]int, len(workflow.Statuses))
+	for i, st := range workflow.Statuses {
+		column[st.Name] = i
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Status != tasks[j].Status {
+			return column[tasks[i].Status] < column[tasks[j].Status]
+		}
+		return boardBefore(tasks[i], tasks[j])
+	})
+}
+
+// boardBefore orders tasks within a column by position, then creation time
+// and id, as in the ListColumnPositions query.
+func boardBefore(a, b domain.Task) bool {
+	if a.Position != b.Position {
+		return a.Position < b.Position
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID.String() < b.ID.String()
+}
+
+// sameWorkspace reports whether two projects are in the same workspace; tasks
+// never leave theirs. Callers must hold s.mu.
+func (s *MemoryStore) sameWorkspace(projectID, otherID uuid.UUID) bool {
+	return s.projects[projectID].WorkspaceID == s.projects[otherID].WorkspaceID
+}
+
+func (s *MemoryStore) TransferTask(ctx context.Context, projectID, taskID, targetProjectID uuid.UUID) (domain.Task, TransferReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.liveTask(ctx, projectID, taskID); err != nil {
+		return domain.Task{}, TransferReport{}, err
+	}
+	if !s.liveProject(ctx, targetProjectID) || !s.sameWorkspace(projectID, targetProjectID) {
+		return domain.Task{}, TransferReport{}, ErrTargetProjectNotFound
+	}
+	if targetProjectID == projectID {
+		return domain.Task{}, TransferReport{}, ErrTransferSameProject
+	}
+
+	// The task and everything under it
+	tree := []domain.Task{s.tasks[projectID][taskID]}
+	moved := map[uuid.UUID]bool{taskID: true}
+	for i := 0; i < len(tree); i++ {
+		for _, t := range s.tasks[projectID] {
+			if t.ParentTaskID != nil && *t.ParentTaskID == tree[i].ID {
+				tree = append(tree, t)
+				moved[t.ID] = true
+			}
+		}
+	}
+	ids := make([]uuid.UUID, len(tree))
+	for i, t := range tree {
+		ids[i] = t.ID
+	}
+
+	report := TransferReport{Subtasks: len(tree) - 1}
+	for id, blockers := range s.taskBlockers {
+		kept := blockers[:0]
+		for _, b := range blockers {
+			if moved[id] != moved[b] {
+				if err := s.audit(ctx, domain.AuditTaskDependency, id.String(), dependencySnapshot(b), nil); err != nil {
+					return domain.Task{}, TransferReport{}, err
+				}
+				report.DroppedDependencies++
+				continue
+			}
+			kept = append(kept, b)
+		}
+		if len(kept) == 0 {
+			delete(s.taskBlockers, id)
+		} else {
+			s.taskBlockers[id] = kept
+		}
+	}
+
+	relabel, err := s.carryLabels(ctx, ids, targetProjectID, &report)
+	if err != nil {
+		return domain.Task{}, TransferReport{}, err
+	}
+	for _, id := range ids {
+		labels := make(map[uuid.UUID]struct{}, len(s.taskLabels[id]))
+		for labelID := range s.taskLabels[id] {
+			if err := s.audit(ctx, domain.AuditTaskLabel, id.String(), labelSnapshot(labelID), labelSnapshot(relabel[labelID])); err != nil {
+				return domain.Task{}, TransferReport{}, err
+			}
+			labels[relabel[labelID]] = struct{}{}
+		}
+		if len(labels) > 0 {
+			s.taskLabels[id] = labels
+		}
+	}
+	for _, c := range s.comments {
+		if moved[c.TaskID] {
+			report.Comments++
+		}
+	}
+
+	// Keep the moved tasks' relative order at the bottom of their new columns
+	sort.Slice(tree, func(i, j int) bool { return boardBefore(tree[i], tree[j]) })
+	workflow := s.workflow(targetProjectID)
+	if s.tasks[targetProjectID] == nil {
+		s.tasks[targetProjectID] = make(map[uuid.UUID]domain.Task)
+	}
+	var root domain.Task
+	for _, t := range tree {
+		before := t
+		delete(s.tasks[projectID], t.ID)
+		status := transferStatus(workflow, t.Status)
+		t.ProjectID = targetProjectID
+		t.Status = status.Name
+		t.StatusCategory = status.Category
+		t.Position = s.nextPosition(targetProjectID, status.Name)
+		t.Number = s.nextTaskNumber(targetProjectID)
+		if t.ID == taskID {
+			t.ParentTaskID = nil
+			root = t
+		}
+		if err := s.audit(ctx, domain.AuditTask, t.ID.String(), before, t); err != nil {
+			return domain.Task{}, TransferReport{}, err
+		}
+		s.tasks[targetProjectID][t.ID] = t
+	}
+
+	return s.withRelations(root), report, nil
+}
+
+func (s *MemoryStore) CopyTask(ctx context.Context, projectID, taskID, targetProjectID uuid.UUID) (domain.Task, TransferReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	src, err := s.liveTask(ctx, projectID, taskID)
+	if err != nil {
+		return domain.Task{}, TransferReport{}, err
+	}
+	if !s.liveProject(ctx, targetProjectID) || !s.sameWorkspace(projectID, targetProjectID) {
+		return domain.Task{}, TransferReport{}, ErrTargetProjectNotFound
+	}
+
+	status := transferStatus(s.workflow(targetProjectID), src.Status)
+	t := domain.Task{
+		ID:          uuid.New(),
+		ProjectID:   targetProjectID,
+		Title:       src.Title,
+		Description: src.Description,
+		Status:      status.Name,
+		AssigneeID:  src.AssigneeID,
+		DueDate:     src.DueDate,
+		Priority:    src.Priority,
+		CreatedAt:   time.Now().UTC(),
+
+		StatusCategory: status.Category,
+		Position:       s.nextPosition(targetProjectID, status.Name),
+		Number:         s.nextTaskNumber(targetProjectID),
+	}
+	if err := s.audit(ctx, domain.AuditTask, t.ID.String(), nil, t); err != nil {
+		return domain.Task{}, TransferReport{}, err
+	}
+	if s.tasks[targetProjectID] == nil {
+		s.tasks[targetProjectID] = make(map[uuid.UUID]domain.Task)
+	}
+	s.tasks[targetProjectID][t.ID] = t
+
+	report := TransferReport{
+		DroppedDependencies: len(s.taskBlockers[src.ID]),
+		DroppedSubtasks:     s.withRelations(src).Subtasks.Total,
+	}
+	relabel, err := s.carryLabels(ctx, []uuid.UUID{src.ID}, targetProjectID, &report)
+	if err != nil {
+		return domain.Task{}, TransferReport{}, err
+	}
+	if len(relabel) > 0 {
+		s.taskLabels[t.ID] = make(map[uuid.UUID]struct{}, len(relabel))
+		for _, labelID := range relabel {
+			if err := s.audit(ctx, domain.AuditTaskLabel, t.ID.String(), nil, labelSnapshot(labelID)); err != nil {
+				return domain.Task{}, TransferReport{}, err
+			}
+			s.taskLabels[t.ID][labelID] = struct{}{}
+		}
+	}
+
+	copies := make(map[uuid.UUID]uuid.UUID)
+	for _, c := range s.comments {
+		if c.TaskID == src.ID {
+			copies[c.ID] = uuid.New()
+		}
+	}
+	for oldID, newID := range copies {
+		c := s.comments[oldID]
+		c.ID = newID
+		c.TaskID = t.ID
+		if c.ParentID != nil {
+			parentID := copies[*c.ParentID]
+			c.ParentID = &parentID
+		}
+		if err := s.audit(ctx, domain.AuditComment, newID.String(), nil, c); err != nil {
+			return domain.Task{}, TransferReport{}, err
+		}
+		s.comments[newID] = c
+	}
+	report.Comments = len(copies)
+
+	return s.withRelations(t), report, nil
+}
+
+// carryLabels maps the labels on the given tasks to labels of the same name
+// in the target project, creating any that are missing, and records them in
+// report. Callers must hold s.mu.
+func (s *MemoryStore) carryLabels(ctx context.Context, taskIDs []uuid.UUID, targetProjectID uuid.UUID, report *TransferReport) (map[uuid.UUID]uuid.UUID, error) {
+	var labels []domain.Label
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range taskIDs {
+		for labelID := range s.taskLabels[id] {
+			if !seen[labelID] {
+				seen[labelID] = true
+				labels = append(labels, s.labels[labelID])
+			}
+		}
+	}
+	sortLabels(labels)
+
+	relabel := make(map[uuid.UUID]uuid.UUID, len(labels))
+	for _, l := range labels {
+		report.Labels = append(report.Labels, l.Name)
+		target, ok := s.labelByName(targetProjectID, l.Name)
+		if !ok {
+			target = domain.Label{
+				ID:        uuid.New(),
+				ProjectID: targetProjectID,
+				Name:      l.Name,
+				Color:     l.Color,
+				CreatedAt: time.Now().UTC(),
+			}
+			if err := s.audit(ctx, domain.AuditLabel, target.ID.String(), nil, target); err != nil {
+				return nil, err
+			}
+			s.labels[target.ID] = target
+			report.CreatedLabels = append(report.CreatedLabels, l.Name)
+		}
+		relabel[l.ID] = target.ID
+	}
+	return relabel, nil
+}
+
+// labelByName looks up a project's label by name. Callers must hold s.mu.
+func (s *MemoryStore) labelByName(projectID uuid.UUID, name string) (domain.Label, bool) {
+	for _, l := range s.labels {
+		if l.ProjectID == projectID && l.Name == name {
+			return l, true
+		}
+	}
+	return domain.Label{}, false
+}
+
+func (s *MemoryStore) DeleteTask(ctx context.Context, projectID, taskID uuid.UUID, subtasks SubtaskPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.liveTask(ctx, projectID, taskID)
+	if err != nil {
+		return err
+	}
+	if err := s.audit(ctx, domain.AuditTask, taskID.String(), task, nil); err != nil {
+		return err
+	}
+
+	projectTasks := s.tasks[projectID]
+	if subtasks == SubtasksCascade {
+		// Mirror ON DELETE CASCADE on tasks_parent_fkey, one level at a time
+		doomed := []uuid.UUID{taskID}
+		for len(doomed) > 0 {
+			id := doomed[0]
+			doomed = doomed[1:]
+			delete(projectTasks, id)
+			s.deleteTaskChildren(id)
+			for subID, sub := range projectTasks {
+				if sub.ParentTaskID != nil && *sub.ParentTaskID == id {
+					doomed = append(doomed, subID)
+				}
+			}
+		}
+		return nil
+	}
+
+	for subID, sub := range projectTasks {
+		if sub.ParentTaskID != nil && *sub.ParentTaskID == taskID {
+			sub.ParentTaskID = task.ParentTaskID
+			projectTasks[subID] = sub
+		}
+	}
+	delete(projectTasks, taskID)
+	s.deleteTaskChildren(taskID)
+	return nil
+}
+
+// deleteTaskChildren mirrors the ON DELETE CASCADE from tasks to task_labels,
+// task_dependencies (on either side) and comments. Callers must hold s.mu.
+func (s *MemoryStore) deleteTaskChildren(taskID uuid.UUID) {
+	delete(s.taskLabels, taskID)
+	delete(s.taskBlockers, taskID)
+	for id, blockers := range s.taskBlockers {
+		s.taskBlockers[id] = slices.DeleteFunc(blockers, func(b uuid.UUID) bool { return b == taskID })
+	}
+	for id, c := range s.comments {
+		if c.TaskID == taskID {
+			delete(s.comments, id)
+		}
+	}
+}
+
+func (s *MemoryStore) ListOverdueTasks(ctx context.Context, params ListOverdueTasksParams) ([]domain.Task, int, error) {
+	s.mu.RLock()
+	var tasks []domain.Task
+	for projectID, projectTasks := range s.tasks {
+		if !s.liveProject(ctx, projectID) || !s.isMember(projectID, params.Member) {
+			continue
+		}
+		for _, t := range projectTasks {
+			if t.DueDate != nil && t.DueDate.Before(params.AsOf) && t.StatusCategory != domain.StatusCategoryDone {
+				tasks = append(tasks, s.withRelations(t))
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	// Earliest due first, then id, as in the ListOverdueTasks query
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if !a.DueDate.Equal(*b.DueDate) {
+			return a.DueDate.Before(*b.DueDate)
+		}
+		return a.ID.String() < b.ID.String()
+	})
+	return paginate(tasks, params.Limit, params.Offset), len(tasks), nil
+}
+
+func (s *MemoryStore) AddTaskBlocker(ctx context.Context, projectID, taskID, blockerID uuid.UUID) (domain.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.dependencyTasks(ctx, projectID, taskID, blockerID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if slices.Contains(s.taskBlockers[taskID], blockerID) {
+		return s.withRelations(task), nil
+	}
+	if blockerID == taskID || s.dependsOn(blockerID, taskID) {
+		return domain.Task{}, ErrDependencyCycle
+	}
+
+	if err := s.audit(ctx, domain.AuditTaskDependency, taskID.String(), nil, dependencySnapshot(blockerID)); err != nil {
+		return domain.Task{}, err
+	}
+	s.taskBlockers[taskID] = append(s.taskBlockers[taskID], blockerID)
+	return s.withRelations(task), nil
+}
+
+func (s *MemoryStore) RemoveTaskBlocker(ctx context.Context, projectID, taskID, blockerID uuid.UUID) (domain.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.dependencyTasks(ctx, projectID, taskID, blockerID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	if slices.Contains(s.taskBlockers[taskID], blockerID) {
+		if err := s.audit(ctx, domain.AuditTaskDependency, taskID.String(), dependencySnapshot(blockerID), nil); err != nil {
+			return domain.Task{}, err
+		}
+	}
+	s.taskBlockers[taskID] = slices.DeleteFunc(s.taskBlockers[taskID], func(b uuid.UUID) bool { return b == blockerID })
+	return s.withRelations(task), nil
+}
+
+func (s *MemoryStore) ListTaskBlockers(ctx context.Context, projectID, taskID uuid.UUID) ([]domain.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.liveTask(ctx, projectID, taskID); err != nil {
+		return nil, err
+	}
+
+	blockers := make([]domain.Task, 0, len(s.taskBlockers[taskID]))
+	for _, id := range s.taskBlockers[taskID] {
+		blockers = append(blockers, s.withRelations(s.tasks[projectID][id]))
+	}
+	return blockers, nil
+}
+
+func (s *MemoryStore) ListBlockedTasks(ctx context.Context, projectID uuid.UUID, params ListBlockedTasksParams) ([]domain.Task, int, error) {
+	s.mu.RLock()
+	if !s.liveProject(ctx, projectID) {
+		s.mu.RUnlock()
+		return []domain.Task{}, 0, ErrProjectNotFound
+	}
+
+	tasks := []domain.Task{}
+	for _, t := range s.tasks[projectID] {
+		if t.StatusCategory != domain.StatusCategoryDone && s.hasOpenBlockers(projectID, t.ID) {
+			tasks = append(tasks, s.withRelations(t))
+		}
+	}
+	s.mu.RUnlock()
+
+	sortTasks(tasks, TaskSortNewest)
+	return paginate(tasks, params.Limit, params.Offset), len(tasks), nil
+}
+
+// dependencyTasks resolves the task and blocker of an add or remove request;
+// a blocker outside the project counts as missing. Callers must hold s.mu.
+func (s *MemoryStore) dependencyTasks(ctx context.Context, projectID, taskID, blockerID uuid.UUID) (domain.Task, error) {
+	task, err := s.liveTask(ctx, projectID, taskID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if _, err := s.liveTask(ctx, projectID, blockerID); err != nil {
+		return domain.Task{}, err
+	}
+	return task, nil
+}
+
+// dependsOn reports whether taskID is blocked by blockerID, directly or
+// through other blockers. Callers must hold s.mu.
+func (s *MemoryStore) dependsOn(taskID, blockerID uuid.UUID) bool {
+	seen := map[uuid.UUID]bool{}
+	queue := []uuid.UUID{taskID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, b := range s.taskBlockers[id] {
+			if b == blockerID {
+				return true
+			}
+			if !seen[b] {
+				seen[b] = true
+				queue = append(queue, b)
+			}
+		}
+	}
+	return false
+}
+
+// hasOpenBlockers reports whether any blocker of the task is not done.
+// Callers must hold s.mu.
+func (s *MemoryStore) hasOpenBlockers(projectID, taskID uuid.UUID) bool {
+	for _, id := range s.taskBlockers[taskID] {
+		if s.tasks[projectID][id].StatusCategory != domain.StatusCategoryDone {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MemoryStore) InsertUser(ctx context.Context, name, email string) (domain.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workspaceID := Workspace(ctx)
+	for _, u := range s.users {
+		if u.WorkspaceID == workspaceID && u.Email == email {
+			return domain.User{}, ErrEmailTaken
+		}
+	}
+
+	u := domain.User{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		Name:        name,
+		Email:       email,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := s.audit(ctx, domain.AuditUser, u.ID.String(), nil, u); err != nil {
+		return domain.User{}, err
+	}
+	s.users[u.ID] = u
+	return u, nil
+}
+
+// user returns the user if it is in the workspace ctx is scoped to. Callers
+// must hold s.mu.
+func (s *MemoryStore) user(ctx context.Context, id uuid.UUID) (domain.User, bool) {
+	u, ok := s.users[id]
+	if !ok || !inWorkspace(ctx, u.WorkspaceID) {
+		return domain.User{}, false
+	}
+	return u, true
+}
+
+// userIn reports whether the user exists in the workspace. Like the foreign
+// keys it stands in for, it goes by the workspace of what refers to the user
+// rather than the one ctx is scoped to. Callers must hold s.mu.
+func (s *MemoryStore) userIn(workspaceID, id uuid.UUID) bool {
+	u, ok := s.users[id]
+	return ok && u.WorkspaceID == workspaceID
+}
+
+func (s *MemoryStore) GetUser(ctx context.Context, id uuid.UUID) (domain.User, error) {
+	s.mu.RLock()
+	u, ok := s.user(ctx, id)
+	s.mu.RUnlock()
+
+	if !ok {
+		return domain.User{}, ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (s *MemoryStore) ListUserTasks(ctx context.Context, userID uuid.UUID, params ListUserTasksParams) ([]domain.Task, int, error) {
+	s.mu.RLock()
+	if _, ok := s.user(ctx, userID); !ok {
+		s.mu.RUnlock()
+		return []domain.Task{}, 0, ErrUserNotFound
+	}
+
+	var tasks []domain.Task
+	for projectID, projectTasks := range s.tasks {
+		if !s.liveProject(ctx, projectID) || !s.isMember(projectID, params.Member) {
+			continue
+		}
+		for _, t := range projectTasks {
+			if t.AssigneeID == nil || *t.AssigneeID != userID {
+				continue
+			}
+			if len(params.Statuses) > 0 && !slices.Contains(params.Statuses, t.Status) {
+				continue
+			}
+			tasks = append(tasks, s.withRelations(t))
+		}
+	}
+	s.mu.RUnlock()
+
+	sortTasks(tasks, TaskSortNewest)
+	return paginate(tasks, params.Limit, params.Offset), len(tasks), nil
+}
+
+func (s *MemoryStore) InsertLabel(ctx context.Context, projectID uuid.UUID, name, color string) (domain.Label, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.liveProject(ctx, projectID) {
+		return domain.Label{}, ErrProjectNotFound
+	}
+	if s.labelNameTaken(projectID, uuid.Nil, name) {
+		return domain.Label{}, ErrLabelExists
+	}
+
+	l := domain.Label{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		Name:      name,
+		Color:     color,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.audit(ctx, domain.AuditLabel, l.ID.String(), nil, l); err != nil {
+		return domain.Label{}, err
+	}
+	s.labels[l.ID] = l
+	return l, nil
+}
+
+// labelNameTaken reports whether another label of the project (other than
+// except) already uses name. Callers must hold s.mu.
+func (s *MemoryStore) labelNameTaken(projectID, except uuid.UUID, name string) bool {
+	for _, l := range s.labels {
+		if l.ProjectID == projectID && l.ID != except && l.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// projectLabel looks up a label of a live project. Callers must hold s.mu.
+func (s *MemoryStore) projectLabel(ctx context.Context, projectID, labelID uuid.UUID) (domain.Label, error) {
+	if !s.liveProject(ctx, projectID) {
+		return domain.Label{}, ErrProjectNotFound
+	}
+	l, ok := s.labels[labelID]
+	if !ok || l.ProjectID != projectID {
+		return domain.Label{}, ErrLabelNotFound
+	}
+	return l, nil
+}
+
+func (s *MemoryStore) GetLabel(ctx context.Context, projectID, labelID uuid.UUID) (domain.Label, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.projectLabel(ctx, projectID, labelID)
+}
+
+func (s *MemoryStore) ListLabels(ctx context.Context, projectID uuid.UUID) ([]domain.Label, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.liveProject(ctx, projectID) {
+		return nil, ErrProjectNotFound
+	}
+
+	labels := []domain.Label{}
+	for _, l := range s.labels {
+		if l.ProjectID == projectID {
+			labels = append(labels, l)
+		}
+	}
+	sortLabels(labels)
+	return labels, nil
+}
+
+func (s *MemoryStore) UpdateLabel(ctx context.Context, projectID, labelID uuid.UUID, update LabelUpdate) (domain.Label, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.projectLabel(ctx, projectID, labelID)
+	if err != nil {
+		return domain.Label{}, err
+	}
+	before := l
+
+	if update.Name != nil {
+		if s.labelNameTaken(projectID, labelID, *update.Name) {
+			return domain.Label{}, ErrLabelExists
+		}
+		l.Name = *update.Name
+	}
+	if update.Color != nil {
+		l.Color = *update.Color
+	}
+	if err := s.audit(ctx, domain.AuditLabel, labelID.String(), before, l); err != nil {
+		return domain.Label{}, err
+	}
+	s.labels[labelID] = l
+	return l, nil
+}
+
+func (s *MemoryStore) DeleteLabel(ctx context.Context, projectID, labelID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.projectLabel(ctx, projectID, labelID)
+	if err != nil {
+		return err
+	}
+	if err := s.audit(ctx, domain.AuditLabel, labelID.String(), l, nil); err != nil {
+		return err
+	}
+
+	// Mirror ON DELETE CASCADE on task_labels.label_id
+	for _, labelIDs := range s.taskLabels {
+		delete(labelIDs, labelID)
+	}
+	delete(s.labels, labelID)
+	return nil
+}
+
+func (s *MemoryStore) AttachLabel(ctx context.Context, projectID, taskID, labelID uuid.UUID) (domain.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.labelTask(ctx, projectID, taskID, labelID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	if _, ok := s.taskLabels[taskID][labelID]; !ok {
+		if err := s.audit(ctx, domain.AuditTaskLabel, taskID.String(), nil, labelSnapshot(labelID)); err != nil {
+			return domain.Task{}, err
+		}
+	}
+	if s.taskLabels[taskID] == nil {
+		s.taskLabels[taskID] = make(map[uuid.UUID]struct{})
+	}
+	s.taskLabels[taskID][labelID] = struct{}{}
+	return s.withRelations(task), nil
+}
+
+func (s *MemoryStore) DetachLabel(ctx context.Context, projectID, taskID, labelID uuid.UUID) (domain.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.labelTask(ctx, projectID, taskID, labelID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	if _, ok := s.taskLabels[taskID][labelID]; ok {
+		if err := s.audit(ctx, domain.AuditTaskLabel, taskID.String(), labelSnapshot(labelID), nil); err != nil {
+			return domain.Task{}, err
+		}
+	}
+	delete(s.taskLabels[taskID], labelID)
+	return s.withRelations(task), nil
+}
+
+// labelTask resolves the task and label of an attach or detach request.
+// Callers must hold s.mu.
+func (s *MemoryStore) labelTask(ctx context.Context, projectID, taskID, labelID uuid.UUID) (domain.Task, error) {
+	task, err := s.liveTask(ctx, projectID, taskID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if _, err := s.projectLabel(ctx, projectID, labelID); err != nil {
+		return domain.Task{}, err
+	}
+	return task, nil
+}
+
+// liveTask looks up a task of a live project. Callers must hold s.mu.
+func (s *MemoryStore) liveTask(ctx context.Context, projectID, taskID uuid.UUID) (domain.Task, error) {
+	if !s.liveProject(ctx, projectID) {
+		return domain.Task{}, ErrProjectNotFound
+	}
+	task, ok := s.tasks[projectID][taskID]
+	if !ok {
+		return domain.Task{}, ErrTaskNotFound
+	}
+	return task, nil
+}
+
+// taskComment looks up a comment on a task of a live project. Callers must hold s.mu.
+func (s *MemoryStore) taskComment(ctx context.Context, projectID, taskID, commentID uuid.UUID) (domain.Comment, error) {
+	if _, err := s.liveTask(ctx, projectID, taskID); err != nil {
+		return domain.Comment{}, err
+	}
+	c, ok := s.comments[commentID]
+	if !ok || c.TaskID != taskID {
+		return domain.Comment{}, ErrCommentNotFound
+	}
+	return c, nil
+}
+
+func (s *MemoryStore) InsertComment(ctx context.Context, projectID, taskID uuid.UUID, comment NewComment) (domain.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.liveTask(ctx, projectID, taskID); err != nil {
+		return domain.Comment{}, err
+	}
+	if comment.ParentID != nil {
+		if _, err := s.taskComment(ctx, projectID, taskID, *comment.ParentID); err != nil {
+			return domain.Comment{}, err
+		}
+	}
+	if !s.userIn(s.projects[projectID].WorkspaceID, comment.AuthorID) {
+		return domain.Comment{}, ErrUserNotFound
+	}
+
+	c := domain.Comment{
+		ID:        uuid.New(),
+		TaskID:    taskID,
+		ParentID:  comment.ParentID,
+		AuthorID:  comment.AuthorID,
+		Body:      comment.Body,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.audit(ctx, domain.AuditComment, c.ID.String(), nil, c); err != nil {
+		return domain.Comment{}, err
+	}
+	s.comments[c.ID] = c
+	return c, nil
+}
+
+func (s *MemoryStore) GetComment(ctx context.Context, projectID, taskID, commentID uuid.UUID) (domain.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.taskComment(ctx, projectID, taskID, commentID)
+}
+
+func (s *MemoryStore) ListComments(ctx context.Context, projectID, taskID uuid.UUID, params ListCommentsParams) ([]domain.Comment, int, error) {
+	s.mu.RLock()
+	if _, err := s.liveTask(ctx, projectID, taskID); err != nil {
+		s.mu.RUnlock()
+		return []domain.Comment{}, 0, err
+	}
+
+	var comments []domain.Comment
+	for _, c := range s.comments {
+		if c.TaskID == taskID {
+			comments = append(comments, c)
+		}
+	}
+	s.mu.RUnlock()
+
+	// Oldest first, as in the ListComments query
+	sort.Slice(comments, func(i, j int) bool {
+		return newerThan(comments[j].CreatedAt, comments[j].ID, comments[i].CreatedAt, comments[i].ID)
+	})
+	return paginate(comments, params.Limit, params.Offset), len(comments), nil
+}
+
+func (s *MemoryStore) UpdateComment(ctx context.Context, projectID, taskID, commentID uuid.UUID, body string) (domain.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.taskComment(ctx, projectID, taskID, commentID)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	before := c
+	now := time.Now().UTC()
+	c.Body = body
+	c.UpdatedAt = &now
+	if err := s.audit(ctx, domain.AuditComment, commentID.String(), before, c); err != nil {
+		return domain.Comment{}, err
+	}
+	s.comments[commentID] = c
+	return c, nil
+}
+
+func (s *MemoryStore) DeleteComment(ctx context.Context, projectID, taskID, commentID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.taskComment(ctx, projectID, taskID, commentID)
+	if err != nil {
+		return err
+	}
+	if err := s.audit(ctx, domain.AuditComment, commentID.String(), c, nil); err != nil {
+		return err
+	}
+
+	// Mirror ON DELETE CASCADE on comments.parent_id, one level at a time
+	doomed := []uuid.UUID{commentID}
+	for len(doomed) > 0 {
+		id := doomed[0]
+		doomed = doomed[1:]
+		delete(s.comments, id)
+		for replyID, c := range s.comments {
+			if c.ParentID != nil && *c.ParentID == id {
+				doomed = append(doomed, replyID)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetProjectMember(ctx context.Context, projectID uuid.UUID, subject string) (domain.ProjectMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.project(ctx, projectID); !ok {
+		return domain.ProjectMember{}, ErrMemberNotFound
+	}
+	m, ok := s.members[projectID][subject]
+	if !ok {
+		return domain.ProjectMember{}, ErrMemberNotFound
+	}
+	return m, nil
+}
+
+func (s *MemoryStore) ListProjectMembers(ctx context.Context, projectID uuid.UUID) ([]domain.ProjectMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.liveProject(ctx, projectID) {
+		return nil, ErrProjectNotFound
+	}
+
+	members := make([]domain.ProjectMember, 0, len(s.members[projectID]))
+	for _, m := range s.members[projectID] {
+		members = append(members, m)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if !members[i].CreatedAt.Equal(members[j].CreatedAt) {
+			return members[i].CreatedAt.Before(members[j].CreatedAt)
+		}
+		return members[i].Subject < members[j].Subject
+	})
+	return members, nil
+}
+
+func (s *MemoryStore) PutProjectMember(ctx context.Context, projectID uuid.UUID, subject, role string) (domain.ProjectMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.liveProject(ctx, projectID) {
+		return domain.ProjectMember{}, ErrProjectNotFound
+	}
+
+	m, ok := s.members[projectID][subject]
+	if ok && m.Role == domain.RoleOwner && role != domain.RoleOwner && s.ownerCount(projectID) == 1 {
+		return domain.ProjectMember{}, ErrLastOwner
+	}
+	var before any
+	if ok {
+		before = m
+	} else {
+		m = domain.ProjectMember{ProjectID: projectID, Subject: subject, CreatedAt: time.Now().UTC()}
+	}
+	m.Role = role
+	if err := s.audit(ctx, domain.AuditMember, projectID.String(), before, m); err != nil {
+		return domain.ProjectMember{}, err
+	}
+
+	if s.members[projectID] == nil {
+		s.members[projectID] = make(map[string]domain.ProjectMember)
+	}
+	s.members[projectID][subject] = m
+	return m, nil
+}
+
+func (s *MemoryStore) RemoveProjectMember(ctx context.Context, projectID uuid.UUID, subject string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.liveProject(ctx, projectID) {
+		return ErrProjectNotFound
+	}
+
+	m, ok := s.members[projectID][subject]
+	if !ok {
+		return ErrMemberNotFound
+	}
+	if m.Role == domain.RoleOwner && s.ownerCount(projectID) == 1 {
+		return ErrLastOwner
+	}
+	if err := s.audit(ctx, domain.AuditMember, projectID.String(), m, nil); err != nil {
+		return err
+	}
+	delete(s.members[projectID], subject)
+	return nil
+}
+
+// ownerCount counts the project's owners. Callers must hold s.mu.
+func (s *MemoryStore) ownerCount(projectID uuid.UUID) int {
+	n := 0
+	for _, m := range s.members[projectID] {
+		if m.Role == domain.RoleOwner {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *MemoryStore) InsertAPIKey(ctx context.Context, key NewAPIKey) (domain.APIKey, error) {
+	k := domain.APIKey{
+		ID:        uuid.New(),
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Hash:      slices.Clone(key.Hash),
+		Admin:     key.Admin,
+		CreatedAt: time.Now().UTC(),
+	}
+	if key.WorkspaceID != nil && *key.WorkspaceID != uuid.Nil {
+		workspaceID := *key.WorkspaceID
+		k.WorkspaceID = &workspaceID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.audit(ctx, domain.AuditAPIKey, k.ID.String(), nil, k); err != nil {
+		return domain.APIKey{}, err
+	}
+	s.apiKeys[k.ID] = k
+	return k, nil
+}
+
+func (s *MemoryStore) GetAPIKeyByHash(ctx context.Context, hash []byte) (domain.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, k := range s.apiKeys {
+		if bytes.Equal(k.Hash, hash) {
+			return k, nil
+		}
+	}
+	return domain.APIKey{}, ErrAPIKeyNotFound
+}
+
+func (s *MemoryStore) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]domain.APIKey, 0, len(s.apiKeys))
+	for _, k := range s.apiKeys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID.String() < keys[j].ID.String()
+	})
+	return keys, nil
+}
+
+func (s *MemoryStore) RevokeAPIKey(ctx context.Context, id uuid.UUID) (domain.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, ok := s.apiKeys[id]
+	if !ok {
+		return domain.APIKey{}, ErrAPIKeyNotFound
+	}
+	if k.RevokedAt == nil {
+		before := k
+		now := time.Now().UTC()
+		k.RevokedAt = &now
+		if err := s.audit(ctx, domain.AuditAPIKey, id.String(), before, k); err != nil {
+			return domain.APIKey{}, err
+		}
+		s.apiKeys[id] = k
+	}
+	return k, nil
+}
+
+func (s *MemoryStore) InsertOrganization(ctx context.Context, name string) (domain.Organization, error) {
+	o := domain.Organization{
+		ID:        uuid.New(),
+		Name:      name,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.audit(ctx, domain.AuditOrganization, o.ID.String(), nil, o); err != nil {
+		return domain.Organization{}, err
+	}
+	s.organizations[o.ID] = o
+	return o, nil
+}
+
+func (s *MemoryStore) GetOrganization(ctx context.Context, id uuid.UUID) (domain.Organization, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	o, ok := s.organizations[id]
+	if !ok {
+		return domain.Organization{}, ErrOrganizationNotFound
+	}
+	return o, nil
+}
+
+func (s *MemoryStore) ListOrganizations(ctx context.Context) ([]domain.Organization, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	orgs := make([]domain.Organization, 0, len(s.organizations))
+	for _, o := range s.organizations {
+		orgs = append(orgs, o)
+	}
+	sort.Slice(orgs, func(i, j int) bool {
+		if !orgs[i].CreatedAt.Equal(orgs[j].CreatedAt) {
+			return orgs[i].CreatedAt.Before(orgs[j].CreatedAt)
+		}
+		return orgs[i].ID.String() < orgs[j].ID.String()
+	})
+	return orgs, nil
+}
+
+// audit records a change to an entity; see newAuditEntry. Callers must hold
+// s.mu.
+func (s *MemoryStore) audit(ctx context.Context, entity, entityID string, before, after any) error {
+	e, ok, err := newAuditEntry(ctx, entity, entityID, before, after)
+	if err != nil || !ok {
+		return err
+	}
+	s.appendAudit(e)
+	return nil
+}
+
+// appendAudit numbers e and adds it to the log. Callers must hold s.mu.
+func (s *MemoryStore) appendAudit(e domain.AuditEntry) {
+	s.auditSeq++
+	e.ID = s.auditSeq
+	s.auditLog = append(s.auditLog, e)
+}
+
+func (s *MemoryStore) ListAuditEntries(ctx context.Context, params ListAuditParams) ([]domain.AuditEntry, int, error) {
+	s.mu.RLock()
+	entries := []domain.AuditEntry{}
+	// Newest first, as in the ListAuditEntries query
+	for i := len(s.auditLog) - 1; i >= 0; i-- {
+		e := s.auditLog[i]
+		if !inWorkspace(ctx, e.WorkspaceID) {
+			continue
+		}
+		if params.Entity != "" && e.Entity != params.Entity {
+			continue
+		}
+		if params.EntityID != "" && e.EntityID != params.EntityID {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	s.mu.RUnlock()
+
+	return paginate(entries, params.Limit, params.Offset), len(entries), nil
+}
+
+func (s *MemoryStore) PurgeAuditEntries(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.auditLog)
+	s.auditLog = slices.DeleteFunc(s.auditLog, func(e domain.AuditEntry) bool {
+		return e.CreatedAt.Before(before)
+	})
+	return n - len(s.auditLog), nil
 }
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS projects_deleted_at_idx;
+
+DROP INDEX IF EXISTS projects_live_newest_idx;
+
+ALTER TABLE projects
+DROP COLUMN IF EXISTS deleted_at,
+DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,14 @@
+ALTER TABLE projects
+ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Default listings only show live (not archived, not deleted) projects
+CREATE INDEX IF NOT EXISTS projects_live_newest_idx ON projects (created_at DESC, id DESC)
+WHERE
+    archived_at IS NULL
+    AND deleted_at IS NULL;
+
+-- Lets the purge job find expired soft-deleted projects without a full scan
+CREATE INDEX IF NOT EXISTS projects_deleted_at_idx ON projects (deleted_at)
+WHERE
+    deleted_at IS NOT NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS tasks_assignee_newest_idx;
+
+ALTER TABLE tasks
+DROP COLUMN IF EXISTS assignee_id;
+
+DROP TABLE IF EXISTS users;
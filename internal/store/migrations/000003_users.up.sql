@@ -0,0 +1,18 @@
+CREATE TABLE
+    IF NOT EXISTS users (
+        id UUID PRIMARY KEY,
+        name TEXT NOT NULL,
+        email TEXT NOT NULL,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+        CONSTRAINT users_name_nonempty CHECK (length (btrim (name)) > 0),
+        CONSTRAINT users_email_key UNIQUE (email)
+    );
+
+-- Unassigned when the user is removed
+ALTER TABLE tasks
+ADD COLUMN IF NOT EXISTS assignee_id UUID CONSTRAINT tasks_assignee_id_fkey REFERENCES users (id) ON DELETE SET NULL;
+
+-- Lists a user's assigned tasks newest-first across projects
+CREATE INDEX IF NOT EXISTS tasks_assignee_newest_idx ON tasks (assignee_id, created_at DESC, id DESC)
+WHERE
+    assignee_id IS NOT NULL;
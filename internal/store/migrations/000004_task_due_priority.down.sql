@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS tasks_overdue_idx;
+
+DROP INDEX IF EXISTS tasks_project_due_idx;
+
+ALTER TABLE tasks
+DROP COLUMN IF EXISTS priority,
+DROP COLUMN IF EXISTS due_date;
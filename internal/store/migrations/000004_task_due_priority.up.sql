@@ -0,0 +1,14 @@
+ALTER TABLE tasks
+ADD COLUMN IF NOT EXISTS due_date TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS priority TEXT NOT NULL DEFAULT 'medium' CONSTRAINT tasks_priority_valid CHECK (priority IN ('low', 'medium', 'high', 'urgent'));
+
+-- due_before / due_after filters within a project
+CREATE INDEX IF NOT EXISTS tasks_project_due_idx ON tasks (project_id, due_date)
+WHERE
+    due_date IS NOT NULL;
+
+-- Overdue listing across projects: open tasks, earliest due first
+CREATE INDEX IF NOT EXISTS tasks_overdue_idx ON tasks (due_date, id)
+WHERE
+    due_date IS NOT NULL
+    AND status <> 'done';
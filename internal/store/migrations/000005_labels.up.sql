@@ -0,0 +1,20 @@
+CREATE TABLE
+    IF NOT EXISTS labels (
+        id UUID PRIMARY KEY,
+        project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+        name TEXT NOT NULL,
+        color TEXT NOT NULL DEFAULT '',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+        CONSTRAINT labels_name_nonempty CHECK (length (btrim (name)) > 0),
+        CONSTRAINT labels_project_name_key UNIQUE (project_id, name)
+    );
+
+CREATE TABLE
+    IF NOT EXISTS task_labels (
+        task_id UUID NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+        label_id UUID NOT NULL REFERENCES labels (id) ON DELETE CASCADE,
+        PRIMARY KEY (task_id, label_id)
+    );
+
+-- Finds the tasks carrying a label (label filter, label delete cascade)
+CREATE INDEX IF NOT EXISTS task_labels_label_idx ON task_labels (label_id);
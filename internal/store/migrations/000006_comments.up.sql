@@ -0,0 +1,19 @@
+CREATE TABLE
+    IF NOT EXISTS comments (
+        id UUID PRIMARY KEY,
+        task_id UUID NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+        -- Replies go with the comment they answer
+        parent_id UUID REFERENCES comments (id) ON DELETE CASCADE,
+        author_id UUID NOT NULL CONSTRAINT comments_author_id_fkey REFERENCES users (id),
+        body TEXT NOT NULL,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+        updated_at TIMESTAMPTZ,
+        CONSTRAINT comments_body_nonempty CHECK (length (btrim (body)) > 0)
+    );
+
+-- Oldest-first listing per task
+CREATE INDEX IF NOT EXISTS comments_task_oldest_idx ON comments (task_id, created_at, id);
+
+CREATE INDEX IF NOT EXISTS comments_parent_idx ON comments (parent_id)
+WHERE
+    parent_id IS NOT NULL;
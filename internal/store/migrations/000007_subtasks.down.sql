@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS tasks_parent_idx;
+
+ALTER TABLE tasks
+DROP CONSTRAINT IF EXISTS tasks_parent_fkey,
+DROP CONSTRAINT IF EXISTS tasks_project_id_id_key,
+DROP COLUMN IF EXISTS parent_task_id;
@@ -0,0 +1,19 @@
+ALTER TABLE tasks
+ADD COLUMN IF NOT EXISTS parent_task_id UUID;
+
+-- The composite foreign key below keeps a subtask in its parent's project.
+-- Deleting a parent deletes its subtasks unless they were reparented first.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'tasks_project_id_id_key') THEN
+        ALTER TABLE tasks ADD CONSTRAINT tasks_project_id_id_key UNIQUE (project_id, id);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'tasks_parent_fkey') THEN
+        ALTER TABLE tasks ADD CONSTRAINT tasks_parent_fkey FOREIGN KEY (project_id, parent_task_id)
+            REFERENCES tasks (project_id, id) ON DELETE CASCADE;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS tasks_parent_idx ON tasks (parent_task_id)
+WHERE
+    parent_task_id IS NOT NULL;
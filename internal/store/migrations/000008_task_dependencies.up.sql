@@ -0,0 +1,11 @@
+CREATE TABLE
+    IF NOT EXISTS task_dependencies (
+        task_id UUID NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+        blocker_id UUID NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+        PRIMARY KEY (task_id, blocker_id),
+        CONSTRAINT task_dependencies_not_self CHECK (task_id <> blocker_id)
+    );
+
+-- Finds the tasks a blocker holds up (blocked listing, blocker delete cascade)
+CREATE INDEX IF NOT EXISTS task_dependencies_blocker_idx ON task_dependencies (blocker_id);
@@ -0,0 +1,24 @@
+DROP INDEX IF EXISTS tasks_overdue_idx;
+
+-- Fold custom statuses back into the fixed set by category
+UPDATE tasks
+SET
+    status = CASE status_category
+        WHEN 'in_progress' THEN 'doing'
+        ELSE status_category
+    END
+WHERE
+    status NOT IN ('todo', 'doing', 'done');
+
+ALTER TABLE tasks
+DROP COLUMN IF EXISTS status_category;
+
+ALTER TABLE tasks
+ADD CONSTRAINT tasks_status_valid CHECK (status IN ('todo', 'doing', 'done'));
+
+CREATE INDEX IF NOT EXISTS tasks_overdue_idx ON tasks (due_date, id)
+WHERE
+    due_date IS NOT NULL
+    AND status <> 'done';
+
+DROP TABLE IF EXISTS project_workflows;
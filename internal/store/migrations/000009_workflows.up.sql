@@ -0,0 +1,33 @@
+-- A project without a row here uses the default todo/doing/done workflow.
+-- definition holds the statuses (ordered, each with a category) and the
+-- optional allowed transitions.
+CREATE TABLE
+    IF NOT EXISTS project_workflows (
+        project_id UUID PRIMARY KEY REFERENCES projects (id) ON DELETE CASCADE,
+        definition JSONB NOT NULL,
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now ()
+    );
+
+-- Statuses are now validated against the project's workflow
+ALTER TABLE tasks
+DROP CONSTRAINT IF EXISTS tasks_status_valid;
+
+-- The category of the task's status, kept in step with the workflow so that
+-- "is it done" checks do not need to read the workflow
+ALTER TABLE tasks
+ADD COLUMN IF NOT EXISTS status_category TEXT NOT NULL DEFAULT 'todo' CONSTRAINT tasks_status_category_valid CHECK (status_category IN ('todo', 'in_progress', 'done'));
+
+UPDATE tasks
+SET
+    status_category = CASE status
+        WHEN 'doing' THEN 'in_progress'
+        WHEN 'done' THEN 'done'
+        ELSE 'todo'
+    END;
+
+DROP INDEX IF EXISTS tasks_overdue_idx;
+
+CREATE INDEX IF NOT EXISTS tasks_overdue_idx ON tasks (due_date, id)
+WHERE
+    due_date IS NOT NULL
+    AND status_category <> 'done';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS tasks_board_idx;
+
+ALTER TABLE tasks
+DROP COLUMN IF EXISTS position;
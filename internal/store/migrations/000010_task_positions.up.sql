@@ -0,0 +1,29 @@
+-- Manual order of a task within its status column on the board. Positions
+-- are fractional so a card can be dropped between two others without
+-- touching the rest; a column is renumbered when the gaps run out.
+ALTER TABLE tasks
+ADD COLUMN IF NOT EXISTS position DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- Existing tasks keep their creation order, oldest at the top
+UPDATE tasks
+SET
+    position = ranked.rn * 1024
+FROM
+    (
+        SELECT
+            id,
+            row_number() OVER (
+                PARTITION BY
+                    project_id,
+                    status
+                ORDER BY
+                    created_at,
+                    id
+            ) AS rn
+        FROM
+            tasks
+    ) AS ranked
+WHERE
+    tasks.id = ranked.id;
+
+CREATE INDEX IF NOT EXISTS tasks_board_idx ON tasks (project_id, status, position, id);
@@ -0,0 +1,9 @@
+CREATE TABLE
+    IF NOT EXISTS project_templates (
+        id UUID PRIMARY KEY,
+        name TEXT NOT NULL,
+        -- Task blueprints, in order
+        tasks JSONB NOT NULL DEFAULT '[]',
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+        CONSTRAINT project_templates_name_nonempty CHECK (length (btrim (name)) > 0)
+    );
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS project_task_counters;
+
+ALTER TABLE tasks
+DROP CONSTRAINT IF EXISTS tasks_project_number_key;
+
+ALTER TABLE tasks
+DROP COLUMN IF EXISTS number;
+
+ALTER TABLE projects
+DROP COLUMN IF EXISTS updated_at,
+DROP COLUMN IF EXISTS key,
+DROP COLUMN IF EXISTS icon,
+DROP COLUMN IF EXISTS color,
+DROP COLUMN IF EXISTS owner_id,
+DROP COLUMN IF EXISTS description;
@@ -0,0 +1,71 @@
+ALTER TABLE projects
+ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS owner_id UUID CONSTRAINT projects_owner_id_fkey REFERENCES users (id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS color TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS icon TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS key TEXT CONSTRAINT projects_key_key UNIQUE CONSTRAINT projects_key_valid CHECK (key ~ '^[A-Z][A-Z0-9]{1,9}$'),
+ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+
+UPDATE projects
+SET
+    updated_at = created_at
+WHERE
+    updated_at IS NULL;
+
+ALTER TABLE projects
+ALTER COLUMN updated_at SET DEFAULT now (),
+ALTER COLUMN updated_at SET NOT NULL;
+
+-- Per-project task numbers, handed out from a counter row per project
+ALTER TABLE tasks
+ADD COLUMN IF NOT EXISTS number BIGINT;
+
+UPDATE tasks
+SET
+    number = ranked.rn
+FROM
+    (
+        SELECT
+            id,
+            row_number() OVER (
+                PARTITION BY
+                    project_id
+                ORDER BY
+                    created_at,
+                    id
+            ) AS rn
+        FROM
+            tasks
+    ) AS ranked
+WHERE
+    tasks.id = ranked.id;
+
+ALTER TABLE tasks
+ALTER COLUMN number SET NOT NULL;
+
+-- Deferred so a task moving between projects can pass through a number that
+-- is taken in its new project before it is given a fresh one.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'tasks_project_number_key') THEN
+        ALTER TABLE tasks ADD CONSTRAINT tasks_project_number_key UNIQUE (project_id, number)
+            DEFERRABLE INITIALLY DEFERRED;
+    END IF;
+END $$;
+
+CREATE TABLE
+    IF NOT EXISTS project_task_counters (
+        project_id UUID PRIMARY KEY REFERENCES projects (id) ON DELETE CASCADE,
+        last_number BIGINT NOT NULL
+    );
+
+INSERT INTO
+    project_task_counters (project_id, last_number)
+SELECT
+    project_id,
+    max(number)
+FROM
+    tasks
+GROUP BY
+    project_id
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,13 @@
+CREATE TABLE
+    IF NOT EXISTS api_keys (
+        id UUID PRIMARY KEY,
+        name TEXT NOT NULL,
+        -- The first characters of the key, shown so people can tell keys apart
+        prefix TEXT NOT NULL,
+        -- SHA-256 of the key; the key itself is only shown once, on creation
+        key_hash BYTEA NOT NULL CONSTRAINT api_keys_key_hash_key UNIQUE,
+        admin BOOLEAN NOT NULL DEFAULT false,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+        revoked_at TIMESTAMPTZ,
+        CONSTRAINT api_keys_name_nonempty CHECK (length (btrim (name)) > 0)
+    );
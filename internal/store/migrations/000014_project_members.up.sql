@@ -0,0 +1,13 @@
+CREATE TABLE
+    IF NOT EXISTS project_members (
+        project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+        -- Who the caller authenticates as: a JWT subject or api-key:<id>
+        subject TEXT NOT NULL,
+        role TEXT NOT NULL,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+        PRIMARY KEY (project_id, subject),
+        CONSTRAINT project_members_role_check CHECK (role IN ('viewer', 'editor', 'owner'))
+    );
+
+-- Finds the projects a caller is a member of (project and task listings)
+CREATE INDEX IF NOT EXISTS project_members_subject_idx ON project_members (subject, project_id);
@@ -0,0 +1,68 @@
+DROP TRIGGER IF EXISTS comments_author_in_workspace ON comments;
+
+DROP FUNCTION IF EXISTS comments_author_in_workspace ();
+
+DROP TRIGGER IF EXISTS tasks_assignee_in_workspace ON tasks;
+
+DROP FUNCTION IF EXISTS tasks_assignee_in_workspace ();
+
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['projects', 'project_templates', 'users', 'tasks', 'labels', 'project_workflows',
+            'project_task_counters', 'project_members', 'task_labels', 'comments', 'task_dependencies'] LOOP
+        EXECUTE format('DROP POLICY IF EXISTS %I ON %I', t || '_workspace', t);
+        EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', t);
+    END LOOP;
+END $$;
+
+DROP FUNCTION IF EXISTS app_workspace_visible (UUID);
+
+-- pm_tenant itself is left in place: roles belong to the whole cluster
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'pm_tenant') THEN
+        REVOKE ALL ON ALL TABLES IN SCHEMA public FROM pm_tenant;
+        REVOKE ALL ON SCHEMA public FROM pm_tenant;
+    END IF;
+END $$;
+
+ALTER TABLE api_keys
+DROP COLUMN IF EXISTS workspace_id;
+
+ALTER TABLE projects
+DROP CONSTRAINT IF EXISTS projects_owner_id_fkey;
+
+ALTER TABLE projects
+ADD CONSTRAINT projects_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE SET NULL;
+
+ALTER TABLE users
+DROP CONSTRAINT IF EXISTS users_workspace_id_key;
+
+ALTER TABLE users
+DROP CONSTRAINT IF EXISTS users_workspace_email_key;
+
+ALTER TABLE users
+ADD CONSTRAINT users_email_key UNIQUE (email);
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS workspace_id;
+
+ALTER TABLE project_templates
+DROP COLUMN IF EXISTS workspace_id;
+
+DROP INDEX IF EXISTS projects_workspace_newest_idx;
+
+CREATE INDEX IF NOT EXISTS projects_newest_idx ON projects (created_at DESC, id DESC);
+
+ALTER TABLE projects
+DROP CONSTRAINT IF EXISTS projects_workspace_key_key;
+
+ALTER TABLE projects
+ADD CONSTRAINT projects_key_key UNIQUE (key);
+
+ALTER TABLE projects
+DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS organizations;
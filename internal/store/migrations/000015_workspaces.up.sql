@@ -0,0 +1,197 @@
+CREATE TABLE
+    IF NOT EXISTS organizations (
+        id UUID PRIMARY KEY,
+        name TEXT NOT NULL,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+        CONSTRAINT organizations_name_nonempty CHECK (length (btrim (name)) > 0)
+    );
+
+-- Everything that predates workspaces belongs to the default organization
+INSERT INTO
+    organizations (id, name)
+VALUES
+    ('00000000-0000-0000-0000-000000000001', 'Default')
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE projects
+ADD COLUMN IF NOT EXISTS workspace_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' CONSTRAINT projects_workspace_id_fkey REFERENCES organizations (id);
+
+ALTER TABLE projects
+ALTER COLUMN workspace_id DROP DEFAULT;
+
+-- Project keys only need to be unique within a workspace
+ALTER TABLE projects
+DROP CONSTRAINT IF EXISTS projects_key_key;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'projects_workspace_key_key') THEN
+        ALTER TABLE projects ADD CONSTRAINT projects_workspace_key_key UNIQUE (workspace_id, key);
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS projects_newest_idx;
+
+CREATE INDEX IF NOT EXISTS projects_workspace_newest_idx ON projects (workspace_id, created_at DESC, id DESC);
+
+ALTER TABLE project_templates
+ADD COLUMN IF NOT EXISTS workspace_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' CONSTRAINT project_templates_workspace_id_fkey REFERENCES organizations (id);
+
+ALTER TABLE project_templates
+ALTER COLUMN workspace_id DROP DEFAULT;
+
+-- NULL only for admin keys, which may pick any workspace
+ALTER TABLE api_keys
+ADD COLUMN IF NOT EXISTS workspace_id UUID CONSTRAINT api_keys_workspace_id_fkey REFERENCES organizations (id);
+
+UPDATE api_keys
+SET
+    workspace_id = '00000000-0000-0000-0000-000000000001'
+WHERE
+    NOT admin
+    AND workspace_id IS NULL;
+
+-- Users belong to a workspace too, and an email only has to be unique
+-- within one
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS workspace_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' CONSTRAINT users_workspace_id_fkey REFERENCES organizations (id);
+
+ALTER TABLE users
+ALTER COLUMN workspace_id DROP DEFAULT;
+
+ALTER TABLE users
+DROP CONSTRAINT IF EXISTS users_email_key;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'users_workspace_email_key') THEN
+        ALTER TABLE users ADD CONSTRAINT users_workspace_email_key UNIQUE (workspace_id, email);
+    END IF;
+    -- Target of projects_owner_id_fkey below
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'users_workspace_id_key') THEN
+        ALTER TABLE users ADD CONSTRAINT users_workspace_id_key UNIQUE (workspace_id, id);
+    END IF;
+END $$;
+
+-- A project's owner must be in the project's workspace
+ALTER TABLE projects
+DROP CONSTRAINT IF EXISTS projects_owner_id_fkey;
+
+ALTER TABLE projects
+ADD CONSTRAINT projects_owner_id_fkey FOREIGN KEY (workspace_id, owner_id) REFERENCES users (workspace_id, id) ON DELETE SET NULL (owner_id);
+
+-- Row-level security. The API sets app.workspace_id (or app.all_workspaces)
+-- on every connection it hands out and then works as pm_tenant, which the
+-- policies below apply to; superusers and table owners, such as the role
+-- running these migrations, bypass them.
+CREATE OR REPLACE FUNCTION app_workspace_visible (ws UUID) RETURNS BOOLEAN LANGUAGE sql STABLE AS $$
+    SELECT coalesce(current_setting('app.all_workspaces', true), '') = 'on'
+        OR ws = nullif(current_setting('app.workspace_id', true), '')::uuid
+$$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'pm_tenant') THEN
+        CREATE ROLE pm_tenant NOLOGIN;
+    END IF;
+END $$;
+
+GRANT pm_tenant TO CURRENT_USER;
+
+GRANT USAGE ON SCHEMA public TO pm_tenant;
+
+-- Only what the API needs, table by table; later migrations grant on the
+-- tables they add. First the tenant-scoped tables, each under a policy below
+GRANT SELECT, INSERT, UPDATE, DELETE ON projects, project_templates, users, tasks, labels, project_workflows,
+project_task_counters, project_members, task_labels, comments, task_dependencies TO pm_tenant;
+
+-- Then the two shared by every workspace, which only admins manage: keys are
+-- revoked rather than deleted, and organizations never change
+GRANT SELECT, INSERT, UPDATE ON api_keys TO pm_tenant;
+
+GRANT SELECT, INSERT ON organizations TO pm_tenant;
+
+ALTER TABLE projects ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS projects_workspace ON projects;
+
+CREATE POLICY projects_workspace ON projects USING (app_workspace_visible (workspace_id))
+WITH
+    CHECK (app_workspace_visible (workspace_id));
+
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS users_workspace ON users;
+
+CREATE POLICY users_workspace ON users USING (app_workspace_visible (workspace_id))
+WITH
+    CHECK (app_workspace_visible (workspace_id));
+
+ALTER TABLE project_templates ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS project_templates_workspace ON project_templates;
+
+CREATE POLICY project_templates_workspace ON project_templates USING (app_workspace_visible (workspace_id))
+WITH
+    CHECK (app_workspace_visible (workspace_id));
+
+-- Rows that hang off a project or task are visible when it is; the subquery
+-- is itself subject to the projects and tasks policies
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['tasks', 'labels', 'project_workflows', 'project_task_counters', 'project_members'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('DROP POLICY IF EXISTS %I ON %I', t || '_workspace', t);
+        EXECUTE format(
+            'CREATE POLICY %I ON %I USING (EXISTS (SELECT 1 FROM projects p WHERE p.id = project_id))',
+            t || '_workspace', t);
+    END LOOP;
+    FOREACH t IN ARRAY ARRAY['task_labels', 'comments', 'task_dependencies'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('DROP POLICY IF EXISTS %I ON %I', t || '_workspace', t);
+        EXECUTE format(
+            'CREATE POLICY %I ON %I USING (EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_id))',
+            t || '_workspace', t);
+    END LOOP;
+END $$;
+
+-- Foreign keys ignore row-level security, so these keep tasks and comments
+-- from pointing at a user in another workspace. They fail as the foreign
+-- key on the column would, so callers need not tell the two apart.
+CREATE OR REPLACE FUNCTION tasks_assignee_in_workspace () RETURNS TRIGGER LANGUAGE plpgsql AS $$
+BEGIN
+    IF NEW.assignee_id IS NOT NULL AND NOT EXISTS (
+        SELECT 1 FROM users u JOIN projects p ON p.workspace_id = u.workspace_id
+        WHERE u.id = NEW.assignee_id AND p.id = NEW.project_id
+    ) THEN
+        RAISE EXCEPTION 'assignee % is not in the workspace of project %', NEW.assignee_id, NEW.project_id
+            USING ERRCODE = 'foreign_key_violation', CONSTRAINT = 'tasks_assignee_id_fkey';
+    END IF;
+    RETURN NEW;
+END $$;
+
+DROP TRIGGER IF EXISTS tasks_assignee_in_workspace ON tasks;
+
+CREATE TRIGGER tasks_assignee_in_workspace BEFORE INSERT OR UPDATE OF assignee_id, project_id ON tasks FOR EACH ROW
+EXECUTE FUNCTION tasks_assignee_in_workspace ();
+
+CREATE OR REPLACE FUNCTION comments_author_in_workspace () RETURNS TRIGGER LANGUAGE plpgsql AS $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM users u
+        JOIN projects p ON p.workspace_id = u.workspace_id
+        JOIN tasks t ON t.project_id = p.id
+        WHERE u.id = NEW.author_id AND t.id = NEW.task_id
+    ) THEN
+        RAISE EXCEPTION 'author % is not in the workspace of task %', NEW.author_id, NEW.task_id
+            USING ERRCODE = 'foreign_key_violation', CONSTRAINT = 'comments_author_id_fkey';
+    END IF;
+    RETURN NEW;
+END $$;
+
+DROP TRIGGER IF EXISTS comments_author_in_workspace ON comments;
+
+CREATE TRIGGER comments_author_in_workspace BEFORE INSERT OR UPDATE OF author_id, task_id ON comments FOR EACH ROW
+EXECUTE FUNCTION comments_author_in_workspace ();
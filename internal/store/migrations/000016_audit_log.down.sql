@@ -0,0 +1,5 @@
+DROP FUNCTION IF EXISTS purge_audit_log (TIMESTAMPTZ);
+
+DROP TABLE IF EXISTS audit_log;
+
+DROP FUNCTION IF EXISTS audit_log_immutable ();
@@ -0,0 +1,58 @@
+CREATE TABLE
+    IF NOT EXISTS audit_log (
+        id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+        -- No foreign key: entries outlive what they describe
+        workspace_id UUID NOT NULL,
+        actor TEXT NOT NULL,
+        request_id TEXT,
+        action TEXT NOT NULL,
+        entity TEXT NOT NULL,
+        entity_id TEXT NOT NULL,
+        before JSONB,
+        after JSONB,
+        created_at TIMESTAMPTZ NOT NULL,
+        CONSTRAINT audit_log_action_check CHECK (action IN ('create', 'update', 'delete'))
+    );
+
+CREATE INDEX IF NOT EXISTS audit_log_entity_idx ON audit_log (workspace_id, entity, entity_id, id DESC);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON audit_log (created_at);
+
+-- Append-only: the API may read and add entries but never change them, and
+-- only purge_audit_log, for retention, removes them
+REVOKE ALL ON audit_log FROM pm_tenant;
+
+GRANT SELECT, INSERT ON audit_log TO pm_tenant;
+
+CREATE OR REPLACE FUNCTION audit_log_immutable () RETURNS TRIGGER LANGUAGE plpgsql AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log entries cannot be changed';
+END $$;
+
+DROP TRIGGER IF EXISTS audit_log_immutable ON audit_log;
+
+CREATE TRIGGER audit_log_immutable BEFORE UPDATE ON audit_log FOR EACH ROW
+EXECUTE FUNCTION audit_log_immutable ();
+
+-- Runs as the owner, so it deletes across workspaces and despite the
+-- revoked DELETE
+CREATE OR REPLACE FUNCTION purge_audit_log (recorded_before TIMESTAMPTZ) RETURNS BIGINT LANGUAGE sql SECURITY DEFINER
+SET
+    search_path = public AS $$
+    WITH purged AS (
+        DELETE FROM audit_log WHERE created_at < recorded_before RETURNING 1
+    )
+    SELECT count(*) FROM purged
+$$;
+
+REVOKE ALL ON FUNCTION purge_audit_log (TIMESTAMPTZ) FROM PUBLIC;
+
+GRANT EXECUTE ON FUNCTION purge_audit_log (TIMESTAMPTZ) TO pm_tenant;
+
+ALTER TABLE audit_log ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS audit_log_workspace ON audit_log;
+
+CREATE POLICY audit_log_workspace ON audit_log USING (app_workspace_visible (workspace_id))
+WITH
+    CHECK (app_workspace_visible (workspace_id));
@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var nameCleanRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create scaffolds the next numbered up/down pair for name in dir (normally
+// internal/store/migrations) and returns the paths it wrote.
+func Create(dir, name string) (upPath, downPath string, err error) {
+	slug := strings.Trim(nameCleanRe.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if slug == "" {
+		return "", "", errors.New("migration name must contain letters or digits")
+	}
+
+	existing, err := load(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+	next := int64(1)
+	if len(existing) > 0 {
+		next = existing[len(existing)-1].Version + 1
+	}
+
+	base := fmt.Sprintf("%06d_%s", next, slug)
+	upPath = filepath.Join(dir, base+".up.sql")
+	downPath = filepath.Join(dir, base+".down.sql")
+
+	if err := writeNew(upPath, "-- "+base+" (up)\n"); err != nil {
+		return "", "", err
+	}
+	if err := writeNew(downPath, "-- "+base+" (down): undo everything the up file does\n"); err != nil {
+		_ = os.Remove(upPath)
+		return "", "", err
+	}
+	return upPath, downPath, nil
+}
+
+// writeNew creates path with contents, refusing to overwrite an existing file.
+func writeNew(path, contents string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(contents); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreate_ScaffoldsNextVersion(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "000007_init.up.sql"), []byte("select 1;"), 0o644); err != nil {
+		t.Fatalf("seed migration: %v", err)
+	}
+
+	up, down, err := Create(dir, "Add Task Labels")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if filepath.Base(up) != "000008_add_task_labels.up.sql" || filepath.Base(down) != "000008_add_task_labels.down.sql" {
+		t.Fatalf("unexpected paths: %s, %s", up, down)
+	}
+
+	// The scaffold must itself be loadable.
+	migrations, err := load(os.DirFS(dir))
+	if err != nil {
+		t.Fatalf("load after create: %v", err)
+	}
+	if len(migrations) != 2 || migrations[1].Version != 8 {
+		t.Fatalf("unexpected migrations after create: %+v", migrations)
+	}
+}
+
+func TestCreate_EmptyDirStartsAtOne(t *testing.T) {
+	up, _, err := Create(t.TempDir(), "init")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if filepath.Base(up) != "000001_init.up.sql" {
+		t.Fatalf("unexpected path: %s", up)
+	}
+}
+
+func TestCreate_RejectsBlankName(t *testing.T) {
+	if _, _, err := Create(t.TempDir(), " -- "); err == nil {
+		t.Fatalf("expected error for blank name")
+	}
+}
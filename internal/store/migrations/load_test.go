@@ -0,0 +1,78 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad_EmbeddedMigrationsArePaired(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatalf("expected embedded migrations")
+	}
+
+	for i, m := range migrations {
+		if i > 0 && m.Version <= migrations[i-1].Version {
+			t.Fatalf("migrations not strictly ordered: %d after %d", m.Version, migrations[i-1].Version)
+		}
+		if strings.TrimSpace(m.DownSQL) == "" {
+			t.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+		}
+		if len(m.Checksum) != 64 {
+			t.Errorf("migration %d_%s has checksum %q", m.Version, m.Name, m.Checksum)
+		}
+	}
+}
+
+func TestLoad_OrdersAndPairsFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000010_second.up.sql":  {Data: []byte("create table b ();")},
+		"000002_first.up.sql":   {Data: []byte("create table a ();")},
+		"000002_first.down.sql": {Data: []byte("drop table a;")},
+	}
+
+	migrations, err := load(fsys)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("expected 2 migrations; got %d", len(migrations))
+	}
+	if migrations[0].Version != 2 || migrations[0].Name != "first" || migrations[0].DownSQL != "drop table a;" {
+		t.Fatalf("unexpected first migration: %+v", migrations[0])
+	}
+	if migrations[1].Version != 10 || migrations[1].DownSQL != "" {
+		t.Fatalf("unexpected second migration: %+v", migrations[1])
+	}
+}
+
+func TestLoad_ChecksumTracksUpSQL(t *testing.T) {
+	a, err := load(fstest.MapFS{"000001_init.up.sql": {Data: []byte("create table a ();")}})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	b, err := load(fstest.MapFS{"000001_init.up.sql": {Data: []byte("create table b ();")}})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if a[0].Checksum == b[0].Checksum {
+		t.Fatalf("expected different checksums for different SQL")
+	}
+}
+
+func TestLoad_RejectsBadFiles(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"bad name":      {"init.up.sql": {Data: []byte("select 1;")}},
+		"missing up":    {"000001_init.down.sql": {Data: []byte("select 1;")}},
+		"version clash": {"000001_a.up.sql": {Data: []byte("select 1;")}, "000001_b.up.sql": {Data: []byte("select 1;")}},
+		"zero version":  {"000000_init.up.sql": {Data: []byte("select 1;")}},
+	} {
+		if _, err := load(fsys); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -2,51 +2,487 @@ package migrations
 
 import (
 	"context"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 //go:embed *.sql
 var migrationsFS embed.FS
 
-// Apply runs all *.up.sql files in lexical order
-// For our testcontainers DB (fresh DB each time), this is enough
-// Later we can add a schema_migrations table to track versions.
+// lockKey is the pg_advisory_lock key that serialises concurrent runners
+// (e.g. two cmd/migrate containers started at once).
+const lockKey int64 = 0x706d5f6d69677261 // "pm_migra"
 
-func Apply(ctx context.Context, pool *pgxpool.Pool) error {
-	files, err := fs.Glob(migrationsFS, "*.up.sql")
+var (
+	// ErrChecksumMismatch means an applied migration file was edited after it ran.
+	ErrChecksumMismatch = errors.New("applied migration has been modified")
+	// ErrMissingMigration means the database records a version this binary has no file for.
+	ErrMissingMigration = errors.New("applied migration is missing")
+	// ErrUnknownVersion means a target version does not match any migration.
+	ErrUnknownVersion = errors.New("unknown migration version")
+)
+
+// Migration is one numbered schema change, loaded from a
+// NNNNNN_name.up.sql file and its optional NNNNNN_name.down.sql partner.
+type Migration struct {
+	Version  int64
+	Name     string
+	UpSQL    string
+	DownSQL  string
+	Checksum string // sha256 of UpSQL, recorded when the migration is applied
+}
+
+var fileNameRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Load returns the embedded migrations ordered by version.
+func Load() ([]Migration, error) {
+	return load(migrationsFS)
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
 	if err != nil {
-		return fmt.Errorf("glob migrations: %w", err)
+		return nil, fmt.Errorf("glob migrations: %w", err)
 	}
-	sort.Strings(files)
 
-	conn, err := pool.Acquire(ctx)
+	byVersion := make(map[int64]*Migration)
+	hasUp := make(map[int64]bool)
+	for _, name := range files {
+		m := fileNameRe.FindStringSubmatch(name)
+		if m == nil {
+			return nil, fmt.Errorf("migration %q: name must look like 000001_name.up.sql", name)
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %q: invalid version", name)
+		}
+
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("read migration %q: %w", name, err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration version %d used by both %q and %q", version, mig.Name, m[2])
+		}
+
+		if m[3] == "up" {
+			mig.UpSQL = string(b)
+			hasUp[version] = true
+		} else {
+			mig.DownSQL = string(b)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if !hasUp[mig.Version] {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mig.Version, mig.Name)
+		}
+		sum := sha256.Sum256([]byte(mig.UpSQL))
+		mig.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Runner applies and rolls back migrations, recording progress in schema_migrations.
+type Runner struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+
+	// Out, when set, receives one line per migration applied or rolled back,
+	// and in dry-run mode the SQL that would run.
+	Out io.Writer
+	// DryRun prints the planned SQL instead of executing it. Nothing is written
+	// to the database, not even schema_migrations.
+	DryRun bool
+}
+
+// NewRunner returns a Runner over the embedded migrations.
+func NewRunner(pool *pgxpool.Pool) (*Runner, error) {
+	migrations, err := Load()
 	if err != nil {
-		return fmt.Errorf("acquire conn: %w", err)
+		return nil, err
 	}
-	defer conn.Release()
+	return &Runner{pool: pool, migrations: migrations}, nil
+}
 
-	for _, name := range files {
-		b, err := migrationsFS.ReadFile(name)
+// Apply brings the database up to the latest migration.
+func Apply(ctx context.Context, pool *pgxpool.Pool) error {
+	r, err := NewRunner(pool)
+	if err != nil {
+		return err
+	}
+	return r.Up(ctx)
+}
+
+// Latest returns the highest known migration version, or 0 if there are none.
+func (r *Runner) Latest() int64 {
+	if len(r.migrations) == 0 {
+		return 0
+	}
+	return r.migrations[len(r.migrations)-1].Version
+}
+
+// Up applies every pending migration.
+func (r *Runner) Up(ctx context.Context) error {
+	return r.To(ctx, r.Latest())
+}
+
+// To migrates the database to target: pending migrations up to and including
+// target are applied in ascending order, then applied migrations above target
+// are rolled back in descending order. A target of 0 rolls everything back.
+func (r *Runner) To(ctx context.Context, target int64) error {
+	if target != 0 && r.find(target) == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, target)
+	}
+
+	return r.run(ctx, func(applied map[int64]bool) []step {
+		var steps []step
+		for _, m := range r.migrations {
+			if m.Version <= target && !applied[m.Version] {
+				steps = append(steps, step{migration: m, up: true})
+			}
+		}
+		for i := len(r.migrations) - 1; i >= 0; i-- {
+			m := r.migrations[i]
+			if m.Version > target && applied[m.Version] {
+				steps = append(steps, step{migration: m, up: false})
+			}
+		}
+		return steps
+	})
+}
+
+// Down rolls back the n most recently applied migrations (by version).
+// Pending migrations below them are left alone.
+func (r *Runner) Down(ctx context.Context, n int) error {
+	if n < 1 {
+		return errors.New("down: n must be >= 1")
+	}
+
+	return r.run(ctx, func(applied map[int64]bool) []step {
+		var steps []step
+		for i := len(r.migrations) - 1; i >= 0 && len(steps) < n; i-- {
+			if m := r.migrations[i]; applied[m.Version] {
+				steps = append(steps, step{migration: m, up: false})
+			}
+		}
+		return steps
+	})
+}
+
+// Force rewrites schema_migrations so that exactly the migrations up to and
+// including version are recorded as applied, with their current checksums.
+// No migration SQL runs. It is the way out of a checksum mismatch or of a
+// schema that was changed by hand.
+func (r *Runner) Force(ctx context.Context, version int64) error {
+	if version != 0 && r.find(version) == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return r.withLock(ctx, func(conn *pgx.Conn) error {
+		if r.DryRun {
+			r.printf("-- force: record versions <= %d as applied\n", version)
+			return nil
+		}
+
+		return inTx(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, "DELETE FROM schema_migrations"); err != nil {
+				return fmt.Errorf("clear schema_migrations: %w", err)
+			}
+			for _, m := range r.migrations {
+				if m.Version > version {
+					break
+				}
+				if err := record(ctx, tx, m); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// MigrationState describes how a migration relates to the database.
+type MigrationState string
+
+const (
+	StatePending  MigrationState = "pending"
+	StateApplied  MigrationState = "applied"
+	StateModified MigrationState = "modified" // applied, but the file changed since
+	StateMissing  MigrationState = "missing"  // applied, but no file in this build
+)
+
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	State     MigrationState
+	AppliedAt *time.Time
+}
+
+// Status lists every known or applied migration in version order.
+func (r *Runner) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+
+	err := r.withLock(ctx, func(conn *pgx.Conn) error {
+		applied, err := loadApplied(ctx, conn)
 		if err != nil {
-			return fmt.Errorf("read migration %q: %w", name, err)
+			return err
+		}
+
+		byVersion := make(map[int64]appliedMigration, len(applied))
+		for _, a := range applied {
+			byVersion[a.Version] = a
 		}
-		sql := strings.TrimSpace(string(b))
-		if sql == "" {
-			continue
+
+		for _, m := range r.migrations {
+			st := MigrationStatus{Version: m.Version, Name: m.Name, State: StatePending}
+			if a, ok := byVersion[m.Version]; ok {
+				st.State = StateApplied
+				if a.Checksum != m.Checksum {
+					st.State = StateModified
+				}
+				st.AppliedAt = &a.AppliedAt
+				delete(byVersion, m.Version)
+			}
+			statuses = append(statuses, st)
+		}
+		for _, a := range byVersion {
+			statuses = append(statuses, MigrationStatus{
+				Version:   a.Version,
+				Name:      a.Name,
+				State:     StateMissing,
+				AppliedAt: &a.AppliedAt,
+			})
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		// Use Pg.Conn.Exec so multi-statement SQL files work reliably
-		_, err = conn.Conn().PgConn().Exec(ctx, sql).ReadAll()
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+type step struct {
+	migration Migration
+	up        bool
+}
+
+// run verifies the recorded state, asks plan for the steps to take and
+// executes (or, in dry-run mode, prints) them in order.
+func (r *Runner) run(ctx context.Context, plan func(applied map[int64]bool) []step) error {
+	return r.withLock(ctx, func(conn *pgx.Conn) error {
+		applied, err := r.verify(ctx, conn)
 		if err != nil {
-			return fmt.Errorf("exec migration %q: %w", name, err)
+			return err
 		}
+
+		for _, st := range plan(applied) {
+			m := st.migration
+			direction, sql := "up", m.UpSQL
+			if !st.up {
+				direction, sql = "down", m.DownSQL
+			}
+
+			if r.DryRun {
+				r.printf("-- %06d_%s (%s)\n%s\n\n", m.Version, m.Name, direction, strings.TrimSpace(sql))
+				continue
+			}
+
+			if st.up {
+				err = runUp(ctx, conn, m)
+			} else {
+				err = runDown(ctx, conn, m)
+			}
+			if err != nil {
+				return err
+			}
+			r.printf("%s %06d_%s\n", direction, m.Version, m.Name)
+		}
+		return nil
+	})
+}
+
+func (r *Runner) printf(format string, args ...any) {
+	if r.Out != nil {
+		fmt.Fprintf(r.Out, format, args...)
 	}
+}
 
+func (r *Runner) find(version int64) *Migration {
+	for i := range r.migrations {
+		if r.migrations[i].Version == version {
+			return &r.migrations[i]
+		}
+	}
 	return nil
 }
+
+// withLock runs fn on a dedicated connection while holding the migration
+// advisory lock, creating schema_migrations first if needed.
+func (r *Runner) withLock(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire conn: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		// Session locks outlive a cancelled ctx; always release before the
+		// connection goes back to the pool.
+		_, _ = conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+	}()
+
+	if !r.DryRun {
+		if _, err := conn.Exec(ctx, createTableSQL); err != nil {
+			return fmt.Errorf("create schema_migrations: %w", err)
+		}
+	}
+
+	return fn(conn.Conn())
+}
+
+const createTableSQL = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version BIGINT PRIMARY KEY,
+	name TEXT NOT NULL,
+	checksum TEXT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`
+
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// loadApplied reads schema_migrations; a missing table (possible in dry-run
+// mode) means nothing has been applied yet.
+func loadApplied(ctx context.Context, conn *pgx.Conn) ([]appliedMigration, error) {
+	var exists bool
+	if err := conn.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check schema_migrations: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	rows, err := conn.Query(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	applied, err := pgx.CollectRows(rows, pgx.RowToStructByPos[appliedMigration])
+	if err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// verify checks every applied migration against the files on disk and returns
+// the set of applied versions.
+func (r *Runner) verify(ctx context.Context, conn *pgx.Conn) (map[int64]bool, error) {
+	applied, err := loadApplied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[int64]bool, len(applied))
+	for _, a := range applied {
+		m := r.find(a.Version)
+		if m == nil {
+			return nil, fmt.Errorf("%w: version %d (%s)", ErrMissingMigration, a.Version, a.Name)
+		}
+		if m.Checksum != a.Checksum {
+			return nil, fmt.Errorf("%w: version %d (%s)", ErrChecksumMismatch, a.Version, a.Name)
+		}
+		versions[a.Version] = true
+	}
+	return versions, nil
+}
+
+// runUp applies one migration and records it in the same transaction, so a
+// failure leaves neither the schema change nor the bookkeeping row behind.
+func runUp(ctx context.Context, conn *pgx.Conn, m Migration) error {
+	return inTx(ctx, conn, func(tx pgx.Tx) error {
+		if err := execScript(ctx, tx, m.UpSQL); err != nil {
+			return fmt.Errorf("apply migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		return record(ctx, tx, m)
+	})
+}
+
+func record(ctx context.Context, tx pgx.Tx, m Migration) error {
+	_, err := tx.Exec(ctx,
+		"INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+		m.Version, m.Name, m.Checksum)
+	if err != nil {
+		return fmt.Errorf("record migration %d_%s: %w", m.Version, m.Name, err)
+	}
+	return nil
+}
+
+func runDown(ctx context.Context, conn *pgx.Conn, m Migration) error {
+	if strings.TrimSpace(m.DownSQL) == "" {
+		return fmt.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+	}
+
+	return inTx(ctx, conn, func(tx pgx.Tx) error {
+		if err := execScript(ctx, tx, m.DownSQL); err != nil {
+			return fmt.Errorf("roll back migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", m.Version); err != nil {
+			return fmt.Errorf("unrecord migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		return nil
+	})
+}
+
+func inTx(ctx context.Context, conn *pgx.Conn, fn func(tx pgx.Tx) error) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// execScript runs a (possibly multi-statement) SQL file inside tx.
+func execScript(ctx context.Context, tx pgx.Tx, sql string) error {
+	sql = strings.TrimSpace(sql)
+	if sql == "" {
+		return nil
+	}
+
+	// Use the simple protocol so multi-statement SQL files work reliably
+	_, err := tx.Conn().PgConn().Exec(ctx, sql).ReadAll()
+	return err
+}
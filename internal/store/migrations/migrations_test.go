@@ -2,9 +2,12 @@ package migrations
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/linus5304/project-manager-api/internal/store/pgtest"
 )
@@ -41,3 +44,212 @@ func TestApply_CreateSchema(t *testing.T) {
 		t.Fatalf("tasks table does not exist")
 	}
 }
+
+func newPool(t *testing.T) (context.Context, *pgxpool.Pool) {
+	t.Helper()
+
+	pg := pgtest.StartPostgres(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+
+	pool, err := pgxpool.New(ctx, pg.ConnString)
+	if err != nil {
+		t.Fatalf("connect pgxpool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return ctx, pool
+}
+
+func appliedVersions(t *testing.T, ctx context.Context, pool *pgxpool.Pool) []int64 {
+	t.Helper()
+
+	rows, err := pool.Query(ctx, "select version from schema_migrations order by version")
+	if err != nil {
+		t.Fatalf("query schema_migrations: %v", err)
+	}
+	versions, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		t.Fatalf("collect versions: %v", err)
+	}
+	return versions
+}
+
+func TestApply_IsIdempotentAndTracked(t *testing.T) {
+	ctx, pool := newPool(t)
+
+	if err := Apply(ctx, pool); err != nil {
+		t.Fatalf("first apply: %v", err)
+	}
+	if err := Apply(ctx, pool); err != nil {
+		t.Fatalf("second apply: %v", err)
+	}
+
+	r, err := NewRunner(pool)
+	if err != nil {
+		t.Fatalf("NewRunner: %v", err)
+	}
+	got := appliedVersions(t, ctx, pool)
+	if len(got) != len(r.migrations) || got[len(got)-1] != r.Latest() {
+		t.Fatalf("expected all %d migrations recorded; got %v", len(r.migrations), got)
+	}
+}
+
+func TestRunner_RollsBackToTarget(t *testing.T) {
+	ctx, pool := newPool(t)
+
+	r, err := NewRunner(pool)
+	if err != nil {
+		t.Fatalf("NewRunner: %v", err)
+	}
+	if err := r.Up(ctx); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+
+	if err := r.To(ctx, 1); err != nil {
+		t.Fatalf("down to 1: %v", err)
+	}
+	if got := appliedVersions(t, ctx, pool); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("expected only version 1 applied; got %v", got)
+	}
+
+	if err := r.To(ctx, 0); err != nil {
+		t.Fatalf("down to 0: %v", err)
+	}
+	var projectReg *string
+	if err := pool.QueryRow(ctx, "select to_regclass('public.projects')").Scan(&projectReg); err != nil {
+		t.Fatalf("query projects table: %v", err)
+	}
+	if projectReg != nil {
+		t.Fatalf("expected projects table to be dropped")
+	}
+
+	if err := r.To(ctx, 999999); !errors.Is(err, ErrUnknownVersion) {
+		t.Fatalf("expected ErrUnknownVersion; got %v", err)
+	}
+}
+
+func TestRunner_DetectsChecksumMismatch(t *testing.T) {
+	ctx, pool := newPool(t)
+
+	if err := Apply(ctx, pool); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "update schema_migrations set checksum = 'edited' where version = 1"); err != nil {
+		t.Fatalf("tamper checksum: %v", err)
+	}
+
+	if err := Apply(ctx, pool); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch; got %v", err)
+	}
+}
+
+func TestApply_ConcurrentRunnersAreSerialised(t *testing.T) {
+	ctx, pool := newPool(t)
+
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() { errs <- Apply(ctx, pool) }()
+	}
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Fatalf("concurrent apply: %v", err)
+		}
+	}
+
+	r, err := NewRunner(pool)
+	if err != nil {
+		t.Fatalf("NewRunner: %v", err)
+	}
+	if got := appliedVersions(t, ctx, pool); len(got) != len(r.migrations) {
+		t.Fatalf("expected each migration recorded once; got %v", got)
+	}
+}
+
+func TestRunner_DownRollsBackMostRecent(t *testing.T) {
+	ctx, pool := newPool(t)
+
+	r, err := NewRunner(pool)
+	if err != nil {
+		t.Fatalf("NewRunner: %v", err)
+	}
+	if err := r.Up(ctx); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+
+	if err := r.Down(ctx, 1); err != nil {
+		t.Fatalf("down 1: %v", err)
+	}
+	got := appliedVersions(t, ctx, pool)
+	if len(got) != len(r.migrations)-1 || (len(got) > 0 && got[len(got)-1] == r.Latest()) {
+		t.Fatalf("expected latest version rolled back; got %v", got)
+	}
+}
+
+func TestRunner_ForceClearsChecksumMismatch(t *testing.T) {
+	ctx, pool := newPool(t)
+
+	r, err := NewRunner(pool)
+	if err != nil {
+		t.Fatalf("NewRunner: %v", err)
+	}
+	if err := r.Up(ctx); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if _, err := pool.Exec(ctx, "update schema_migrations set checksum = 'edited' where version = 1"); err != nil {
+		t.Fatalf("tamper checksum: %v", err)
+	}
+
+	statuses, err := r.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if statuses[0].State != StateModified {
+		t.Fatalf("expected version 1 modified; got %s", statuses[0].State)
+	}
+
+	if err := r.Force(ctx, r.Latest()); err != nil {
+		t.Fatalf("force: %v", err)
+	}
+	if err := r.Up(ctx); err != nil {
+		t.Fatalf("up after force: %v", err)
+	}
+	statuses, err = r.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	for _, st := range statuses {
+		if st.State != StateApplied {
+			t.Fatalf("expected all applied after force; got %+v", st)
+		}
+	}
+}
+
+func TestRunner_DryRunChangesNothing(t *testing.T) {
+	ctx, pool := newPool(t)
+
+	r, err := NewRunner(pool)
+	if err != nil {
+		t.Fatalf("NewRunner: %v", err)
+	}
+	var out strings.Builder
+	r.Out = &out
+	r.DryRun = true
+
+	if err := r.Up(ctx); err != nil {
+		t.Fatalf("dry-run up: %v", err)
+	}
+	if !strings.Contains(out.String(), "CREATE TABLE") {
+		t.Fatalf("expected planned SQL in output; got %q", out.String())
+	}
+
+	statuses, err := r.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	for _, st := range statuses {
+		if st.State != StatePending {
+			t.Fatalf("expected nothing applied after dry run; got %+v", st)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package store
+
+import "github.com/google/uuid"
+
+// positionGap is the distance between neighbouring cards after a column is
+// (re)numbered, and below the bottom card for appends.
+const positionGap = 1024
+
+// columnEntry is one card of a board column.
+type columnEntry struct {
+	ID       uuid.UUID
+	Position float64
+}
+
+// slotPosition picks the position for a card dropped into column, which is in
+// board order and leaves out the card itself. The card goes right after
+// afterID and/or right before beforeID, or to the bottom when both are nil.
+// When both are set they must still be neighbours, so a drop based on a stale
+// view of the board fails with ErrMoveConflict instead of landing somewhere
+// unexpected. ok is false when there is no room left between the neighbours
+// and the column has to be renumbered first.
+func slotPosition(column []columnEntry, afterID, beforeID *uuid.UUID) (pos float64, ok bool, err error) {
+	indexOf := func(id uuid.UUID) int {
+		for i, e := range column {
+			if e.ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+
+	// lo and hi are the indexes of the cards either side of the slot
+	lo, hi := len(column)-1, len(column)
+	if afterID != nil {
+		if lo = indexOf(*afterID); lo < 0 {
+			return 0, false, ErrMoveTargetInvalid
+		}
+		hi = lo + 1
+	}
+	if beforeID != nil {
+		i := indexOf(*beforeID)
+		if i < 0 {
+			return 0, false, ErrMoveTargetInvalid
+		}
+		if afterID != nil && i != hi {
+			return 0, false, ErrMoveConflict
+		}
+		lo, hi = i-1, i
+	}
+
+	switch {
+	case lo < 0 && hi >= len(column):
+		return positionGap, true, nil
+	case lo < 0:
+		return column[hi].Position - positionGap, true, nil
+	case hi >= len(column):
+		return column[lo].Position + positionGap, true, nil
+	}
+	a, b := column[lo].Position, column[hi].Position
+	mid := a + (b-a)/2
+	return mid, a < mid && mid < b, nil
+}
+
+// renumberColumn spaces the cards of column evenly, keeping their order.
+func renumberColumn(column []columnEntry) {
+	for i := range column {
+		column[i].Position = float64(i+1) * positionGap
+	}
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSlotPosition(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	column := []columnEntry{{a, 1024}, {b, 2048}, {c, 2048}}
+
+	for _, tc := range []struct {
+		name          string
+		after, before *uuid.UUID
+		want          float64
+		wantOK        bool
+		wantErr       error
+	}{
+		{name: "bottom", want: 3072, wantOK: true},
+		{name: "top", before: &a, want: 0, wantOK: true},
+		{name: "after", after: &a, want: 1536, wantOK: true},
+		{name: "before", before: &b, want: 1536, wantOK: true},
+		{name: "between", after: &a, before: &b, want: 1536, wantOK: true},
+		{name: "tied neighbours", after: &b, before: &c, want: 2048, wantOK: false},
+		{name: "not adjacent", after: &a, before: &c, wantErr: ErrMoveConflict},
+		{name: "unknown", after: new(uuid.UUID), wantErr: ErrMoveTargetInvalid},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok, err := slotPosition(column, tc.after, tc.before)
+			if err != tc.wantErr {
+				t.Fatalf("expected error %v; got %v", tc.wantErr, err)
+			}
+			if err == nil && (got != tc.want || ok != tc.wantOK) {
+				t.Fatalf("expected %v (ok=%v); got %v (ok=%v)", tc.want, tc.wantOK, got, ok)
+			}
+		})
+	}
+
+	if _, ok, _ := slotPosition([]columnEntry{}, nil, nil); !ok {
+		t.Fatalf("expected an empty column to have room")
+	}
+}
@@ -2,7 +2,12 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"math"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,8 +30,26 @@ func (s *PostgresStore) Ping(ctx context.Context) error {
 	return s.pool.Ping(ctx)
 }
 
+// tenantRole is the role the store works as. Unlike the superuser or table
+// owner that usually connects, it is bound by the row-level security
+// policies that keep each workspace's rows to itself.
+const tenantRole = "pm_tenant"
+
+// NewPostgresStore connects to an already migrated database. Every
+// connection switches to tenantRole and, each time it is handed out, is
+// scoped to the workspace of the context it is acquired with.
 func NewPostgresStore(ctx context.Context, dsn string) (*PostgresStore, error) {
-	pool, err := pgxpool.New(ctx, dsn)
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		_, err := conn.Exec(ctx, "SET ROLE "+tenantRole)
+		return err
+	}
+	config.PrepareConn = scopeConn
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, err
 	}
@@ -46,26 +69,135 @@ func (s *PostgresStore) Close() {
 	s.pool.Close()
 }
 
-func (s *PostgresStore) InsertProject(ctx context.Context, name string) (domain.Project, error) {
-	p := domain.Project{
-		ID:        uuid.New(),
-		Name:      name,
-		CreatedAt: time.Now().UTC(),
+// scopeConn sets the session variables the row-level security policies
+// read; see app_workspace_visible in the migrations.
+func scopeConn(ctx context.Context, conn *pgx.Conn) (bool, error) {
+	all := "off"
+	if inWorkspace(ctx, allWorkspaces) {
+		all = "on"
 	}
+	_, err := conn.Exec(ctx,
+		"SELECT set_config('app.workspace_id', $1, false), set_config('app.all_workspaces', $2, false)",
+		Workspace(ctx).String(), all)
+	return err == nil, err
+}
 
-	row, err := s.queries.InsertProject(ctx, sqlc.InsertProjectParams{
-		ID:        p.ID,
-		Name:      p.Name,
-		CreatedAt: p.CreatedAt,
-	})
+// inTx runs fn in a transaction, committing if it returns nil.
+func (s *PostgresStore) inTx(ctx context.Context, fn func(q *sqlc.Queries) error) error {
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return domain.Project{}, err
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(s.queries.WithTx(tx)); err != nil {
+		return err
 	}
+	return tx.Commit(ctx)
+}
+
+func toDomainProject(row sqlc.Project) domain.Project {
 	return domain.Project{
-		ID:        row.ID,
-		Name:      row.Name,
-		CreatedAt: row.CreatedAt,
-	}, nil
+		ID:          row.ID,
+		WorkspaceID: row.WorkspaceID,
+		Name:        row.Name,
+		Description: row.Description,
+		OwnerID:     row.OwnerID,
+		Color:       row.Color,
+		Icon:        row.Icon,
+		Key:         row.Key.String,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+		ArchivedAt:  row.ArchivedAt,
+		DeletedAt:   row.DeletedAt,
+	}
+}
+
+const (
+	// projectsOwnerFK names the projects.owner_id foreign key.
+	projectsOwnerFK = "projects_owner_id_fkey"
+	// projectsKeyUnique is the unique constraint on projects.key within a
+	// workspace.
+	projectsKeyUnique = "projects_workspace_key_key"
+)
+
+// projectError maps constraint violations on the projects table to store
+// errors.
+func projectError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "23503" && pgErr.ConstraintName == projectsOwnerFK:
+			return ErrUserNotFound
+		case pgErr.Code == "23505" && pgErr.ConstraintName == projectsKeyUnique:
+			return ErrProjectKeyTaken
+		}
+	}
+	return err
+}
+
+// optKey maps an empty project key to NULL, which the unique constraint
+// lets any number of projects share.
+func optKey(key string) pgtype.Text {
+	return pgtype.Text{String: key, Valid: key != ""}
+}
+
+// optMember maps an empty member filter to NULL, which keeps all rows.
+func optMember(subject string) pgtype.Text {
+	return pgtype.Text{String: subject, Valid: subject != ""}
+}
+
+// addOwner makes subject the first owner of a new project; an empty subject
+// leaves it without members.
+func addOwner(ctx context.Context, q *sqlc.Queries, project sqlc.Project, subject string) error {
+	if subject == "" {
+		return nil
+	}
+	row, err := q.UpsertProjectMember(ctx, sqlc.UpsertProjectMemberParams{
+		ProjectID: project.ID,
+		Subject:   subject,
+		Role:      domain.RoleOwner,
+		CreatedAt: project.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	return audit(ctx, q, domain.AuditMember, project.ID.String(), nil, toDomainMember(row))
+}
+
+// offset32 converts a page offset for a query, capping it at the largest
+// int32: no table comes near that many rows, so the page is empty either way.
+func offset32(offset int) int32 {
+	return int32(min(offset, math.MaxInt32))
+}
+
+func (s *PostgresStore) InsertProject(ctx context.Context, project NewProject) (domain.Project, error) {
+	var row sqlc.Project
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		row, err = q.InsertProject(ctx, sqlc.InsertProjectParams{
+			ID:          uuid.New(),
+			Name:        project.Name,
+			CreatedAt:   time.Now().UTC(),
+			Description: project.Description,
+			OwnerID:     project.OwnerID,
+			Color:       project.Color,
+			Icon:        project.Icon,
+			Key:         optKey(project.Key),
+			WorkspaceID: Workspace(ctx),
+		})
+		if err != nil {
+			return err
+		}
+		if err := audit(ctx, q, domain.AuditProject, row.ID.String(), nil, toDomainProject(row)); err != nil {
+			return err
+		}
+		return addOwner(ctx, q, row, project.CreatedBy)
+	})
+	if err != nil {
+		return domain.Project{}, projectError(err)
+	}
+	return toDomainProject(row), nil
 }
 
 func (s *PostgresStore) GetProject(ctx context.Context, id uuid.UUID) (domain.Project, error) {
@@ -76,96 +208,486 @@ func (s *PostgresStore) GetProject(ctx context.Context, id uuid.UUID) (domain.Pr
 		}
 		return domain.Project{}, err
 	}
-	return domain.Project{
-		ID:        row.ID,
-		Name:      row.Name,
-		CreatedAt: row.CreatedAt,
-	}, nil
+	return toDomainProject(row), nil
 }
 
-func (s *PostgresStore) ListProjects(ctx context.Context) ([]domain.Project, error) {
-	rows, err := s.queries.ListProjects(ctx)
+func (s *PostgresStore) UpdateProject(ctx context.Context, id uuid.UUID, update ProjectUpdate) (domain.Project, error) {
+	params := sqlc.UpdateProjectParams{
+		ID:          id,
+		Name:        optText(update.Name),
+		Description: optText(update.Description),
+		Color:       optText(update.Color),
+		Icon:        optText(update.Icon),
+		UpdatedAt:   time.Now().UTC(),
+	}
+	if update.OwnerID != nil {
+		params.SetOwner = true
+		params.OwnerID = optUUID(update.OwnerID)
+	}
+	if update.Key != nil {
+		params.SetKey = true
+		params.Key = optKey(*update.Key)
+	}
+
+	row, err := s.changeProject(ctx, id, func(q *sqlc.Queries) (sqlc.Project, error) {
+		return q.UpdateProject(ctx, params)
+	})
 	if err != nil {
-		return nil, err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Project{}, ErrNotFound
+		}
+		return domain.Project{}, projectError(err)
+	}
+	return toDomainProject(row), nil
+}
+
+func (s *PostgresStore) DeleteProject(ctx context.Context, id uuid.UUID) error {
+	return s.inTx(ctx, func(q *sqlc.Queries) error {
+		before, err := q.GetProjectForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		n, err := q.SoftDeleteProject(ctx, sqlc.SoftDeleteProjectParams{
+			ID:        id,
+			DeletedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrNotFound
+		}
+		after, err := q.GetProjectForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditProject, id.String(), toDomainProject(before), toDomainProject(after))
+	})
+}
+
+// changeProject applies change to the locked project in one transaction and
+// records the result in the audit log.
+func (s *PostgresStore) changeProject(ctx context.Context, id uuid.UUID, change func(q *sqlc.Queries) (sqlc.Project, error)) (sqlc.Project, error) {
+	var row sqlc.Project
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		before, err := q.GetProjectForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if row, err = change(q); err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditProject, id.String(), toDomainProject(before), toDomainProject(row))
+	})
+	return row, err
+}
+
+func (s *PostgresStore) ArchiveProject(ctx context.Context, id uuid.UUID) (domain.Project, error) {
+	row, err := s.changeProject(ctx, id, func(q *sqlc.Queries) (sqlc.Project, error) {
+		return q.ArchiveProject(ctx, sqlc.ArchiveProjectParams{
+			ID:         id,
+			ArchivedAt: time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Project{}, ErrNotFound
+		}
+		return domain.Project{}, err
+	}
+	return toDomainProject(row), nil
+}
+
+func (s *PostgresStore) RestoreProject(ctx context.Context, id uuid.UUID) (domain.Project, error) {
+	row, err := s.changeProject(ctx, id, func(q *sqlc.Queries) (sqlc.Project, error) {
+		return q.RestoreProject(ctx, id)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Project{}, ErrNotFound
+		}
+		return domain.Project{}, err
+	}
+	return toDomainProject(row), nil
+}
+
+func (s *PostgresStore) PurgeDeletedProjects(ctx context.Context, deletedBefore time.Time) (int, error) {
+	var n int
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		rows, err := q.PurgeDeletedProjects(ctx, deletedBefore)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			e, _, err := newAuditEntry(ctx, domain.AuditProject, row.ID.String(), toDomainProject(row), nil)
+			if err != nil {
+				return err
+			}
+			e.WorkspaceID = row.WorkspaceID
+			if err := insertAuditEntry(ctx, q, e); err != nil {
+				return err
+			}
+		}
+		n = len(rows)
+		return nil
+	})
+	return n, err
+}
+
+func (s *PostgresStore) ListProjects(ctx context.Context, params ListProjectsParams) ([]domain.Project, int, error) {
+	total, err := s.queries.CountProjects(ctx, sqlc.CountProjectsParams{
+		IncludeArchived: params.IncludeArchived,
+		IncludeDeleted:  params.IncludeDeleted,
+		Member:          optMember(params.Member),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var rows []sqlc.Project
+	if params.After != nil {
+		rows, err = s.queries.ListProjectsAfter(ctx, sqlc.ListProjectsAfterParams{
+			AfterCreatedAt:  params.After.CreatedAt,
+			AfterID:         params.After.ID,
+			IncludeArchived: params.IncludeArchived,
+			IncludeDeleted:  params.IncludeDeleted,
+			Member:          optMember(params.Member),
+			Limit:           int32(params.Limit),
+		})
+	} else {
+		rows, err = s.queries.ListProjects(ctx, sqlc.ListProjectsParams{
+			IncludeArchived: params.IncludeArchived,
+			IncludeDeleted:  params.IncludeDeleted,
+			Member:          optMember(params.Member),
+			Limit:           int32(params.Limit),
+			Offset:          offset32(params.Offset),
+		})
+	}
+	if err != nil {
+		return nil, 0, err
 	}
 
 	projects := make([]domain.Project, 0, len(rows))
 	for _, row := range rows {
-		projects = append(projects, domain.Project{
-			ID:        row.ID,
-			Name:      row.Name,
-			CreatedAt: row.CreatedAt,
+		projects = append(projects, toDomainProject(row))
+	}
+	return projects, int(total), nil
+}
+
+func toDomainTask(row sqlc.Task) domain.Task {
+	return domain.Task{
+		ID:          row.ID,
+		ProjectID:   row.ProjectID,
+		Number:      row.Number,
+		Title:       row.Title,
+		Description: row.Description,
+		Status:      row.Status,
+		AssigneeID:  row.AssigneeID,
+		DueDate:     row.DueDate,
+		Priority:    row.Priority,
+		Labels:      []domain.Label{},
+		CreatedAt:   row.CreatedAt,
+
+		StatusCategory: row.StatusCategory,
+		ParentTaskID:   row.ParentTaskID,
+		Position:       row.Position,
+		BlockedBy:      []uuid.UUID{},
+	}
+}
+
+// toDomainTasks converts rows and fills in each task's identifier, labels,
+// subtask rollup and blockers, with one query each.
+func (s *PostgresStore) toDomainTasks(ctx context.Context, rows []sqlc.Task) ([]domain.Task, error) {
+	tasks := make([]domain.Task, 0, len(rows))
+	if len(rows) == 0 {
+		return tasks, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	byID := make(map[uuid.UUID]int, len(rows))
+	projectIDs := make([]uuid.UUID, 0, 1)
+	for i, r := range rows {
+		tasks = append(tasks, toDomainTask(r))
+		ids = append(ids, r.ID)
+		byID[r.ID] = i
+		if !slices.Contains(projectIDs, r.ProjectID) {
+			projectIDs = append(projectIDs, r.ProjectID)
+		}
+	}
+
+	keyRows, err := s.queries.ListProjectKeys(ctx, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[uuid.UUID]string, len(keyRows))
+	for _, kr := range keyRows {
+		keys[kr.ID] = kr.Key.String
+	}
+	for i := range tasks {
+		tasks[i].Identifier = domain.TaskIdentifier(keys[tasks[i].ProjectID], tasks[i].Number)
+	}
+
+	labelRows, err := s.queries.ListTaskLabels(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, lr := range labelRows {
+		t := &tasks[byID[lr.TaskID]]
+		t.Labels = append(t.Labels, domain.Label{
+			ID:        lr.ID,
+			ProjectID: lr.ProjectID,
+			Name:      lr.Name,
+			Color:     lr.Color,
+			CreatedAt: lr.CreatedAt,
 		})
 	}
-	return projects, nil
+
+	rollups, err := s.queries.ListSubtaskRollups(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rollups {
+		tasks[byID[r.ParentTaskID]].Subtasks = domain.SubtaskRollup{
+			Done:  int(r.Done),
+			Total: int(r.Total),
+		}
+	}
+
+	blockerRows, err := s.queries.ListTaskBlockerIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, br := range blockerRows {
+		t := &tasks[byID[br.TaskID]]
+		t.BlockedBy = append(t.BlockedBy, br.BlockerID)
+	}
+	return tasks, nil
+}
+
+// toDomainTaskWithRelations is toDomainTasks for a single row.
+func (s *PostgresStore) toDomainTaskWithRelations(ctx context.Context, row sqlc.Task) (domain.Task, error) {
+	tasks, err := s.toDomainTasks(ctx, []sqlc.Task{row})
+	if err != nil {
+		return domain.Task{}, err
+	}
+	return tasks[0], nil
 }
 
-func (s *PostgresStore) InsertTask(ctx context.Context, projectID uuid.UUID, title, description string) (domain.Task, error) {
+// tasksAssigneeFK names the tasks.assignee_id foreign key, so an FK violation
+// can be told apart from one on tasks.project_id.
+const tasksAssigneeFK = "tasks_assignee_id_fkey"
+
+// tasksParentFK is the composite (project_id, parent_task_id) foreign key, so
+// it also rejects parents that live in another project.
+const tasksParentFK = "tasks_parent_fkey"
+
+func (s *PostgresStore) InsertTask(ctx context.Context, projectID uuid.UUID, task NewTask) (domain.Task, error) {
 	t := domain.Task{
 		ID:          uuid.New(),
 		ProjectID:   projectID,
-		Title:       title,
-		Description: description,
-		Status:      "todo",
+		Title:       task.Title,
+		Description: task.Description,
+		AssigneeID:  task.AssigneeID,
+		DueDate:     task.DueDate,
+		Priority:    task.Priority,
 		CreatedAt:   time.Now().UTC(),
+
+		ParentTaskID: task.ParentTaskID,
+	}
+	if t.Priority == "" {
+		t.Priority = DefaultTaskPriority
 	}
 
-	row, err := s.queries.InsertTask(ctx, sqlc.InsertTaskParams{
-		ID:          t.ID,
-		ProjectID:   t.ProjectID,
-		Title:       t.Title,
-		Description: t.Description,
-		Status:      t.Status,
-		CreatedAt:   t.CreatedAt,
+	// Hold the project so the workflow cannot change before the task lands
+	// in its initial status.
+	var row sqlc.Task
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		if _, err := q.LockProjectShared(ctx, projectID); err != nil {
+			return err
+		}
+		workflow, err := loadWorkflow(ctx, q, projectID)
+		if err != nil {
+			return err
+		}
+		initial := workflow.Statuses[0]
+
+		row, err = q.InsertTask(ctx, sqlc.InsertTaskParams{
+			ID:          t.ID,
+			ProjectID:   t.ProjectID,
+			Title:       t.Title,
+			Description: t.Description,
+			Status:      initial.Name,
+			CreatedAt:   t.CreatedAt,
+			AssigneeID:  t.AssigneeID,
+			DueDate:     t.DueDate,
+			Priority:    t.Priority,
+
+			ParentTaskID:   t.ParentTaskID,
+			StatusCategory: initial.Category,
+		})
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditTask, row.ID.String(), nil, toDomainTask(row))
 	})
 
 	if err != nil {
+		// No rows: the project is missing or soft-deleted. FK violation: the
+		// assignee does not exist, or the project was purged between the
+		// existence check and the insert.
 		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == tasksAssigneeFK {
+			return domain.Task{}, ErrUserNotFound
+		}
+		if pgErr != nil && pgErr.Code == "23503" && pgErr.ConstraintName == tasksParentFK {
+			return domain.Task{}, ErrParentTaskNotFound
+		}
+		if errors.Is(err, pgx.ErrNoRows) || (pgErr != nil && pgErr.Code == "23503") {
 			return domain.Task{}, ErrProjectNotFound
 		}
 		return domain.Task{}, err
 	}
 
-	return domain.Task{
-		ID:          row.ID,
-		ProjectID:   row.ProjectID,
-		Title:       row.Title,
-		Description: row.Description,
-		Status:      row.Status,
-		CreatedAt:   row.CreatedAt,
-	}, nil
+	return s.toDomainTaskWithRelations(ctx, row)
 }
 
-func (s *PostgresStore) ListTasks(ctx context.Context, projectID uuid.UUID) ([]domain.Task, error) {
-	rows, err := s.queries.ListTasks(ctx, projectID)
+func (s *PostgresStore) GetTask(ctx context.Context, projectID, taskID uuid.UUID) (domain.Task, error) {
+	row, err := s.queries.GetTask(ctx, sqlc.GetTaskParams{
+		ProjectID: projectID,
+		ID:        taskID,
+	})
 	if err != nil {
-		return nil, err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Task{}, s.taskNotFound(ctx, projectID)
+		}
+		return domain.Task{}, err
 	}
 
-	if len(rows) == 0 {
+	return s.toDomainTaskWithRelations(ctx, row)
+}
+
+func (s *PostgresStore) GetTaskByNumber(ctx context.Context, projectID uuid.UUID, number int64) (domain.Task, error) {
+	row, err := s.queries.GetTaskByNumber(ctx, sqlc.GetTaskByNumberParams{
+		ProjectID: projectID,
+		Number:    number,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Task{}, s.taskNotFound(ctx, projectID)
+		}
+		return domain.Task{}, err
+	}
+
+	return s.toDomainTaskWithRelations(ctx, row)
+}
+
+func (s *PostgresStore) ListTasks(ctx context.Context, projectID uuid.UUID, params ListTasksParams) ([]domain.Task, int, error) {
+	statuses := optStrings(params.Statuses)
+	titlePattern := optContains(params.Query)
+	labels := optStrings(params.Labels)
+
+	total, err := s.queries.CountTasks(ctx, sqlc.CountTasksParams{
+		ProjectID:    projectID,
+		Statuses:     statuses,
+		TitlePattern: titlePattern,
+		DueBefore:    params.DueBefore,
+		DueAfter:     params.DueAfter,
+		Labels:       labels,
+		ParentTaskID: params.ParentTaskID,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if total == 0 {
 		_, err := s.GetProject(ctx, projectID)
 		if err != nil {
 			if errors.Is(err, ErrNotFound) {
-				return nil, ErrProjectNotFound
+				return nil, 0, ErrProjectNotFound
 			}
-			return nil, err
+			return nil, 0, err
 		}
-		return []domain.Task{}, nil
+		return []domain.Task{}, 0, nil
 	}
 
-	tasks := make([]domain.Task, 0, len(rows))
-	for _, r := range rows {
-		tasks = append(tasks, domain.Task{
-			ID:          r.ID,
-			ProjectID:   r.ProjectID,
-			Title:       r.Title,
-			Description: r.Description,
-			Status:      r.Status,
-			CreatedAt:   r.CreatedAt,
+	var rows []sqlc.Task
+	if params.After != nil {
+		rows, err = s.queries.ListTasksAfter(ctx, sqlc.ListTasksAfterParams{
+			ProjectID:      projectID,
+			AfterCreatedAt: params.After.CreatedAt,
+			AfterID:        params.After.ID,
+			Statuses:       statuses,
+			TitlePattern:   titlePattern,
+			DueBefore:      params.DueBefore,
+			DueAfter:       params.DueAfter,
+			Labels:         labels,
+			ParentTaskID:   params.ParentTaskID,
+			Limit:          int32(params.Limit),
+		})
+	} else {
+		sortKey := params.Sort
+		if sortKey == "" {
+			sortKey = TaskSortNewest
+		}
+		rows, err = s.queries.ListTasks(ctx, sqlc.ListTasksParams{
+			ProjectID:    projectID,
+			Statuses:     statuses,
+			TitlePattern: titlePattern,
+			DueBefore:    params.DueBefore,
+			DueAfter:     params.DueAfter,
+			Labels:       labels,
+			ParentTaskID: params.ParentTaskID,
+			Sort:         string(sortKey),
+			Limit:        int32(params.Limit),
+			Offset:       offset32(params.Offset),
 		})
 	}
-	return tasks, nil
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tasks, err := s.toDomainTasks(ctx, rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tasks, int(total), nil
+}
+
+// optStrings maps an empty filter list to SQL NULL so the query skips the filter.
+func optStrings(v []string) []string {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// optContains builds an ILIKE pattern matching titles that contain q literally.
+func optContains(q string) pgtype.Text {
+	if q == "" {
+		return pgtype.Text{Valid: false}
+	}
+	return pgtype.Text{String: "%" + likeEscaper.Replace(q) + "%", Valid: true}
+}
+
+// optUUID maps uuid.Nil (and nil) to SQL NULL.
+func optUUID(id *uuid.UUID) *uuid.UUID {
+	if id == nil || *id == uuid.Nil {
+		return nil
+	}
+	return id
+}
+
+// optTime maps the zero time (and nil) to SQL NULL.
+func optTime(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return t
 }
 
 func optText(s *string) pgtype.Text {
@@ -176,35 +698,1953 @@ func optText(s *string) pgtype.Text {
 }
 
 func (s *PostgresStore) UpdateTask(ctx context.Context, projectID, taskID uuid.UUID, update TaskUpdate) (domain.Task, error) {
-	row, err := s.queries.UpdateTask(ctx, sqlc.UpdateTaskParams{
-		ProjectID:   projectID,
-		ID:          taskID,
-		Title:       optText(update.Title),
-		Description: optText(update.Description),
-		Status:      optText(update.Status),
+	params := sqlc.UpdateTaskParams{
+		ProjectID:    projectID,
+		ID:           taskID,
+		Title:        optText(update.Title),
+		Description:  optText(update.Description),
+		Status:       optText(update.Status),
+		SetAssignee:  update.AssigneeID != nil,
+		AssigneeID:   optUUID(update.AssigneeID),
+		SetDueDate:   update.DueDate != nil,
+		DueDate:      optTime(update.DueDate),
+		Priority:     optText(update.Priority),
+		SetParent:    update.ParentTaskID != nil,
+		ParentTaskID: optUUID(update.ParentTaskID),
+	}
+
+	var row sqlc.Task
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		if params.ParentTaskID != nil {
+			// Moving under a new parent: lock the project so two concurrent
+			// moves cannot each pass the cycle check and together form a loop.
+			if _, err := q.LockProject(ctx, projectID); err != nil {
+				return err
+			}
+			cycle, err := q.TaskHasAncestor(ctx, sqlc.TaskHasAncestorParams{
+				TaskID:     *params.ParentTaskID,
+				AncestorID: taskID,
+			})
+			if err != nil {
+				return err
+			}
+			if cycle {
+				return ErrTaskCycle
+			}
+		}
+		if update.Status != nil {
+			category, changed, err := checkStatusChange(ctx, q, projectID, taskID, *update.Status)
+			if err != nil {
+				return err
+			}
+			params.StatusCategory = pgtype.Text{String: category, Valid: true}
+			if changed {
+				// A task changing status goes to the bottom of its new column.
+				pos, err := q.NextTaskPosition(ctx, sqlc.NextTaskPositionParams{
+					ProjectID: projectID,
+					Status:    *update.Status,
+				})
+				if err != nil {
+					return err
+				}
+				params.Position = pgtype.Float8{Float64: pos, Valid: true}
+			}
+		}
+		// Project locks come first, as everywhere else
+		before, err := q.GetTaskForUpdate(ctx, sqlc.GetTaskForUpdateParams{ProjectID: projectID, ID: taskID})
+		if err != nil {
+			return err
+		}
+		if row, err = q.UpdateTask(ctx, params); err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditTask, taskID.String(), toDomainTask(before), toDomainTask(row))
 	})
 
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == tasksAssigneeFK {
+			return domain.Task{}, ErrUserNotFound
+		}
+		if pgErr != nil && pgErr.Code == "23503" && pgErr.ConstraintName == tasksParentFK {
+			return domain.Task{}, ErrParentTaskNotFound
+		}
+		if errors.Is(err, ErrTaskCycle) || errors.Is(err, ErrTaskBlocked) ||
+			errors.Is(err, ErrUnknownStatus) || errors.Is(err, ErrTransitionNotAllowed) {
+			return domain.Task{}, err
+		}
 		if errors.Is(err, pgx.ErrNoRows) {
-			// could be project not found or task not found
-			_, perr := s.GetProject(ctx, projectID)
-			if perr != nil {
-				if errors.Is(perr, ErrNotFound) {
-					return domain.Task{}, ErrProjectNotFound
-				}
-				return domain.Task{}, perr
-			}
-			return domain.Task{}, ErrTaskNotFound
+			return domain.Task{}, s.taskNotFound(ctx, projectID)
 		}
 		return domain.Task{}, err
 	}
 
-	return domain.Task{
-		ID:          row.ID,
-		ProjectID:   row.ProjectID,
-		Title:       row.Title,
-		Description: row.Description,
-		Status:      row.Status,
+	return s.toDomainTaskWithRelations(ctx, row)
+}
+
+// checkStatusChange validates moving a task to status against the project's
+// workflow and the task's blockers, and returns the status's category and
+// whether the status actually changes. It holds the project so the workflow
+// stays put until the transaction ends.
+func checkStatusChange(ctx context.Context, q *sqlc.Queries, projectID, taskID uuid.UUID, status string) (string, bool, error) {
+	if _, err := q.LockProjectShared(ctx, projectID); err != nil {
+		return "", false, err
+	}
+	current, err := q.GetTask(ctx, sqlc.GetTaskParams{ProjectID: projectID, ID: taskID})
+	if err != nil {
+		return "", false, err
+	}
+	if current.Status == status {
+		return current.StatusCategory, false, nil
+	}
+
+	workflow, err := loadWorkflow(ctx, q, projectID)
+	if err != nil {
+		return "", false, err
+	}
+	st, ok := workflow.Status(status)
+	if !ok {
+		return "", false, ErrUnknownStatus
+	}
+	if !workflow.Allows(current.Status, st.Name) {
+		return "", false, ErrTransitionNotAllowed
+	}
+	if st.Category != domain.StatusCategoryTodo {
+		blocked, err := q.HasOpenBlockers(ctx, sqlc.HasOpenBlockersParams{
+			TaskID: taskID,
+			Status: status,
+		})
+		if err != nil {
+			return "", false, err
+		}
+		if blocked {
+			return "", false, ErrTaskBlocked
+		}
+	}
+	return st.Category, true, nil
+}
+
+func (s *PostgresStore) MoveTask(ctx context.Context, projectID, taskID uuid.UUID, move TaskMove) (domain.Task, error) {
+	var row sqlc.Task
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		// Concurrent drags into the same column read and renumber the same
+		// neighbours, so they take turns on the project row.
+		if _, err := q.LockProject(ctx, projectID); err != nil {
+			return err
+		}
+		current, err := q.GetTaskForUpdate(ctx, sqlc.GetTaskForUpdateParams{ProjectID: projectID, ID: taskID})
+		if err != nil {
+			return err
+		}
+
+		params := sqlc.UpdateTaskParams{ProjectID: projectID, ID: taskID}
+		status := current.Status
+		if move.Status != nil {
+			category, _, err := checkStatusChange(ctx, q, projectID, taskID, *move.Status)
+			if err != nil {
+				return err
+			}
+			status = *move.Status
+			params.Status = pgtype.Text{String: status, Valid: true}
+			params.StatusCategory = pgtype.Text{String: category, Valid: true}
+		}
+
+		rows, err := q.ListColumnPositions(ctx, sqlc.ListColumnPositionsParams{
+			ProjectID: projectID,
+			Status:    status,
+			ExcludeID: taskID,
+		})
+		if err != nil {
+			return err
+		}
+		column := make([]columnEntry, len(rows))
+		for i, r := range rows {
+			column[i] = columnEntry{ID: r.ID, Position: r.Position}
+		}
+
+		pos, ok, err := slotPosition(column, move.AfterID, move.BeforeID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			renumberColumn(column)
+			for _, e := range column {
+				if err := q.SetTaskPosition(ctx, sqlc.SetTaskPositionParams{
+					ProjectID: projectID,
+					ID:        e.ID,
+					Position:  e.Position,
+				}); err != nil {
+					return err
+				}
+			}
+			if pos, _, err = slotPosition(column, move.AfterID, move.BeforeID); err != nil {
+				return err
+			}
+		}
+
+		params.Position = pgtype.Float8{Float64: pos, Valid: true}
+		if row, err = q.UpdateTask(ctx, params); err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditTask, taskID.String(), toDomainTask(current), toDomainTask(row))
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Task{}, s.taskNotFound(ctx, projectID)
+		}
+		return domain.Task{}, err
+	}
+
+	return s.toDomainTaskWithRelations(ctx, row)
+}
+
+func (s *PostgresStore) TransferTask(ctx context.Context, projectID, taskID, targetProjectID uuid.UUID) (domain.Task, TransferReport, error) {
+	var root sqlc.Task
+	var report TransferReport
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		if err := lockProjects(ctx, q, projectID, targetProjectID); err != nil {
+			return err
+		}
+		if targetProjectID == projectID {
+			if _, err := q.GetTask(ctx, sqlc.GetTaskParams{ProjectID: projectID, ID: taskID}); err != nil {
+				return err
+			}
+			return ErrTransferSameProject
+		}
+		before, err := q.GetTaskForUpdate(ctx, sqlc.GetTaskForUpdateParams{ProjectID: projectID, ID: taskID})
+		if err != nil {
+			return err
+		}
+
+		tree, err := q.MoveTaskTree(ctx, sqlc.MoveTaskTreeParams{
+			ProjectID:       projectID,
+			ID:              taskID,
+			TargetProjectID: targetProjectID,
+		})
+		if err != nil {
+			return err
+		}
+		if len(tree) == 0 {
+			return pgx.ErrNoRows
+		}
+		ids := make([]uuid.UUID, len(tree))
+		for i, t := range tree {
+			ids[i] = t.ID
+		}
+		report.Subtasks = len(tree) - 1
+
+		dropped, err := q.DeleteCrossProjectDependencies(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, d := range dropped {
+			if err := audit(ctx, q, domain.AuditTaskDependency, d.TaskID.String(), dependencySnapshot(d.BlockerID), nil); err != nil {
+				return err
+			}
+		}
+		report.DroppedDependencies = len(dropped)
+
+		relabel, err := carryLabels(ctx, q, ids, targetProjectID, &report)
+		if err != nil {
+			return err
+		}
+		for oldID, newID := range relabel {
+			relabeled, err := q.RelabelTasks(ctx, sqlc.RelabelTasksParams{
+				TaskIds:    ids,
+				OldLabelID: oldID,
+				NewLabelID: newID,
+			})
+			if err != nil {
+				return err
+			}
+			for _, id := range relabeled {
+				if err := audit(ctx, q, domain.AuditTaskLabel, id.String(), labelSnapshot(oldID), labelSnapshot(newID)); err != nil {
+					return err
+				}
+			}
+		}
+
+		comments, err := q.CountCommentsOnTasks(ctx, ids)
+		if err != nil {
+			return err
+		}
+		report.Comments = int(comments)
+
+		// Fit the tree into the target workflow, keeping the moved tasks'
+		// relative order at the bottom of their new columns.
+		workflow, err := loadWorkflow(ctx, q, targetProjectID)
+		if err != nil {
+			return err
+		}
+		sort.Slice(tree, func(i, j int) bool {
+			return boardBefore(toDomainTask(tree[i]), toDomainTask(tree[j]))
+		})
+		for _, t := range tree {
+			// tasks_project_number_key is deferred, so the old number may
+			// clash in the target until the new one is assigned.
+			if err := q.AssignTaskNumber(ctx, sqlc.AssignTaskNumberParams{
+				ProjectID: targetProjectID,
+				ID:        t.ID,
+			}); err != nil {
+				return err
+			}
+			status := transferStatus(workflow, t.Status)
+			pos, err := q.NextTaskPosition(ctx, sqlc.NextTaskPositionParams{
+				ProjectID: targetProjectID,
+				Status:    status.Name,
+			})
+			if err != nil {
+				return err
+			}
+			row, err := q.UpdateTask(ctx, sqlc.UpdateTaskParams{
+				ProjectID:      targetProjectID,
+				ID:             t.ID,
+				Status:         pgtype.Text{String: status.Name, Valid: true},
+				StatusCategory: pgtype.Text{String: status.Category, Valid: true},
+				Position:       pgtype.Float8{Float64: pos, Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+			// MoveTaskTree only changed the project of the subtasks
+			old := t
+			old.ProjectID = projectID
+			if row.ID == taskID {
+				old, root = before, row
+			}
+			if err := audit(ctx, q, domain.AuditTask, row.ID.String(), toDomainTask(old), toDomainTask(row)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Task{}, TransferReport{}, s.taskNotFound(ctx, projectID)
+		}
+		return domain.Task{}, TransferReport{}, err
+	}
+
+	task, err := s.toDomainTaskWithRelations(ctx, root)
+	return task, report, err
+}
+
+func (s *PostgresStore) CopyTask(ctx context.Context, projectID, taskID, targetProjectID uuid.UUID) (domain.Task, TransferReport, error) {
+	var row sqlc.Task
+	var report TransferReport
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		if err := lockProjects(ctx, q, projectID, targetProjectID); err != nil {
+			return err
+		}
+		src, err := q.GetTask(ctx, sqlc.GetTaskParams{ProjectID: projectID, ID: taskID})
+		if err != nil {
+			return err
+		}
+
+		workflow, err := loadWorkflow(ctx, q, targetProjectID)
+		if err != nil {
+			return err
+		}
+		status := transferStatus(workflow, src.Status)
+		row, err = q.InsertTask(ctx, sqlc.InsertTaskParams{
+			ID:          uuid.New(),
+			ProjectID:   targetProjectID,
+			Title:       src.Title,
+			Description: src.Description,
+			Status:      status.Name,
+			CreatedAt:   time.Now().UTC(),
+			AssigneeID:  src.AssigneeID,
+			DueDate:     src.DueDate,
+			Priority:    src.Priority,
+
+			StatusCategory: status.Category,
+		})
+		if err != nil {
+			return err
+		}
+		if err := audit(ctx, q, domain.AuditTask, row.ID.String(), nil, toDomainTask(row)); err != nil {
+			return err
+		}
+
+		srcIDs := []uuid.UUID{src.ID}
+		relabel, err := carryLabels(ctx, q, srcIDs, targetProjectID, &report)
+		if err != nil {
+			return err
+		}
+		for _, labelID := range relabel {
+			if _, err := q.AttachLabel(ctx, sqlc.AttachLabelParams{TaskID: row.ID, LabelID: labelID}); err != nil {
+				return err
+			}
+			if err := audit(ctx, q, domain.AuditTaskLabel, row.ID.String(), nil, labelSnapshot(labelID)); err != nil {
+				return err
+			}
+		}
+
+		comments, err := q.ListAllComments(ctx, src.ID)
+		if err != nil {
+			return err
+		}
+		if err := copyComments(ctx, q, comments, row.ID); err != nil {
+			return err
+		}
+		report.Comments = len(comments)
+
+		blockers, err := q.ListTaskBlockerIDs(ctx, srcIDs)
+		if err != nil {
+			return err
+		}
+		report.DroppedDependencies = len(blockers)
+		rollups, err := q.ListSubtaskRollups(ctx, srcIDs)
+		if err != nil {
+			return err
+		}
+		for _, r := range rollups {
+			report.DroppedSubtasks = int(r.Total)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Task{}, TransferReport{}, s.taskNotFound(ctx, projectID)
+		}
+		return domain.Task{}, TransferReport{}, err
+	}
+
+	task, err := s.toDomainTaskWithRelations(ctx, row)
+	return task, report, err
+}
+
+// lockProjects takes LockProject on a task's project and a transfer target,
+// in a fixed order so two transfers in opposite directions cannot deadlock.
+// Tasks never leave their workspace, so a target in another one is treated
+// as missing.
+func lockProjects(ctx context.Context, q *sqlc.Queries, projectID, targetProjectID uuid.UUID) error {
+	ids := []uuid.UUID{projectID, targetProjectID}
+	if targetProjectID == projectID {
+		ids = ids[:1]
+	} else if targetProjectID.String() < projectID.String() {
+		ids[0], ids[1] = ids[1], ids[0]
+	}
+	workspaces := make(map[uuid.UUID]uuid.UUID, len(ids))
+	for _, id := range ids {
+		row, err := q.LockProject(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) && id == targetProjectID && id != projectID {
+				return ErrTargetProjectNotFound
+			}
+			return err
+		}
+		workspaces[id] = row.WorkspaceID
+	}
+	if workspaces[targetProjectID] != workspaces[projectID] {
+		return ErrTargetProjectNotFound
+	}
+	return nil
+}
+
+// carryLabels maps the labels on the given tasks to labels of the same name
+// in the target project, creating any that are missing, and records them in
+// report.
+func carryLabels(ctx context.Context, q *sqlc.Queries, taskIDs []uuid.UUID, targetProjectID uuid.UUID, report *TransferReport) (map[uuid.UUID]uuid.UUID, error) {
+	rows, err := q.ListTaskLabels(ctx, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	var labels []domain.Label
+	seen := make(map[uuid.UUID]bool)
+	for _, r := range rows {
+		if !seen[r.ID] {
+			seen[r.ID] = true
+			labels = append(labels, domain.Label{ID: r.ID, Name: r.Name, Color: r.Color})
+		}
+	}
+	sortLabels(labels)
+
+	relabel := make(map[uuid.UUID]uuid.UUID, len(labels))
+	for _, l := range labels {
+		report.Labels = append(report.Labels, l.Name)
+		created, err := q.InsertLabelIfMissing(ctx, sqlc.InsertLabelIfMissingParams{
+			ID:        uuid.New(),
+			ProjectID: targetProjectID,
+			Name:      l.Name,
+			Color:     l.Color,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err == nil {
+			if err := audit(ctx, q, domain.AuditLabel, created.ID.String(), nil, domain.Label(created)); err != nil {
+				return nil, err
+			}
+			report.CreatedLabels = append(report.CreatedLabels, l.Name)
+			relabel[l.ID] = created.ID
+			continue
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		existing, err := q.GetLabelByName(ctx, sqlc.GetLabelByNameParams{ProjectID: targetProjectID, Name: l.Name})
+		if err != nil {
+			return nil, err
+		}
+		relabel[l.ID] = existing.ID
+	}
+	return relabel, nil
+}
+
+// copyComments copies a task's comments (oldest first) onto taskID, keeping
+// their threads. A reply waits until its parent has been copied.
+func copyComments(ctx context.Context, q *sqlc.Queries, comments []sqlc.Comment, taskID uuid.UUID) error {
+	copies := make(map[uuid.UUID]uuid.UUID, len(comments))
+	for _, c := range comments {
+		copies[c.ID] = uuid.New()
+	}
+	copied := make(map[uuid.UUID]bool, len(comments))
+	for pending := comments; len(pending) > 0; {
+		var next []sqlc.Comment
+		for _, c := range pending {
+			var parentID *uuid.UUID
+			if c.ParentID != nil {
+				if !copied[*c.ParentID] {
+					next = append(next, c)
+					continue
+				}
+				id := copies[*c.ParentID]
+				parentID = &id
+			}
+			row, err := q.CopyComment(ctx, sqlc.CopyCommentParams{
+				ID:        copies[c.ID],
+				TaskID:    taskID,
+				ParentID:  parentID,
+				AuthorID:  c.AuthorID,
+				Body:      c.Body,
+				CreatedAt: c.CreatedAt,
+				UpdatedAt: c.UpdatedAt,
+			})
+			if err != nil {
+				return err
+			}
+			if err := audit(ctx, q, domain.AuditComment, row.ID.String(), nil, domain.Comment(row)); err != nil {
+				return err
+			}
+			copied[c.ID] = true
+		}
+		if len(next) == len(pending) {
+			return errors.New("comment replies to a comment on another task")
+		}
+		pending = next
+	}
+	return nil
+}
+
+func (s *PostgresStore) ListBoardTasks(ctx context.Context, projectID uuid.UUID) ([]domain.Task, error) {
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := s.queries.ListBoardTasks(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toDomainTasks(ctx, rows)
+}
+
+func (s *PostgresStore) DeleteTask(ctx context.Context, projectID, taskID uuid.UUID, subtasks SubtaskPolicy) error {
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		before, err := q.GetTaskForUpdate(ctx, sqlc.GetTaskForUpdateParams{ProjectID: projectID, ID: taskID})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrTaskNotFound
+			}
+			return err
+		}
+		// Cascading is left to tasks_parent_fkey
+		if subtasks != SubtasksCascade {
+			if err := q.ReparentSubtasks(ctx, taskID); err != nil {
+				return err
+			}
+		}
+		n, err := q.DeleteTask(ctx, sqlc.DeleteTaskParams{
+			ProjectID: projectID,
+			ID:        taskID,
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrTaskNotFound
+		}
+		return audit(ctx, q, domain.AuditTask, taskID.String(), toDomainTask(before), nil)
+	})
+	if errors.Is(err, ErrTaskNotFound) {
+		return s.taskNotFound(ctx, projectID)
+	}
+	return err
+}
+
+// taskNotFound resolves a task lookup that matched no rows: either the
+// project is missing or the task is.
+func (s *PostgresStore) taskNotFound(ctx context.Context, projectID uuid.UUID) error {
+	_, err := s.GetProject(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+	return ErrTaskNotFound
+}
+
+func (s *PostgresStore) ListOverdueTasks(ctx context.Context, params ListOverdueTasksParams) ([]domain.Task, int, error) {
+	total, err := s.queries.CountOverdueTasks(ctx, sqlc.CountOverdueTasksParams{
+		AsOf:   params.AsOf,
+		Member: optMember(params.Member),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.queries.ListOverdueTasks(ctx, sqlc.ListOverdueTasksParams{
+		AsOf:   params.AsOf,
+		Member: optMember(params.Member),
+		Limit:  int32(params.Limit),
+		Offset: offset32(params.Offset),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tasks, err := s.toDomainTasks(ctx, rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tasks, int(total), nil
+}
+
+func (s *PostgresStore) AddTaskBlocker(ctx context.Context, projectID, taskID, blockerID uuid.UUID) (domain.Task, error) {
+	if err := s.checkDependencyTasks(ctx, projectID, taskID, blockerID); err != nil {
+		return domain.Task{}, err
+	}
+
+	// Lock the project so two concurrent additions cannot each pass the
+	// cycle check and together close a loop.
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		if _, err := q.LockProject(ctx, projectID); err != nil {
+			return err
+		}
+		if blockerID == taskID {
+			return ErrDependencyCycle
+		}
+		cycle, err := q.TaskDependsOn(ctx, sqlc.TaskDependsOnParams{
+			TaskID:    blockerID,
+			BlockerID: taskID,
+		})
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return ErrDependencyCycle
+		}
+		n, err := q.AddTaskBlocker(ctx, sqlc.AddTaskBlockerParams{
+			TaskID:    taskID,
+			BlockerID: blockerID,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil || n == 0 {
+			return err
+		}
+		return audit(ctx, q, domain.AuditTaskDependency, taskID.String(), nil, dependencySnapshot(blockerID))
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Task{}, s.taskNotFound(ctx, projectID)
+		}
+		return domain.Task{}, err
+	}
+	return s.GetTask(ctx, projectID, taskID)
+}
+
+func (s *PostgresStore) RemoveTaskBlocker(ctx context.Context, projectID, taskID, blockerID uuid.UUID) (domain.Task, error) {
+	if err := s.checkDependencyTasks(ctx, projectID, taskID, blockerID); err != nil {
+		return domain.Task{}, err
+	}
+
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		n, err := q.RemoveTaskBlocker(ctx, sqlc.RemoveTaskBlockerParams{
+			TaskID:    taskID,
+			BlockerID: blockerID,
+		})
+		if err != nil || n == 0 {
+			return err
+		}
+		return audit(ctx, q, domain.AuditTaskDependency, taskID.String(), dependencySnapshot(blockerID), nil)
+	})
+	if err != nil {
+		return domain.Task{}, err
+	}
+	return s.GetTask(ctx, projectID, taskID)
+}
+
+// checkDependencyTasks reports which of the project, task or blocker of an
+// add or remove request is missing, in that order.
+func (s *PostgresStore) checkDependencyTasks(ctx context.Context, projectID, taskID, blockerID uuid.UUID) error {
+	if _, err := s.GetTask(ctx, projectID, taskID); err != nil {
+		return err
+	}
+	_, err := s.GetTask(ctx, projectID, blockerID)
+	return err
+}
+
+func (s *PostgresStore) ListTaskBlockers(ctx context.Context, projectID, taskID uuid.UUID) ([]domain.Task, error) {
+	if _, err := s.GetTask(ctx, projectID, taskID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.queries.ListTaskBlockers(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toDomainTasks(ctx, rows)
+}
+
+func (s *PostgresStore) ListBlockedTasks(ctx context.Context, projectID uuid.UUID, params ListBlockedTasksParams) ([]domain.Task, int, error) {
+	total, err := s.queries.CountBlockedTasks(ctx, projectID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		if _, err := s.GetProject(ctx, projectID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil, 0, ErrProjectNotFound
+			}
+			return nil, 0, err
+		}
+		return []domain.Task{}, 0, nil
+	}
+
+	rows, err := s.queries.ListBlockedTasks(ctx, sqlc.ListBlockedTasksParams{
+		ProjectID: projectID,
+		Limit:     int32(params.Limit),
+		Offset:    offset32(params.Offset),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tasks, err := s.toDomainTasks(ctx, rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tasks, int(total), nil
+}
+
+func (s *PostgresStore) GetWorkflow(ctx context.Context, projectID uuid.UUID) (domain.Workflow, error) {
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return domain.Workflow{}, ErrProjectNotFound
+		}
+		return domain.Workflow{}, err
+	}
+	return loadWorkflow(ctx, s.queries, projectID)
+}
+
+func (s *PostgresStore) UpdateWorkflow(ctx context.Context, projectID uuid.UUID, workflow domain.Workflow) (domain.Workflow, error) {
+	definition, err := json.Marshal(workflow)
+	if err != nil {
+		return domain.Workflow{}, err
+	}
+
+	names := make([]string, 0, len(workflow.Statuses))
+	for _, st := range workflow.Statuses {
+		names = append(names, st.Name)
+	}
+
+	err = s.inTx(ctx, func(q *sqlc.Queries) error {
+		if _, err := q.LockProject(ctx, projectID); err != nil {
+			return err
+		}
+		before, err := loadWorkflow(ctx, q, projectID)
+		if err != nil {
+			return err
+		}
+		stranded, err := q.CountTasksOutsideStatuses(ctx, sqlc.CountTasksOutsideStatusesParams{
+			ProjectID: projectID,
+			Statuses:  names,
+		})
+		if err != nil {
+			return err
+		}
+		if stranded > 0 {
+			return ErrStatusInUse
+		}
+		for _, st := range workflow.Statuses {
+			err := q.SetTaskStatusCategory(ctx, sqlc.SetTaskStatusCategoryParams{
+				ProjectID: projectID,
+				Status:    st.Name,
+				Category:  st.Category,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		err = q.UpsertWorkflow(ctx, sqlc.UpsertWorkflowParams{
+			ProjectID:  projectID,
+			Definition: definition,
+			UpdatedAt:  time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditWorkflow, projectID.String(), before, workflow)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Workflow{}, ErrProjectNotFound
+		}
+		return domain.Workflow{}, err
+	}
+	return workflow, nil
+}
+
+// auditClonedWorkflow records the workflow a clone was given as a change
+// from the default one every new project starts with.
+func auditClonedWorkflow(ctx context.Context, q *sqlc.Queries, projectID uuid.UUID) error {
+	workflow, err := loadWorkflow(ctx, q, projectID)
+	if err != nil {
+		return err
+	}
+	return audit(ctx, q, domain.AuditWorkflow, projectID.String(), domain.DefaultWorkflow(), workflow)
+}
+
+func (s *PostgresStore) CloneProject(ctx context.Context, projectID uuid.UUID, clone ProjectClone) (domain.Project, error) {
+	now := time.Now().UTC()
+	var project sqlc.Project
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		// Keep the source's tasks, hierarchy and workflow still while copying
+		if _, err := q.LockProject(ctx, projectID); err != nil {
+			return err
+		}
+		src, err := q.GetProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		// Everything but the key, which must stay unique
+		project, err = q.InsertProject(ctx, sqlc.InsertProjectParams{
+			ID:          uuid.New(),
+			Name:        clone.Name,
+			CreatedAt:   now,
+			Description: src.Description,
+			OwnerID:     src.OwnerID,
+			Color:       src.Color,
+			Icon:        src.Icon,
+			WorkspaceID: src.WorkspaceID,
+		})
+		if err != nil {
+			return err
+		}
+		if err := audit(ctx, q, domain.AuditProject, project.ID.String(), nil, toDomainProject(project)); err != nil {
+			return err
+		}
+		if err := addOwner(ctx, q, project, clone.CreatedBy); err != nil {
+			return err
+		}
+
+		definition, err := q.GetWorkflow(ctx, projectID)
+		if err == nil {
+			err = q.UpsertWorkflow(ctx, sqlc.UpsertWorkflowParams{
+				ProjectID:  project.ID,
+				Definition: definition,
+				UpdatedAt:  now,
+			})
+			if err == nil {
+				err = auditClonedWorkflow(ctx, q, project.ID)
+			}
+		}
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		labelRows, err := q.ListLabels(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		labels := make(map[uuid.UUID]uuid.UUID, len(labelRows))
+		for _, l := range labelRows {
+			copied, err := q.InsertLabel(ctx, sqlc.InsertLabelParams{
+				ID:        uuid.New(),
+				ProjectID: project.ID,
+				Name:      l.Name,
+				Color:     l.Color,
+				CreatedAt: now,
+			})
+			if err != nil {
+				return err
+			}
+			if err := audit(ctx, q, domain.AuditLabel, copied.ID.String(), nil, domain.Label(copied)); err != nil {
+				return err
+			}
+			labels[l.ID] = copied.ID
+		}
+		if !clone.IncludeTasks {
+			return nil
+		}
+
+		workflow, err := loadWorkflow(ctx, q, projectID)
+		if err != nil {
+			return err
+		}
+		rows, err := q.ListBoardTasks(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		tasks := make([]domain.Task, len(rows))
+		oldIDs := make([]uuid.UUID, len(rows))
+		copies := make(map[uuid.UUID]uuid.UUID, len(rows))
+		for i, r := range rows {
+			tasks[i] = toDomainTask(r)
+			oldIDs[i] = r.ID
+			copies[r.ID] = uuid.New()
+		}
+		sortBoard(workflow, tasks)
+
+		// Insert in board order so each column keeps its order, then link
+		// subtasks once all their parents exist.
+		inserted := make([]sqlc.Task, len(tasks))
+		for i, t := range tasks {
+			status, category := t.Status, t.StatusCategory
+			if clone.ResetStatuses {
+				status, category = workflow.Statuses[0].Name, workflow.Statuses[0].Category
+			}
+			inserted[i], err = q.InsertTask(ctx, sqlc.InsertTaskParams{
+				ID:          copies[t.ID],
+				ProjectID:   project.ID,
+				Title:       t.Title,
+				Description: t.Description,
+				Status:      status,
+				CreatedAt:   now,
+				AssigneeID:  t.AssigneeID,
+				DueDate:     t.DueDate,
+				Priority:    t.Priority,
+
+				StatusCategory: category,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		for i, t := range tasks {
+			if t.ParentTaskID == nil {
+				continue
+			}
+			parentID := copies[*t.ParentTaskID]
+			inserted[i], err = q.UpdateTask(ctx, sqlc.UpdateTaskParams{
+				ProjectID:    project.ID,
+				ID:           copies[t.ID],
+				SetParent:    true,
+				ParentTaskID: &parentID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		for _, row := range inserted {
+			if err := audit(ctx, q, domain.AuditTask, row.ID.String(), nil, toDomainTask(row)); err != nil {
+				return err
+			}
+		}
+
+		taskLabels, err := q.ListTaskLabels(ctx, oldIDs)
+		if err != nil {
+			return err
+		}
+		for _, tl := range taskLabels {
+			taskID, labelID := copies[tl.TaskID], labels[tl.ID]
+			if _, err := q.AttachLabel(ctx, sqlc.AttachLabelParams{TaskID: taskID, LabelID: labelID}); err != nil {
+				return err
+			}
+			if err := audit(ctx, q, domain.AuditTaskLabel, taskID.String(), nil, labelSnapshot(labelID)); err != nil {
+				return err
+			}
+		}
+		blockers, err := q.ListTaskBlockerIDs(ctx, oldIDs)
+		if err != nil {
+			return err
+		}
+		for _, b := range blockers {
+			taskID, blockerID := copies[b.TaskID], copies[b.BlockerID]
+			if _, err := q.AddTaskBlocker(ctx, sqlc.AddTaskBlockerParams{
+				TaskID:    taskID,
+				BlockerID: blockerID,
+				CreatedAt: now,
+			}); err != nil {
+				return err
+			}
+			if err := audit(ctx, q, domain.AuditTaskDependency, taskID.String(), nil, dependencySnapshot(blockerID)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Project{}, ErrProjectNotFound
+		}
+		return domain.Project{}, err
+	}
+	return toDomainProject(project), nil
+}
+
+func toDomainTemplate(row sqlc.ProjectTemplate) (domain.Template, error) {
+	t := domain.Template{
+		ID:          row.ID,
+		WorkspaceID: row.WorkspaceID,
+		Name:        row.Name,
+		CreatedAt:   row.CreatedAt,
+	}
+	if err := json.Unmarshal(row.Tasks, &t.Tasks); err != nil {
+		return domain.Template{}, err
+	}
+	if t.Tasks == nil {
+		t.Tasks = []domain.TaskBlueprint{}
+	}
+	return t, nil
+}
+
+func (s *PostgresStore) InsertTemplate(ctx context.Context, name string, tasks []domain.TaskBlueprint) (domain.Template, error) {
+	if tasks == nil {
+		tasks = []domain.TaskBlueprint{}
+	}
+	blueprints, err := json.Marshal(tasks)
+	if err != nil {
+		return domain.Template{}, err
+	}
+
+	var t domain.Template
+	err = s.inTx(ctx, func(q *sqlc.Queries) error {
+		row, err := q.InsertTemplate(ctx, sqlc.InsertTemplateParams{
+			ID:          uuid.New(),
+			Name:        name,
+			Tasks:       blueprints,
+			CreatedAt:   time.Now().UTC(),
+			WorkspaceID: Workspace(ctx),
+		})
+		if err != nil {
+			return err
+		}
+		if t, err = toDomainTemplate(row); err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditTemplate, t.ID.String(), nil, t)
+	})
+	if err != nil {
+		return domain.Template{}, err
+	}
+	return t, nil
+}
+
+func (s *PostgresStore) GetTemplate(ctx context.Context, id uuid.UUID) (domain.Template, error) {
+	row, err := s.queries.GetTemplate(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Template{}, ErrTemplateNotFound
+		}
+		return domain.Template{}, err
+	}
+	return toDomainTemplate(row)
+}
+
+func (s *PostgresStore) ListTemplates(ctx context.Context) ([]domain.Template, error) {
+	rows, err := s.queries.ListTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	templates := make([]domain.Template, 0, len(rows))
+	for _, row := range rows {
+		t, err := toDomainTemplate(row)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+func (s *PostgresStore) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
+	return s.inTx(ctx, func(q *sqlc.Queries) error {
+		row, err := q.GetTemplate(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrTemplateNotFound
+			}
+			return err
+		}
+		before, err := toDomainTemplate(row)
+		if err != nil {
+			return err
+		}
+		n, err := q.DeleteTemplate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrTemplateNotFound
+		}
+		return audit(ctx, q, domain.AuditTemplate, id.String(), before, nil)
+	})
+}
+
+func (s *PostgresStore) InstantiateTemplate(ctx context.Context, templateID uuid.UUID, name, createdBy string) (domain.Project, error) {
+	tpl, err := s.GetTemplate(ctx, templateID)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	if name == "" {
+		name = tpl.Name
+	}
+
+	now := time.Now().UTC()
+	var project sqlc.Project
+	err = s.inTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		project, err = q.InsertProject(ctx, sqlc.InsertProjectParams{
+			ID:          uuid.New(),
+			Name:        name,
+			CreatedAt:   now,
+			WorkspaceID: tpl.WorkspaceID,
+		})
+		if err != nil {
+			return err
+		}
+		if err := audit(ctx, q, domain.AuditProject, project.ID.String(), nil, toDomainProject(project)); err != nil {
+			return err
+		}
+		if err := addOwner(ctx, q, project, createdBy); err != nil {
+			return err
+		}
+
+		// A new project has the default workflow
+		initial := domain.DefaultWorkflow().Statuses[0]
+		for _, b := range tpl.Tasks {
+			priority := b.Priority
+			if priority == "" {
+				priority = DefaultTaskPriority
+			}
+			row, err := q.InsertTask(ctx, sqlc.InsertTaskParams{
+				ID:          uuid.New(),
+				ProjectID:   project.ID,
+				Title:       b.Title,
+				Description: b.Description,
+				Status:      initial.Name,
+				CreatedAt:   now,
+				Priority:    priority,
+
+				StatusCategory: initial.Category,
+			})
+			if err != nil {
+				return err
+			}
+			if err := audit(ctx, q, domain.AuditTask, row.ID.String(), nil, toDomainTask(row)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Project{}, err
+	}
+	return toDomainProject(project), nil
+}
+
+// loadWorkflow reads a project's workflow, falling back to the default for
+// projects without one.
+func loadWorkflow(ctx context.Context, q *sqlc.Queries, projectID uuid.UUID) (domain.Workflow, error) {
+	definition, err := q.GetWorkflow(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.DefaultWorkflow(), nil
+		}
+		return domain.Workflow{}, err
+	}
+
+	var workflow domain.Workflow
+	if err := json.Unmarshal(definition, &workflow); err != nil {
+		return domain.Workflow{}, err
+	}
+	return workflow, nil
+}
+
+func toDomainUser(row sqlc.User) domain.User {
+	return domain.User{
+		ID:          row.ID,
+		WorkspaceID: row.WorkspaceID,
+		Name:        row.Name,
+		Email:       row.Email,
+		CreatedAt:   row.CreatedAt,
+	}
+}
+
+func (s *PostgresStore) InsertUser(ctx context.Context, name, email string) (domain.User, error) {
+	var row sqlc.User
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		row, err = q.InsertUser(ctx, sqlc.InsertUserParams{
+			ID:          uuid.New(),
+			Name:        name,
+			Email:       email,
+			CreatedAt:   time.Now().UTC(),
+			WorkspaceID: Workspace(ctx),
+		})
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditUser, row.ID.String(), nil, toDomainUser(row))
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.User{}, ErrEmailTaken
+		}
+		return domain.User{}, err
+	}
+	return toDomainUser(row), nil
+}
+
+func (s *PostgresStore) GetUser(ctx context.Context, id uuid.UUID) (domain.User, error) {
+	row, err := s.queries.GetUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.User{}, ErrUserNotFound
+		}
+		return domain.User{}, err
+	}
+	return toDomainUser(row), nil
+}
+
+func (s *PostgresStore) ListUserTasks(ctx context.Context, userID uuid.UUID, params ListUserTasksParams) ([]domain.Task, int, error) {
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return nil, 0, err
+	}
+
+	statuses := optStrings(params.Statuses)
+	total, err := s.queries.CountUserTasks(ctx, sqlc.CountUserTasksParams{
+		AssigneeID: userID,
+		Statuses:   statuses,
+		Member:     optMember(params.Member),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.queries.ListUserTasks(ctx, sqlc.ListUserTasksParams{
+		AssigneeID: userID,
+		Statuses:   statuses,
+		Member:     optMember(params.Member),
+		Limit:      int32(params.Limit),
+		Offset:     offset32(params.Offset),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tasks, err := s.toDomainTasks(ctx, rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tasks, int(total), nil
+}
+
+func (s *PostgresStore) InsertLabel(ctx context.Context, projectID uuid.UUID, name, color string) (domain.Label, error) {
+	var row sqlc.Label
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		row, err = q.InsertLabel(ctx, sqlc.InsertLabelParams{
+			ID:        uuid.New(),
+			ProjectID: projectID,
+			Name:      name,
+			Color:     color,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditLabel, row.ID.String(), nil, domain.Label(row))
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.Label{}, ErrLabelExists
+		}
+		// As with tasks: no rows or an FK violation means the project is gone.
+		if errors.Is(err, pgx.ErrNoRows) || (pgErr != nil && pgErr.Code == "23503") {
+			return domain.Label{}, ErrProjectNotFound
+		}
+		return domain.Label{}, err
+	}
+	return domain.Label(row), nil
+}
+
+func (s *PostgresStore) GetLabel(ctx context.Context, projectID, labelID uuid.UUID) (domain.Label, error) {
+	row, err := s.queries.GetLabel(ctx, sqlc.GetLabelParams{
+		ProjectID: projectID,
+		ID:        labelID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Label{}, s.labelNotFound(ctx, projectID)
+		}
+		return domain.Label{}, err
+	}
+	return domain.Label(row), nil
+}
+
+func (s *PostgresStore) ListLabels(ctx context.Context, projectID uuid.UUID) ([]domain.Label, error) {
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := s.queries.ListLabels(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make([]domain.Label, 0, len(rows))
+	for _, row := range rows {
+		labels = append(labels, domain.Label(row))
+	}
+	return labels, nil
+}
+
+func (s *PostgresStore) UpdateLabel(ctx context.Context, projectID, labelID uuid.UUID, update LabelUpdate) (domain.Label, error) {
+	var row sqlc.Label
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		before, err := q.GetLabelForUpdate(ctx, sqlc.GetLabelForUpdateParams{ProjectID: projectID, ID: labelID})
+		if err != nil {
+			return err
+		}
+		row, err = q.UpdateLabel(ctx, sqlc.UpdateLabelParams{
+			ProjectID: projectID,
+			ID:        labelID,
+			Name:      optText(update.Name),
+			Color:     optText(update.Color),
+		})
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditLabel, labelID.String(), domain.Label(before), domain.Label(row))
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.Label{}, ErrLabelExists
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Label{}, s.labelNotFound(ctx, projectID)
+		}
+		return domain.Label{}, err
+	}
+	return domain.Label(row), nil
+}
+
+func (s *PostgresStore) DeleteLabel(ctx context.Context, projectID, labelID uuid.UUID) error {
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		before, err := q.GetLabelForUpdate(ctx, sqlc.GetLabelForUpdateParams{ProjectID: projectID, ID: labelID})
+		if err != nil {
+			return err
+		}
+		if _, err := q.DeleteLabel(ctx, sqlc.DeleteLabelParams{
+			ProjectID: projectID,
+			ID:        labelID,
+		}); err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditLabel, labelID.String(), domain.Label(before), nil)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s.labelNotFound(ctx, projectID)
+	}
+	return err
+}
+
+func (s *PostgresStore) AttachLabel(ctx context.Context, projectID, taskID, labelID uuid.UUID) (domain.Task, error) {
+	if err := s.checkLabelTask(ctx, projectID, taskID, labelID); err != nil {
+		return domain.Task{}, err
+	}
+
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		n, err := q.AttachLabel(ctx, sqlc.AttachLabelParams{
+			TaskID:  taskID,
+			LabelID: labelID,
+		})
+		if err != nil || n == 0 {
+			return err
+		}
+		return audit(ctx, q, domain.AuditTaskLabel, taskID.String(), nil, labelSnapshot(labelID))
+	})
+	if err != nil {
+		return domain.Task{}, err
+	}
+	return s.GetTask(ctx, projectID, taskID)
+}
+
+func (s *PostgresStore) DetachLabel(ctx context.Context, projectID, taskID, labelID uuid.UUID) (domain.Task, error) {
+	if err := s.checkLabelTask(ctx, projectID, taskID, labelID); err != nil {
+		return domain.Task{}, err
+	}
+
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		n, err := q.DetachLabel(ctx, sqlc.DetachLabelParams{
+			TaskID:  taskID,
+			LabelID: labelID,
+		})
+		if err != nil || n == 0 {
+			return err
+		}
+		return audit(ctx, q, domain.AuditTaskLabel, taskID.String(), labelSnapshot(labelID), nil)
+	})
+	if err != nil {
+		return domain.Task{}, err
+	}
+	return s.GetTask(ctx, projectID, taskID)
+}
+
+// checkLabelTask reports which of the project, task or label of an attach or
+// detach request is missing, in that order.
+func (s *PostgresStore) checkLabelTask(ctx context.Context, projectID, taskID, labelID uuid.UUID) error {
+	if _, err := s.GetTask(ctx, projectID, taskID); err != nil {
+		return err
+	}
+	_, err := s.GetLabel(ctx, projectID, labelID)
+	return err
+}
+
+// labelNotFound resolves a label lookup that matched no rows: either the
+// project is missing or the label is.
+func (s *PostgresStore) labelNotFound(ctx context.Context, projectID uuid.UUID) error {
+	_, err := s.GetProject(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+	return ErrLabelNotFound
+}
+
+// commentsAuthorFK names the comments.author_id foreign key.
+const commentsAuthorFK = "comments_author_id_fkey"
+
+func (s *PostgresStore) InsertComment(ctx context.Context, projectID, taskID uuid.UUID, comment NewComment) (domain.Comment, error) {
+	var row sqlc.Comment
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		row, err = q.InsertComment(ctx, sqlc.InsertCommentParams{
+			ID:        uuid.New(),
+			TaskID:    taskID,
+			ParentID:  comment.ParentID,
+			AuthorID:  comment.AuthorID,
+			Body:      comment.Body,
+			CreatedAt: time.Now().UTC(),
+			ProjectID: projectID,
+		})
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditComment, row.ID.String(), nil, domain.Comment(row))
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == commentsAuthorFK {
+			return domain.Comment{}, ErrUserNotFound
+		}
+		// No rows: the task is gone or the parent is not on it. Any other FK
+		// violation: the task or parent was deleted concurrently.
+		if errors.Is(err, pgx.ErrNoRows) || (pgErr != nil && pgErr.Code == "23503") {
+			return domain.Comment{}, s.commentNotFound(ctx, projectID, taskID)
+		}
+		return domain.Comment{}, err
+	}
+	return domain.Comment(row), nil
+}
+
+func (s *PostgresStore) GetComment(ctx context.Context, projectID, taskID, commentID uuid.UUID) (domain.Comment, error) {
+	row, err := s.queries.GetComment(ctx, sqlc.GetCommentParams{
+		ID:        commentID,
+		TaskID:    taskID,
+		ProjectID: projectID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Comment{}, s.commentNotFound(ctx, projectID, taskID)
+		}
+		return domain.Comment{}, err
+	}
+	return domain.Comment(row), nil
+}
+
+func (s *PostgresStore) ListComments(ctx context.Context, projectID, taskID uuid.UUID, params ListCommentsParams) ([]domain.Comment, int, error) {
+	total, err := s.queries.CountComments(ctx, sqlc.CountCommentsParams{
+		TaskID:    taskID,
+		ProjectID: projectID,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		if _, err := s.GetTask(ctx, projectID, taskID); err != nil {
+			return nil, 0, err
+		}
+		return []domain.Comment{}, 0, nil
+	}
+
+	rows, err := s.queries.ListComments(ctx, sqlc.ListCommentsParams{
+		TaskID:    taskID,
+		ProjectID: projectID,
+		Limit:     int32(params.Limit),
+		Offset:    offset32(params.Offset),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	comments := make([]domain.Comment, 0, len(rows))
+	for _, row := range rows {
+		comments = append(comments, domain.Comment(row))
+	}
+	return comments, int(total), nil
+}
+
+func (s *PostgresStore) UpdateComment(ctx context.Context, projectID, taskID, commentID uuid.UUID, body string) (domain.Comment, error) {
+	var row sqlc.Comment
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		before, err := q.GetCommentForUpdate(ctx, sqlc.GetCommentForUpdateParams{
+			ID:        commentID,
+			TaskID:    taskID,
+			ProjectID: projectID,
+		})
+		if err != nil {
+			return err
+		}
+		row, err = q.UpdateComment(ctx, sqlc.UpdateCommentParams{
+			Body:      body,
+			UpdatedAt: time.Now().UTC(),
+			ID:        commentID,
+			TaskID:    taskID,
+			ProjectID: projectID,
+		})
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditComment, commentID.String(), domain.Comment(before), domain.Comment(row))
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Comment{}, s.commentNotFound(ctx, projectID, taskID)
+		}
+		return domain.Comment{}, err
+	}
+	return domain.Comment(row), nil
+}
+
+func (s *PostgresStore) DeleteComment(ctx context.Context, projectID, taskID, commentID uuid.UUID) error {
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		before, err := q.GetCommentForUpdate(ctx, sqlc.GetCommentForUpdateParams{
+			ID:        commentID,
+			TaskID:    taskID,
+			ProjectID: projectID,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := q.DeleteComment(ctx, sqlc.DeleteCommentParams{
+			ID:        commentID,
+			TaskID:    taskID,
+			ProjectID: projectID,
+		}); err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditComment, commentID.String(), domain.Comment(before), nil)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s.commentNotFound(ctx, projectID, taskID)
+	}
+	return err
+}
+
+// commentNotFound resolves a comment lookup that matched no rows: the
+// project, the task or the comment itself is missing.
+func (s *PostgresStore) commentNotFound(ctx context.Context, projectID, taskID uuid.UUID) error {
+	if _, err := s.GetTask(ctx, projectID, taskID); err != nil {
+		return err
+	}
+	return ErrCommentNotFound
+}
+
+func toDomainAPIKey(row sqlc.ApiKey) domain.APIKey {
+	return domain.APIKey{
+		ID:          row.ID,
+		Name:        row.Name,
+		Prefix:      row.Prefix,
+		Hash:        row.KeyHash,
+		Admin:       row.Admin,
 		CreatedAt:   row.CreatedAt,
-	}, nil
+		RevokedAt:   row.RevokedAt,
+		WorkspaceID: row.WorkspaceID,
+	}
+}
+
+func toDomainMember(row sqlc.ProjectMember) domain.ProjectMember {
+	return domain.ProjectMember(row)
+}
+
+func (s *PostgresStore) GetProjectMember(ctx context.Context, projectID uuid.UUID, subject string) (domain.ProjectMember, error) {
+	row, err := s.queries.GetProjectMember(ctx, sqlc.GetProjectMemberParams{
+		ProjectID: projectID,
+		Subject:   subject,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ProjectMember{}, ErrMemberNotFound
+		}
+		return domain.ProjectMember{}, err
+	}
+	return toDomainMember(row), nil
+}
+
+func (s *PostgresStore) ListProjectMembers(ctx context.Context, projectID uuid.UUID) ([]domain.ProjectMember, error) {
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := s.queries.ListProjectMembers(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]domain.ProjectMember, 0, len(rows))
+	for _, row := range rows {
+		members = append(members, toDomainMember(row))
+	}
+	return members, nil
+}
+
+// lockMembers locks the project for a membership change and returns
+// subject's current membership, if any.
+func lockMembers(ctx context.Context, q *sqlc.Queries, projectID uuid.UUID, subject string) (sqlc.ProjectMember, bool, error) {
+	if _, err := q.LockProject(ctx, projectID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.ProjectMember{}, false, ErrProjectNotFound
+		}
+		return sqlc.ProjectMember{}, false, err
+	}
+
+	m, err := q.GetProjectMember(ctx, sqlc.GetProjectMemberParams{ProjectID: projectID, Subject: subject})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.ProjectMember{}, false, nil
+		}
+		return sqlc.ProjectMember{}, false, err
+	}
+	return m, true, nil
+}
+
+// lastOwner reports whether m is the project's only owner.
+func lastOwner(ctx context.Context, q *sqlc.Queries, m sqlc.ProjectMember) (bool, error) {
+	if m.Role != domain.RoleOwner {
+		return false, nil
+	}
+	n, err := q.CountProjectOwners(ctx, m.ProjectID)
+	return n == 1, err
+}
+
+func (s *PostgresStore) PutProjectMember(ctx context.Context, projectID uuid.UUID, subject, role string) (domain.ProjectMember, error) {
+	var row sqlc.ProjectMember
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		m, ok, err := lockMembers(ctx, q, projectID, subject)
+		if err != nil {
+			return err
+		}
+		if ok && role != domain.RoleOwner {
+			last, err := lastOwner(ctx, q, m)
+			if err != nil {
+				return err
+			}
+			if last {
+				return ErrLastOwner
+			}
+		}
+
+		row, err = q.UpsertProjectMember(ctx, sqlc.UpsertProjectMemberParams{
+			ProjectID: projectID,
+			Subject:   subject,
+			Role:      role,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+		var before any
+		if ok {
+			before = toDomainMember(m)
+		}
+		return audit(ctx, q, domain.AuditMember, projectID.String(), before, toDomainMember(row))
+	})
+	if err != nil {
+		return domain.ProjectMember{}, err
+	}
+	return toDomainMember(row), nil
+}
+
+func (s *PostgresStore) RemoveProjectMember(ctx context.Context, projectID uuid.UUID, subject string) error {
+	return s.inTx(ctx, func(q *sqlc.Queries) error {
+		m, ok, err := lockMembers(ctx, q, projectID, subject)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrMemberNotFound
+		}
+		last, err := lastOwner(ctx, q, m)
+		if err != nil {
+			return err
+		}
+		if last {
+			return ErrLastOwner
+		}
+
+		if _, err := q.DeleteProjectMember(ctx, sqlc.DeleteProjectMemberParams{ProjectID: projectID, Subject: subject}); err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditMember, projectID.String(), toDomainMember(m), nil)
+	})
+}
+
+func (s *PostgresStore) InsertAPIKey(ctx context.Context, key NewAPIKey) (domain.APIKey, error) {
+	var row sqlc.ApiKey
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		row, err = q.InsertAPIKey(ctx, sqlc.InsertAPIKeyParams{
+			ID:          uuid.New(),
+			Name:        key.Name,
+			Prefix:      key.Prefix,
+			KeyHash:     key.Hash,
+			Admin:       key.Admin,
+			CreatedAt:   time.Now().UTC(),
+			WorkspaceID: optUUID(key.WorkspaceID),
+		})
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditAPIKey, row.ID.String(), nil, toDomainAPIKey(row))
+	})
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+	return toDomainAPIKey(row), nil
+}
+
+func (s *PostgresStore) GetAPIKeyByHash(ctx context.Context, hash []byte) (domain.APIKey, error) {
+	row, err := s.queries.GetAPIKeyByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.APIKey{}, ErrAPIKeyNotFound
+		}
+		return domain.APIKey{}, err
+	}
+	return toDomainAPIKey(row), nil
+}
+
+func (s *PostgresStore) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := s.queries.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]domain.APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, toDomainAPIKey(row))
+	}
+	return keys, nil
+}
+
+func (s *PostgresStore) RevokeAPIKey(ctx context.Context, id uuid.UUID) (domain.APIKey, error) {
+	var row sqlc.ApiKey
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		before, err := q.GetAPIKeyForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		row, err = q.RevokeAPIKey(ctx, sqlc.RevokeAPIKeyParams{
+			ID:        id,
+			RevokedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditAPIKey, id.String(), toDomainAPIKey(before), toDomainAPIKey(row))
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.APIKey{}, ErrAPIKeyNotFound
+		}
+		return domain.APIKey{}, err
+	}
+	return toDomainAPIKey(row), nil
+}
+
+func (s *PostgresStore) InsertOrganization(ctx context.Context, name string) (domain.Organization, error) {
+	var row sqlc.Organization
+	err := s.inTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		row, err = q.InsertOrganization(ctx, sqlc.InsertOrganizationParams{
+			ID:        uuid.New(),
+			Name:      name,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, domain.AuditOrganization, row.ID.String(), nil, domain.Organization(row))
+	})
+	if err != nil {
+		return domain.Organization{}, err
+	}
+	return domain.Organization(row), nil
+}
+
+func (s *PostgresStore) GetOrganization(ctx context.Context, id uuid.UUID) (domain.Organization, error) {
+	row, err := s.queries.GetOrganization(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Organization{}, ErrOrganizationNotFound
+		}
+		return domain.Organization{}, err
+	}
+	return domain.Organization(row), nil
+}
+
+func (s *PostgresStore) ListOrganizations(ctx context.Context) ([]domain.Organization, error) {
+	rows, err := s.queries.ListOrganizations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orgs := make([]domain.Organization, 0, len(rows))
+	for _, row := range rows {
+		orgs = append(orgs, domain.Organization(row))
+	}
+	return orgs, nil
+}
+
+func toDomainAuditEntry(row sqlc.AuditLog) (domain.AuditEntry, error) {
+	e := domain.AuditEntry{
+		ID:          row.ID,
+		WorkspaceID: row.WorkspaceID,
+		Actor:       row.Actor,
+		RequestID:   row.RequestID.String,
+		Action:      row.Action,
+		Entity:      row.Entity,
+		EntityID:    row.EntityID,
+		CreatedAt:   row.CreatedAt,
+	}
+	if row.Before != nil {
+		if err := json.Unmarshal(row.Before, &e.Before); err != nil {
+			return domain.AuditEntry{}, err
+		}
+	}
+	if row.After != nil {
+		if err := json.Unmarshal(row.After, &e.After); err != nil {
+			return domain.AuditEntry{}, err
+		}
+	}
+	return e, nil
+}
+
+// audit records a change to an entity in q's transaction; see
+// newAuditEntry.
+func audit(ctx context.Context, q *sqlc.Queries, entity, entityID string, before, after any) error {
+	e, ok, err := newAuditEntry(ctx, entity, entityID, before, after)
+	if err != nil || !ok {
+		return err
+	}
+	return insertAuditEntry(ctx, q, e)
+}
+
+func insertAuditEntry(ctx context.Context, q *sqlc.Queries, e domain.AuditEntry) error {
+	before, err := optJSON(e.Before)
+	if err != nil {
+		return err
+	}
+	after, err := optJSON(e.After)
+	if err != nil {
+		return err
+	}
+	return q.InsertAuditEntry(ctx, sqlc.InsertAuditEntryParams{
+		WorkspaceID: e.WorkspaceID,
+		Actor:       e.Actor,
+		RequestID:   pgtype.Text{String: e.RequestID, Valid: e.RequestID != ""},
+		Action:      e.Action,
+		Entity:      e.Entity,
+		EntityID:    e.EntityID,
+		Before:      before,
+		After:       after,
+		CreatedAt:   e.CreatedAt,
+	})
+}
+
+// optJSON maps a nil object to SQL NULL.
+func optJSON(m map[string]any) ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+func (s *PostgresStore) ListAuditEntries(ctx context.Context, params ListAuditParams) ([]domain.AuditEntry, int, error) {
+	entity := pgtype.Text{String: params.Entity, Valid: params.Entity != ""}
+	entityID := pgtype.Text{String: params.EntityID, Valid: params.EntityID != ""}
+
+	total, err := s.queries.CountAuditEntries(ctx, sqlc.CountAuditEntriesParams{
+		Entity:   entity,
+		EntityID: entityID,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.queries.ListAuditEntries(ctx, sqlc.ListAuditEntriesParams{
+		Entity:   entity,
+		EntityID: entityID,
+		Limit:    int32(params.Limit),
+		Offset:   offset32(params.Offset),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]domain.AuditEntry, 0, len(rows))
+	for _, row := range rows {
+		e, err := toDomainAuditEntry(row)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, int(total), nil
+}
+
+func (s *PostgresStore) PurgeAuditEntries(ctx context.Context, before time.Time) (int, error) {
+	n, err := s.queries.PurgeAuditEntries(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
 }
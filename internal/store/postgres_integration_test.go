@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/linus5304/project-manager-api/internal/store/migrations"
 	"github.com/linus5304/project-manager-api/internal/store/pgtest"
 )
@@ -18,23 +19,31 @@ func newPGStore(t *testing.T) (context.Context, *PostgresStore) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	t.Cleanup(cancel)
 
+	// Migrate as the connecting user first: the store itself works as
+	// pm_tenant, which the migrations create and which may not change the schema
+	pool, err := pgxpool.New(ctx, pg.ConnString)
+	if err != nil {
+		t.Fatalf("connect pgxpool: %v", err)
+	}
+	err = migrations.Apply(ctx, pool)
+	pool.Close()
+	if err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+
 	s, err := NewPostgresStore(ctx, pg.ConnString)
 	if err != nil {
 		t.Fatalf("failed to create PostgresStore: %v", err)
 	}
 	t.Cleanup(s.Close)
 
-	if err := migrations.Apply(ctx, s.pool); err != nil {
-		t.Fatalf("failed to apply migrations: %v", err)
-	}
-
 	return ctx, s
 }
 
 func TestPostgresStore_InsertGetProject(t *testing.T) {
 	ctx, s := newPGStore(t)
 
-	created, err := s.InsertProject(ctx, "Alpha")
+	created, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
 	if err != nil {
 		t.Fatalf("InsertProject failed: %v", err)
 	}
@@ -62,7 +71,7 @@ func TestPostgresStore_InsertGetProject(t *testing.T) {
 func TestPostgresStore_InsertTask_ProjectNotFound(t *testing.T) {
 	ctx, s := newPGStore(t)
 
-	_, err := s.InsertTask(ctx, uuid.New(), "t1", "desc")
+	_, err := s.InsertTask(ctx, uuid.New(), NewTask{Title: "t1", Description: "desc"})
 	if err == nil {
 		t.Fatal("expected ErrProjectNotFound, got nil")
 	}
@@ -75,13 +84,13 @@ func TestPostgresStore_ListTasks_EmptyVsMissingProject(t *testing.T) {
 	ctx, s := newPGStore(t)
 
 	// create project with no tasks
-	p, err := s.InsertProject(ctx, "Alpha")
+	p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
 	if err != nil {
 		t.Fatalf("InsertProject: %v", err)
 	}
 
 	// Existing project, no tasks -> empty list, nil error
-	tasks, err := s.ListTasks(ctx, p.ID)
+	tasks, _, err := s.ListTasks(ctx, p.ID, ListTasksParams{})
 	if err != nil {
 		t.Fatalf("ListTasks existing project: %v", err)
 	}
@@ -90,7 +99,7 @@ func TestPostgresStore_ListTasks_EmptyVsMissingProject(t *testing.T) {
 	}
 
 	// Missing project -> ErrProjectNotFound
-	_, err = s.ListTasks(ctx, uuid.New())
+	_, _, err = s.ListTasks(ctx, uuid.New(), ListTasksParams{})
 	if err == nil {
 		t.Fatalf("expected ErrProjectNotFound, got nil")
 	}
@@ -102,11 +111,11 @@ func TestPostgresStore_ListTasks_EmptyVsMissingProject(t *testing.T) {
 func TestPostgresStore_UpdateTask_Partial(t *testing.T) {
 	ctx, s := newPGStore(t)
 
-	p, err := s.InsertProject(ctx, "Alpha")
+	p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
 	if err != nil {
 		t.Fatalf("InsertProject: %v", err)
 	}
-	task, err := s.InsertTask(ctx, p.ID, "T1", "desc")
+	task, err := s.InsertTask(ctx, p.ID, NewTask{Title: "T1", Description: "desc"})
 	if err != nil {
 		t.Fatalf("InsertTask: %v", err)
 	}
@@ -133,12 +142,12 @@ func TestPostgresStore_UpdateTask_Partial(t *testing.T) {
 func TestPostgresStore_UpdateTask_AllowsEmptyDescription(t *testing.T) {
 	ctx, s := newPGStore(t)
 
-	p, err := s.InsertProject(ctx, "Alpha")
+	p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
 	if err != nil {
 		t.Fatalf("InsertProject: %v", err)
 	}
 
-	task, err := s.InsertTask(ctx, p.ID, "T1", "desc")
+	task, err := s.InsertTask(ctx, p.ID, NewTask{Title: "T1", Description: "desc"})
 	if err != nil {
 		t.Fatalf("InsertTask: %v", err)
 	}
@@ -165,7 +174,7 @@ func TestPostgresStore_UpdateTask_NotFoundMapping(t *testing.T) {
 	}
 
 	// project exists, task missing
-	p, err := s.InsertProject(ctx, "Alpha")
+	p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
 	if err != nil {
 		t.Fatalf("InsertProject: %v", err)
 	}
@@ -175,3 +184,179 @@ func TestPostgresStore_UpdateTask_NotFoundMapping(t *testing.T) {
 		t.Fatalf("expected ErrTaskNotFound; got %v", err)
 	}
 }
+
+func TestPostgresStore_ListProjects_Paginated(t *testing.T) {
+	ctx, s := newPGStore(t)
+
+	for _, name := range []string{"Alpha", "Beta", "Gamma"} {
+		if _, err := s.InsertProject(ctx, NewProject{Name: name}); err != nil {
+			t.Fatalf("InsertProject %s: %v", name, err)
+		}
+	}
+
+	page, total, err := s.ListProjects(ctx, ListProjectsParams{Limit: 2, Offset: 0})
+	if err != nil {
+		t.Fatalf("ListProjects: %v", err)
+	}
+	if total != 3 {
+		t.Fatalf("expected total 3; got %d", total)
+	}
+	if len(page) != 2 || page[0].Name != "Gamma" || page[1].Name != "Beta" {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+
+	page, total, err = s.ListProjects(ctx, ListProjectsParams{Limit: 2, Offset: 2})
+	if err != nil {
+		t.Fatalf("ListProjects: %v", err)
+	}
+	if total != 3 || len(page) != 1 || page[0].Name != "Alpha" {
+		t.Fatalf("unexpected second page: total=%d page=%+v", total, page)
+	}
+}
+
+func TestPostgresStore_ListTasks_KeysetStableAcrossInserts(t *testing.T) {
+	ctx, s := newPGStore(t)
+
+	p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
+	if err != nil {
+		t.Fatalf("InsertProject: %v", err)
+	}
+	for _, title := range []string{"T1", "T2", "T3"} {
+		if _, err := s.InsertTask(ctx, p.ID, NewTask{Title: title}); err != nil {
+			t.Fatalf("InsertTask %s: %v", title, err)
+		}
+	}
+
+	first, total, err := s.ListTasks(ctx, p.ID, ListTasksParams{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListTasks: %v", err)
+	}
+	if total != 3 || len(first) != 2 || first[0].Title != "T3" {
+		t.Fatalf("unexpected first page: total=%d page=%+v", total, first)
+	}
+
+	// A task inserted between page fetches must not shift the next page.
+	if _, err := s.InsertTask(ctx, p.ID, NewTask{Title: "T4"}); err != nil {
+		t.Fatalf("InsertTask T4: %v", err)
+	}
+
+	last := first[len(first)-1]
+	second, _, err := s.ListTasks(ctx, p.ID, ListTasksParams{
+		Limit: 2,
+		After: &Cursor{CreatedAt: last.CreatedAt, ID: last.ID},
+	})
+	if err != nil {
+		t.Fatalf("ListTasks after cursor: %v", err)
+	}
+	if len(second) != 1 || second[0].Title != "T1" {
+		t.Fatalf("expected only T1 after cursor; got %+v", second)
+	}
+}
+
+func TestPostgresStore_ListTasks_FilterSortPage(t *testing.T) {
+	ctx, s := newPGStore(t)
+
+	p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
+	if err != nil {
+		t.Fatalf("InsertProject: %v", err)
+	}
+	for _, title := range []string{"b_fix", "a_fix", "100% done", "c_other"} {
+		if _, err := s.InsertTask(ctx, p.ID, NewTask{Title: title}); err != nil {
+			t.Fatalf("InsertTask %s: %v", title, err)
+		}
+	}
+
+	tasks, total, err := s.ListTasks(ctx, p.ID, ListTasksParams{Limit: 10, Query: "FIX", Sort: TaskSortTitle})
+	if err != nil {
+		t.Fatalf("ListTasks: %v", err)
+	}
+	if total != 2 || len(tasks) != 2 || tasks[0].Title != "a_fix" || tasks[1].Title != "b_fix" {
+		t.Fatalf("unexpected title-sorted matches: total=%d tasks=%+v", total, tasks)
+	}
+
+	// LIKE wildcards in the query are matched literally.
+	tasks, _, err = s.ListTasks(ctx, p.ID, ListTasksParams{Limit: 10, Query: "%"})
+	if err != nil {
+		t.Fatalf("ListTasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Title != "100% done" {
+		t.Fatalf("expected only the literal %% match; got %+v", tasks)
+	}
+
+	tasks, total, err = s.ListTasks(ctx, p.ID, ListTasksParams{Limit: 1, Offset: 1, Statuses: []string{"todo"}, Sort: TaskSortOldest})
+	if err != nil {
+		t.Fatalf("ListTasks: %v", err)
+	}
+	if total != 4 || len(tasks) != 1 || tasks[0].Title != "a_fix" {
+		t.Fatalf("unexpected oldest-first page: total=%d tasks=%+v", total, tasks)
+	}
+
+	_, total, err = s.ListTasks(ctx, p.ID, ListTasksParams{Limit: 10, Statuses: []string{"done"}})
+	if err != nil {
+		t.Fatalf("ListTasks: %v", err)
+	}
+	if total != 0 {
+		t.Fatalf("expected no done tasks; got %d", total)
+	}
+}
+
+func TestPostgresStore_UpdateProject(t *testing.T) {
+	ctx, s := newPGStore(t)
+
+	p, err := s.InsertProject(ctx, NewProject{Name: "Alpah"})
+	if err != nil {
+		t.Fatalf("InsertProject: %v", err)
+	}
+
+	name := "Alpha"
+	updated, err := s.UpdateProject(ctx, p.ID, ProjectUpdate{Name: &name})
+	if err != nil {
+		t.Fatalf("UpdateProject: %v", err)
+	}
+	if updated.Name != "Alpha" || !updated.CreatedAt.Equal(p.CreatedAt) {
+		t.Fatalf("unexpected updated project: %+v", updated)
+	}
+
+	if _, err := s.UpdateProject(ctx, uuid.New(), ProjectUpdate{Name: &name}); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound; got %v", err)
+	}
+}
+
+func TestPostgresStore_PurgeDeletedProjects_CascadesTasks(t *testing.T) {
+	ctx, s := newPGStore(t)
+
+	p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
+	if err != nil {
+		t.Fatalf("InsertProject: %v", err)
+	}
+	task, err := s.InsertTask(ctx, p.ID, NewTask{Title: "T1"})
+	if err != nil {
+		t.Fatalf("InsertTask: %v", err)
+	}
+
+	if err := s.DeleteProject(ctx, p.ID); err != nil {
+		t.Fatalf("DeleteProject: %v", err)
+	}
+	if _, err := s.InsertTask(ctx, p.ID, NewTask{Title: "T2"}); err != ErrProjectNotFound {
+		t.Fatalf("expected ErrProjectNotFound inserting into deleted project; got %v", err)
+	}
+
+	n, err := s.PurgeDeletedProjects(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("PurgeDeletedProjects: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 purged project; got %d", n)
+	}
+
+	if err := s.pool.QueryRow(ctx, "select count(*) from tasks where id = $1", task.ID).Scan(&n); err != nil {
+		t.Fatalf("count tasks: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("expected task to be cascaded away; found %d", n)
+	}
+
+	if err := s.DeleteProject(ctx, p.ID); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound on second delete; got %v", err)
+	}
+}
@@ -2,23 +2,397 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/linus5304/project-manager-api/internal/domain"
 )
 
-type TaskUpdate struct {
-	Title       *string
+// NewProject holds the caller-supplied fields of a project being created.
+// OwnerID, when set, must refer to an existing user, and Key, when set, must
+// not be used by another project. CreatedBy, when set, is the subject made
+// the project's first owner member.
+type NewProject struct {
+	Name        string
+	Description string
+	OwnerID     *uuid.UUID
+	Color       string
+	Icon        string
+	Key         string
+	CreatedBy   string
+}
+
+// ProjectUpdate holds the fields to change; nil leaves a field as it is.
+// An OwnerID of uuid.Nil clears the owner and an empty Key removes the key.
+type ProjectUpdate struct {
+	Name        *string
 	Description *string
-	Status      *string
+	OwnerID     *uuid.UUID
+	Color       *string
+	Icon        *string
+	Key         *string
+}
+
+type LabelUpdate struct {
+	Name  *string
+	Color *string
+}
+
+// NewComment holds the caller-supplied fields of a comment being created.
+// ParentID, when set, must refer to a comment on the same task.
+type NewComment struct {
+	AuthorID uuid.UUID
+	ParentID *uuid.UUID
+	Body     string
+}
+
+// ListCommentsParams selects one page of a task's comments, oldest first.
+type ListCommentsParams struct {
+	Limit  int
+	Offset int
+}
+
+// DefaultTaskPriority is given to tasks created without a priority.
+const DefaultTaskPriority = "medium"
+
+// NewTask holds the caller-supplied fields of a task being created.
+// AssigneeID, when set, must refer to an existing user and ParentTaskID to a
+// task in the same project. An empty Priority means DefaultTaskPriority.
+type NewTask struct {
+	Title        string
+	Description  string
+	AssigneeID   *uuid.UUID
+	DueDate      *time.Time
+	Priority     string
+	ParentTaskID *uuid.UUID
+}
+
+// TaskUpdate holds the fields to change; nil leaves a field as it is.
+// An AssigneeID of uuid.Nil unassigns the task, a zero DueDate clears it and
+// a ParentTaskID of uuid.Nil makes it a top-level task.
+type TaskUpdate struct {
+	Title        *string
+	Description  *string
+	Status       *string
+	AssigneeID   *uuid.UUID
+	DueDate      *time.Time
+	Priority     *string
+	ParentTaskID *uuid.UUID
 }
 
+// TaskMove places a task on the board: into Status (nil keeps the current
+// one), right after AfterID and/or right before BeforeID, or at the bottom of
+// the column when both are nil.
+type TaskMove struct {
+	Status   *string
+	AfterID  *uuid.UUID
+	BeforeID *uuid.UUID
+}
+
+// NewAPIKey holds the fields of an API key being created. Hash is the
+// SHA-256 of the key; the key itself never reaches the store. WorkspaceID,
+// when set, pins the key to that workspace.
+type NewAPIKey struct {
+	Name        string
+	Prefix      string
+	Hash        []byte
+	Admin       bool
+	WorkspaceID *uuid.UUID
+}
+
+// ProjectClone says how CloneProject copies a project.
+type ProjectClone struct {
+	Name string
+	// CreatedBy, when set, is made the clone's first owner; members are not
+	// copied.
+	CreatedBy string
+	// IncludeTasks copies the tasks too; ResetStatuses then starts them all
+	// over in the workflow's first status.
+	IncludeTasks  bool
+	ResetStatuses bool
+}
+
+// TransferReport says what came along when a task was moved or copied to
+// another project, and what was left behind.
+type TransferReport struct {
+	// Subtasks counts the subtasks moved along with the task.
+	Subtasks int
+	// Comments counts the comments that came along.
+	Comments int
+	// Labels names the labels carried over; CreatedLabels those of them the
+	// target project did not have yet.
+	Labels        []string
+	CreatedLabels []string
+	// DroppedDependencies counts blocker links to tasks left behind.
+	DroppedDependencies int
+	// DroppedSubtasks counts the direct subtasks a copy leaves out.
+	DroppedSubtasks int
+}
+
+// SubtaskPolicy says what happens to the subtasks of a deleted task.
+type SubtaskPolicy string
+
+const (
+	// SubtasksReparent moves the subtasks up to the deleted task's parent.
+	SubtasksReparent SubtaskPolicy = "reparent"
+	// SubtasksCascade deletes the whole subtree.
+	SubtasksCascade SubtaskPolicy = "cascade"
+)
+
+// Cursor is a keyset position in a newest-first (created_at DESC, id DESC) listing.
+// A page that starts after a cursor contains only rows strictly older than it.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// ListProjectsParams selects one page of projects, newest first.
+// When After is set, Offset is ignored and the page starts after the cursor.
+// Archived and soft-deleted projects are left out unless explicitly included.
+type ListProjectsParams struct {
+	Limit  int
+	Offset int
+	After  *Cursor
+
+	IncludeArchived bool
+	IncludeDeleted  bool
+	// Member keeps projects that subject is a member of; empty keeps all.
+	Member string
+}
+
+// TaskSort names an ordering for task listings.
+type TaskSort string
+
+const (
+	TaskSortNewest TaskSort = "-created_at"
+	TaskSortOldest TaskSort = "created_at"
+	TaskSortTitle  TaskSort = "title"
+)
+
+// ListTasksParams selects one filtered, sorted page of a project's tasks.
+// An empty Sort means TaskSortNewest. After is only honoured with the newest-first
+// order; when it is set, Offset is ignored.
+type ListTasksParams struct {
+	Limit  int
+	Offset int
+	After  *Cursor
+
+	// Statuses keeps tasks whose status is any of the given values; empty keeps all.
+	Statuses []string
+	// Query keeps tasks whose title contains it, case-insensitively; empty keeps all.
+	Query string
+	// DueBefore and DueAfter keep tasks due strictly before/after the given
+	// time; tasks without a due date never match them.
+	DueBefore *time.Time
+	DueAfter  *time.Time
+	// Labels keeps tasks carrying any of the named labels; empty keeps all.
+	Labels []string
+	// ParentTaskID keeps only the direct subtasks of that task; nil keeps all.
+	ParentTaskID *uuid.UUID
+	Sort         TaskSort
+}
+
+// ListUserTasksParams selects one page of the tasks assigned to a user across
+// all live projects, newest first.
+type ListUserTasksParams struct {
+	Limit  int
+	Offset int
+
+	// Statuses keeps tasks whose status is any of the given values; empty keeps all.
+	Statuses []string
+	// Member keeps tasks of projects that subject is a member of; empty keeps all.
+	Member string
+}
+
+// ListOverdueTasksParams selects one page of open tasks whose due date is
+// before AsOf across all live projects, earliest due first.
+type ListOverdueTasksParams struct {
+	AsOf   time.Time
+	Limit  int
+	Offset int
+
+	// Member keeps tasks of projects that subject is a member of; empty keeps all.
+	Member string
+}
+
+// ListBlockedTasksParams selects one page of a project's blocked tasks.
+type ListBlockedTasksParams struct {
+	Limit  int
+	Offset int
+}
+
+// ListAuditParams selects one page of audit entries, newest first.
+type ListAuditParams struct {
+	Limit  int
+	Offset int
+
+	// Entity keeps entries about that kind of entity; empty keeps all.
+	Entity string
+	// EntityID keeps entries about the entity with that ID; empty keeps all.
+	EntityID string
+}
+
+// ProjectStore keeps projects, templates and everything in them apart by
+// workspace: each call only sees the workspace its context is scoped to with
+// WithWorkspace, and anything in another workspace fails with the same
+// not-found error as if it did not exist. Users, API keys and organizations
+// are shared by the whole deployment.
+//
+// Every create, update and delete is recorded in the audit log, in the same
+// transaction, with the actor the context carries from WithActor.
 type ProjectStore interface {
-	InsertProject(ctx context.Context, name string) (domain.Project, error)
+	InsertProject(ctx context.Context, project NewProject) (domain.Project, error)
 	GetProject(ctx context.Context, id uuid.UUID) (domain.Project, error)
-	ListProjects(ctx context.Context) ([]domain.Project, error)
+	UpdateProject(ctx context.Context, id uuid.UUID, update ProjectUpdate) (domain.Project, error)
+	// DeleteProject soft-deletes the project: it and its tasks become invisible
+	// until restored, and are removed for good by PurgeDeletedProjects.
+	DeleteProject(ctx context.Context, id uuid.UUID) error
+	// ArchiveProject hides the project from default listings. Archiving twice keeps
+	// the original timestamp.
+	ArchiveProject(ctx context.Context, id uuid.UUID) (domain.Project, error)
+	// RestoreProject clears both the archived and deleted state.
+	RestoreProject(ctx context.Context, id uuid.UUID) (domain.Project, error)
+	// PurgeDeletedProjects hard-deletes projects (and their tasks) soft-deleted
+	// before the given time and reports how many were removed.
+	PurgeDeletedProjects(ctx context.Context, deletedBefore time.Time) (int, error)
+	// ListProjects returns the requested page along with the total number of projects.
+	ListProjects(ctx context.Context, params ListProjectsParams) ([]domain.Project, int, error)
+	// GetWorkflow returns the project's workflow, or domain.DefaultWorkflow if
+	// it has not defined one.
+	GetWorkflow(ctx context.Context, projectID uuid.UUID) (domain.Workflow, error)
+	// UpdateWorkflow replaces the project's workflow and moves each task's
+	// status category to match. It fails with ErrStatusInUse when a task still
+	// has a status the new workflow drops.
+	UpdateWorkflow(ctx context.Context, projectID uuid.UUID, workflow domain.Workflow) (domain.Workflow, error)
+	// CloneProject creates a new project with the same workflow and labels in
+	// one transaction. Copied tasks keep their subtasks, labels, dependencies
+	// and board order, but not their comments.
+	CloneProject(ctx context.Context, projectID uuid.UUID, clone ProjectClone) (domain.Project, error)
+
+	InsertTemplate(ctx context.Context, name string, tasks []domain.TaskBlueprint) (domain.Template, error)
+	GetTemplate(ctx context.Context, id uuid.UUID) (domain.Template, error)
+	// ListTemplates returns all templates ordered by name.
+	ListTemplates(ctx context.Context) ([]domain.Template, error)
+	DeleteTemplate(ctx context.Context, id uuid.UUID) error
+	// InstantiateTemplate creates a project with the template's tasks in one
+	// transaction, named name or, when that is empty, after the template.
+	// createdBy is made its first owner, as with NewProject.CreatedBy.
+	InstantiateTemplate(ctx context.Context, templateID uuid.UUID, name, createdBy string) (domain.Project, error)
 
-	InsertTask(ctx context.Context, projectID uuid.UUID, title, description string) (domain.Task, error)
-	ListTasks(ctx context.Context, projectID uuid.UUID) ([]domain.Task, error)
+	// InsertTask fails with ErrUserNotFound when the assignee does not exist and
+	// with ErrParentTaskNotFound when the parent is not a task of the project.
+	InsertTask(ctx context.Context, projectID uuid.UUID, task NewTask) (domain.Task, error)
+	GetTask(ctx context.Context, projectID, taskID uuid.UUID) (domain.Task, error)
+	// GetTaskByNumber looks a task up by its per-project number.
+	GetTaskByNumber(ctx context.Context, projectID uuid.UUID, number int64) (domain.Task, error)
+	// ListTasks returns the requested page along with the total number of matching tasks.
+	ListTasks(ctx context.Context, projectID uuid.UUID, params ListTasksParams) ([]domain.Task, int, error)
+	// UpdateTask fails like InsertTask, with ErrTaskCycle when the new parent
+	// is the task itself or one of its subtasks, with ErrUnknownStatus or
+	// ErrTransitionNotAllowed when the project's workflow rejects the status,
+	// and with ErrTaskBlocked when moving it out of the todo category while a
+	// blocker is not done.
 	UpdateTask(ctx context.Context, projectID, taskID uuid.UUID, update TaskUpdate) (domain.Task, error)
+	DeleteTask(ctx context.Context, projectID, taskID uuid.UUID, subtasks SubtaskPolicy) error
+	// MoveTask changes a task's status and position in one step. A status
+	// change fails like UpdateTask; neighbours outside the target column fail
+	// with ErrMoveTargetInvalid, and neighbours that are no longer adjacent
+	// with ErrMoveConflict.
+	MoveTask(ctx context.Context, projectID, taskID uuid.UUID, move TaskMove) (domain.Task, error)
+	// TransferTask moves a task and its subtasks, with their comments, to
+	// another project; the task becomes top level there. Labels are matched by
+	// name in the target project and created there if missing, and tasks whose
+	// status the target workflow lacks start over in its first status.
+	// Dependencies on tasks left behind are dropped. A missing target fails with
+	// ErrTargetProjectNotFound.
+	TransferTask(ctx context.Context, projectID, taskID, targetProjectID uuid.UUID) (domain.Task, TransferReport, error)
+	// CopyTask copies a task with its labels and comments into a project
+	// (possibly its own), following the same rules as TransferTask. Subtasks
+	// and dependencies are not copied.
+	CopyTask(ctx context.Context, projectID, taskID, targetProjectID uuid.UUID) (domain.Task, TransferReport, error)
+	// ListBoardTasks returns all of a project's tasks ordered by status, then
+	// by position within each status.
+	ListBoardTasks(ctx context.Context, projectID uuid.UUID) ([]domain.Task, error)
+	// ListOverdueTasks returns the requested page of overdue tasks along with
+	// their total number.
+	ListOverdueTasks(ctx context.Context, params ListOverdueTasksParams) ([]domain.Task, int, error)
+
+	// AddTaskBlocker records that the task cannot start until blocker, a task
+	// of the same project, is done; adding it again is a no-op. It fails with
+	// ErrDependencyCycle when blocker already depends on the task.
+	// RemoveTaskBlocker removes the dependency, also as a no-op when absent.
+	AddTaskBlocker(ctx context.Context, projectID, taskID, blockerID uuid.UUID) (domain.Task, error)
+	RemoveTaskBlocker(ctx context.Context, projectID, taskID, blockerID uuid.UUID) (domain.Task, error)
+	// ListTaskBlockers returns the task's blockers, oldest dependency first.
+	ListTaskBlockers(ctx context.Context, projectID, taskID uuid.UUID) ([]domain.Task, error)
+	// ListBlockedTasks returns the requested page of open tasks with a blocker
+	// that is not done, newest first, along with their total number. "Done"
+	// here and elsewhere means the done status category.
+	ListBlockedTasks(ctx context.Context, projectID uuid.UUID, params ListBlockedTasksParams) ([]domain.Task, int, error)
+
+	// InsertLabel fails with ErrLabelExists when the project already has a
+	// label with that name.
+	InsertLabel(ctx context.Context, projectID uuid.UUID, name, color string) (domain.Label, error)
+	GetLabel(ctx context.Context, projectID, labelID uuid.UUID) (domain.Label, error)
+	// ListLabels returns all of a project's labels ordered by name.
+	ListLabels(ctx context.Context, projectID uuid.UUID) ([]domain.Label, error)
+	UpdateLabel(ctx context.Context, projectID, labelID uuid.UUID, update LabelUpdate) (domain.Label, error)
+	// DeleteLabel also detaches the label from every task.
+	DeleteLabel(ctx context.Context, projectID, labelID uuid.UUID) error
+	// AttachLabel adds a label of the task's project to the task; attaching it
+	// again is a no-op. DetachLabel removes it, also as a no-op when absent.
+	AttachLabel(ctx context.Context, projectID, taskID, labelID uuid.UUID) (domain.Task, error)
+	DetachLabel(ctx context.Context, projectID, taskID, labelID uuid.UUID) (domain.Task, error)
+
+	// InsertComment fails with ErrUserNotFound when the author does not exist
+	// and with ErrCommentNotFound when the parent is not on the same task.
+	InsertComment(ctx context.Context, projectID, taskID uuid.UUID, comment NewComment) (domain.Comment, error)
+	GetComment(ctx context.Context, projectID, taskID, commentID uuid.UUID) (domain.Comment, error)
+	// ListComments returns the requested page along with the total number of comments on the task.
+	ListComments(ctx context.Context, projectID, taskID uuid.UUID, params ListCommentsParams) ([]domain.Comment, int, error)
+	// UpdateComment replaces the body and records when it was edited.
+	UpdateComment(ctx context.Context, projectID, taskID, commentID uuid.UUID, body string) (domain.Comment, error)
+	// DeleteComment also deletes every reply below the comment.
+	DeleteComment(ctx context.Context, projectID, taskID, commentID uuid.UUID) error
+
+	// InsertUser fails with ErrEmailTaken when another user has the same email.
+	InsertUser(ctx context.Context, name, email string) (domain.User, error)
+	GetUser(ctx context.Context, id uuid.UUID) (domain.User, error)
+	// ListUserTasks returns the requested page of tasks assigned to the user
+	// along with their total number.
+	ListUserTasks(ctx context.Context, userID uuid.UUID, params ListUserTasksParams) ([]domain.Task, int, error)
+
+	// GetProjectMember finds a member of a project, soft-deleted or not, and
+	// fails with ErrMemberNotFound when subject is not one.
+	GetProjectMember(ctx context.Context, projectID uuid.UUID, subject string) (domain.ProjectMember, error)
+	// ListProjectMembers returns the project's members, oldest first.
+	ListProjectMembers(ctx context.Context, projectID uuid.UUID) ([]domain.ProjectMember, error)
+	// PutProjectMember adds subject to the project or changes their role.
+	// Demoting the last owner fails with ErrLastOwner.
+	PutProjectMember(ctx context.Context, projectID uuid.UUID, subject, role string) (domain.ProjectMember, error)
+	// RemoveProjectMember fails with ErrMemberNotFound when subject is not a
+	// member and with ErrLastOwner when they are the last owner.
+	RemoveProjectMember(ctx context.Context, projectID uuid.UUID, subject string) error
+
+	InsertOrganization(ctx context.Context, name string) (domain.Organization, error)
+	GetOrganization(ctx context.Context, id uuid.UUID) (domain.Organization, error)
+	// ListOrganizations returns all organizations, oldest first.
+	ListOrganizations(ctx context.Context) ([]domain.Organization, error)
+
+	// ListAuditEntries returns the requested page of the audit log along
+	// with the total number of matching entries.
+	ListAuditEntries(ctx context.Context, params ListAuditParams) ([]domain.AuditEntry, int, error)
+	// PurgeAuditEntries deletes the entries, in every workspace, recorded
+	// before the given time and reports how many were removed. Nothing else
+	// ever changes or removes them.
+	PurgeAuditEntries(ctx context.Context, before time.Time) (int, error)
+
+	InsertAPIKey(ctx context.Context, key NewAPIKey) (domain.APIKey, error)
+	// GetAPIKeyByHash finds a key, revoked or not, by the hash of its value.
+	GetAPIKeyByHash(ctx context.Context, hash []byte) (domain.APIKey, error)
+	// ListAPIKeys returns all keys, revoked ones included, oldest first.
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	// RevokeAPIKey stops a key from working. Revoking twice keeps the first
+	// revocation time.
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) (domain.APIKey, error)
 }
@@ -0,0 +1,27 @@
+-- name: InsertAPIKey :one
+INSERT INTO api_keys (id, name, prefix, key_hash, admin, created_at, workspace_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, name, prefix, key_hash, admin, created_at, revoked_at, workspace_id;
+
+-- name: GetAPIKeyByHash :one
+SELECT id, name, prefix, key_hash, admin, created_at, revoked_at, workspace_id
+FROM api_keys
+WHERE key_hash = $1;
+
+-- name: ListAPIKeys :many
+SELECT id, name, prefix, key_hash, admin, created_at, revoked_at, workspace_id
+FROM api_keys
+ORDER BY created_at, id;
+
+-- name: GetAPIKeyForUpdate :one
+SELECT id, name, prefix, key_hash, admin, created_at, revoked_at, workspace_id
+FROM api_keys
+WHERE id = $1
+FOR UPDATE;
+
+-- name: RevokeAPIKey :one
+-- Revoking twice keeps the first revocation time.
+UPDATE api_keys
+SET revoked_at = COALESCE(revoked_at, sqlc.arg('revoked_at')::timestamptz)
+WHERE id = $1
+RETURNING id, name, prefix, key_hash, admin, created_at, revoked_at, workspace_id;
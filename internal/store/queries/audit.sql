@@ -0,0 +1,21 @@
+-- name: InsertAuditEntry :exec
+INSERT INTO audit_log (workspace_id, actor, request_id, action, entity, entity_id, before, after, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: ListAuditEntries :many
+-- Newest first; the workspace is left to the row-level security policy.
+SELECT id, workspace_id, actor, request_id, action, entity, entity_id, before, after, created_at
+FROM audit_log
+WHERE (sqlc.narg('entity')::text IS NULL OR entity = sqlc.narg('entity')::text)
+  AND (sqlc.narg('entity_id')::text IS NULL OR entity_id = sqlc.narg('entity_id')::text)
+ORDER BY id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountAuditEntries :one
+SELECT count(*)
+FROM audit_log
+WHERE (sqlc.narg('entity')::text IS NULL OR entity = sqlc.narg('entity')::text)
+  AND (sqlc.narg('entity_id')::text IS NULL OR entity_id = sqlc.narg('entity_id')::text);
+
+-- name: PurgeAuditEntries :one
+SELECT purge_audit_log(sqlc.arg('recorded_before')::timestamptz)::bigint AS purged;
@@ -0,0 +1,81 @@
+-- name: InsertComment :one
+-- Inserts nothing (no rows) when the task is missing, its project is missing
+-- or soft-deleted, or the parent is not a comment on the same task.
+INSERT INTO comments (id, task_id, parent_id, author_id, body, created_at)
+SELECT
+  sqlc.arg('id')::uuid,
+  sqlc.arg('task_id')::uuid,
+  sqlc.narg('parent_id')::uuid,
+  sqlc.arg('author_id')::uuid,
+  sqlc.arg('body')::text,
+  sqlc.arg('created_at')::timestamptz
+WHERE EXISTS (
+    SELECT 1 FROM tasks t JOIN projects p ON p.id = t.project_id
+    WHERE t.id = sqlc.arg('task_id')::uuid AND t.project_id = sqlc.arg('project_id')::uuid AND p.deleted_at IS NULL
+  )
+  AND (sqlc.narg('parent_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM comments c WHERE c.id = sqlc.narg('parent_id')::uuid AND c.task_id = sqlc.arg('task_id')::uuid
+  ))
+RETURNING id, task_id, parent_id, author_id, body, created_at, updated_at;
+
+-- name: GetComment :one
+SELECT id, task_id, parent_id, author_id, body, created_at, updated_at
+FROM comments
+WHERE comments.id = sqlc.arg('id') AND comments.task_id = sqlc.arg('task_id')
+  AND EXISTS (
+    SELECT 1 FROM tasks t JOIN projects p ON p.id = t.project_id
+    WHERE t.id = comments.task_id AND t.project_id = sqlc.arg('project_id')::uuid AND p.deleted_at IS NULL
+  );
+
+-- name: ListComments :many
+-- Oldest first, so replies follow the comments they answer.
+SELECT id, task_id, parent_id, author_id, body, created_at, updated_at
+FROM comments
+WHERE comments.task_id = sqlc.arg('task_id')
+  AND EXISTS (
+    SELECT 1 FROM tasks t JOIN projects p ON p.id = t.project_id
+    WHERE t.id = comments.task_id AND t.project_id = sqlc.arg('project_id')::uuid AND p.deleted_at IS NULL
+  )
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountComments :one
+SELECT count(*)
+FROM comments
+WHERE comments.task_id = sqlc.arg('task_id')
+  AND EXISTS (
+    SELECT 1 FROM tasks t JOIN projects p ON p.id = t.project_id
+    WHERE t.id = comments.task_id AND t.project_id = sqlc.arg('project_id')::uuid AND p.deleted_at IS NULL
+  );
+
+-- name: GetCommentForUpdate :one
+-- GetComment, locking the comment for a change that is audited.
+SELECT id, task_id, parent_id, author_id, body, created_at, updated_at
+FROM comments
+WHERE comments.id = sqlc.arg('id') AND comments.task_id = sqlc.arg('task_id')
+  AND EXISTS (
+    SELECT 1 FROM tasks t JOIN projects p ON p.id = t.project_id
+    WHERE t.id = comments.task_id AND t.project_id = sqlc.arg('project_id')::uuid AND p.deleted_at IS NULL
+  )
+FOR UPDATE OF comments;
+
+-- name: UpdateComment :one
+UPDATE comments
+SET
+  body = sqlc.arg('body'),
+  updated_at = sqlc.arg('updated_at')::timestamptz
+WHERE comments.id = sqlc.arg('id') AND comments.task_id = sqlc.arg('task_id')
+  AND EXISTS (
+    SELECT 1 FROM tasks t JOIN projects p ON p.id = t.project_id
+    WHERE t.id = comments.task_id AND t.project_id = sqlc.arg('project_id')::uuid AND p.deleted_at IS NULL
+  )
+RETURNING id, task_id, parent_id, author_id, body, created_at, updated_at;
+
+-- name: DeleteComment :execrows
+-- Replies are removed with their parent (ON DELETE CASCADE).
+DELETE FROM comments
+WHERE comments.id = sqlc.arg('id') AND comments.task_id = sqlc.arg('task_id')
+  AND EXISTS (
+    SELECT 1 FROM tasks t JOIN projects p ON p.id = t.project_id
+    WHERE t.id = comments.task_id AND t.project_id = sqlc.arg('project_id')::uuid AND p.deleted_at IS NULL
+  );
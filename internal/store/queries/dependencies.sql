@@ -0,0 +1,75 @@
+-- name: AddTaskBlocker :execrows
+-- Adding a blocker twice is a no-op. Both tasks must be in the same project.
+INSERT INTO task_dependencies (task_id, blocker_id, created_at)
+SELECT t.id, b.id, sqlc.arg('created_at')
+FROM tasks t
+JOIN tasks b ON b.project_id = t.project_id
+WHERE t.id = sqlc.arg('task_id')::uuid AND b.id = sqlc.arg('blocker_id')::uuid
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveTaskBlocker :execrows
+DELETE FROM task_dependencies
+WHERE task_id = $1 AND blocker_id = $2;
+
+-- name: TaskDependsOn :one
+-- Reports whether task_id is blocked by blocker_id, directly or through
+-- other blockers.
+WITH RECURSIVE blockers AS (
+  SELECT d.blocker_id FROM task_dependencies d WHERE d.task_id = sqlc.arg('task_id')::uuid
+  UNION
+  SELECT d.blocker_id FROM task_dependencies d JOIN blockers b ON d.task_id = b.blocker_id
+)
+SELECT EXISTS (SELECT 1 FROM blockers WHERE blockers.blocker_id = sqlc.arg('blocker_id')::uuid);
+
+-- name: HasOpenBlockers :one
+-- Reports whether moving the task to status is held up by a blocker that is
+-- not done. Keeping the task in its current status never is.
+SELECT EXISTS (
+  SELECT 1
+  FROM task_dependencies d
+  JOIN tasks t ON t.id = d.task_id
+  JOIN tasks b ON b.id = d.blocker_id
+  WHERE d.task_id = sqlc.arg('task_id')::uuid
+    AND t.status <> sqlc.arg('status')::text
+    AND b.status_category <> 'done'
+);
+
+-- name: ListTaskBlockerIDs :many
+-- Blocker IDs of the given tasks, oldest dependency first within each task.
+SELECT task_id, blocker_id
+FROM task_dependencies
+WHERE task_id = ANY (sqlc.arg('task_ids')::uuid[])
+ORDER BY task_id, created_at, blocker_id;
+
+-- name: ListTaskBlockers :many
+SELECT tasks.id, tasks.project_id, tasks.title, tasks.description, tasks.status, tasks.created_at, tasks.assignee_id, tasks.due_date, tasks.priority, tasks.parent_task_id, tasks.status_category, tasks.position, tasks.number
+FROM tasks
+JOIN task_dependencies d ON d.blocker_id = tasks.id
+WHERE d.task_id = $1
+ORDER BY d.created_at, tasks.id;
+
+-- name: CountBlockedTasks :one
+SELECT count(*)
+FROM tasks
+WHERE tasks.project_id = sqlc.arg('project_id')
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+  AND tasks.status_category <> 'done'
+  AND EXISTS (
+    SELECT 1 FROM task_dependencies d JOIN tasks b ON b.id = d.blocker_id
+    WHERE d.task_id = tasks.id AND b.status_category <> 'done'
+  );
+
+-- name: ListBlockedTasks :many
+-- Open tasks of a live project with at least one blocker that is not done,
+-- newest first.
+SELECT id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
+FROM tasks
+WHERE tasks.project_id = sqlc.arg('project_id')
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+  AND tasks.status_category <> 'done'
+  AND EXISTS (
+    SELECT 1 FROM task_dependencies d JOIN tasks b ON b.id = d.blocker_id
+    WHERE d.task_id = tasks.id AND b.status_category <> 'done'
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
@@ -0,0 +1,66 @@
+-- name: InsertLabel :one
+-- Inserts nothing (no rows) when the project is missing or soft-deleted.
+INSERT INTO labels (id, project_id, name, color, created_at)
+SELECT
+  sqlc.arg('id')::uuid,
+  sqlc.arg('project_id')::uuid,
+  sqlc.arg('name')::text,
+  sqlc.arg('color')::text,
+  sqlc.arg('created_at')::timestamptz
+WHERE EXISTS (SELECT 1 FROM projects p WHERE p.id = sqlc.arg('project_id')::uuid AND p.deleted_at IS NULL)
+RETURNING id, project_id, name, color, created_at;
+
+-- name: GetLabel :one
+SELECT id, project_id, name, color, created_at
+FROM labels
+WHERE labels.project_id = $1 AND labels.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = labels.project_id AND p.deleted_at IS NULL);
+
+-- name: GetLabelForUpdate :one
+-- GetLabel, locking the label for a change that is audited.
+SELECT id, project_id, name, color, created_at
+FROM labels
+WHERE labels.project_id = $1 AND labels.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = labels.project_id AND p.deleted_at IS NULL)
+FOR UPDATE OF labels;
+
+-- name: ListLabels :many
+SELECT id, project_id, name, color, created_at
+FROM labels
+WHERE project_id = $1
+ORDER BY name COLLATE "C", id;
+
+-- name: UpdateLabel :one
+UPDATE labels
+SET
+  name = COALESCE(sqlc.narg('name'), name),
+  color = COALESCE(sqlc.narg('color'), color)
+WHERE labels.project_id = $1 AND labels.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = labels.project_id AND p.deleted_at IS NULL)
+RETURNING id, project_id, name, color, created_at;
+
+-- name: DeleteLabel :execrows
+DELETE FROM labels
+WHERE labels.project_id = $1 AND labels.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = labels.project_id AND p.deleted_at IS NULL);
+
+-- name: AttachLabel :execrows
+-- Attaching a label twice is a no-op. The label must belong to the task's project.
+INSERT INTO task_labels (task_id, label_id)
+SELECT t.id, l.id
+FROM tasks t
+JOIN labels l ON l.project_id = t.project_id
+WHERE t.id = sqlc.arg('task_id')::uuid AND l.id = sqlc.arg('label_id')::uuid
+ON CONFLICT DO NOTHING;
+
+-- name: DetachLabel :execrows
+DELETE FROM task_labels
+WHERE task_id = $1 AND label_id = $2;
+
+-- name: ListTaskLabels :many
+-- Labels of the given tasks, ordered by name within each task.
+SELECT tl.task_id, l.id, l.project_id, l.name, l.color, l.created_at
+FROM task_labels tl
+JOIN labels l ON l.id = tl.label_id
+WHERE tl.task_id = ANY (sqlc.arg('task_ids')::uuid[])
+ORDER BY tl.task_id, l.name COLLATE "C", l.id;
@@ -0,0 +1,26 @@
+-- name: GetProjectMember :one
+SELECT project_id, subject, role, created_at
+FROM project_members
+WHERE project_id = $1 AND subject = $2;
+
+-- name: ListProjectMembers :many
+SELECT project_id, subject, role, created_at
+FROM project_members
+WHERE project_id = $1
+ORDER BY created_at, subject;
+
+-- name: UpsertProjectMember :one
+-- Changing the role of an existing member keeps when they joined.
+INSERT INTO project_members (project_id, subject, role, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (project_id, subject) DO UPDATE SET role = EXCLUDED.role
+RETURNING project_id, subject, role, created_at;
+
+-- name: DeleteProjectMember :execrows
+DELETE FROM project_members
+WHERE project_id = $1 AND subject = $2;
+
+-- name: CountProjectOwners :one
+SELECT count(*)
+FROM project_members
+WHERE project_id = $1 AND role = 'owner';
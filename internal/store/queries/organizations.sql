@@ -0,0 +1,14 @@
+-- name: InsertOrganization :one
+INSERT INTO organizations (id, name, created_at)
+VALUES ($1, $2, $3)
+RETURNING id, name, created_at;
+
+-- name: GetOrganization :one
+SELECT id, name, created_at
+FROM organizations
+WHERE id = $1;
+
+-- name: ListOrganizations :many
+SELECT id, name, created_at
+FROM organizations
+ORDER BY created_at, id;
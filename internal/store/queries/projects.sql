@@ -1,14 +1,105 @@
 -- name: InsertProject :one
-INSERT INTO projects (id, name, created_at)
-VALUES ($1, $2, $3)
-RETURNING id, name, created_at;
+INSERT INTO projects (id, name, created_at, updated_at, description, owner_id, color, icon, key, workspace_id)
+VALUES ($1, $2, $3, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id;
 
 -- name: GetProject :one
-SELECT id, name, created_at
+-- Soft-deleted projects are invisible everywhere except RestoreProject and PurgeDeletedProjects.
+SELECT id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id
 FROM projects
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: ListProjects :many
-SELECT id, name, created_at
+SELECT id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id
 FROM projects
-ORDER BY created_at DESC, id DESC;
+WHERE (sqlc.arg('include_archived')::bool OR archived_at IS NULL)
+  AND (sqlc.arg('include_deleted')::bool OR deleted_at IS NULL)
+  AND (sqlc.narg('member')::text IS NULL OR EXISTS (
+    SELECT 1 FROM project_members m WHERE m.project_id = projects.id AND m.subject = sqlc.narg('member')::text))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListProjectsAfter :many
+-- Keyset page over projects_newest_idx: rows strictly older than the cursor.
+SELECT id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id
+FROM projects
+WHERE (created_at, id) < (sqlc.arg('after_created_at')::timestamptz, sqlc.arg('after_id')::uuid)
+  AND (sqlc.arg('include_archived')::bool OR archived_at IS NULL)
+  AND (sqlc.arg('include_deleted')::bool OR deleted_at IS NULL)
+  AND (sqlc.narg('member')::text IS NULL OR EXISTS (
+    SELECT 1 FROM project_members m WHERE m.project_id = projects.id AND m.subject = sqlc.narg('member')::text))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CountProjects :one
+SELECT count(*)
+FROM projects
+WHERE (sqlc.arg('include_archived')::bool OR archived_at IS NULL)
+  AND (sqlc.arg('include_deleted')::bool OR deleted_at IS NULL)
+  AND (sqlc.narg('member')::text IS NULL OR EXISTS (
+    SELECT 1 FROM project_members m WHERE m.project_id = projects.id AND m.subject = sqlc.narg('member')::text));
+
+-- name: UpdateProject :one
+UPDATE projects
+SET
+  name = COALESCE(sqlc.narg('name'), name),
+  description = COALESCE(sqlc.narg('description'), description),
+  owner_id = CASE WHEN sqlc.arg('set_owner')::bool THEN sqlc.narg('owner_id')::uuid ELSE owner_id END,
+  color = COALESCE(sqlc.narg('color'), color),
+  icon = COALESCE(sqlc.narg('icon'), icon),
+  key = CASE WHEN sqlc.arg('set_key')::bool THEN sqlc.narg('key')::text ELSE key END,
+  updated_at = sqlc.arg('updated_at')::timestamptz
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id;
+
+-- name: ArchiveProject :one
+UPDATE projects
+SET archived_at = COALESCE(archived_at, sqlc.arg('archived_at')::timestamptz)
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id;
+
+-- name: RestoreProject :one
+UPDATE projects
+SET archived_at = NULL, deleted_at = NULL
+WHERE id = $1
+RETURNING id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id;
+
+-- name: SoftDeleteProject :execrows
+UPDATE projects
+SET deleted_at = sqlc.arg('deleted_at')::timestamptz
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: PurgeDeletedProjects :many
+-- Tasks are removed by the ON DELETE CASCADE on tasks.project_id.
+DELETE FROM projects
+WHERE deleted_at < sqlc.arg('deleted_before')::timestamptz
+RETURNING id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id;
+
+-- name: GetProjectForUpdate :one
+-- Locks a project, soft-deleted or not, for a change that is audited.
+SELECT id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id
+FROM projects
+WHERE id = $1
+FOR UPDATE;
+
+-- name: LockProject :one
+-- Serialises changes to a project's task hierarchy, dependencies or workflow
+-- for the rest of the transaction.
+SELECT id, workspace_id
+FROM projects
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: LockProjectShared :one
+-- Held while a task's status is checked against the workflow, so the
+-- workflow cannot change underneath (UpdateWorkflow takes LockProject).
+SELECT id
+FROM projects
+WHERE id = $1 AND deleted_at IS NULL
+FOR SHARE;
+
+-- name: ListProjectKeys :many
+-- Keys of the given projects, for building task identifiers.
+SELECT id, key
+FROM projects
+WHERE id = ANY (sqlc.arg('ids')::uuid[]) AND key IS NOT NULL;
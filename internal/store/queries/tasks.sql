@@ -1,19 +1,236 @@
 -- name: InsertTask :one
-INSERT INTO tasks (id, project_id, title, description, status, created_at)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, project_id, title, description, status, created_at;
+-- Inserts nothing (no rows) when the project is missing or soft-deleted.
+-- The task takes the next number from the project's counter row, whose row
+-- lock serialises concurrent inserts, and goes to the bottom of its status
+-- column.
+WITH counter AS (
+  INSERT INTO project_task_counters (project_id, last_number)
+  SELECT p.id, 1 FROM projects p
+  WHERE p.id = sqlc.arg('project_id')::uuid AND p.deleted_at IS NULL
+  ON CONFLICT (project_id) DO UPDATE SET last_number = project_task_counters.last_number + 1
+  RETURNING last_number
+)
+INSERT INTO tasks (id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number)
+SELECT
+  sqlc.arg('id')::uuid,
+  sqlc.arg('project_id')::uuid,
+  sqlc.arg('title')::text,
+  sqlc.arg('description')::text,
+  sqlc.arg('status')::text,
+  sqlc.arg('created_at')::timestamptz,
+  sqlc.narg('assignee_id')::uuid,
+  sqlc.narg('due_date')::timestamptz,
+  sqlc.arg('priority')::text,
+  sqlc.narg('parent_task_id')::uuid,
+  sqlc.arg('status_category')::text,
+  (SELECT COALESCE(max(c.position), 0) + 1024 FROM tasks c
+   WHERE c.project_id = sqlc.arg('project_id')::uuid AND c.status = sqlc.arg('status')::text),
+  counter.last_number
+FROM counter
+RETURNING id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number;
+
+-- name: GetTask :one
+SELECT id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
+FROM tasks
+WHERE tasks.project_id = $1 AND tasks.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL);
+
+-- name: GetTaskForUpdate :one
+-- GetTask, locking the task for a change that is audited.
+SELECT id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
+FROM tasks
+WHERE tasks.project_id = $1 AND tasks.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+FOR UPDATE OF tasks;
+
+-- name: GetTaskByNumber :one
+SELECT id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
+FROM tasks
+WHERE tasks.project_id = $1 AND tasks.number = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL);
 
 -- name: ListTasks :many
-SELECT id, project_id, title, description, status, created_at
+-- Optional filters are skipped when NULL. Sort keys other than "title" and
+-- "created_at" fall through to the default newest-first order.
+SELECT id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
+FROM tasks
+WHERE tasks.project_id = sqlc.arg('project_id')
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+  AND (sqlc.narg('statuses')::text[] IS NULL OR status = ANY (sqlc.narg('statuses')::text[]))
+  AND (sqlc.narg('title_pattern')::text IS NULL OR title ILIKE sqlc.narg('title_pattern')::text)
+  AND (sqlc.narg('due_before')::timestamptz IS NULL OR due_date < sqlc.narg('due_before')::timestamptz)
+  AND (sqlc.narg('due_after')::timestamptz IS NULL OR due_date > sqlc.narg('due_after')::timestamptz)
+  AND (sqlc.narg('labels')::text[] IS NULL OR EXISTS (
+    SELECT 1 FROM task_labels tl JOIN labels l ON l.id = tl.label_id
+    WHERE tl.task_id = tasks.id AND l.name = ANY (sqlc.narg('labels')::text[])
+  ))
+  AND (sqlc.narg('parent_task_id')::uuid IS NULL OR tasks.parent_task_id = sqlc.narg('parent_task_id')::uuid)
+ORDER BY
+  CASE WHEN sqlc.arg('sort')::text = 'title' THEN title COLLATE "C" END ASC,
+  CASE WHEN sqlc.arg('sort')::text = 'created_at' THEN created_at END ASC,
+  CASE WHEN sqlc.arg('sort')::text = 'created_at' THEN id END ASC,
+  created_at DESC,
+  id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListTasksAfter :many
+-- Keyset page over tasks_project_newest_idx: rows strictly older than the cursor.
+SELECT id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
 FROM tasks
-WHERE project_id = $1
-ORDER BY created_at DESC, id DESC;
+WHERE tasks.project_id = sqlc.arg('project_id')
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+  AND (created_at, id) < (sqlc.arg('after_created_at')::timestamptz, sqlc.arg('after_id')::uuid)
+  AND (sqlc.narg('statuses')::text[] IS NULL OR status = ANY (sqlc.narg('statuses')::text[]))
+  AND (sqlc.narg('title_pattern')::text IS NULL OR title ILIKE sqlc.narg('title_pattern')::text)
+  AND (sqlc.narg('due_before')::timestamptz IS NULL OR due_date < sqlc.narg('due_before')::timestamptz)
+  AND (sqlc.narg('due_after')::timestamptz IS NULL OR due_date > sqlc.narg('due_after')::timestamptz)
+  AND (sqlc.narg('labels')::text[] IS NULL OR EXISTS (
+    SELECT 1 FROM task_labels tl JOIN labels l ON l.id = tl.label_id
+    WHERE tl.task_id = tasks.id AND l.name = ANY (sqlc.narg('labels')::text[])
+  ))
+  AND (sqlc.narg('parent_task_id')::uuid IS NULL OR tasks.parent_task_id = sqlc.narg('parent_task_id')::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CountTasks :one
+SELECT count(*)
+FROM tasks
+WHERE tasks.project_id = sqlc.arg('project_id')
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+  AND (sqlc.narg('statuses')::text[] IS NULL OR status = ANY (sqlc.narg('statuses')::text[]))
+  AND (sqlc.narg('title_pattern')::text IS NULL OR title ILIKE sqlc.narg('title_pattern')::text)
+  AND (sqlc.narg('due_before')::timestamptz IS NULL OR due_date < sqlc.narg('due_before')::timestamptz)
+  AND (sqlc.narg('due_after')::timestamptz IS NULL OR due_date > sqlc.narg('due_after')::timestamptz)
+  AND (sqlc.narg('labels')::text[] IS NULL OR EXISTS (
+    SELECT 1 FROM task_labels tl JOIN labels l ON l.id = tl.label_id
+    WHERE tl.task_id = tasks.id AND l.name = ANY (sqlc.narg('labels')::text[])
+  ))
+  AND (sqlc.narg('parent_task_id')::uuid IS NULL OR tasks.parent_task_id = sqlc.narg('parent_task_id')::uuid);
 
 -- name: UpdateTask :one
 UPDATE tasks
 SET
   title = COALESCE(sqlc.narg('title'), title),
   description = COALESCE(sqlc.narg('description'), description),
-  status = COALESCE(sqlc.narg('status'), status)
-WHERE project_id = $1 AND id = $2
-RETURNING id, project_id, title, description, status, created_at;
+  status = COALESCE(sqlc.narg('status'), status),
+  status_category = COALESCE(sqlc.narg('status_category'), status_category),
+  position = COALESCE(sqlc.narg('position')::float8, position),
+  assignee_id = CASE WHEN sqlc.arg('set_assignee')::bool THEN sqlc.narg('assignee_id')::uuid ELSE assignee_id END,
+  due_date = CASE WHEN sqlc.arg('set_due_date')::bool THEN sqlc.narg('due_date')::timestamptz ELSE due_date END,
+  priority = COALESCE(sqlc.narg('priority'), priority),
+  parent_task_id = CASE WHEN sqlc.arg('set_parent')::bool THEN sqlc.narg('parent_task_id')::uuid ELSE parent_task_id END
+WHERE tasks.project_id = $1 AND tasks.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+RETURNING id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number;
+
+-- name: DeleteTask :execrows
+DELETE FROM tasks
+WHERE tasks.project_id = $1 AND tasks.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL);
+
+-- name: ListUserTasks :many
+-- Tasks assigned to a user across all live projects, newest first.
+SELECT tasks.id, tasks.project_id, tasks.title, tasks.description, tasks.status, tasks.created_at, tasks.assignee_id, tasks.due_date, tasks.priority, tasks.parent_task_id, tasks.status_category, tasks.position, tasks.number
+FROM tasks
+JOIN projects p ON p.id = tasks.project_id AND p.deleted_at IS NULL
+WHERE tasks.assignee_id = sqlc.arg('assignee_id')::uuid
+  AND (sqlc.narg('statuses')::text[] IS NULL OR tasks.status = ANY (sqlc.narg('statuses')::text[]))
+  AND (sqlc.narg('member')::text IS NULL OR EXISTS (
+    SELECT 1 FROM project_members m WHERE m.project_id = tasks.project_id AND m.subject = sqlc.narg('member')::text))
+ORDER BY tasks.created_at DESC, tasks.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountUserTasks :one
+SELECT count(*)
+FROM tasks
+JOIN projects p ON p.id = tasks.project_id AND p.deleted_at IS NULL
+WHERE tasks.assignee_id = sqlc.arg('assignee_id')::uuid
+  AND (sqlc.narg('statuses')::text[] IS NULL OR tasks.status = ANY (sqlc.narg('statuses')::text[]))
+  AND (sqlc.narg('member')::text IS NULL OR EXISTS (
+    SELECT 1 FROM project_members m WHERE m.project_id = tasks.project_id AND m.subject = sqlc.narg('member')::text));
+
+-- name: ListOverdueTasks :many
+-- Open tasks past their due date across all live projects, earliest due first.
+SELECT tasks.id, tasks.project_id, tasks.title, tasks.description, tasks.status, tasks.created_at, tasks.assignee_id, tasks.due_date, tasks.priority, tasks.parent_task_id, tasks.status_category, tasks.position, tasks.number
+FROM tasks
+JOIN projects p ON p.id = tasks.project_id AND p.deleted_at IS NULL
+WHERE tasks.due_date < sqlc.arg('as_of')::timestamptz
+  AND tasks.status_category <> 'done'
+  AND (sqlc.narg('member')::text IS NULL OR EXISTS (
+    SELECT 1 FROM project_members m WHERE m.project_id = tasks.project_id AND m.subject = sqlc.narg('member')::text))
+ORDER BY tasks.due_date ASC, tasks.id ASC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountOverdueTasks :one
+SELECT count(*)
+FROM tasks
+JOIN projects p ON p.id = tasks.project_id AND p.deleted_at IS NULL
+WHERE tasks.due_date < sqlc.arg('as_of')::timestamptz
+  AND tasks.status_category <> 'done'
+  AND (sqlc.narg('member')::text IS NULL OR EXISTS (
+    SELECT 1 FROM project_members m WHERE m.project_id = tasks.project_id AND m.subject = sqlc.narg('member')::text));
+
+-- name: ListSubtaskRollups :many
+-- Direct subtask counts for each of the given parents that has any.
+SELECT
+  parent_task_id::uuid AS parent_task_id,
+  count(*) AS total,
+  count(*) FILTER (WHERE status_category = 'done') AS done
+FROM tasks
+WHERE parent_task_id = ANY (sqlc.arg('parent_ids')::uuid[])
+GROUP BY parent_task_id;
+
+-- name: TaskHasAncestor :one
+-- Reports whether ancestor_id is task_id itself or one of its ancestors.
+WITH RECURSIVE ancestors AS (
+  SELECT t.id, t.parent_task_id FROM tasks t WHERE t.id = sqlc.arg('task_id')::uuid
+  UNION
+  SELECT t.id, t.parent_task_id FROM tasks t JOIN ancestors a ON t.id = a.parent_task_id
+)
+SELECT EXISTS (SELECT 1 FROM ancestors WHERE ancestors.id = sqlc.arg('ancestor_id')::uuid);
+
+-- name: ReparentSubtasks :exec
+-- Moves the direct subtasks of a task up to that task's own parent.
+UPDATE tasks
+SET parent_task_id = (SELECT p.parent_task_id FROM tasks p WHERE p.id = sqlc.arg('task_id')::uuid)
+WHERE tasks.parent_task_id = sqlc.arg('task_id')::uuid;
+
+-- name: NextTaskPosition :one
+-- Position just below the bottom card of a status column.
+SELECT (COALESCE(max(position), 0) + 1024)::float8
+FROM tasks
+WHERE project_id = $1 AND status = $2;
+
+-- name: ListColumnPositions :many
+-- A status column in board order, leaving out the task being moved.
+SELECT id, position
+FROM tasks
+WHERE project_id = sqlc.arg('project_id') AND status = sqlc.arg('status')
+  AND id <> sqlc.arg('exclude_id')
+ORDER BY position, created_at, id;
+
+-- name: SetTaskPosition :exec
+UPDATE tasks
+SET position = $3
+WHERE project_id = $1 AND id = $2;
+
+-- name: ListBoardTasks :many
+-- Every task of a live project in board order within each status.
+SELECT id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
+FROM tasks
+WHERE tasks.project_id = $1
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+ORDER BY status, position, created_at, id;
+
+-- name: AssignTaskNumber :exec
+-- Gives a task that moved into a project the next number there.
+WITH counter AS (
+  INSERT INTO project_task_counters (project_id, last_number)
+  VALUES (sqlc.arg('project_id')::uuid, 1)
+  ON CONFLICT (project_id) DO UPDATE SET last_number = project_task_counters.last_number + 1
+  RETURNING last_number
+)
+UPDATE tasks
+SET number = counter.last_number
+FROM counter
+WHERE tasks.id = sqlc.arg('id')::uuid;
@@ -0,0 +1,18 @@
+-- name: InsertTemplate :one
+INSERT INTO project_templates (id, name, tasks, created_at, workspace_id)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, name, tasks, created_at, workspace_id;
+
+-- name: GetTemplate :one
+SELECT id, name, tasks, created_at, workspace_id
+FROM project_templates
+WHERE id = $1;
+
+-- name: ListTemplates :many
+SELECT id, name, tasks, created_at, workspace_id
+FROM project_templates
+ORDER BY name COLLATE "C", id;
+
+-- name: DeleteTemplate :execrows
+DELETE FROM project_templates
+WHERE id = $1;
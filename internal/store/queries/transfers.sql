@@ -0,0 +1,63 @@
+-- name: MoveTaskTree :many
+-- Moves a task and all of its subtasks to another project. It is a single
+-- statement so tasks_parent_fkey is only checked once the whole tree has
+-- moved. The task itself becomes top level in the target project.
+WITH RECURSIVE tree AS (
+  SELECT t.id FROM tasks t
+  WHERE t.project_id = sqlc.arg('project_id')::uuid AND t.id = sqlc.arg('id')::uuid
+  UNION
+  SELECT c.id FROM tasks c JOIN tree ON c.parent_task_id = tree.id
+)
+UPDATE tasks
+SET
+  project_id = sqlc.arg('target_project_id')::uuid,
+  parent_task_id = CASE WHEN tasks.id = sqlc.arg('id')::uuid THEN NULL ELSE tasks.parent_task_id END
+WHERE tasks.id IN (SELECT id FROM tree)
+RETURNING id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number;
+
+-- name: DeleteCrossProjectDependencies :many
+-- Drops dependencies of the given tasks whose other end is now in a
+-- different project.
+DELETE FROM task_dependencies d
+USING tasks t, tasks b
+WHERE t.id = d.task_id AND b.id = d.blocker_id
+  AND t.project_id <> b.project_id
+  AND (d.task_id = ANY (sqlc.arg('task_ids')::uuid[]) OR d.blocker_id = ANY (sqlc.arg('task_ids')::uuid[]))
+RETURNING d.task_id, d.blocker_id;
+
+-- name: InsertLabelIfMissing :one
+-- Inserts nothing (no rows) when the project already has a label by that name.
+INSERT INTO labels (id, project_id, name, color, created_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (project_id, name) DO NOTHING
+RETURNING id, project_id, name, color, created_at;
+
+-- name: GetLabelByName :one
+SELECT id, project_id, name, color, created_at
+FROM labels
+WHERE project_id = $1 AND name = $2;
+
+-- name: RelabelTasks :many
+-- Swaps one label for another on the given tasks, returning the tasks that
+-- had it.
+UPDATE task_labels
+SET label_id = sqlc.arg('new_label_id')::uuid
+WHERE task_id = ANY (sqlc.arg('task_ids')::uuid[]) AND label_id = sqlc.arg('old_label_id')::uuid
+RETURNING task_id;
+
+-- name: CountCommentsOnTasks :one
+SELECT count(*)
+FROM comments
+WHERE task_id = ANY (sqlc.arg('task_ids')::uuid[]);
+
+-- name: ListAllComments :many
+-- Every comment on a task, oldest first.
+SELECT id, task_id, parent_id, author_id, body, created_at, updated_at
+FROM comments
+WHERE task_id = $1
+ORDER BY created_at, id;
+
+-- name: CopyComment :one
+INSERT INTO comments (id, task_id, parent_id, author_id, body, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, task_id, parent_id, author_id, body, created_at, updated_at;
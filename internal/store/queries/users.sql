@@ -0,0 +1,9 @@
+-- name: InsertUser :one
+INSERT INTO users (id, name, email, created_at, workspace_id)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, name, email, created_at, workspace_id;
+
+-- name: GetUser :one
+SELECT id, name, email, created_at, workspace_id
+FROM users
+WHERE id = $1;
@@ -0,0 +1,24 @@
+-- name: GetWorkflow :one
+SELECT definition
+FROM project_workflows
+WHERE project_id = $1;
+
+-- name: UpsertWorkflow :exec
+INSERT INTO project_workflows (project_id, definition, updated_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (project_id) DO UPDATE
+SET definition = EXCLUDED.definition, updated_at = EXCLUDED.updated_at;
+
+-- name: CountTasksOutsideStatuses :one
+-- Tasks of the project whose status is not in the given list.
+SELECT count(*)
+FROM tasks
+WHERE project_id = sqlc.arg('project_id')
+  AND NOT (status = ANY (sqlc.arg('statuses')::text[]));
+
+-- name: SetTaskStatusCategory :exec
+UPDATE tasks
+SET status_category = sqlc.arg('category')::text
+WHERE project_id = sqlc.arg('project_id')
+  AND status = sqlc.arg('status')::text
+  AND status_category <> sqlc.arg('category')::text;
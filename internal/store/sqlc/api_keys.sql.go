@@ -0,0 +1,162 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: api_keys.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const getAPIKeyByHash = `-- name: GetAPIKeyByHash :one
+SELECT id, name, prefix, key_hash, admin, created_at, revoked_at, workspace_id
+FROM api_keys
+WHERE key_hash = $1
+`
+
+func (q *Queries) GetAPIKeyByHash(ctx context.Context, keyHash []byte) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, getAPIKeyByHash, keyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Admin,
+		&i.CreatedAt,
+		&i.RevokedAt,
+		&i.WorkspaceID,
+	)
+	return i, err
+}
+
+const getAPIKeyForUpdate = `-- name: GetAPIKeyForUpdate :one
+SELECT id, name, prefix, key_hash, admin, created_at, revoked_at, workspace_id
+FROM api_keys
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetAPIKeyForUpdate(ctx context.Context, id uuid.UUID) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, getAPIKeyForUpdate, id)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Admin,
+		&i.CreatedAt,
+		&i.RevokedAt,
+		&i.WorkspaceID,
+	)
+	return i, err
+}
+
+const insertAPIKey = `-- name: InsertAPIKey :one
+INSERT INTO api_keys (id, name, prefix, key_hash, admin, created_at, workspace_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, name, prefix, key_hash, admin, created_at, revoked_at, workspace_id
+`
+
+type InsertAPIKeyParams struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	KeyHash     []byte     `json:"key_hash"`
+	Admin       bool       `json:"admin"`
+	CreatedAt   time.Time  `json:"created_at"`
+	WorkspaceID *uuid.UUID `json:"workspace_id"`
+}
+
+func (q *Queries) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, insertAPIKey,
+		arg.ID,
+		arg.Name,
+		arg.Prefix,
+		arg.KeyHash,
+		arg.Admin,
+		arg.CreatedAt,
+		arg.WorkspaceID,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Admin,
+		&i.CreatedAt,
+		&i.RevokedAt,
+		&i.WorkspaceID,
+	)
+	return i, err
+}
+
+const listAPIKeys = `-- name: ListAPIKeys :many
+SELECT id, name, prefix, key_hash, admin, created_at, revoked_at, workspace_id
+FROM api_keys
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListAPIKeys(ctx context.Context) ([]ApiKey, error) {
+	rows, err := q.db.Query(ctx, listAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApiKey{}
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.Admin,
+			&i.CreatedAt,
+			&i.RevokedAt,
+			&i.WorkspaceID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = COALESCE(revoked_at, $2::timestamptz)
+WHERE id = $1
+RETURNING id, name, prefix, key_hash, admin, created_at, revoked_at, workspace_id
+`
+
+type RevokeAPIKeyParams struct {
+	ID        uuid.UUID `json:"id"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+// Revoking twice keeps the first revocation time.
+func (q *Queries) RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, revokeAPIKey, arg.ID, arg.RevokedAt)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Admin,
+		&i.CreatedAt,
+		&i.RevokedAt,
+		&i.WorkspaceID,
+	)
+	return i, err
+}
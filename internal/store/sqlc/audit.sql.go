@@ -0,0 +1,129 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: audit.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countAuditEntries = `-- name: CountAuditEntries :one
+SELECT count(*)
+FROM audit_log
+WHERE ($1::text IS NULL OR entity = $1::text)
+  AND ($2::text IS NULL OR entity_id = $2::text)
+`
+
+type CountAuditEntriesParams struct {
+	Entity   pgtype.Text `json:"entity"`
+	EntityID pgtype.Text `json:"entity_id"`
+}
+
+func (q *Queries) CountAuditEntries(ctx context.Context, arg CountAuditEntriesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countAuditEntries, arg.Entity, arg.EntityID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const insertAuditEntry = `-- name: InsertAuditEntry :exec
+INSERT INTO audit_log (workspace_id, actor, request_id, action, entity, entity_id, before, after, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+`
+
+type InsertAuditEntryParams struct {
+	WorkspaceID uuid.UUID   `json:"workspace_id"`
+	Actor       string      `json:"actor"`
+	RequestID   pgtype.Text `json:"request_id"`
+	Action      string      `json:"action"`
+	Entity      string      `json:"entity"`
+	EntityID    string      `json:"entity_id"`
+	Before      []byte      `json:"before"`
+	After       []byte      `json:"after"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+func (q *Queries) InsertAuditEntry(ctx context.Context, arg InsertAuditEntryParams) error {
+	_, err := q.db.Exec(ctx, insertAuditEntry,
+		arg.WorkspaceID,
+		arg.Actor,
+		arg.RequestID,
+		arg.Action,
+		arg.Entity,
+		arg.EntityID,
+		arg.Before,
+		arg.After,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const listAuditEntries = `-- name: ListAuditEntries :many
+SELECT id, workspace_id, actor, request_id, action, entity, entity_id, before, after, created_at
+FROM audit_log
+WHERE ($1::text IS NULL OR entity = $1::text)
+  AND ($2::text IS NULL OR entity_id = $2::text)
+ORDER BY id DESC
+LIMIT $4 OFFSET $3
+`
+
+type ListAuditEntriesParams struct {
+	Entity   pgtype.Text `json:"entity"`
+	EntityID pgtype.Text `json:"entity_id"`
+	Offset   int32       `json:"offset"`
+	Limit    int32       `json:"limit"`
+}
+
+// Newest first; the workspace is left to the row-level security policy.
+func (q *Queries) ListAuditEntries(ctx context.Context, arg ListAuditEntriesParams) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, listAuditEntries,
+		arg.Entity,
+		arg.EntityID,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditLog{}
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.WorkspaceID,
+			&i.Actor,
+			&i.RequestID,
+			&i.Action,
+			&i.Entity,
+			&i.EntityID,
+			&i.Before,
+			&i.After,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeAuditEntries = `-- name: PurgeAuditEntries :one
+SELECT purge_audit_log($1::timestamptz)::bigint AS purged
+`
+
+func (q *Queries) PurgeAuditEntries(ctx context.Context, recordedBefore time.Time) (int64, error) {
+	row := q.db.QueryRow(ctx, purgeAuditEntries, recordedBefore)
+	var purged int64
+	err := row.Scan(&purged)
+	return purged, err
+}
@@ -0,0 +1,272 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: comments.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const countComments = `-- name: CountComments :one
+SELECT count(*)
+FROM comments
+WHERE comments.task_id = $1
+  AND EXISTS (
+    SELECT 1 FROM tasks t JOIN projects p ON p.id = t.project_id
+    WHERE t.id = comments.task_id AND t.project_id = $2::uuid AND p.deleted_at IS NULL
+  )
+`
+
+type CountCommentsParams struct {
+	TaskID    uuid.UUID `json:"task_id"`
+	ProjectID uuid.UUID `json:"project_id"`
+}
+
+func (q *Queries) CountComments(ctx context.Context, arg CountCommentsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countComments, arg.TaskID, arg.ProjectID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteComment = `-- name: DeleteComment :execrows
+DELETE FROM comments
+WHERE comments.id = $1 AND comments.task_id = $2
+  AND EXISTS (
+    SELECT 1 FROM tasks t JOIN projects p ON p.id = t.project_id
+    WHERE t.id = comments.task_id AND t.project_id = $3::uuid AND p.deleted_at IS NULL
+  )
+`
+
+type DeleteCommentParams struct {
+	ID        uuid.UUID `json:"id"`
+	TaskID    uuid.UUID `json:"task_id"`
+	ProjectID uuid.UUID `json:"project_id"`
+}
+
+// Replies are removed with their parent (ON DELETE CASCADE).
+func (q *Queries) DeleteComment(ctx context.Context, arg DeleteCommentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteComment, arg.ID, arg.TaskID, arg.ProjectID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getComment = `-- name: GetComment :one
+SELECT id, task_id, parent_id, author_id, body, created_at, updated_at
+FROM comments
+WHERE comments.id = $1 AND comments.task_id = $2
+  AND EXISTS (
+    SELECT 1 FROM tasks t JOIN projects p ON p.id = t.project_id
+    WHERE t.id = comments.task_id AND t.project_id = $3::uuid AND p.deleted_at IS NULL
+  )
+`
+
+type GetCommentParams struct {
+	ID        uuid.UUID `json:"id"`
+	TaskID    uuid.UUID `json:"task_id"`
+	ProjectID uuid.UUID `json:"project_id"`
+}
+
+func (q *Queries) GetComment(ctx context.Context, arg GetCommentParams) (Comment, error) {
+	row := q.db.QueryRow(ctx, getComment, arg.ID, arg.TaskID, arg.ProjectID)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.ParentID,
+		&i.AuthorID,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getCommentForUpdate = `-- name: GetCommentForUpdate :one
+SELECT id, task_id, parent_id, author_id, body, created_at, updated_at
+FROM comments
+WHERE comments.id = $1 AND comments.task_id = $2
+  AND EXISTS (
+    SELECT 1 FROM tasks t JOIN projects p ON p.id = t.project_id
+    WHERE t.id = comments.task_id AND t.project_id = $3::uuid AND p.deleted_at IS NULL
+  )
+FOR UPDATE OF comments
+`
+
+type GetCommentForUpdateParams struct {
+	ID        uuid.UUID `json:"id"`
+	TaskID    uuid.UUID `json:"task_id"`
+	ProjectID uuid.UUID `json:"project_id"`
+}
+
+// GetComment, locking the comment for a change that is audited.
+func (q *Queries) GetCommentForUpdate(ctx context.Context, arg GetCommentForUpdateParams) (Comment, error) {
+	row := q.db.QueryRow(ctx, getCommentForUpdate, arg.ID, arg.TaskID, arg.ProjectID)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.ParentID,
+		&i.AuthorID,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const insertComment = `-- name: InsertComment :one
+INSERT INTO comments (id, task_id, parent_id, author_id, body, created_at)
+SELECT
+  $1::uuid,
+  $2::uuid,
+  $3::uuid,
+  $4::uuid,
+  $5::text,
+  $6::timestamptz
+WHERE EXISTS (
+    SELECT 1 FROM tasks t JOIN projects p ON p.id = t.project_id
+    WHERE t.id = $2::uuid AND t.project_id = $7::uuid AND p.deleted_at IS NULL
+  )
+  AND ($3::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM comments c WHERE c.id = $3::uuid AND c.task_id = $2::uuid
+  ))
+RETURNING id, task_id, parent_id, author_id, body, created_at, updated_at
+`
+
+type InsertCommentParams struct {
+	ID        uuid.UUID  `json:"id"`
+	TaskID    uuid.UUID  `json:"task_id"`
+	ParentID  *uuid.UUID `json:"parent_id"`
+	AuthorID  uuid.UUID  `json:"author_id"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	ProjectID uuid.UUID  `json:"project_id"`
+}
+
+// Inserts nothing (no rows) when the task is missing, its project is missing
+// or soft-deleted, or the parent is not a comment on the same task.
+func (q *Queries) InsertComment(ctx context.Context, arg InsertCommentParams) (Comment, error) {
+	row := q.db.QueryRow(ctx, insertComment,
+		arg.ID,
+		arg.TaskID,
+		arg.ParentID,
+		arg.AuthorID,
+		arg.Body,
+		arg.CreatedAt,
+		arg.ProjectID,
+	)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.ParentID,
+		&i.AuthorID,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listComments = `-- name: ListComments :many
+SELECT id, task_id, parent_id, author_id, body, created_at, updated_at
+FROM comments
+WHERE comments.task_id = $1
+  AND EXISTS (
+    SELECT 1 FROM tasks t JOIN projects p ON p.id = t.project_id
+    WHERE t.id = comments.task_id AND t.project_id = $2::uuid AND p.deleted_at IS NULL
+  )
+ORDER BY created_at, id
+LIMIT $4 OFFSET $3
+`
+
+type ListCommentsParams struct {
+	TaskID    uuid.UUID `json:"task_id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Offset    int32     `json:"offset"`
+	Limit     int32     `json:"limit"`
+}
+
+// Oldest first, so replies follow the comments they answer.
+func (q *Queries) ListComments(ctx context.Context, arg ListCommentsParams) ([]Comment, error) {
+	rows, err := q.db.Query(ctx, listComments,
+		arg.TaskID,
+		arg.ProjectID,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Comment{}
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.ParentID,
+			&i.AuthorID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateComment = `-- name: UpdateComment :one
+UPDATE comments
+SET
+  body = $1,
+  updated_at = $2::timestamptz
+WHERE comments.id = $3 AND comments.task_id = $4
+  AND EXISTS (
+    SELECT 1 FROM tasks t JOIN projects p ON p.id = t.project_id
+    WHERE t.id = comments.task_id AND t.project_id = $5::uuid AND p.deleted_at IS NULL
+  )
+RETURNING id, task_id, parent_id, author_id, body, created_at, updated_at
+`
+
+type UpdateCommentParams struct {
+	Body      string    `json:"body"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ID        uuid.UUID `json:"id"`
+	TaskID    uuid.UUID `json:"task_id"`
+	ProjectID uuid.UUID `json:"project_id"`
+}
+
+func (q *Queries) UpdateComment(ctx context.Context, arg UpdateCommentParams) (Comment, error) {
+	row := q.db.QueryRow(ctx, updateComment,
+		arg.Body,
+		arg.UpdatedAt,
+		arg.ID,
+		arg.TaskID,
+		arg.ProjectID,
+	)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.ParentID,
+		&i.AuthorID,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,254 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: dependencies.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const addTaskBlocker = `-- name: AddTaskBlocker :execrows
+INSERT INTO task_dependencies (task_id, blocker_id, created_at)
+SELECT t.id, b.id, $1
+FROM tasks t
+JOIN tasks b ON b.project_id = t.project_id
+WHERE t.id = $2::uuid AND b.id = $3::uuid
+ON CONFLICT DO NOTHING
+`
+
+type AddTaskBlockerParams struct {
+	CreatedAt time.Time `json:"created_at"`
+	TaskID    uuid.UUID `json:"task_id"`
+	BlockerID uuid.UUID `json:"blocker_id"`
+}
+
+// Adding a blocker twice is a no-op. Both tasks must be in the same project.
+func (q *Queries) AddTaskBlocker(ctx context.Context, arg AddTaskBlockerParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addTaskBlocker, arg.CreatedAt, arg.TaskID, arg.BlockerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countBlockedTasks = `-- name: CountBlockedTasks :one
+SELECT count(*)
+FROM tasks
+WHERE tasks.project_id = $1
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+  AND tasks.status_category <> 'done'
+  AND EXISTS (
+    SELECT 1 FROM task_dependencies d JOIN tasks b ON b.id = d.blocker_id
+    WHERE d.task_id = tasks.id AND b.status_category <> 'done'
+  )
+`
+
+func (q *Queries) CountBlockedTasks(ctx context.Context, projectID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countBlockedTasks, projectID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const hasOpenBlockers = `-- name: HasOpenBlockers :one
+SELECT EXISTS (
+  SELECT 1
+  FROM task_dependencies d
+  JOIN tasks t ON t.id = d.task_id
+  JOIN tasks b ON b.id = d.blocker_id
+  WHERE d.task_id = $1::uuid
+    AND t.status <> $2::text
+    AND b.status_category <> 'done'
+)
+`
+
+type HasOpenBlockersParams struct {
+	TaskID uuid.UUID `json:"task_id"`
+	Status string    `json:"status"`
+}
+
+// Reports whether moving the task to status is held up by a blocker that is
+// not done. Keeping the task in its current status never is.
+func (q *Queries) HasOpenBlockers(ctx context.Context, arg HasOpenBlockersParams) (bool, error) {
+	row := q.db.QueryRow(ctx, hasOpenBlockers, arg.TaskID, arg.Status)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listBlockedTasks = `-- name: ListBlockedTasks :many
+SELECT id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
+FROM tasks
+WHERE tasks.project_id = $1
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+  AND tasks.status_category <> 'done'
+  AND EXISTS (
+    SELECT 1 FROM task_dependencies d JOIN tasks b ON b.id = d.blocker_id
+    WHERE d.task_id = tasks.id AND b.status_category <> 'done'
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $3 OFFSET $2
+`
+
+type ListBlockedTasksParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Offset    int32     `json:"offset"`
+	Limit     int32     `json:"limit"`
+}
+
+// Open tasks of a live project with at least one blocker that is not done,
+// newest first.
+func (q *Queries) ListBlockedTasks(ctx context.Context, arg ListBlockedTasksParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, listBlockedTasks, arg.ProjectID, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.CreatedAt,
+			&i.AssigneeID,
+			&i.DueDate,
+			&i.Priority,
+			&i.ParentTaskID,
+			&i.StatusCategory,
+			&i.Position,
+			&i.Number,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskBlockerIDs = `-- name: ListTaskBlockerIDs :many
+SELECT task_id, blocker_id
+FROM task_dependencies
+WHERE task_id = ANY ($1::uuid[])
+ORDER BY task_id, created_at, blocker_id
+`
+
+type ListTaskBlockerIDsRow struct {
+	TaskID    uuid.UUID `json:"task_id"`
+	BlockerID uuid.UUID `json:"blocker_id"`
+}
+
+// Blocker IDs of the given tasks, oldest dependency first within each task.
+func (q *Queries) ListTaskBlockerIDs(ctx context.Context, taskIds []uuid.UUID) ([]ListTaskBlockerIDsRow, error) {
+	rows, err := q.db.Query(ctx, listTaskBlockerIDs, taskIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTaskBlockerIDsRow{}
+	for rows.Next() {
+		var i ListTaskBlockerIDsRow
+		if err := rows.Scan(&i.TaskID, &i.BlockerID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskBlockers = `-- name: ListTaskBlockers :many
+SELECT tasks.id, tasks.project_id, tasks.title, tasks.description, tasks.status, tasks.created_at, tasks.assignee_id, tasks.due_date, tasks.priority, tasks.parent_task_id, tasks.status_category, tasks.position, tasks.number
+FROM tasks
+JOIN task_dependencies d ON d.blocker_id = tasks.id
+WHERE d.task_id = $1
+ORDER BY d.created_at, tasks.id
+`
+
+func (q *Queries) ListTaskBlockers(ctx context.Context, taskID uuid.UUID) ([]Task, error) {
+	rows, err := q.db.Query(ctx, listTaskBlockers, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.CreatedAt,
+			&i.AssigneeID,
+			&i.DueDate,
+			&i.Priority,
+			&i.ParentTaskID,
+			&i.StatusCategory,
+			&i.Position,
+			&i.Number,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeTaskBlocker = `-- name: RemoveTaskBlocker :execrows
+DELETE FROM task_dependencies
+WHERE task_id = $1 AND blocker_id = $2
+`
+
+type RemoveTaskBlockerParams struct {
+	TaskID    uuid.UUID `json:"task_id"`
+	BlockerID uuid.UUID `json:"blocker_id"`
+}
+
+func (q *Queries) RemoveTaskBlocker(ctx context.Context, arg RemoveTaskBlockerParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeTaskBlocker, arg.TaskID, arg.BlockerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const taskDependsOn = `-- name: TaskDependsOn :one
+WITH RECURSIVE blockers AS (
+  SELECT d.blocker_id FROM task_dependencies d WHERE d.task_id = $2::uuid
+  UNION
+  SELECT d.blocker_id FROM task_dependencies d JOIN blockers b ON d.task_id = b.blocker_id
+)
+SELECT EXISTS (SELECT 1 FROM blockers WHERE blockers.blocker_id = $1::uuid)
+`
+
+type TaskDependsOnParams struct {
+	BlockerID uuid.UUID `json:"blocker_id"`
+	TaskID    uuid.UUID `json:"task_id"`
+}
+
+// Reports whether task_id is blocked by blocker_id, directly or through
+// other blockers.
+func (q *Queries) TaskDependsOn(ctx context.Context, arg TaskDependsOnParams) (bool, error) {
+	row := q.db.QueryRow(ctx, taskDependsOn, arg.BlockerID, arg.TaskID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
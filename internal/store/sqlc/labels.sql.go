@@ -0,0 +1,279 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: labels.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const attachLabel = `-- name: AttachLabel :execrows
+INSERT INTO task_labels (task_id, label_id)
+SELECT t.id, l.id
+FROM tasks t
+JOIN labels l ON l.project_id = t.project_id
+WHERE t.id = $1::uuid AND l.id = $2::uuid
+ON CONFLICT DO NOTHING
+`
+
+type AttachLabelParams struct {
+	TaskID  uuid.UUID `json:"task_id"`
+	LabelID uuid.UUID `json:"label_id"`
+}
+
+// Attaching a label twice is a no-op. The label must belong to the task's project.
+func (q *Queries) AttachLabel(ctx context.Context, arg AttachLabelParams) (int64, error) {
+	result, err := q.db.Exec(ctx, attachLabel, arg.TaskID, arg.LabelID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteLabel = `-- name: DeleteLabel :execrows
+DELETE FROM labels
+WHERE labels.project_id = $1 AND labels.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = labels.project_id AND p.deleted_at IS NULL)
+`
+
+type DeleteLabelParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func (q *Queries) DeleteLabel(ctx context.Context, arg DeleteLabelParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteLabel, arg.ProjectID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const detachLabel = `-- name: DetachLabel :execrows
+DELETE FROM task_labels
+WHERE task_id = $1 AND label_id = $2
+`
+
+type DetachLabelParams struct {
+	TaskID  uuid.UUID `json:"task_id"`
+	LabelID uuid.UUID `json:"label_id"`
+}
+
+func (q *Queries) DetachLabel(ctx context.Context, arg DetachLabelParams) (int64, error) {
+	result, err := q.db.Exec(ctx, detachLabel, arg.TaskID, arg.LabelID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getLabel = `-- name: GetLabel :one
+SELECT id, project_id, name, color, created_at
+FROM labels
+WHERE labels.project_id = $1 AND labels.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = labels.project_id AND p.deleted_at IS NULL)
+`
+
+type GetLabelParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func (q *Queries) GetLabel(ctx context.Context, arg GetLabelParams) (Label, error) {
+	row := q.db.QueryRow(ctx, getLabel, arg.ProjectID, arg.ID)
+	var i Label
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Color,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLabelForUpdate = `-- name: GetLabelForUpdate :one
+SELECT id, project_id, name, color, created_at
+FROM labels
+WHERE labels.project_id = $1 AND labels.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = labels.project_id AND p.deleted_at IS NULL)
+FOR UPDATE OF labels
+`
+
+type GetLabelForUpdateParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// GetLabel, locking the label for a change that is audited.
+func (q *Queries) GetLabelForUpdate(ctx context.Context, arg GetLabelForUpdateParams) (Label, error) {
+	row := q.db.QueryRow(ctx, getLabelForUpdate, arg.ProjectID, arg.ID)
+	var i Label
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Color,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertLabel = `-- name: InsertLabel :one
+INSERT INTO labels (id, project_id, name, color, created_at)
+SELECT
+  $1::uuid,
+  $2::uuid,
+  $3::text,
+  $4::text,
+  $5::timestamptz
+WHERE EXISTS (SELECT 1 FROM projects p WHERE p.id = $2::uuid AND p.deleted_at IS NULL)
+RETURNING id, project_id, name, color, created_at
+`
+
+type InsertLabelParams struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Inserts nothing (no rows) when the project is missing or soft-deleted.
+func (q *Queries) InsertLabel(ctx context.Context, arg InsertLabelParams) (Label, error) {
+	row := q.db.QueryRow(ctx, insertLabel,
+		arg.ID,
+		arg.ProjectID,
+		arg.Name,
+		arg.Color,
+		arg.CreatedAt,
+	)
+	var i Label
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Color,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listLabels = `-- name: ListLabels :many
+SELECT id, project_id, name, color, created_at
+FROM labels
+WHERE project_id = $1
+ORDER BY name COLLATE "C", id
+`
+
+func (q *Queries) ListLabels(ctx context.Context, projectID uuid.UUID) ([]Label, error) {
+	rows, err := q.db.Query(ctx, listLabels, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Label{}
+	for rows.Next() {
+		var i Label
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Color,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskLabels = `-- name: ListTaskLabels :many
+SELECT tl.task_id, l.id, l.project_id, l.name, l.color, l.created_at
+FROM task_labels tl
+JOIN labels l ON l.id = tl.label_id
+WHERE tl.task_id = ANY ($1::uuid[])
+ORDER BY tl.task_id, l.name COLLATE "C", l.id
+`
+
+type ListTaskLabelsRow struct {
+	TaskID    uuid.UUID `json:"task_id"`
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Labels of the given tasks, ordered by name within each task.
+func (q *Queries) ListTaskLabels(ctx context.Context, taskIds []uuid.UUID) ([]ListTaskLabelsRow, error) {
+	rows, err := q.db.Query(ctx, listTaskLabels, taskIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTaskLabelsRow{}
+	for rows.Next() {
+		var i ListTaskLabelsRow
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.ID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Color,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateLabel = `-- name: UpdateLabel :one
+UPDATE labels
+SET
+  name = COALESCE($3, name),
+  color = COALESCE($4, color)
+WHERE labels.project_id = $1 AND labels.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = labels.project_id AND p.deleted_at IS NULL)
+RETURNING id, project_id, name, color, created_at
+`
+
+type UpdateLabelParams struct {
+	ProjectID uuid.UUID   `json:"project_id"`
+	ID        uuid.UUID   `json:"id"`
+	Name      pgtype.Text `json:"name"`
+	Color     pgtype.Text `json:"color"`
+}
+
+func (q *Queries) UpdateLabel(ctx context.Context, arg UpdateLabelParams) (Label, error) {
+	row := q.db.QueryRow(ctx, updateLabel,
+		arg.ProjectID,
+		arg.ID,
+		arg.Name,
+		arg.Color,
+	)
+	var i Label
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Color,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,131 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: members.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const countProjectOwners = `-- name: CountProjectOwners :one
+SELECT count(*)
+FROM project_members
+WHERE project_id = $1 AND role = 'owner'
+`
+
+func (q *Queries) CountProjectOwners(ctx context.Context, projectID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countProjectOwners, projectID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteProjectMember = `-- name: DeleteProjectMember :execrows
+DELETE FROM project_members
+WHERE project_id = $1 AND subject = $2
+`
+
+type DeleteProjectMemberParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Subject   string    `json:"subject"`
+}
+
+func (q *Queries) DeleteProjectMember(ctx context.Context, arg DeleteProjectMemberParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteProjectMember, arg.ProjectID, arg.Subject)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getProjectMember = `-- name: GetProjectMember :one
+SELECT project_id, subject, role, created_at
+FROM project_members
+WHERE project_id = $1 AND subject = $2
+`
+
+type GetProjectMemberParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Subject   string    `json:"subject"`
+}
+
+func (q *Queries) GetProjectMember(ctx context.Context, arg GetProjectMemberParams) (ProjectMember, error) {
+	row := q.db.QueryRow(ctx, getProjectMember, arg.ProjectID, arg.Subject)
+	var i ProjectMember
+	err := row.Scan(
+		&i.ProjectID,
+		&i.Subject,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listProjectMembers = `-- name: ListProjectMembers :many
+SELECT project_id, subject, role, created_at
+FROM project_members
+WHERE project_id = $1
+ORDER BY created_at, subject
+`
+
+func (q *Queries) ListProjectMembers(ctx context.Context, projectID uuid.UUID) ([]ProjectMember, error) {
+	rows, err := q.db.Query(ctx, listProjectMembers, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ProjectMember{}
+	for rows.Next() {
+		var i ProjectMember
+		if err := rows.Scan(
+			&i.ProjectID,
+			&i.Subject,
+			&i.Role,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertProjectMember = `-- name: UpsertProjectMember :one
+INSERT INTO project_members (project_id, subject, role, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (project_id, subject) DO UPDATE SET role = EXCLUDED.role
+RETURNING project_id, subject, role, created_at
+`
+
+type UpsertProjectMemberParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Subject   string    `json:"subject"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Changing the role of an existing member keeps when they joined.
+func (q *Queries) UpsertProjectMember(ctx context.Context, arg UpsertProjectMemberParams) (ProjectMember, error) {
+	row := q.db.QueryRow(ctx, upsertProjectMember,
+		arg.ProjectID,
+		arg.Subject,
+		arg.Role,
+		arg.CreatedAt,
+	)
+	var i ProjectMember
+	err := row.Scan(
+		&i.ProjectID,
+		&i.Subject,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
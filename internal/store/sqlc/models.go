@@ -8,19 +8,129 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type Project struct {
+type ApiKey struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	KeyHash     []byte     `json:"key_hash"`
+	Admin       bool       `json:"admin"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	WorkspaceID *uuid.UUID `json:"workspace_id"`
+}
+
+type AuditLog struct {
+	ID          int64       `json:"id"`
+	WorkspaceID uuid.UUID   `json:"workspace_id"`
+	Actor       string      `json:"actor"`
+	RequestID   pgtype.Text `json:"request_id"`
+	Action      string      `json:"action"`
+	Entity      string      `json:"entity"`
+	EntityID    string      `json:"entity_id"`
+	Before      []byte      `json:"before"`
+	After       []byte      `json:"after"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+type Comment struct {
+	ID        uuid.UUID  `json:"id"`
+	TaskID    uuid.UUID  `json:"task_id"`
+	ParentID  *uuid.UUID `json:"parent_id"`
+	AuthorID  uuid.UUID  `json:"author_id"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type Label struct {
 	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
 	Name      string    `json:"name"`
+	Color     string    `json:"color"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type Organization struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Project struct {
+	ID          uuid.UUID   `json:"id"`
+	Name        string      `json:"name"`
+	CreatedAt   time.Time   `json:"created_at"`
+	ArchivedAt  *time.Time  `json:"archived_at"`
+	DeletedAt   *time.Time  `json:"deleted_at"`
+	Description string      `json:"description"`
+	OwnerID     *uuid.UUID  `json:"owner_id"`
+	Color       string      `json:"color"`
+	Icon        string      `json:"icon"`
+	Key         pgtype.Text `json:"key"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	WorkspaceID uuid.UUID   `json:"workspace_id"`
+}
+
+type ProjectMember struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Subject   string    `json:"subject"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ProjectTaskCounter struct {
+	ProjectID  uuid.UUID `json:"project_id"`
+	LastNumber int64     `json:"last_number"`
+}
+
+type ProjectTemplate struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Tasks       []byte    `json:"tasks"`
+	CreatedAt   time.Time `json:"created_at"`
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+}
+
+type ProjectWorkflow struct {
+	ProjectID  uuid.UUID `json:"project_id"`
+	Definition []byte    `json:"definition"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
 type Task struct {
+	ID             uuid.UUID  `json:"id"`
+	ProjectID      uuid.UUID  `json:"project_id"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Status         string     `json:"status"`
+	CreatedAt      time.Time  `json:"created_at"`
+	AssigneeID     *uuid.UUID `json:"assignee_id"`
+	DueDate        *time.Time `json:"due_date"`
+	Priority       string     `json:"priority"`
+	ParentTaskID   *uuid.UUID `json:"parent_task_id"`
+	StatusCategory string     `json:"status_category"`
+	Position       float64    `json:"position"`
+	Number         int64      `json:"number"`
+}
+
+type TaskDependency struct {
+	TaskID    uuid.UUID `json:"task_id"`
+	BlockerID uuid.UUID `json:"blocker_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TaskLabel struct {
+	TaskID  uuid.UUID `json:"task_id"`
+	LabelID uuid.UUID `json:"label_id"`
+}
+
+type User struct {
 	ID          uuid.UUID `json:"id"`
-	ProjectID   uuid.UUID `json:"project_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
 	CreatedAt   time.Time `json:"created_at"`
+	WorkspaceID uuid.UUID `json:"workspace_id"`
 }
@@ -10,48 +10,287 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const archiveProject = `-- name: ArchiveProject :one
+UPDATE projects
+SET archived_at = COALESCE(archived_at, $2::timestamptz)
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id
+`
+
+type ArchiveProjectParams struct {
+	ID         uuid.UUID `json:"id"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+func (q *Queries) ArchiveProject(ctx context.Context, arg ArchiveProjectParams) (Project, error) {
+	row := q.db.QueryRow(ctx, archiveProject, arg.ID, arg.ArchivedAt)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.ArchivedAt,
+		&i.DeletedAt,
+		&i.Description,
+		&i.OwnerID,
+		&i.Color,
+		&i.Icon,
+		&i.Key,
+		&i.UpdatedAt,
+		&i.WorkspaceID,
+	)
+	return i, err
+}
+
+const countProjects = `-- name: CountProjects :one
+SELECT count(*)
+FROM projects
+WHERE ($1::bool OR archived_at IS NULL)
+  AND ($2::bool OR deleted_at IS NULL)
+  AND ($3::text IS NULL OR EXISTS (
+    SELECT 1 FROM project_members m WHERE m.project_id = projects.id AND m.subject = $3::text))
+`
+
+type CountProjectsParams struct {
+	IncludeArchived bool        `json:"include_archived"`
+	IncludeDeleted  bool        `json:"include_deleted"`
+	Member          pgtype.Text `json:"member"`
+}
+
+func (q *Queries) CountProjects(ctx context.Context, arg CountProjectsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countProjects, arg.IncludeArchived, arg.IncludeDeleted, arg.Member)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const getProject = `-- name: GetProject :one
-SELECT id, name, created_at
+SELECT id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id
 FROM projects
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
+// Soft-deleted projects are invisible everywhere except RestoreProject and PurgeDeletedProjects.
 func (q *Queries) GetProject(ctx context.Context, id uuid.UUID) (Project, error) {
 	row := q.db.QueryRow(ctx, getProject, id)
 	var i Project
-	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.ArchivedAt,
+		&i.DeletedAt,
+		&i.Description,
+		&i.OwnerID,
+		&i.Color,
+		&i.Icon,
+		&i.Key,
+		&i.UpdatedAt,
+		&i.WorkspaceID,
+	)
+	return i, err
+}
+
+const getProjectForUpdate = `-- name: GetProjectForUpdate :one
+SELECT id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id
+FROM projects
+WHERE id = $1
+FOR UPDATE
+`
+
+// Locks a project, soft-deleted or not, for a change that is audited.
+func (q *Queries) GetProjectForUpdate(ctx context.Context, id uuid.UUID) (Project, error) {
+	row := q.db.QueryRow(ctx, getProjectForUpdate, id)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.ArchivedAt,
+		&i.DeletedAt,
+		&i.Description,
+		&i.OwnerID,
+		&i.Color,
+		&i.Icon,
+		&i.Key,
+		&i.UpdatedAt,
+		&i.WorkspaceID,
+	)
 	return i, err
 }
 
 const insertProject = `-- name: InsertProject :one
-INSERT INTO projects (id, name, created_at)
-VALUES ($1, $2, $3)
-RETURNING id, name, created_at
+INSERT INTO projects (id, name, created_at, updated_at, description, owner_id, color, icon, key, workspace_id)
+VALUES ($1, $2, $3, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id
 `
 
 type InsertProjectParams struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          uuid.UUID   `json:"id"`
+	Name        string      `json:"name"`
+	CreatedAt   time.Time   `json:"created_at"`
+	Description string      `json:"description"`
+	OwnerID     *uuid.UUID  `json:"owner_id"`
+	Color       string      `json:"color"`
+	Icon        string      `json:"icon"`
+	Key         pgtype.Text `json:"key"`
+	WorkspaceID uuid.UUID   `json:"workspace_id"`
 }
 
 func (q *Queries) InsertProject(ctx context.Context, arg InsertProjectParams) (Project, error) {
-	row := q.db.QueryRow(ctx, insertProject, arg.ID, arg.Name, arg.CreatedAt)
+	row := q.db.QueryRow(ctx, insertProject,
+		arg.ID,
+		arg.Name,
+		arg.CreatedAt,
+		arg.Description,
+		arg.OwnerID,
+		arg.Color,
+		arg.Icon,
+		arg.Key,
+		arg.WorkspaceID,
+	)
 	var i Project
-	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.ArchivedAt,
+		&i.DeletedAt,
+		&i.Description,
+		&i.OwnerID,
+		&i.Color,
+		&i.Icon,
+		&i.Key,
+		&i.UpdatedAt,
+		&i.WorkspaceID,
+	)
 	return i, err
 }
 
+const listProjectKeys = `-- name: ListProjectKeys :many
+SELECT id, key
+FROM projects
+WHERE id = ANY ($1::uuid[]) AND key IS NOT NULL
+`
+
+type ListProjectKeysRow struct {
+	ID  uuid.UUID   `json:"id"`
+	Key pgtype.Text `json:"key"`
+}
+
+// Keys of the given projects, for building task identifiers.
+func (q *Queries) ListProjectKeys(ctx context.Context, ids []uuid.UUID) ([]ListProjectKeysRow, error) {
+	rows, err := q.db.Query(ctx, listProjectKeys, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListProjectKeysRow{}
+	for rows.Next() {
+		var i ListProjectKeysRow
+		if err := rows.Scan(&i.ID, &i.Key); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listProjects = `-- name: ListProjects :many
-SELECT id, name, created_at
+SELECT id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id
+FROM projects
+WHERE ($1::bool OR archived_at IS NULL)
+  AND ($2::bool OR deleted_at IS NULL)
+  AND ($3::text IS NULL OR EXISTS (
+    SELECT 1 FROM project_members m WHERE m.project_id = projects.id AND m.subject = $3::text))
+ORDER BY created_at DESC, id DESC
+LIMIT $5 OFFSET $4
+`
+
+type ListProjectsParams struct {
+	IncludeArchived bool        `json:"include_archived"`
+	IncludeDeleted  bool        `json:"include_deleted"`
+	Member          pgtype.Text `json:"member"`
+	Offset          int32       `json:"offset"`
+	Limit           int32       `json:"limit"`
+}
+
+func (q *Queries) ListProjects(ctx context.Context, arg ListProjectsParams) ([]Project, error) {
+	rows, err := q.db.Query(ctx, listProjects,
+		arg.IncludeArchived,
+		arg.IncludeDeleted,
+		arg.Member,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Project{}
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.ArchivedAt,
+			&i.DeletedAt,
+			&i.Description,
+			&i.OwnerID,
+			&i.Color,
+			&i.Icon,
+			&i.Key,
+			&i.UpdatedAt,
+			&i.WorkspaceID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectsAfter = `-- name: ListProjectsAfter :many
+SELECT id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id
 FROM projects
+WHERE (created_at, id) < ($1::timestamptz, $2::uuid)
+  AND ($3::bool OR archived_at IS NULL)
+  AND ($4::bool OR deleted_at IS NULL)
+  AND ($5::text IS NULL OR EXISTS (
+    SELECT 1 FROM project_members m WHERE m.project_id = projects.id AND m.subject = $5::text))
 ORDER BY created_at DESC, id DESC
+LIMIT $6
 `
 
-func (q *Queries) ListProjects(ctx context.Context) ([]Project, error) {
-	rows, err := q.db.Query(ctx, listProjects)
+type ListProjectsAfterParams struct {
+	AfterCreatedAt  time.Time   `json:"after_created_at"`
+	AfterID         uuid.UUID   `json:"after_id"`
+	IncludeArchived bool        `json:"include_archived"`
+	IncludeDeleted  bool        `json:"include_deleted"`
+	Member          pgtype.Text `json:"member"`
+	Limit           int32       `json:"limit"`
+}
+
+// Keyset page over projects_newest_idx: rows strictly older than the cursor.
+func (q *Queries) ListProjectsAfter(ctx context.Context, arg ListProjectsAfterParams) ([]Project, error) {
+	rows, err := q.db.Query(ctx, listProjectsAfter,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.IncludeArchived,
+		arg.IncludeDeleted,
+		arg.Member,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +298,20 @@ func (q *Queries) ListProjects(ctx context.Context) ([]Project, error) {
 	items := []Project{}
 	for rows.Next() {
 		var i Project
-		if err := rows.Scan(&i.ID, &i.Name, &i.CreatedAt); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.ArchivedAt,
+			&i.DeletedAt,
+			&i.Description,
+			&i.OwnerID,
+			&i.Color,
+			&i.Icon,
+			&i.Key,
+			&i.UpdatedAt,
+			&i.WorkspaceID,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -69,3 +321,183 @@ func (q *Queries) ListProjects(ctx context.Context) ([]Project, error) {
 	}
 	return items, nil
 }
+
+const lockProject = `-- name: LockProject :one
+SELECT id, workspace_id
+FROM projects
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE
+`
+
+type LockProjectRow struct {
+	ID          uuid.UUID `json:"id"`
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+}
+
+// Serialises changes to a project's task hierarchy, dependencies or workflow
+// for the rest of the transaction.
+func (q *Queries) LockProject(ctx context.Context, id uuid.UUID) (LockProjectRow, error) {
+	row := q.db.QueryRow(ctx, lockProject, id)
+	var i LockProjectRow
+	err := row.Scan(&i.ID, &i.WorkspaceID)
+	return i, err
+}
+
+const lockProjectShared = `-- name: LockProjectShared :one
+SELECT id
+FROM projects
+WHERE id = $1 AND deleted_at IS NULL
+FOR SHARE
+`
+
+// Held while a task's status is checked against the workflow, so the
+// workflow cannot change underneath (UpdateWorkflow takes LockProject).
+func (q *Queries) LockProjectShared(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, lockProjectShared, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const purgeDeletedProjects = `-- name: PurgeDeletedProjects :many
+DELETE FROM projects
+WHERE deleted_at < $1::timestamptz
+RETURNING id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id
+`
+
+// Tasks are removed by the ON DELETE CASCADE on tasks.project_id.
+func (q *Queries) PurgeDeletedProjects(ctx context.Context, deletedBefore time.Time) ([]Project, error) {
+	rows, err := q.db.Query(ctx, purgeDeletedProjects, deletedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Project{}
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.ArchivedAt,
+			&i.DeletedAt,
+			&i.Description,
+			&i.OwnerID,
+			&i.Color,
+			&i.Icon,
+			&i.Key,
+			&i.UpdatedAt,
+			&i.WorkspaceID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreProject = `-- name: RestoreProject :one
+UPDATE projects
+SET archived_at = NULL, deleted_at = NULL
+WHERE id = $1
+RETURNING id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id
+`
+
+func (q *Queries) RestoreProject(ctx context.Context, id uuid.UUID) (Project, error) {
+	row := q.db.QueryRow(ctx, restoreProject, id)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.ArchivedAt,
+		&i.DeletedAt,
+		&i.Description,
+		&i.OwnerID,
+		&i.Color,
+		&i.Icon,
+		&i.Key,
+		&i.UpdatedAt,
+		&i.WorkspaceID,
+	)
+	return i, err
+}
+
+const softDeleteProject = `-- name: SoftDeleteProject :execrows
+UPDATE projects
+SET deleted_at = $2::timestamptz
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+type SoftDeleteProjectParams struct {
+	ID        uuid.UUID `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+func (q *Queries) SoftDeleteProject(ctx context.Context, arg SoftDeleteProjectParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteProject, arg.ID, arg.DeletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateProject = `-- name: UpdateProject :one
+UPDATE projects
+SET
+  name = COALESCE($2, name),
+  description = COALESCE($3, description),
+  owner_id = CASE WHEN $4::bool THEN $5::uuid ELSE owner_id END,
+  color = COALESCE($6, color),
+  icon = COALESCE($7, icon),
+  key = CASE WHEN $8::bool THEN $9::text ELSE key END,
+  updated_at = $10::timestamptz
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, created_at, archived_at, deleted_at, description, owner_id, color, icon, key, updated_at, workspace_id
+`
+
+type UpdateProjectParams struct {
+	ID          uuid.UUID   `json:"id"`
+	Name        pgtype.Text `json:"name"`
+	Description pgtype.Text `json:"description"`
+	SetOwner    bool        `json:"set_owner"`
+	OwnerID     *uuid.UUID  `json:"owner_id"`
+	Color       pgtype.Text `json:"color"`
+	Icon        pgtype.Text `json:"icon"`
+	SetKey      bool        `json:"set_key"`
+	Key         pgtype.Text `json:"key"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (Project, error) {
+	row := q.db.QueryRow(ctx, updateProject,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.SetOwner,
+		arg.OwnerID,
+		arg.Color,
+		arg.Icon,
+		arg.SetKey,
+		arg.Key,
+		arg.UpdatedAt,
+	)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.ArchivedAt,
+		&i.DeletedAt,
+		&i.Description,
+		&i.OwnerID,
+		&i.Color,
+		&i.Icon,
+		&i.Key,
+		&i.UpdatedAt,
+		&i.WorkspaceID,
+	)
+	return i, err
+}
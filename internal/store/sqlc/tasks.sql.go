@@ -13,21 +13,282 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const assignTaskNumber = `-- name: AssignTaskNumber :exec
+WITH counter AS (
+  INSERT INTO project_task_counters (project_id, last_number)
+  VALUES ($2::uuid, 1)
+  ON CONFLICT (project_id) DO UPDATE SET last_number = project_task_counters.last_number + 1
+  RETURNING last_number
+)
+UPDATE tasks
+SET number = counter.last_number
+FROM counter
+WHERE tasks.id = $1::uuid
+`
+
+type AssignTaskNumberParams struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+}
+
+// Gives a task that moved into a project the next number there.
+func (q *Queries) AssignTaskNumber(ctx context.Context, arg AssignTaskNumberParams) error {
+	_, err := q.db.Exec(ctx, assignTaskNumber, arg.ID, arg.ProjectID)
+	return err
+}
+
+const countOverdueTasks = `-- name: CountOverdueTasks :one
+SELECT count(*)
+FROM tasks
+JOIN projects p ON p.id = tasks.project_id AND p.deleted_at IS NULL
+WHERE tasks.due_date < $1::timestamptz
+  AND tasks.status_category <> 'done'
+  AND ($2::text IS NULL OR EXISTS (
+    SELECT 1 FROM project_members m WHERE m.project_id = tasks.project_id AND m.subject = $2::text))
+`
+
+type CountOverdueTasksParams struct {
+	AsOf   time.Time   `json:"as_of"`
+	Member pgtype.Text `json:"member"`
+}
+
+func (q *Queries) CountOverdueTasks(ctx context.Context, arg CountOverdueTasksParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countOverdueTasks, arg.AsOf, arg.Member)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countTasks = `-- name: CountTasks :one
+SELECT count(*)
+FROM tasks
+WHERE tasks.project_id = $1
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+  AND ($2::text[] IS NULL OR status = ANY ($2::text[]))
+  AND ($3::text IS NULL OR title ILIKE $3::text)
+  AND ($4::timestamptz IS NULL OR due_date < $4::timestamptz)
+  AND ($5::timestamptz IS NULL OR due_date > $5::timestamptz)
+  AND ($6::text[] IS NULL OR EXISTS (
+    SELECT 1 FROM task_labels tl JOIN labels l ON l.id = tl.label_id
+    WHERE tl.task_id = tasks.id AND l.name = ANY ($6::text[])
+  ))
+  AND ($7::uuid IS NULL OR tasks.parent_task_id = $7::uuid)
+`
+
+type CountTasksParams struct {
+	ProjectID    uuid.UUID   `json:"project_id"`
+	Statuses     []string    `json:"statuses"`
+	TitlePattern pgtype.Text `json:"title_pattern"`
+	DueBefore    *time.Time  `json:"due_before"`
+	DueAfter     *time.Time  `json:"due_after"`
+	Labels       []string    `json:"labels"`
+	ParentTaskID *uuid.UUID  `json:"parent_task_id"`
+}
+
+func (q *Queries) CountTasks(ctx context.Context, arg CountTasksParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countTasks,
+		arg.ProjectID,
+		arg.Statuses,
+		arg.TitlePattern,
+		arg.DueBefore,
+		arg.DueAfter,
+		arg.Labels,
+		arg.ParentTaskID,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserTasks = `-- name: CountUserTasks :one
+SELECT count(*)
+FROM tasks
+JOIN projects p ON p.id = tasks.project_id AND p.deleted_at IS NULL
+WHERE tasks.assignee_id = $1::uuid
+  AND ($2::text[] IS NULL OR tasks.status = ANY ($2::text[]))
+  AND ($3::text IS NULL OR EXISTS (
+    SELECT 1 FROM project_members m WHERE m.project_id = tasks.project_id AND m.subject = $3::text))
+`
+
+type CountUserTasksParams struct {
+	AssigneeID uuid.UUID   `json:"assignee_id"`
+	Statuses   []string    `json:"statuses"`
+	Member     pgtype.Text `json:"member"`
+}
+
+func (q *Queries) CountUserTasks(ctx context.Context, arg CountUserTasksParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUserTasks, arg.AssigneeID, arg.Statuses, arg.Member)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteTask = `-- name: DeleteTask :execrows
+DELETE FROM tasks
+WHERE tasks.project_id = $1 AND tasks.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+`
+
+type DeleteTaskParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func (q *Queries) DeleteTask(ctx context.Context, arg DeleteTaskParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTask, arg.ProjectID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getTask = `-- name: GetTask :one
+SELECT id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
+FROM tasks
+WHERE tasks.project_id = $1 AND tasks.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+`
+
+type GetTaskParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func (q *Queries) GetTask(ctx context.Context, arg GetTaskParams) (Task, error) {
+	row := q.db.QueryRow(ctx, getTask, arg.ProjectID, arg.ID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Description,
+		&i.Status,
+		&i.CreatedAt,
+		&i.AssigneeID,
+		&i.DueDate,
+		&i.Priority,
+		&i.ParentTaskID,
+		&i.StatusCategory,
+		&i.Position,
+		&i.Number,
+	)
+	return i, err
+}
+
+const getTaskByNumber = `-- name: GetTaskByNumber :one
+SELECT id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
+FROM tasks
+WHERE tasks.project_id = $1 AND tasks.number = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+`
+
+type GetTaskByNumberParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Number    int64     `json:"number"`
+}
+
+func (q *Queries) GetTaskByNumber(ctx context.Context, arg GetTaskByNumberParams) (Task, error) {
+	row := q.db.QueryRow(ctx, getTaskByNumber, arg.ProjectID, arg.Number)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Description,
+		&i.Status,
+		&i.CreatedAt,
+		&i.AssigneeID,
+		&i.DueDate,
+		&i.Priority,
+		&i.ParentTaskID,
+		&i.StatusCategory,
+		&i.Position,
+		&i.Number,
+	)
+	return i, err
+}
+
+const getTaskForUpdate = `-- name: GetTaskForUpdate :one
+SELECT id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
+FROM tasks
+WHERE tasks.project_id = $1 AND tasks.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+FOR UPDATE OF tasks
+`
+
+type GetTaskForUpdateParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// GetTask, locking the task for a change that is audited.
+func (q *Queries) GetTaskForUpdate(ctx context.Context, arg GetTaskForUpdateParams) (Task, error) {
+	row := q.db.QueryRow(ctx, getTaskForUpdate, arg.ProjectID, arg.ID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Description,
+		&i.Status,
+		&i.CreatedAt,
+		&i.AssigneeID,
+		&i.DueDate,
+		&i.Priority,
+		&i.ParentTaskID,
+		&i.StatusCategory,
+		&i.Position,
+		&i.Number,
+	)
+	return i, err
+}
+
 const insertTask = `-- name: InsertTask :one
-INSERT INTO tasks (id, project_id, title, description, status, created_at)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, project_id, title, description, status, created_at
+WITH counter AS (
+  INSERT INTO project_task_counters (project_id, last_number)
+  SELECT p.id, 1 FROM projects p
+  WHERE p.id = $2::uuid AND p.deleted_at IS NULL
+  ON CONFLICT (project_id) DO UPDATE SET last_number = project_task_counters.last_number + 1
+  RETURNING last_number
+)
+INSERT INTO tasks (id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number)
+SELECT
+  $1::uuid,
+  $2::uuid,
+  $3::text,
+  $4::text,
+  $5::text,
+  $6::timestamptz,
+  $7::uuid,
+  $8::timestamptz,
+  $9::text,
+  $10::uuid,
+  $11::text,
+  (SELECT COALESCE(max(c.position), 0) + 1024 FROM tasks c
+   WHERE c.project_id = $2::uuid AND c.status = $5::text),
+  counter.last_number
+FROM counter
+RETURNING id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
 `
 
 type InsertTaskParams struct {
-	ID          uuid.UUID `json:"id"`
-	ProjectID   uuid.UUID `json:"project_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID             uuid.UUID  `json:"id"`
+	ProjectID      uuid.UUID  `json:"project_id"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Status         string     `json:"status"`
+	CreatedAt      time.Time  `json:"created_at"`
+	AssigneeID     *uuid.UUID `json:"assignee_id"`
+	DueDate        *time.Time `json:"due_date"`
+	Priority       string     `json:"priority"`
+	ParentTaskID   *uuid.UUID `json:"parent_task_id"`
+	StatusCategory string     `json:"status_category"`
 }
 
+// Inserts nothing (no rows) when the project is missing or soft-deleted.
+// The task takes the next number from the project's counter row, whose row
+// lock serialises concurrent inserts, and goes to the bottom of its status
+// column.
 func (q *Queries) InsertTask(ctx context.Context, arg InsertTaskParams) (Task, error) {
 	row := q.db.QueryRow(ctx, insertTask,
 		arg.ID,
@@ -36,6 +297,11 @@ func (q *Queries) InsertTask(ctx context.Context, arg InsertTaskParams) (Task, e
 		arg.Description,
 		arg.Status,
 		arg.CreatedAt,
+		arg.AssigneeID,
+		arg.DueDate,
+		arg.Priority,
+		arg.ParentTaskID,
+		arg.StatusCategory,
 	)
 	var i Task
 	err := row.Scan(
@@ -45,19 +311,325 @@ func (q *Queries) InsertTask(ctx context.Context, arg InsertTaskParams) (Task, e
 		&i.Description,
 		&i.Status,
 		&i.CreatedAt,
+		&i.AssigneeID,
+		&i.DueDate,
+		&i.Priority,
+		&i.ParentTaskID,
+		&i.StatusCategory,
+		&i.Position,
+		&i.Number,
 	)
 	return i, err
 }
 
+const listBoardTasks = `-- name: ListBoardTasks :many
+SELECT id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
+FROM tasks
+WHERE tasks.project_id = $1
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+ORDER BY status, position, created_at, id
+`
+
+// Every task of a live project in board order within each status.
+func (q *Queries) ListBoardTasks(ctx context.Context, projectID uuid.UUID) ([]Task, error) {
+	rows, err := q.db.Query(ctx, listBoardTasks, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.CreatedAt,
+			&i.AssigneeID,
+			&i.DueDate,
+			&i.Priority,
+			&i.ParentTaskID,
+			&i.StatusCategory,
+			&i.Position,
+			&i.Number,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listColumnPositions = `-- name: ListColumnPositions :many
+SELECT id, position
+FROM tasks
+WHERE project_id = $1 AND status = $2
+  AND id <> $3
+ORDER BY position, created_at, id
+`
+
+type ListColumnPositionsParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Status    string    `json:"status"`
+	ExcludeID uuid.UUID `json:"exclude_id"`
+}
+
+type ListColumnPositionsRow struct {
+	ID       uuid.UUID `json:"id"`
+	Position float64   `json:"position"`
+}
+
+// A status column in board order, leaving out the task being moved.
+func (q *Queries) ListColumnPositions(ctx context.Context, arg ListColumnPositionsParams) ([]ListColumnPositionsRow, error) {
+	rows, err := q.db.Query(ctx, listColumnPositions, arg.ProjectID, arg.Status, arg.ExcludeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListColumnPositionsRow{}
+	for rows.Next() {
+		var i ListColumnPositionsRow
+		if err := rows.Scan(&i.ID, &i.Position); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOverdueTasks = `-- name: ListOverdueTasks :many
+SELECT tasks.id, tasks.project_id, tasks.title, tasks.description, tasks.status, tasks.created_at, tasks.assignee_id, tasks.due_date, tasks.priority, tasks.parent_task_id, tasks.status_category, tasks.position, tasks.number
+FROM tasks
+JOIN projects p ON p.id = tasks.project_id AND p.deleted_at IS NULL
+WHERE tasks.due_date < $1::timestamptz
+  AND tasks.status_category <> 'done'
+  AND ($2::text IS NULL OR EXISTS (
+    SELECT 1 FROM project_members m WHERE m.project_id = tasks.project_id AND m.subject = $2::text))
+ORDER BY tasks.due_date ASC, tasks.id ASC
+LIMIT $4 OFFSET $3
+`
+
+type ListOverdueTasksParams struct {
+	AsOf   time.Time   `json:"as_of"`
+	Member pgtype.Text `json:"member"`
+	Offset int32       `json:"offset"`
+	Limit  int32       `json:"limit"`
+}
+
+// Open tasks past their due date across all live projects, earliest due first.
+func (q *Queries) ListOverdueTasks(ctx context.Context, arg ListOverdueTasksParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, listOverdueTasks,
+		arg.AsOf,
+		arg.Member,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.CreatedAt,
+			&i.AssigneeID,
+			&i.DueDate,
+			&i.Priority,
+			&i.ParentTaskID,
+			&i.StatusCategory,
+			&i.Position,
+			&i.Number,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubtaskRollups = `-- name: ListSubtaskRollups :many
+SELECT
+  parent_task_id::uuid AS parent_task_id,
+  count(*) AS total,
+  count(*) FILTER (WHERE status_category = 'done') AS done
+FROM tasks
+WHERE parent_task_id = ANY ($1::uuid[])
+GROUP BY parent_task_id
+`
+
+type ListSubtaskRollupsRow struct {
+	ParentTaskID uuid.UUID `json:"parent_task_id"`
+	Total        int64     `json:"total"`
+	Done         int64     `json:"done"`
+}
+
+// Direct subtask counts for each of the given parents that has any.
+func (q *Queries) ListSubtaskRollups(ctx context.Context, parentIds []uuid.UUID) ([]ListSubtaskRollupsRow, error) {
+	rows, err := q.db.Query(ctx, listSubtaskRollups, parentIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSubtaskRollupsRow{}
+	for rows.Next() {
+		var i ListSubtaskRollupsRow
+		if err := rows.Scan(&i.ParentTaskID, &i.Total, &i.Done); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTasks = `-- name: ListTasks :many
-SELECT id, project_id, title, description, status, created_at
+SELECT id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
 FROM tasks
-WHERE project_id = $1
+WHERE tasks.project_id = $1
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+  AND ($2::text[] IS NULL OR status = ANY ($2::text[]))
+  AND ($3::text IS NULL OR title ILIKE $3::text)
+  AND ($4::timestamptz IS NULL OR due_date < $4::timestamptz)
+  AND ($5::timestamptz IS NULL OR due_date > $5::timestamptz)
+  AND ($6::text[] IS NULL OR EXISTS (
+    SELECT 1 FROM task_labels tl JOIN labels l ON l.id = tl.label_id
+    WHERE tl.task_id = tasks.id AND l.name = ANY ($6::text[])
+  ))
+  AND ($7::uuid IS NULL OR tasks.parent_task_id = $7::uuid)
+ORDER BY
+  CASE WHEN $8::text = 'title' THEN title COLLATE "C" END ASC,
+  CASE WHEN $8::text = 'created_at' THEN created_at END ASC,
+  CASE WHEN $8::text = 'created_at' THEN id END ASC,
+  created_at DESC,
+  id DESC
+LIMIT $10 OFFSET $9
+`
+
+type ListTasksParams struct {
+	ProjectID    uuid.UUID   `json:"project_id"`
+	Statuses     []string    `json:"statuses"`
+	TitlePattern pgtype.Text `json:"title_pattern"`
+	DueBefore    *time.Time  `json:"due_before"`
+	DueAfter     *time.Time  `json:"due_after"`
+	Labels       []string    `json:"labels"`
+	ParentTaskID *uuid.UUID  `json:"parent_task_id"`
+	Sort         string      `json:"sort"`
+	Offset       int32       `json:"offset"`
+	Limit        int32       `json:"limit"`
+}
+
+// Optional filters are skipped when NULL. Sort keys other than "title" and
+// "created_at" fall through to the default newest-first order.
+func (q *Queries) ListTasks(ctx context.Context, arg ListTasksParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, listTasks,
+		arg.ProjectID,
+		arg.Statuses,
+		arg.TitlePattern,
+		arg.DueBefore,
+		arg.DueAfter,
+		arg.Labels,
+		arg.ParentTaskID,
+		arg.Sort,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.CreatedAt,
+			&i.AssigneeID,
+			&i.DueDate,
+			&i.Priority,
+			&i.ParentTaskID,
+			&i.StatusCategory,
+			&i.Position,
+			&i.Number,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTasksAfter = `-- name: ListTasksAfter :many
+SELECT id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
+FROM tasks
+WHERE tasks.project_id = $1
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+  AND (created_at, id) < ($2::timestamptz, $3::uuid)
+  AND ($4::text[] IS NULL OR status = ANY ($4::text[]))
+  AND ($5::text IS NULL OR title ILIKE $5::text)
+  AND ($6::timestamptz IS NULL OR due_date < $6::timestamptz)
+  AND ($7::timestamptz IS NULL OR due_date > $7::timestamptz)
+  AND ($8::text[] IS NULL OR EXISTS (
+    SELECT 1 FROM task_labels tl JOIN labels l ON l.id = tl.label_id
+    WHERE tl.task_id = tasks.id AND l.name = ANY ($8::text[])
+  ))
+  AND ($9::uuid IS NULL OR tasks.parent_task_id = $9::uuid)
 ORDER BY created_at DESC, id DESC
+LIMIT $10
 `
 
-func (q *Queries) ListTasks(ctx context.Context, projectID uuid.UUID) ([]Task, error) {
-	rows, err := q.db.Query(ctx, listTasks, projectID)
+type ListTasksAfterParams struct {
+	ProjectID      uuid.UUID   `json:"project_id"`
+	AfterCreatedAt time.Time   `json:"after_created_at"`
+	AfterID        uuid.UUID   `json:"after_id"`
+	Statuses       []string    `json:"statuses"`
+	TitlePattern   pgtype.Text `json:"title_pattern"`
+	DueBefore      *time.Time  `json:"due_before"`
+	DueAfter       *time.Time  `json:"due_after"`
+	Labels         []string    `json:"labels"`
+	ParentTaskID   *uuid.UUID  `json:"parent_task_id"`
+	Limit          int32       `json:"limit"`
+}
+
+// Keyset page over tasks_project_newest_idx: rows strictly older than the cursor.
+func (q *Queries) ListTasksAfter(ctx context.Context, arg ListTasksAfterParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, listTasksAfter,
+		arg.ProjectID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Statuses,
+		arg.TitlePattern,
+		arg.DueBefore,
+		arg.DueAfter,
+		arg.Labels,
+		arg.ParentTaskID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +644,13 @@ func (q *Queries) ListTasks(ctx context.Context, projectID uuid.UUID) ([]Task, e
 			&i.Description,
 			&i.Status,
 			&i.CreatedAt,
+			&i.AssigneeID,
+			&i.DueDate,
+			&i.Priority,
+			&i.ParentTaskID,
+			&i.StatusCategory,
+			&i.Position,
+			&i.Number,
 		); err != nil {
 			return nil, err
 		}
@@ -83,22 +662,169 @@ func (q *Queries) ListTasks(ctx context.Context, projectID uuid.UUID) ([]Task, e
 	return items, nil
 }
 
+const listUserTasks = `-- name: ListUserTasks :many
+SELECT tasks.id, tasks.project_id, tasks.title, tasks.description, tasks.status, tasks.created_at, tasks.assignee_id, tasks.due_date, tasks.priority, tasks.parent_task_id, tasks.status_category, tasks.position, tasks.number
+FROM tasks
+JOIN projects p ON p.id = tasks.project_id AND p.deleted_at IS NULL
+WHERE tasks.assignee_id = $1::uuid
+  AND ($2::text[] IS NULL OR tasks.status = ANY ($2::text[]))
+  AND ($3::text IS NULL OR EXISTS (
+    SELECT 1 FROM project_members m WHERE m.project_id = tasks.project_id AND m.subject = $3::text))
+ORDER BY tasks.created_at DESC, tasks.id DESC
+LIMIT $5 OFFSET $4
+`
+
+type ListUserTasksParams struct {
+	AssigneeID uuid.UUID   `json:"assignee_id"`
+	Statuses   []string    `json:"statuses"`
+	Member     pgtype.Text `json:"member"`
+	Offset     int32       `json:"offset"`
+	Limit      int32       `json:"limit"`
+}
+
+// Tasks assigned to a user across all live projects, newest first.
+func (q *Queries) ListUserTasks(ctx context.Context, arg ListUserTasksParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, listUserTasks,
+		arg.AssigneeID,
+		arg.Statuses,
+		arg.Member,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.CreatedAt,
+			&i.AssigneeID,
+			&i.DueDate,
+			&i.Priority,
+			&i.ParentTaskID,
+			&i.StatusCategory,
+			&i.Position,
+			&i.Number,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const nextTaskPosition = `-- name: NextTaskPosition :one
+SELECT (COALESCE(max(position), 0) + 1024)::float8
+FROM tasks
+WHERE project_id = $1 AND status = $2
+`
+
+type NextTaskPositionParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Status    string    `json:"status"`
+}
+
+// Position just below the bottom card of a status column.
+func (q *Queries) NextTaskPosition(ctx context.Context, arg NextTaskPositionParams) (float64, error) {
+	row := q.db.QueryRow(ctx, nextTaskPosition, arg.ProjectID, arg.Status)
+	var column_1 float64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const reparentSubtasks = `-- name: ReparentSubtasks :exec
+UPDATE tasks
+SET parent_task_id = (SELECT p.parent_task_id FROM tasks p WHERE p.id = $1::uuid)
+WHERE tasks.parent_task_id = $1::uuid
+`
+
+// Moves the direct subtasks of a task up to that task's own parent.
+func (q *Queries) ReparentSubtasks(ctx context.Context, taskID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, reparentSubtasks, taskID)
+	return err
+}
+
+const setTaskPosition = `-- name: SetTaskPosition :exec
+UPDATE tasks
+SET position = $3
+WHERE project_id = $1 AND id = $2
+`
+
+type SetTaskPositionParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	ID        uuid.UUID `json:"id"`
+	Position  float64   `json:"position"`
+}
+
+func (q *Queries) SetTaskPosition(ctx context.Context, arg SetTaskPositionParams) error {
+	_, err := q.db.Exec(ctx, setTaskPosition, arg.ProjectID, arg.ID, arg.Position)
+	return err
+}
+
+const taskHasAncestor = `-- name: TaskHasAncestor :one
+WITH RECURSIVE ancestors AS (
+  SELECT t.id, t.parent_task_id FROM tasks t WHERE t.id = $2::uuid
+  UNION
+  SELECT t.id, t.parent_task_id FROM tasks t JOIN ancestors a ON t.id = a.parent_task_id
+)
+SELECT EXISTS (SELECT 1 FROM ancestors WHERE ancestors.id = $1::uuid)
+`
+
+type TaskHasAncestorParams struct {
+	AncestorID uuid.UUID `json:"ancestor_id"`
+	TaskID     uuid.UUID `json:"task_id"`
+}
+
+// Reports whether ancestor_id is task_id itself or one of its ancestors.
+func (q *Queries) TaskHasAncestor(ctx context.Context, arg TaskHasAncestorParams) (bool, error) {
+	row := q.db.QueryRow(ctx, taskHasAncestor, arg.AncestorID, arg.TaskID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const updateTask = `-- name: UpdateTask :one
 UPDATE tasks
 SET
   title = COALESCE($3, title),
   description = COALESCE($4, description),
-  status = COALESCE($5, status)
-WHERE project_id = $1 AND id = $2
-RETURNING id, project_id, title, description, status, created_at
+  status = COALESCE($5, status),
+  status_category = COALESCE($6, status_category),
+  position = COALESCE($7::float8, position),
+  assignee_id = CASE WHEN $8::bool THEN $9::uuid ELSE assignee_id END,
+  due_date = CASE WHEN $10::bool THEN $11::timestamptz ELSE due_date END,
+  priority = COALESCE($12, priority),
+  parent_task_id = CASE WHEN $13::bool THEN $14::uuid ELSE parent_task_id END
+WHERE tasks.project_id = $1 AND tasks.id = $2
+  AND EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.deleted_at IS NULL)
+RETURNING id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
 `
 
 type UpdateTaskParams struct {
-	ProjectID   uuid.UUID   `json:"project_id"`
-	ID          uuid.UUID   `json:"id"`
-	Title       pgtype.Text `json:"title"`
-	Description pgtype.Text `json:"description"`
-	Status      pgtype.Text `json:"status"`
+	ProjectID      uuid.UUID     `json:"project_id"`
+	ID             uuid.UUID     `json:"id"`
+	Title          pgtype.Text   `json:"title"`
+	Description    pgtype.Text   `json:"description"`
+	Status         pgtype.Text   `json:"status"`
+	StatusCategory pgtype.Text   `json:"status_category"`
+	Position       pgtype.Float8 `json:"position"`
+	SetAssignee    bool          `json:"set_assignee"`
+	AssigneeID     *uuid.UUID    `json:"assignee_id"`
+	SetDueDate     bool          `json:"set_due_date"`
+	DueDate        *time.Time    `json:"due_date"`
+	Priority       pgtype.Text   `json:"priority"`
+	SetParent      bool          `json:"set_parent"`
+	ParentTaskID   *uuid.UUID    `json:"parent_task_id"`
 }
 
 func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, error) {
@@ -108,6 +834,15 @@ func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, e
 		arg.Title,
 		arg.Description,
 		arg.Status,
+		arg.StatusCategory,
+		arg.Position,
+		arg.SetAssignee,
+		arg.AssigneeID,
+		arg.SetDueDate,
+		arg.DueDate,
+		arg.Priority,
+		arg.SetParent,
+		arg.ParentTaskID,
 	)
 	var i Task
 	err := row.Scan(
@@ -117,6 +852,13 @@ func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, e
 		&i.Description,
 		&i.Status,
 		&i.CreatedAt,
+		&i.AssigneeID,
+		&i.DueDate,
+		&i.Priority,
+		&i.ParentTaskID,
+		&i.StatusCategory,
+		&i.Position,
+		&i.Number,
 	)
 	return i, err
 }
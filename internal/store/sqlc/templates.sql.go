@@ -0,0 +1,110 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: templates.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const deleteTemplate = `-- name: DeleteTemplate :execrows
+DELETE FROM project_templates
+WHERE id = $1
+`
+
+func (q *Queries) DeleteTemplate(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTemplate, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getTemplate = `-- name: GetTemplate :one
+SELECT id, name, tasks, created_at, workspace_id
+FROM project_templates
+WHERE id = $1
+`
+
+func (q *Queries) GetTemplate(ctx context.Context, id uuid.UUID) (ProjectTemplate, error) {
+	row := q.db.QueryRow(ctx, getTemplate, id)
+	var i ProjectTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Tasks,
+		&i.CreatedAt,
+		&i.WorkspaceID,
+	)
+	return i, err
+}
+
+const insertTemplate = `-- name: InsertTemplate :one
+INSERT INTO project_templates (id, name, tasks, created_at, workspace_id)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, name, tasks, created_at, workspace_id
+`
+
+type InsertTemplateParams struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Tasks       []byte    `json:"tasks"`
+	CreatedAt   time.Time `json:"created_at"`
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+}
+
+func (q *Queries) InsertTemplate(ctx context.Context, arg InsertTemplateParams) (ProjectTemplate, error) {
+	row := q.db.QueryRow(ctx, insertTemplate,
+		arg.ID,
+		arg.Name,
+		arg.Tasks,
+		arg.CreatedAt,
+		arg.WorkspaceID,
+	)
+	var i ProjectTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Tasks,
+		&i.CreatedAt,
+		&i.WorkspaceID,
+	)
+	return i, err
+}
+
+const listTemplates = `-- name: ListTemplates :many
+SELECT id, name, tasks, created_at, workspace_id
+FROM project_templates
+ORDER BY name COLLATE "C", id
+`
+
+func (q *Queries) ListTemplates(ctx context.Context) ([]ProjectTemplate, error) {
+	rows, err := q.db.Query(ctx, listTemplates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ProjectTemplate{}
+	for rows.Next() {
+		var i ProjectTemplate
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Tasks,
+			&i.CreatedAt,
+			&i.WorkspaceID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,289 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: transfers.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const copyComment = `-- name: CopyComment :one
+INSERT INTO comments (id, task_id, parent_id, author_id, body, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, task_id, parent_id, author_id, body, created_at, updated_at
+`
+
+type CopyCommentParams struct {
+	ID        uuid.UUID  `json:"id"`
+	TaskID    uuid.UUID  `json:"task_id"`
+	ParentID  *uuid.UUID `json:"parent_id"`
+	AuthorID  uuid.UUID  `json:"author_id"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+func (q *Queries) CopyComment(ctx context.Context, arg CopyCommentParams) (Comment, error) {
+	row := q.db.QueryRow(ctx, copyComment,
+		arg.ID,
+		arg.TaskID,
+		arg.ParentID,
+		arg.AuthorID,
+		arg.Body,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.ParentID,
+		&i.AuthorID,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const countCommentsOnTasks = `-- name: CountCommentsOnTasks :one
+SELECT count(*)
+FROM comments
+WHERE task_id = ANY ($1::uuid[])
+`
+
+func (q *Queries) CountCommentsOnTasks(ctx context.Context, taskIds []uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countCommentsOnTasks, taskIds)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteCrossProjectDependencies = `-- name: DeleteCrossProjectDependencies :many
+DELETE FROM task_dependencies d
+USING tasks t, tasks b
+WHERE t.id = d.task_id AND b.id = d.blocker_id
+  AND t.project_id <> b.project_id
+  AND (d.task_id = ANY ($1::uuid[]) OR d.blocker_id = ANY ($1::uuid[]))
+RETURNING d.task_id, d.blocker_id
+`
+
+type DeleteCrossProjectDependenciesRow struct {
+	TaskID    uuid.UUID `json:"task_id"`
+	BlockerID uuid.UUID `json:"blocker_id"`
+}
+
+// Drops dependencies of the given tasks whose other end is now in a
+// different project.
+func (q *Queries) DeleteCrossProjectDependencies(ctx context.Context, taskIds []uuid.UUID) ([]DeleteCrossProjectDependenciesRow, error) {
+	rows, err := q.db.Query(ctx, deleteCrossProjectDependencies, taskIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []DeleteCrossProjectDependenciesRow{}
+	for rows.Next() {
+		var i DeleteCrossProjectDependenciesRow
+		if err := rows.Scan(&i.TaskID, &i.BlockerID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLabelByName = `-- name: GetLabelByName :one
+SELECT id, project_id, name, color, created_at
+FROM labels
+WHERE project_id = $1 AND name = $2
+`
+
+type GetLabelByNameParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Name      string    `json:"name"`
+}
+
+func (q *Queries) GetLabelByName(ctx context.Context, arg GetLabelByNameParams) (Label, error) {
+	row := q.db.QueryRow(ctx, getLabelByName, arg.ProjectID, arg.Name)
+	var i Label
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Color,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertLabelIfMissing = `-- name: InsertLabelIfMissing :one
+INSERT INTO labels (id, project_id, name, color, created_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (project_id, name) DO NOTHING
+RETURNING id, project_id, name, color, created_at
+`
+
+type InsertLabelIfMissingParams struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Inserts nothing (no rows) when the project already has a label by that name.
+func (q *Queries) InsertLabelIfMissing(ctx context.Context, arg InsertLabelIfMissingParams) (Label, error) {
+	row := q.db.QueryRow(ctx, insertLabelIfMissing,
+		arg.ID,
+		arg.ProjectID,
+		arg.Name,
+		arg.Color,
+		arg.CreatedAt,
+	)
+	var i Label
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Color,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAllComments = `-- name: ListAllComments :many
+SELECT id, task_id, parent_id, author_id, body, created_at, updated_at
+FROM comments
+WHERE task_id = $1
+ORDER BY created_at, id
+`
+
+// Every comment on a task, oldest first.
+func (q *Queries) ListAllComments(ctx context.Context, taskID uuid.UUID) ([]Comment, error) {
+	rows, err := q.db.Query(ctx, listAllComments, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Comment{}
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.ParentID,
+			&i.AuthorID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveTaskTree = `-- name: MoveTaskTree :many
+WITH RECURSIVE tree AS (
+  SELECT t.id FROM tasks t
+  WHERE t.project_id = $3::uuid AND t.id = $2::uuid
+  UNION
+  SELECT c.id FROM tasks c JOIN tree ON c.parent_task_id = tree.id
+)
+UPDATE tasks
+SET
+  project_id = $1::uuid,
+  parent_task_id = CASE WHEN tasks.id = $2::uuid THEN NULL ELSE tasks.parent_task_id END
+WHERE tasks.id IN (SELECT id FROM tree)
+RETURNING id, project_id, title, description, status, created_at, assignee_id, due_date, priority, parent_task_id, status_category, position, number
+`
+
+type MoveTaskTreeParams struct {
+	TargetProjectID uuid.UUID `json:"target_project_id"`
+	ID              uuid.UUID `json:"id"`
+	ProjectID       uuid.UUID `json:"project_id"`
+}
+
+// Moves a task and all of its subtasks to another project. It is a single
+// statement so tasks_parent_fkey is only checked once the whole tree has
+// moved. The task itself becomes top level in the target project.
+func (q *Queries) MoveTaskTree(ctx context.Context, arg MoveTaskTreeParams) ([]Task, error) {
+	rows, err := q.db.Query(ctx, moveTaskTree, arg.TargetProjectID, arg.ID, arg.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.CreatedAt,
+			&i.AssigneeID,
+			&i.DueDate,
+			&i.Priority,
+			&i.ParentTaskID,
+			&i.StatusCategory,
+			&i.Position,
+			&i.Number,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const relabelTasks = `-- name: RelabelTasks :many
+UPDATE task_labels
+SET label_id = $1::uuid
+WHERE task_id = ANY ($2::uuid[]) AND label_id = $3::uuid
+RETURNING task_id
+`
+
+type RelabelTasksParams struct {
+	NewLabelID uuid.UUID   `json:"new_label_id"`
+	TaskIds    []uuid.UUID `json:"task_ids"`
+	OldLabelID uuid.UUID   `json:"old_label_id"`
+}
+
+// Swaps one label for another on the given tasks, returning the tasks that
+// had it.
+func (q *Queries) RelabelTasks(ctx context.Context, arg RelabelTasksParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, relabelTasks, arg.NewLabelID, arg.TaskIds, arg.OldLabelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var task_id uuid.UUID
+		if err := rows.Scan(&task_id); err != nil {
+			return nil, err
+		}
+		items = append(items, task_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
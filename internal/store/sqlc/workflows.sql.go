@@ -0,0 +1,83 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: workflows.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const countTasksOutsideStatuses = `-- name: CountTasksOutsideStatuses :one
+SELECT count(*)
+FROM tasks
+WHERE project_id = $1
+  AND NOT (status = ANY ($2::text[]))
+`
+
+type CountTasksOutsideStatusesParams struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	Statuses  []string  `json:"statuses"`
+}
+
+// Tasks of the project whose status is not in the given list.
+func (q *Queries) CountTasksOutsideStatuses(ctx context.Context, arg CountTasksOutsideStatusesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countTasksOutsideStatuses, arg.ProjectID, arg.Statuses)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getWorkflow = `-- name: GetWorkflow :one
+SELECT definition
+FROM project_workflows
+WHERE project_id = $1
+`
+
+func (q *Queries) GetWorkflow(ctx context.Context, projectID uuid.UUID) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getWorkflow, projectID)
+	var definition []byte
+	err := row.Scan(&definition)
+	return definition, err
+}
+
+const setTaskStatusCategory = `-- name: SetTaskStatusCategory :exec
+UPDATE tasks
+SET status_category = $1::text
+WHERE project_id = $2
+  AND status = $3::text
+  AND status_category <> $1::text
+`
+
+type SetTaskStatusCategoryParams struct {
+	Category  string    `json:"category"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Status    string    `json:"status"`
+}
+
+func (q *Queries) SetTaskStatusCategory(ctx context.Context, arg SetTaskStatusCategoryParams) error {
+	_, err := q.db.Exec(ctx, setTaskStatusCategory, arg.Category, arg.ProjectID, arg.Status)
+	return err
+}
+
+const upsertWorkflow = `-- name: UpsertWorkflow :exec
+INSERT INTO project_workflows (project_id, definition, updated_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (project_id) DO UPDATE
+SET definition = EXCLUDED.definition, updated_at = EXCLUDED.updated_at
+`
+
+type UpsertWorkflowParams struct {
+	ProjectID  uuid.UUID `json:"project_id"`
+	Definition []byte    `json:"definition"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (q *Queries) UpsertWorkflow(ctx context.Context, arg UpsertWorkflowParams) error {
+	_, err := q.db.Exec(ctx, upsertWorkflow, arg.ProjectID, arg.Definition, arg.UpdatedAt)
+	return err
+}
@@ -0,0 +1,1825 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/domain"
+)
+
+// forEachStore runs fn against every ProjectStore implementation so both
+// stores are held to the same contract. The Postgres run is skipped when
+// no container runtime is available.
+func forEachStore(t *testing.T, fn func(t *testing.T, ctx context.Context, s ProjectStore)) {
+	t.Helper()
+
+	t.Run("memory", func(t *testing.T) {
+		fn(t, context.Background(), NewMemoryStore())
+	})
+	t.Run("postgres", func(t *testing.T) {
+		ctx, s := newPGStore(t)
+		fn(t, ctx, s)
+	})
+}
+
+func TestParity_GetTask(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		created, err := s.InsertTask(ctx, p.ID, NewTask{Title: "T1", Description: "desc"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+
+		got, err := s.GetTask(ctx, p.ID, created.ID)
+		if err != nil {
+			t.Fatalf("GetTask: %v", err)
+		}
+		if got.ID != created.ID || got.Title != "T1" || got.Description != "desc" || got.Status != "todo" {
+			t.Fatalf("unexpected task: %+v", got)
+		}
+
+		if _, err := s.GetTask(ctx, p.ID, uuid.New()); err != ErrTaskNotFound {
+			t.Fatalf("expected ErrTaskNotFound; got %v", err)
+		}
+		if _, err := s.GetTask(ctx, uuid.New(), created.ID); err != ErrProjectNotFound {
+			t.Fatalf("expected ErrProjectNotFound; got %v", err)
+		}
+
+		// A task is only visible through its own project.
+		other, err := s.InsertProject(ctx, NewProject{Name: "Beta"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		if _, err := s.GetTask(ctx, other.ID, created.ID); err != ErrTaskNotFound {
+			t.Fatalf("expected ErrTaskNotFound via other project; got %v", err)
+		}
+	})
+}
+
+func TestParity_DeleteTask(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		task, err := s.InsertTask(ctx, p.ID, NewTask{Title: "T1"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+
+		if err := s.DeleteTask(ctx, p.ID, task.ID, SubtasksReparent); err != nil {
+			t.Fatalf("DeleteTask: %v", err)
+		}
+		if _, err := s.GetTask(ctx, p.ID, task.ID); err != ErrTaskNotFound {
+			t.Fatalf("expected ErrTaskNotFound after delete; got %v", err)
+		}
+		if err := s.DeleteTask(ctx, p.ID, task.ID, SubtasksReparent); err != ErrTaskNotFound {
+			t.Fatalf("expected ErrTaskNotFound on second delete; got %v", err)
+		}
+		if err := s.DeleteTask(ctx, uuid.New(), task.ID, SubtasksReparent); err != ErrProjectNotFound {
+			t.Fatalf("expected ErrProjectNotFound; got %v", err)
+		}
+
+		_, total, err := s.ListTasks(ctx, p.ID, ListTasksParams{Limit: 10})
+		if err != nil {
+			t.Fatalf("ListTasks: %v", err)
+		}
+		if total != 0 {
+			t.Fatalf("expected no tasks after delete; got %d", total)
+		}
+	})
+}
+
+func TestParity_OffsetBeyondInt32(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		if _, err := s.InsertTask(ctx, p.ID, NewTask{Title: "T1"}); err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+
+		offset := 3_000_000_000
+		list, total, err := s.ListProjects(ctx, ListProjectsParams{Limit: 10, Offset: offset})
+		if err != nil || total != 1 || len(list) != 0 {
+			t.Fatalf("ListProjects: expected an empty page of 1; got %+v, %d, %v", list, total, err)
+		}
+		tasks, total, err := s.ListTasks(ctx, p.ID, ListTasksParams{Limit: 10, Offset: offset})
+		if err != nil || total != 1 || len(tasks) != 0 {
+			t.Fatalf("ListTasks: expected an empty page of 1; got %+v, %d, %v", tasks, total, err)
+		}
+	})
+}
+
+func TestParity_ArchiveDeleteRestore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		live, err := s.InsertProject(ctx, NewProject{Name: "Live"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		archived, err := s.InsertProject(ctx, NewProject{Name: "Archived"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		deleted, err := s.InsertProject(ctx, NewProject{Name: "Deleted"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		if _, err := s.InsertTask(ctx, deleted.ID, NewTask{Title: "T1"}); err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+
+		first, err := s.ArchiveProject(ctx, archived.ID)
+		if err != nil {
+			t.Fatalf("ArchiveProject: %v", err)
+		}
+		again, err := s.ArchiveProject(ctx, archived.ID)
+		if err != nil {
+			t.Fatalf("ArchiveProject again: %v", err)
+		}
+		if first.ArchivedAt == nil || again.ArchivedAt == nil || !first.ArchivedAt.Equal(*again.ArchivedAt) {
+			t.Fatalf("expected archiving twice to keep the first timestamp: %v vs %v", first.ArchivedAt, again.ArchivedAt)
+		}
+		if err := s.DeleteProject(ctx, deleted.ID); err != nil {
+			t.Fatalf("DeleteProject: %v", err)
+		}
+
+		for _, tc := range []struct {
+			params ListProjectsParams
+			want   int
+		}{
+			{ListProjectsParams{Limit: 10}, 1},
+			{ListProjectsParams{Limit: 10, IncludeArchived: true}, 2},
+			{ListProjectsParams{Limit: 10, IncludeDeleted: true}, 2},
+			{ListProjectsParams{Limit: 10, IncludeArchived: true, IncludeDeleted: true}, 3},
+		} {
+			page, total, err := s.ListProjects(ctx, tc.params)
+			if err != nil {
+				t.Fatalf("ListProjects(%+v): %v", tc.params, err)
+			}
+			if total != tc.want || len(page) != tc.want {
+				t.Fatalf("ListProjects(%+v): expected %d; got total=%d len=%d", tc.params, tc.want, total, len(page))
+			}
+		}
+
+		if _, err := s.GetProject(ctx, archived.ID); err != nil {
+			t.Fatalf("expected archived project to stay readable; got %v", err)
+		}
+		if _, err := s.GetProject(ctx, deleted.ID); err != ErrNotFound {
+			t.Fatalf("expected ErrNotFound for deleted project; got %v", err)
+		}
+		if _, _, err := s.ListTasks(ctx, deleted.ID, ListTasksParams{Limit: 10}); err != ErrProjectNotFound {
+			t.Fatalf("expected ErrProjectNotFound listing tasks of deleted project; got %v", err)
+		}
+		if _, err := s.InsertTask(ctx, deleted.ID, NewTask{Title: "T2"}); err != ErrProjectNotFound {
+			t.Fatalf("expected ErrProjectNotFound inserting into deleted project; got %v", err)
+		}
+
+		restored, err := s.RestoreProject(ctx, deleted.ID)
+		if err != nil {
+			t.Fatalf("RestoreProject: %v", err)
+		}
+		if restored.DeletedAt != nil || restored.ArchivedAt != nil {
+			t.Fatalf("expected restored project to be live; got %+v", restored)
+		}
+		_, total, err := s.ListTasks(ctx, deleted.ID, ListTasksParams{Limit: 10})
+		if err != nil || total != 1 {
+			t.Fatalf("expected restored project to keep its task; total=%d err=%v", total, err)
+		}
+
+		// Purge only touches projects that are soft-deleted before the cutoff.
+		if err := s.DeleteProject(ctx, live.ID); err != nil {
+			t.Fatalf("DeleteProject: %v", err)
+		}
+		if n, err := s.PurgeDeletedProjects(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+			t.Fatalf("expected nothing purged before cutoff; n=%d err=%v", n, err)
+		}
+		if n, err := s.PurgeDeletedProjects(ctx, time.Now().Add(time.Hour)); err != nil || n != 1 {
+			t.Fatalf("expected 1 project purged; n=%d err=%v", n, err)
+		}
+		if _, err := s.RestoreProject(ctx, live.ID); err != ErrNotFound {
+			t.Fatalf("expected purged project to be gone; got %v", err)
+		}
+	})
+}
+
+func TestParity_TaskAssignees(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		ada, err := s.InsertUser(ctx, "Ada", "ada@example.com")
+		if err != nil {
+			t.Fatalf("InsertUser: %v", err)
+		}
+		if _, err := s.InsertUser(ctx, "Ada again", "ada@example.com"); err != ErrEmailTaken {
+			t.Fatalf("expected ErrEmailTaken; got %v", err)
+		}
+		if got, err := s.GetUser(ctx, ada.ID); err != nil || got.Email != "ada@example.com" {
+			t.Fatalf("GetUser: %+v, %v", got, err)
+		}
+		if _, err := s.GetUser(ctx, uuid.New()); err != ErrUserNotFound {
+			t.Fatalf("expected ErrUserNotFound; got %v", err)
+		}
+
+		alpha, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		beta, err := s.InsertProject(ctx, NewProject{Name: "Beta"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+
+		unknown := uuid.New()
+		if _, err := s.InsertTask(ctx, alpha.ID, NewTask{Title: "T0", AssigneeID: &unknown}); err != ErrUserNotFound {
+			t.Fatalf("expected ErrUserNotFound assigning unknown user; got %v", err)
+		}
+
+		t1, err := s.InsertTask(ctx, alpha.ID, NewTask{Title: "T1", AssigneeID: &ada.ID})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		if t1.AssigneeID == nil || *t1.AssigneeID != ada.ID {
+			t.Fatalf("expected task assigned to ada; got %+v", t1)
+		}
+		t2, err := s.InsertTask(ctx, beta.ID, NewTask{Title: "T2"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		if _, err := s.UpdateTask(ctx, beta.ID, t2.ID, TaskUpdate{AssigneeID: &unknown}); err != ErrUserNotFound {
+			t.Fatalf("expected ErrUserNotFound reassigning to unknown user; got %v", err)
+		}
+		if _, err := s.UpdateTask(ctx, beta.ID, t2.ID, TaskUpdate{AssigneeID: &ada.ID}); err != nil {
+			t.Fatalf("UpdateTask assign: %v", err)
+		}
+
+		tasks, total, err := s.ListUserTasks(ctx, ada.ID, ListUserTasksParams{Limit: 10})
+		if err != nil || total != 2 || len(tasks) != 2 || tasks[0].ID != t2.ID {
+			t.Fatalf("expected both tasks newest first; total=%d tasks=%+v err=%v", total, tasks, err)
+		}
+
+		done := "done"
+		if _, err := s.UpdateTask(ctx, alpha.ID, t1.ID, TaskUpdate{Status: &done}); err != nil {
+			t.Fatalf("UpdateTask status: %v", err)
+		}
+		if _, total, err := s.ListUserTasks(ctx, ada.ID, ListUserTasksParams{Limit: 10, Statuses: []string{"todo"}}); err != nil || total != 1 {
+			t.Fatalf("expected 1 open task; total=%d err=%v", total, err)
+		}
+
+		// Unassigning and soft-deleting both drop tasks from the listing.
+		nilID := uuid.Nil
+		unassigned, err := s.UpdateTask(ctx, beta.ID, t2.ID, TaskUpdate{AssigneeID: &nilID})
+		if err != nil || unassigned.AssigneeID != nil {
+			t.Fatalf("expected task unassigned; got %+v, %v", unassigned, err)
+		}
+		if err := s.DeleteProject(ctx, alpha.ID); err != nil {
+			t.Fatalf("DeleteProject: %v", err)
+		}
+		tasks, total, err = s.ListUserTasks(ctx, ada.ID, ListUserTasksParams{Limit: 10})
+		if err != nil || total != 0 || tasks == nil || len(tasks) != 0 {
+			t.Fatalf("expected no tasks; total=%d tasks=%v err=%v", total, tasks, err)
+		}
+
+		if _, _, err := s.ListUserTasks(ctx, uuid.New(), ListUserTasksParams{Limit: 10}); err != ErrUserNotFound {
+			t.Fatalf("expected ErrUserNotFound; got %v", err)
+		}
+	})
+}
+
+func TestParity_TaskDueDatesAndPriority(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+		yesterday := now.Add(-24 * time.Hour)
+		lastWeek := now.Add(-7 * 24 * time.Hour)
+		tomorrow := now.Add(24 * time.Hour)
+
+		plain, err := s.InsertTask(ctx, p.ID, NewTask{Title: "Plain"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		if plain.Priority != DefaultTaskPriority || plain.DueDate != nil {
+			t.Fatalf("expected default priority and no due date; got %+v", plain)
+		}
+
+		late, err := s.InsertTask(ctx, p.ID, NewTask{Title: "Late", DueDate: &yesterday, Priority: "urgent"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		if late.Priority != "urgent" || late.DueDate == nil || !late.DueDate.Equal(yesterday) {
+			t.Fatalf("unexpected task: %+v", late)
+		}
+		later, err := s.InsertTask(ctx, p.ID, NewTask{Title: "Later", DueDate: &lastWeek})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		if _, err := s.InsertTask(ctx, p.ID, NewTask{Title: "Soon", DueDate: &tomorrow}); err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+
+		tasks, total, err := s.ListTasks(ctx, p.ID, ListTasksParams{Limit: 10, DueBefore: &now, Sort: TaskSortTitle})
+		if err != nil || total != 2 || tasks[0].Title != "Late" || tasks[1].Title != "Later" {
+			t.Fatalf("expected Late and Later due before now; total=%d tasks=%+v err=%v", total, tasks, err)
+		}
+		if _, total, err := s.ListTasks(ctx, p.ID, ListTasksParams{Limit: 10, DueAfter: &lastWeek, DueBefore: &tomorrow}); err != nil || total != 1 {
+			t.Fatalf("expected only Late between last week and tomorrow; total=%d err=%v", total, err)
+		}
+
+		overdue, total, err := s.ListOverdueTasks(ctx, ListOverdueTasksParams{AsOf: now, Limit: 10})
+		if err != nil || total != 2 || overdue[0].ID != later.ID || overdue[1].ID != late.ID {
+			t.Fatalf("expected Later then Late overdue; total=%d tasks=%+v err=%v", total, overdue, err)
+		}
+
+		// Done tasks and cleared due dates are no longer overdue.
+		done := "done"
+		high := "high"
+		updated, err := s.UpdateTask(ctx, p.ID, late.ID, TaskUpdate{Status: &done, Priority: &high})
+		if err != nil || updated.Priority != "high" {
+			t.Fatalf("UpdateTask: %+v, %v", updated, err)
+		}
+		cleared, err := s.UpdateTask(ctx, p.ID, later.ID, TaskUpdate{DueDate: &time.Time{}})
+		if err != nil || cleared.DueDate != nil {
+			t.Fatalf("expected due date cleared; got %+v, %v", cleared, err)
+		}
+		overdue, total, err = s.ListOverdueTasks(ctx, ListOverdueTasksParams{AsOf: now, Limit: 10})
+		if err != nil || total != 0 || len(overdue) != 0 {
+			t.Fatalf("expected nothing overdue; total=%d tasks=%+v err=%v", total, overdue, err)
+		}
+	})
+}
+
+func TestParity_Labels(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		other, err := s.InsertProject(ctx, NewProject{Name: "Beta"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+
+		bug, err := s.InsertLabel(ctx, p.ID, "bug", "#ff0000")
+		if err != nil {
+			t.Fatalf("InsertLabel: %v", err)
+		}
+		backend, err := s.InsertLabel(ctx, p.ID, "backend", "")
+		if err != nil {
+			t.Fatalf("InsertLabel: %v", err)
+		}
+		if _, err := s.InsertLabel(ctx, p.ID, "bug", ""); err != ErrLabelExists {
+			t.Fatalf("expected ErrLabelExists; got %v", err)
+		}
+		if _, err := s.InsertLabel(ctx, other.ID, "bug", ""); err != nil {
+			t.Fatalf("expected label names to be per project; got %v", err)
+		}
+		if _, err := s.InsertLabel(ctx, uuid.New(), "bug", ""); err != ErrProjectNotFound {
+			t.Fatalf("expected ErrProjectNotFound; got %v", err)
+		}
+
+		labels, err := s.ListLabels(ctx, p.ID)
+		if err != nil || len(labels) != 2 || labels[0].Name != "backend" || labels[1].Name != "bug" {
+			t.Fatalf("expected [backend bug]; got %+v, %v", labels, err)
+		}
+		if _, err := s.GetLabel(ctx, other.ID, bug.ID); err != ErrLabelNotFound {
+			t.Fatalf("expected ErrLabelNotFound via other project; got %v", err)
+		}
+
+		t1, err := s.InsertTask(ctx, p.ID, NewTask{Title: "T1"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		if t1.Labels == nil || len(t1.Labels) != 0 {
+			t.Fatalf("expected empty labels; got %#v", t1.Labels)
+		}
+		if _, err := s.InsertTask(ctx, p.ID, NewTask{Title: "T2"}); err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+
+		if _, err := s.AttachLabel(ctx, p.ID, t1.ID, bug.ID); err != nil {
+			t.Fatalf("AttachLabel: %v", err)
+		}
+		got, err := s.AttachLabel(ctx, p.ID, t1.ID, bug.ID)
+		if err != nil {
+			t.Fatalf("AttachLabel twice: %v", err)
+		}
+		got, err = s.AttachLabel(ctx, p.ID, t1.ID, backend.ID)
+		if err != nil || len(got.Labels) != 2 || got.Labels[0].Name != "backend" || got.Labels[1].Name != "bug" {
+			t.Fatalf("expected [backend bug] on task; got %+v, %v", got.Labels, err)
+		}
+		if _, err := s.AttachLabel(ctx, p.ID, uuid.New(), bug.ID); err != ErrTaskNotFound {
+			t.Fatalf("expected ErrTaskNotFound; got %v", err)
+		}
+		if _, err := s.AttachLabel(ctx, p.ID, t1.ID, uuid.New()); err != ErrLabelNotFound {
+			t.Fatalf("expected ErrLabelNotFound; got %v", err)
+		}
+
+		tasks, total, err := s.ListTasks(ctx, p.ID, ListTasksParams{Limit: 10, Labels: []string{"bug", "infra"}})
+		if err != nil || total != 1 || tasks[0].ID != t1.ID || len(tasks[0].Labels) != 2 {
+			t.Fatalf("expected only T1 with its labels; total=%d tasks=%+v err=%v", total, tasks, err)
+		}
+
+		got, err = s.DetachLabel(ctx, p.ID, t1.ID, bug.ID)
+		if err != nil || len(got.Labels) != 1 || got.Labels[0].ID != backend.ID {
+			t.Fatalf("expected only backend left; got %+v, %v", got.Labels, err)
+		}
+
+		renamed := "api"
+		if _, err := s.UpdateLabel(ctx, p.ID, backend.ID, LabelUpdate{Name: &renamed}); err != nil {
+			t.Fatalf("UpdateLabel: %v", err)
+		}
+		taken := "bug"
+		if _, err := s.UpdateLabel(ctx, p.ID, backend.ID, LabelUpdate{Name: &taken}); err != ErrLabelExists {
+			t.Fatalf("expected ErrLabelExists on rename; got %v", err)
+		}
+		got, err = s.GetTask(ctx, p.ID, t1.ID)
+		if err != nil || len(got.Labels) != 1 || got.Labels[0].Name != "api" {
+			t.Fatalf("expected renamed label on task; got %+v, %v", got.Labels, err)
+		}
+
+		if err := s.DeleteLabel(ctx, p.ID, backend.ID); err != nil {
+			t.Fatalf("DeleteLabel: %v", err)
+		}
+		if err := s.DeleteLabel(ctx, p.ID, backend.ID); err != ErrLabelNotFound {
+			t.Fatalf("expected ErrLabelNotFound; got %v", err)
+		}
+		got, err = s.GetTask(ctx, p.ID, t1.ID)
+		if err != nil || len(got.Labels) != 0 {
+			t.Fatalf("expected deleted label detached; got %+v, %v", got.Labels, err)
+		}
+	})
+}
+
+func TestParity_Comments(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		author, err := s.InsertUser(ctx, "Ada", "ada@example.com")
+		if err != nil {
+			t.Fatalf("InsertUser: %v", err)
+		}
+		p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		task, err := s.InsertTask(ctx, p.ID, NewTask{Title: "T1"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		other, err := s.InsertTask(ctx, p.ID, NewTask{Title: "T2"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+
+		comments, total, err := s.ListComments(ctx, p.ID, task.ID, ListCommentsParams{Limit: 10})
+		if err != nil || total != 0 || comments == nil {
+			t.Fatalf("expected empty list; got %v, %d, %v", comments, total, err)
+		}
+
+		root, err := s.InsertComment(ctx, p.ID, task.ID, NewComment{AuthorID: author.ID, Body: "first"})
+		if err != nil {
+			t.Fatalf("InsertComment: %v", err)
+		}
+		reply, err := s.InsertComment(ctx, p.ID, task.ID, NewComment{AuthorID: author.ID, ParentID: &root.ID, Body: "reply"})
+		if err != nil {
+			t.Fatalf("InsertComment reply: %v", err)
+		}
+		if reply.ParentID == nil || *reply.ParentID != root.ID {
+			t.Fatalf("expected reply to point at root; got %+v", reply)
+		}
+		if _, err := s.InsertComment(ctx, p.ID, task.ID, NewComment{AuthorID: author.ID, ParentID: &reply.ID, Body: "nested"}); err != nil {
+			t.Fatalf("InsertComment nested reply: %v", err)
+		}
+
+		if _, err := s.InsertComment(ctx, p.ID, other.ID, NewComment{AuthorID: author.ID, ParentID: &root.ID, Body: "x"}); err != ErrCommentNotFound {
+			t.Fatalf("expected ErrCommentNotFound for parent on another task; got %v", err)
+		}
+		if _, err := s.InsertComment(ctx, p.ID, task.ID, NewComment{AuthorID: uuid.New(), Body: "x"}); err != ErrUserNotFound {
+			t.Fatalf("expected ErrUserNotFound; got %v", err)
+		}
+		if _, err := s.InsertComment(ctx, p.ID, uuid.New(), NewComment{AuthorID: author.ID, Body: "x"}); err != ErrTaskNotFound {
+			t.Fatalf("expected ErrTaskNotFound; got %v", err)
+		}
+		if _, err := s.GetComment(ctx, p.ID, other.ID, root.ID); err != ErrCommentNotFound {
+			t.Fatalf("expected ErrCommentNotFound via other task; got %v", err)
+		}
+
+		comments, total, err = s.ListComments(ctx, p.ID, task.ID, ListCommentsParams{Limit: 2})
+		if err != nil || total != 3 || len(comments) != 2 || comments[0].ID != root.ID || comments[1].ID != reply.ID {
+			t.Fatalf("expected first page [root reply] of 3; got %+v, %d, %v", comments, total, err)
+		}
+
+		edited, err := s.UpdateComment(ctx, p.ID, task.ID, root.ID, "edited")
+		if err != nil || edited.Body != "edited" || edited.UpdatedAt == nil {
+			t.Fatalf("UpdateComment: %+v, %v", edited, err)
+		}
+
+		// Deleting a comment takes its whole thread with it.
+		if err := s.DeleteComment(ctx, p.ID, task.ID, root.ID); err != nil {
+			t.Fatalf("DeleteComment: %v", err)
+		}
+		if _, total, err := s.ListComments(ctx, p.ID, task.ID, ListCommentsParams{Limit: 10}); err != nil || total != 0 {
+			t.Fatalf("expected replies deleted with their parent; total=%d err=%v", total, err)
+		}
+		if err := s.DeleteComment(ctx, p.ID, task.ID, root.ID); err != ErrCommentNotFound {
+			t.Fatalf("expected ErrCommentNotFound; got %v", err)
+		}
+	})
+}
+
+func TestParity_Subtasks(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		otherProject, err := s.InsertProject(ctx, NewProject{Name: "Beta"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		foreign, err := s.InsertTask(ctx, otherProject.ID, NewTask{Title: "foreign"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+
+		root, err := s.InsertTask(ctx, p.ID, NewTask{Title: "root"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		child, err := s.InsertTask(ctx, p.ID, NewTask{Title: "child", ParentTaskID: &root.ID})
+		if err != nil {
+			t.Fatalf("InsertTask child: %v", err)
+		}
+		if child.ParentTaskID == nil || *child.ParentTaskID != root.ID {
+			t.Fatalf("expected child under root; got %+v", child)
+		}
+		grandchild, err := s.InsertTask(ctx, p.ID, NewTask{Title: "grandchild", ParentTaskID: &child.ID})
+		if err != nil {
+			t.Fatalf("InsertTask grandchild: %v", err)
+		}
+		sibling, err := s.InsertTask(ctx, p.ID, NewTask{Title: "sibling", ParentTaskID: &root.ID})
+		if err != nil {
+			t.Fatalf("InsertTask sibling: %v", err)
+		}
+
+		if _, err := s.InsertTask(ctx, p.ID, NewTask{Title: "x", ParentTaskID: &foreign.ID}); err != ErrParentTaskNotFound {
+			t.Fatalf("expected ErrParentTaskNotFound for parent in another project; got %v", err)
+		}
+		missing := uuid.New()
+		if _, err := s.InsertTask(ctx, p.ID, NewTask{Title: "x", ParentTaskID: &missing}); err != ErrParentTaskNotFound {
+			t.Fatalf("expected ErrParentTaskNotFound; got %v", err)
+		}
+
+		if _, err := s.UpdateTask(ctx, p.ID, root.ID, TaskUpdate{ParentTaskID: &grandchild.ID}); err != ErrTaskCycle {
+			t.Fatalf("expected ErrTaskCycle moving root under its grandchild; got %v", err)
+		}
+		if _, err := s.UpdateTask(ctx, p.ID, root.ID, TaskUpdate{ParentTaskID: &root.ID}); err != ErrTaskCycle {
+			t.Fatalf("expected ErrTaskCycle nesting a task under itself; got %v", err)
+		}
+		if _, err := s.UpdateTask(ctx, p.ID, root.ID, TaskUpdate{ParentTaskID: &foreign.ID}); err != ErrParentTaskNotFound {
+			t.Fatalf("expected ErrParentTaskNotFound; got %v", err)
+		}
+
+		done := "done"
+		if _, err := s.UpdateTask(ctx, p.ID, sibling.ID, TaskUpdate{Status: &done}); err != nil {
+			t.Fatalf("UpdateTask: %v", err)
+		}
+		got, err := s.GetTask(ctx, p.ID, root.ID)
+		if err != nil || got.Subtasks != (domain.SubtaskRollup{Done: 1, Total: 2}) {
+			t.Fatalf("expected rollup 1/2; got %+v, %v", got.Subtasks, err)
+		}
+
+		subtasks, total, err := s.ListTasks(ctx, p.ID, ListTasksParams{Limit: 10, ParentTaskID: &root.ID, Sort: TaskSortOldest})
+		if err != nil || total != 2 || len(subtasks) != 2 || subtasks[0].ID != child.ID || subtasks[1].ID != sibling.ID {
+			t.Fatalf("expected [child sibling]; got %+v, %d, %v", subtasks, total, err)
+		}
+		if subtasks[0].Subtasks.Total != 1 {
+			t.Fatalf("expected listed child to carry its rollup; got %+v", subtasks[0].Subtasks)
+		}
+
+		// Reparent: the grandchild moves up to root
+		if err := s.DeleteTask(ctx, p.ID, child.ID, SubtasksReparent); err != nil {
+			t.Fatalf("DeleteTask reparent: %v", err)
+		}
+		got, err = s.GetTask(ctx, p.ID, grandchild.ID)
+		if err != nil || got.ParentTaskID == nil || *got.ParentTaskID != root.ID {
+			t.Fatalf("expected grandchild reparented to root; got %+v, %v", got, err)
+		}
+
+		moved, err := s.UpdateTask(ctx, p.ID, grandchild.ID, TaskUpdate{ParentTaskID: &uuid.Nil})
+		if err != nil || moved.ParentTaskID != nil {
+			t.Fatalf("expected grandchild at top level; got %+v, %v", moved, err)
+		}
+		if _, err := s.UpdateTask(ctx, p.ID, grandchild.ID, TaskUpdate{ParentTaskID: &sibling.ID}); err != nil {
+			t.Fatalf("UpdateTask move under sibling: %v", err)
+		}
+
+		// Cascade: root takes sibling and grandchild with it
+		if err := s.DeleteTask(ctx, p.ID, root.ID, SubtasksCascade); err != nil {
+			t.Fatalf("DeleteTask cascade: %v", err)
+		}
+		for _, id := range []uuid.UUID{root.ID, sibling.ID, grandchild.ID} {
+			if _, err := s.GetTask(ctx, p.ID, id); err != ErrTaskNotFound {
+				t.Fatalf("expected task %s deleted; got %v", id, err)
+			}
+		}
+		if _, err := s.GetTask(ctx, otherProject.ID, foreign.ID); err != nil {
+			t.Fatalf("expected foreign task untouched; got %v", err)
+		}
+	})
+}
+
+func TestParity_TaskDependencies(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		otherProject, err := s.InsertProject(ctx, NewProject{Name: "Beta"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		foreign, err := s.InsertTask(ctx, otherProject.ID, NewTask{Title: "foreign"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+
+		var a, b, c domain.Task
+		for _, tp := range []struct {
+			task  *domain.Task
+			title string
+		}{{&a, "A"}, {&b, "B"}, {&c, "C"}} {
+			if *tp.task, err = s.InsertTask(ctx, p.ID, NewTask{Title: tp.title}); err != nil {
+				t.Fatalf("InsertTask: %v", err)
+			}
+		}
+		if a.BlockedBy == nil || len(a.BlockedBy) != 0 {
+			t.Fatalf("expected empty blockedBy; got %#v", a.BlockedBy)
+		}
+
+		// C is blocked by B, which is blocked by A
+		got, err := s.AddTaskBlocker(ctx, p.ID, b.ID, a.ID)
+		if err != nil || len(got.BlockedBy) != 1 || got.BlockedBy[0] != a.ID {
+			t.Fatalf("AddTaskBlocker: %+v, %v", got.BlockedBy, err)
+		}
+		if _, err := s.AddTaskBlocker(ctx, p.ID, c.ID, b.ID); err != nil {
+			t.Fatalf("AddTaskBlocker: %v", err)
+		}
+		if got, err := s.AddTaskBlocker(ctx, p.ID, b.ID, a.ID); err != nil || len(got.BlockedBy) != 1 {
+			t.Fatalf("expected re-adding to be a no-op; got %+v, %v", got.BlockedBy, err)
+		}
+
+		if _, err := s.AddTaskBlocker(ctx, p.ID, a.ID, c.ID); err != ErrDependencyCycle {
+			t.Fatalf("expected ErrDependencyCycle for A blocked by C; got %v", err)
+		}
+		if _, err := s.AddTaskBlocker(ctx, p.ID, a.ID, a.ID); err != ErrDependencyCycle {
+			t.Fatalf("expected ErrDependencyCycle for a self dependency; got %v", err)
+		}
+		if _, err := s.AddTaskBlocker(ctx, p.ID, a.ID, foreign.ID); err != ErrTaskNotFound {
+			t.Fatalf("expected ErrTaskNotFound for a blocker in another project; got %v", err)
+		}
+
+		blocked, total, err := s.ListBlockedTasks(ctx, p.ID, ListBlockedTasksParams{Limit: 10})
+		if err != nil || total != 2 || len(blocked) != 2 || blocked[0].ID != c.ID || blocked[1].ID != b.ID {
+			t.Fatalf("expected [C B] blocked; got %+v, %d, %v", blocked, total, err)
+		}
+
+		doing, done := "doing", "done"
+		if _, err := s.UpdateTask(ctx, p.ID, b.ID, TaskUpdate{Status: &doing}); err != ErrTaskBlocked {
+			t.Fatalf("expected ErrTaskBlocked; got %v", err)
+		}
+		if _, err := s.UpdateTask(ctx, p.ID, a.ID, TaskUpdate{Status: &done}); err != nil {
+			t.Fatalf("UpdateTask A done: %v", err)
+		}
+		if _, err := s.UpdateTask(ctx, p.ID, b.ID, TaskUpdate{Status: &doing}); err != nil {
+			t.Fatalf("expected B to start once A is done; got %v", err)
+		}
+
+		blocked, total, err = s.ListBlockedTasks(ctx, p.ID, ListBlockedTasksParams{Limit: 10})
+		if err != nil || total != 1 || blocked[0].ID != c.ID {
+			t.Fatalf("expected only C blocked; got %+v, %d, %v", blocked, total, err)
+		}
+		blockers, err := s.ListTaskBlockers(ctx, p.ID, c.ID)
+		if err != nil || len(blockers) != 1 || blockers[0].ID != b.ID {
+			t.Fatalf("expected C blocked by B; got %+v, %v", blockers, err)
+		}
+
+		if got, err := s.RemoveTaskBlocker(ctx, p.ID, c.ID, b.ID); err != nil || len(got.BlockedBy) != 0 {
+			t.Fatalf("RemoveTaskBlocker: %+v, %v", got.BlockedBy, err)
+		}
+		if _, err := s.RemoveTaskBlocker(ctx, p.ID, c.ID, b.ID); err != nil {
+			t.Fatalf("expected removing twice to be a no-op; got %v", err)
+		}
+
+		if err := s.DeleteTask(ctx, p.ID, a.ID, SubtasksReparent); err != nil {
+			t.Fatalf("DeleteTask: %v", err)
+		}
+		if got, err := s.GetTask(ctx, p.ID, b.ID); err != nil || len(got.BlockedBy) != 0 {
+			t.Fatalf("expected deleting a blocker to drop the dependency; got %+v, %v", got.BlockedBy, err)
+		}
+
+		if _, _, err := s.ListBlockedTasks(ctx, uuid.New(), ListBlockedTasksParams{Limit: 10}); err != ErrProjectNotFound {
+			t.Fatalf("expected ErrProjectNotFound; got %v", err)
+		}
+	})
+}
+
+func TestParity_Workflows(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		p, err := s.InsertProject(ctx, NewProject{Name: "Support"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+
+		wf, err := s.GetWorkflow(ctx, p.ID)
+		if err != nil || len(wf.Statuses) != 3 || wf.Statuses[0].Name != "todo" {
+			t.Fatalf("expected default workflow; got %+v, %v", wf, err)
+		}
+		legacy, err := s.InsertTask(ctx, p.ID, NewTask{Title: "legacy"})
+		if err != nil || legacy.Status != "todo" || legacy.StatusCategory != domain.StatusCategoryTodo {
+			t.Fatalf("expected todo task; got %+v, %v", legacy, err)
+		}
+
+		support := domain.Workflow{
+			Statuses: []domain.WorkflowStatus{
+				{Name: "triage", Category: domain.StatusCategoryTodo},
+				{Name: "waiting on customer", Category: domain.StatusCategoryInProgress},
+				{Name: "closed", Category: domain.StatusCategoryDone},
+			},
+			Transitions: map[string][]string{"triage": {"waiting on customer"}},
+		}
+		if _, err := s.UpdateWorkflow(ctx, p.ID, support); err != ErrStatusInUse {
+			t.Fatalf("expected ErrStatusInUse while a task is in todo; got %v", err)
+		}
+
+		// Keep todo around (as done) so the legacy task has somewhere to live
+		support.Statuses = append(support.Statuses, domain.WorkflowStatus{Name: "todo", Category: domain.StatusCategoryDone})
+		if _, err := s.UpdateWorkflow(ctx, p.ID, support); err != nil {
+			t.Fatalf("UpdateWorkflow: %v", err)
+		}
+		got, err := s.GetTask(ctx, p.ID, legacy.ID)
+		if err != nil || got.StatusCategory != domain.StatusCategoryDone {
+			t.Fatalf("expected legacy task recategorised as done; got %+v, %v", got, err)
+		}
+		wf, err = s.GetWorkflow(ctx, p.ID)
+		if err != nil || len(wf.Statuses) != 4 || wf.Transitions["triage"][0] != "waiting on customer" {
+			t.Fatalf("expected stored workflow; got %+v, %v", wf, err)
+		}
+
+		ticket, err := s.InsertTask(ctx, p.ID, NewTask{Title: "ticket"})
+		if err != nil || ticket.Status != "triage" {
+			t.Fatalf("expected new task in triage; got %+v, %v", ticket, err)
+		}
+
+		doing, closed, waiting := "doing", "closed", "waiting on customer"
+		if _, err := s.UpdateTask(ctx, p.ID, ticket.ID, TaskUpdate{Status: &doing}); err != ErrUnknownStatus {
+			t.Fatalf("expected ErrUnknownStatus; got %v", err)
+		}
+		if _, err := s.UpdateTask(ctx, p.ID, ticket.ID, TaskUpdate{Status: &closed}); err != ErrTransitionNotAllowed {
+			t.Fatalf("expected ErrTransitionNotAllowed for triage -> closed; got %v", err)
+		}
+		if _, err := s.UpdateTask(ctx, p.ID, ticket.ID, TaskUpdate{Status: &waiting}); err != nil {
+			t.Fatalf("UpdateTask triage -> waiting: %v", err)
+		}
+		// waiting on customer has no transition entry, so anything goes
+		got, err = s.UpdateTask(ctx, p.ID, ticket.ID, TaskUpdate{Status: &closed})
+		if err != nil || got.StatusCategory != domain.StatusCategoryDone {
+			t.Fatalf("UpdateTask waiting -> closed: %+v, %v", got, err)
+		}
+
+		// Closed counts as done for blockers
+		blocked, err := s.InsertTask(ctx, p.ID, NewTask{Title: "follow-up"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		if _, err := s.AddTaskBlocker(ctx, p.ID, blocked.ID, ticket.ID); err != nil {
+			t.Fatalf("AddTaskBlocker: %v", err)
+		}
+		if _, total, err := s.ListBlockedTasks(ctx, p.ID, ListBlockedTasksParams{Limit: 10}); err != nil || total != 0 {
+			t.Fatalf("expected nothing blocked by a closed ticket; got %d, %v", total, err)
+		}
+
+		if _, err := s.GetWorkflow(ctx, uuid.New()); err != ErrProjectNotFound {
+			t.Fatalf("expected ErrProjectNotFound; got %v", err)
+		}
+		if _, err := s.UpdateWorkflow(ctx, uuid.New(), support); err != ErrProjectNotFound {
+			t.Fatalf("expected ErrProjectNotFound; got %v", err)
+		}
+	})
+}
+
+func TestParity_Board(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		p, err := s.InsertProject(ctx, NewProject{Name: "Board"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+
+		var ids []uuid.UUID
+		for _, title := range []string{"a", "b", "c"} {
+			task, err := s.InsertTask(ctx, p.ID, NewTask{Title: title})
+			if err != nil {
+				t.Fatalf("InsertTask: %v", err)
+			}
+			ids = append(ids, task.ID)
+		}
+		a, b, c := ids[0], ids[1], ids[2]
+
+		column := func(status string) []uuid.UUID {
+			t.Helper()
+			tasks, err := s.ListBoardTasks(ctx, p.ID)
+			if err != nil {
+				t.Fatalf("ListBoardTasks: %v", err)
+			}
+			var got []uuid.UUID
+			for _, task := range tasks {
+				if task.Status == status {
+					got = append(got, task.ID)
+				}
+			}
+			return got
+		}
+		same := func(got, want []uuid.UUID) bool {
+			if len(got) != len(want) {
+				return false
+			}
+			for i := range got {
+				if got[i] != want[i] {
+					return false
+				}
+			}
+			return true
+		}
+
+		if got := column("todo"); !same(got, []uuid.UUID{a, b, c}) {
+			t.Fatalf("expected insertion order; got %v", got)
+		}
+
+		// c between a and b, then a to the bottom
+		if _, err := s.MoveTask(ctx, p.ID, c, TaskMove{AfterID: &a, BeforeID: &b}); err != nil {
+			t.Fatalf("MoveTask: %v", err)
+		}
+		if _, err := s.MoveTask(ctx, p.ID, a, TaskMove{}); err != nil {
+			t.Fatalf("MoveTask: %v", err)
+		}
+		if got := column("todo"); !same(got, []uuid.UUID{c, b, a}) {
+			t.Fatalf("expected c, b, a; got %v", got)
+		}
+
+		// A stale pair (b is no longer right after c) is a conflict
+		if _, err := s.MoveTask(ctx, p.ID, a, TaskMove{AfterID: &b, BeforeID: &c}); err != ErrMoveConflict {
+			t.Fatalf("expected ErrMoveConflict; got %v", err)
+		}
+
+		// Into another column, on top of nothing
+		doing := "doing"
+		moved, err := s.MoveTask(ctx, p.ID, b, TaskMove{Status: &doing})
+		if err != nil || moved.Status != doing || moved.StatusCategory != domain.StatusCategoryInProgress {
+			t.Fatalf("expected b in progress; got %+v, %v", moved, err)
+		}
+		if _, err := s.MoveTask(ctx, p.ID, c, TaskMove{Status: &doing, BeforeID: &b}); err != nil {
+			t.Fatalf("MoveTask: %v", err)
+		}
+		if got := column(doing); !same(got, []uuid.UUID{c, b}) {
+			t.Fatalf("expected c, b in progress; got %v", got)
+		}
+
+		// Neighbours must be in the target column
+		if _, err := s.MoveTask(ctx, p.ID, a, TaskMove{AfterID: &b}); err != ErrMoveTargetInvalid {
+			t.Fatalf("expected ErrMoveTargetInvalid; got %v", err)
+		}
+		if _, err := s.MoveTask(ctx, p.ID, a, TaskMove{AfterID: &a}); err != ErrMoveTargetInvalid {
+			t.Fatalf("expected ErrMoveTargetInvalid for the task itself; got %v", err)
+		}
+
+		// Repeatedly dropping into the same gap eventually renumbers the column
+		for i := 0; i < 60; i++ {
+			if _, err := s.MoveTask(ctx, p.ID, a, TaskMove{Status: &doing, AfterID: &c, BeforeID: &b}); err != nil {
+				t.Fatalf("MoveTask #%d: %v", i, err)
+			}
+			if _, err := s.MoveTask(ctx, p.ID, b, TaskMove{AfterID: &c, BeforeID: &a}); err != nil {
+				t.Fatalf("MoveTask #%d: %v", i, err)
+			}
+		}
+		if got := column(doing); !same(got, []uuid.UUID{c, b, a}) {
+			t.Fatalf("expected c, b, a after renumbering; got %v", got)
+		}
+
+		// A PATCH status change drops the task at the bottom of its new column
+		todo := "todo"
+		if _, err := s.UpdateTask(ctx, p.ID, c, TaskUpdate{Status: &todo}); err != nil {
+			t.Fatalf("UpdateTask: %v", err)
+		}
+		if _, err := s.UpdateTask(ctx, p.ID, b, TaskUpdate{Status: &todo}); err != nil {
+			t.Fatalf("UpdateTask: %v", err)
+		}
+		if got := column(todo); !same(got, []uuid.UUID{c, b}) {
+			t.Fatalf("expected c, b in todo; got %v", got)
+		}
+
+		if _, err := s.MoveTask(ctx, p.ID, uuid.New(), TaskMove{}); err != ErrTaskNotFound {
+			t.Fatalf("expected ErrTaskNotFound; got %v", err)
+		}
+		if _, err := s.ListBoardTasks(ctx, uuid.New()); err != ErrProjectNotFound {
+			t.Fatalf("expected ErrProjectNotFound; got %v", err)
+		}
+	})
+}
+
+func TestParity_TransferTasks(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		src, err := s.InsertProject(ctx, NewProject{Name: "Source"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		dst, err := s.InsertProject(ctx, NewProject{Name: "Target"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		if _, err := s.UpdateWorkflow(ctx, dst.ID, domain.Workflow{Statuses: []domain.WorkflowStatus{
+			{Name: "backlog", Category: domain.StatusCategoryTodo},
+			{Name: "doing", Category: domain.StatusCategoryInProgress},
+		}}); err != nil {
+			t.Fatalf("UpdateWorkflow: %v", err)
+		}
+		u, err := s.InsertUser(ctx, "Ada", "ada@example.com")
+		if err != nil {
+			t.Fatalf("InsertUser: %v", err)
+		}
+
+		parent, _ := s.InsertTask(ctx, src.ID, NewTask{Title: "parent"})
+		task, _ := s.InsertTask(ctx, src.ID, NewTask{Title: "task", ParentTaskID: &parent.ID})
+		sub, _ := s.InsertTask(ctx, src.ID, NewTask{Title: "sub", ParentTaskID: &task.ID})
+		other, _ := s.InsertTask(ctx, src.ID, NewTask{Title: "other"})
+		if _, err := s.AddTaskBlocker(ctx, src.ID, task.ID, other.ID); err != nil {
+			t.Fatalf("AddTaskBlocker: %v", err)
+		}
+		if _, err := s.AddTaskBlocker(ctx, src.ID, task.ID, sub.ID); err != nil {
+			t.Fatalf("AddTaskBlocker: %v", err)
+		}
+
+		bug, _ := s.InsertLabel(ctx, src.ID, "bug", "#d73a4a")
+		infra, _ := s.InsertLabel(ctx, src.ID, "infra", "")
+		if _, err := s.InsertLabel(ctx, dst.ID, "bug", "#000000"); err != nil {
+			t.Fatalf("InsertLabel: %v", err)
+		}
+		for _, l := range []domain.Label{bug, infra} {
+			if _, err := s.AttachLabel(ctx, src.ID, task.ID, l.ID); err != nil {
+				t.Fatalf("AttachLabel: %v", err)
+			}
+		}
+		root, err := s.InsertComment(ctx, src.ID, task.ID, NewComment{AuthorID: u.ID, Body: "first"})
+		if err != nil {
+			t.Fatalf("InsertComment: %v", err)
+		}
+		if _, err := s.InsertComment(ctx, src.ID, task.ID, NewComment{AuthorID: u.ID, ParentID: &root.ID, Body: "reply"}); err != nil {
+			t.Fatalf("InsertComment: %v", err)
+		}
+
+		// Copy first, while the task is still in the source project
+		cp, report, err := s.CopyTask(ctx, src.ID, task.ID, dst.ID)
+		if err != nil {
+			t.Fatalf("CopyTask: %v", err)
+		}
+		if cp.ID == task.ID || cp.ProjectID != dst.ID || cp.Title != "task" || cp.Status != "backlog" || cp.ParentTaskID != nil {
+			t.Fatalf("unexpected copy: %+v", cp)
+		}
+		if len(cp.Labels) != 2 || cp.Labels[0].ProjectID != dst.ID || cp.Labels[0].Color != "#000000" {
+			t.Fatalf("expected copy labelled with the target's labels; got %+v", cp.Labels)
+		}
+		if report.Comments != 2 || report.DroppedDependencies != 2 || report.DroppedSubtasks != 1 ||
+			len(report.Labels) != 2 || len(report.CreatedLabels) != 1 || report.CreatedLabels[0] != "infra" {
+			t.Fatalf("unexpected copy report: %+v", report)
+		}
+		comments, _, err := s.ListComments(ctx, dst.ID, cp.ID, ListCommentsParams{Limit: 10})
+		if err != nil || len(comments) != 2 || comments[1].ParentID == nil || *comments[1].ParentID != comments[0].ID {
+			t.Fatalf("expected copied thread; got %+v, %v", comments, err)
+		}
+
+		// Move the task (and its subtask) over
+		moved, report, err := s.TransferTask(ctx, src.ID, task.ID, dst.ID)
+		if err != nil {
+			t.Fatalf("TransferTask: %v", err)
+		}
+		if moved.ID != task.ID || moved.ProjectID != dst.ID || moved.ParentTaskID != nil || moved.Status != "backlog" {
+			t.Fatalf("unexpected moved task: %+v", moved)
+		}
+		if len(moved.BlockedBy) != 1 || moved.BlockedBy[0] != sub.ID || moved.Subtasks.Total != 1 {
+			t.Fatalf("expected the subtask to stay a blocker and subtask; got %+v", moved)
+		}
+		if report.Subtasks != 1 || report.Comments != 2 || report.DroppedDependencies != 1 ||
+			len(report.Labels) != 2 || len(report.CreatedLabels) != 0 {
+			t.Fatalf("unexpected move report: %+v", report)
+		}
+		if len(moved.Labels) != 2 || moved.Labels[1].ProjectID != dst.ID {
+			t.Fatalf("expected moved task relabelled; got %+v", moved.Labels)
+		}
+		if got, err := s.GetTask(ctx, dst.ID, sub.ID); err != nil || got.ParentTaskID == nil || *got.ParentTaskID != task.ID {
+			t.Fatalf("expected subtask in the target under its parent; got %+v, %v", got, err)
+		}
+		if _, err := s.GetTask(ctx, src.ID, task.ID); err != ErrTaskNotFound {
+			t.Fatalf("expected task gone from the source; got %v", err)
+		}
+		if got, err := s.GetTask(ctx, src.ID, parent.ID); err != nil || got.Subtasks.Total != 0 {
+			t.Fatalf("expected old parent without subtasks; got %+v, %v", got, err)
+		}
+		if comments, total, err := s.ListComments(ctx, dst.ID, task.ID, ListCommentsParams{Limit: 10}); err != nil || total != 2 || len(comments) != 2 {
+			t.Fatalf("expected comments to follow the task; got %d, %v", total, err)
+		}
+
+		if _, _, err := s.TransferTask(ctx, dst.ID, task.ID, dst.ID); err != ErrTransferSameProject {
+			t.Fatalf("expected ErrTransferSameProject; got %v", err)
+		}
+		if _, _, err := s.TransferTask(ctx, dst.ID, task.ID, uuid.New()); err != ErrTargetProjectNotFound {
+			t.Fatalf("expected ErrTargetProjectNotFound; got %v", err)
+		}
+		if _, _, err := s.CopyTask(ctx, dst.ID, task.ID, uuid.New()); err != ErrTargetProjectNotFound {
+			t.Fatalf("expected ErrTargetProjectNotFound; got %v", err)
+		}
+		if _, _, err := s.CopyTask(ctx, src.ID, task.ID, dst.ID); err != ErrTaskNotFound {
+			t.Fatalf("expected ErrTaskNotFound; got %v", err)
+		}
+	})
+}
+
+func TestParity_CloneProject(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		src, err := s.InsertProject(ctx, NewProject{Name: "Sprint 1"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		custom := domain.Workflow{Statuses: []domain.WorkflowStatus{
+			{Name: "open", Category: domain.StatusCategoryTodo},
+			{Name: "closed", Category: domain.StatusCategoryDone},
+		}}
+		if _, err := s.UpdateWorkflow(ctx, src.ID, custom); err != nil {
+			t.Fatalf("UpdateWorkflow: %v", err)
+		}
+		bug, _ := s.InsertLabel(ctx, src.ID, "bug", "#d73a4a")
+
+		epic, _ := s.InsertTask(ctx, src.ID, NewTask{Title: "epic"})
+		story, _ := s.InsertTask(ctx, src.ID, NewTask{Title: "story", ParentTaskID: &epic.ID})
+		chore, _ := s.InsertTask(ctx, src.ID, NewTask{Title: "chore"})
+		if _, err := s.AttachLabel(ctx, src.ID, story.ID, bug.ID); err != nil {
+			t.Fatalf("AttachLabel: %v", err)
+		}
+		if _, err := s.AddTaskBlocker(ctx, src.ID, chore.ID, story.ID); err != nil {
+			t.Fatalf("AddTaskBlocker: %v", err)
+		}
+		closed := "closed"
+		if _, err := s.UpdateTask(ctx, src.ID, epic.ID, TaskUpdate{Status: &closed}); err != nil {
+			t.Fatalf("UpdateTask: %v", err)
+		}
+
+		// Settings only
+		bare, err := s.CloneProject(ctx, src.ID, ProjectClone{Name: "Sprint 2"})
+		if err != nil || bare.Name != "Sprint 2" || bare.ID == src.ID {
+			t.Fatalf("unexpected clone: %+v, %v", bare, err)
+		}
+		if wf, err := s.GetWorkflow(ctx, bare.ID); err != nil || len(wf.Statuses) != 2 || wf.Statuses[0].Name != "open" {
+			t.Fatalf("expected the workflow to be copied; got %+v, %v", wf, err)
+		}
+		if labels, err := s.ListLabels(ctx, bare.ID); err != nil || len(labels) != 1 || labels[0].Name != "bug" || labels[0].ID == bug.ID {
+			t.Fatalf("expected the labels to be copied; got %+v, %v", labels, err)
+		}
+		if _, total, err := s.ListTasks(ctx, bare.ID, ListTasksParams{Limit: 10}); err != nil || total != 0 {
+			t.Fatalf("expected no tasks; got %d, %v", total, err)
+		}
+
+		// With tasks, statuses reset
+		full, err := s.CloneProject(ctx, src.ID, ProjectClone{Name: "Sprint 3", IncludeTasks: true, ResetStatuses: true})
+		if err != nil {
+			t.Fatalf("CloneProject: %v", err)
+		}
+		tasks, err := s.ListBoardTasks(ctx, full.ID)
+		if err != nil || len(tasks) != 3 {
+			t.Fatalf("expected 3 copied tasks; got %d, %v", len(tasks), err)
+		}
+		byTitle := make(map[string]domain.Task)
+		for _, task := range tasks {
+			if task.Status != "open" || task.StatusCategory != domain.StatusCategoryTodo {
+				t.Fatalf("expected statuses reset; got %+v", task)
+			}
+			byTitle[task.Title] = task
+		}
+		copiedStory := byTitle["story"]
+		if copiedStory.ParentTaskID == nil || *copiedStory.ParentTaskID != byTitle["epic"].ID {
+			t.Fatalf("expected the hierarchy to be copied; got %+v", copiedStory)
+		}
+		if len(copiedStory.Labels) != 1 || copiedStory.Labels[0].ProjectID != full.ID {
+			t.Fatalf("expected the story's label to be the clone's; got %+v", copiedStory.Labels)
+		}
+		if bl := byTitle["chore"].BlockedBy; len(bl) != 1 || bl[0] != copiedStory.ID {
+			t.Fatalf("expected the dependency to be copied; got %v", bl)
+		}
+		// The epic moved to closed last, so after the reset it sits at the bottom
+		if tasks[2].Title != "epic" {
+			t.Fatalf("expected board order story, chore, epic; got %s, %s, %s", tasks[0].Title, tasks[1].Title, tasks[2].Title)
+		}
+
+		kept, err := s.CloneProject(ctx, src.ID, ProjectClone{Name: "Sprint 4", IncludeTasks: true})
+		if err != nil {
+			t.Fatalf("CloneProject: %v", err)
+		}
+		if tasks, _ := s.ListBoardTasks(ctx, kept.ID); len(tasks) != 3 || tasks[0].Status != "closed" || tasks[0].Title != "epic" {
+			t.Fatalf("expected statuses kept; got %+v", tasks)
+		}
+
+		if _, err := s.CloneProject(ctx, uuid.New(), ProjectClone{Name: "x"}); err != ErrProjectNotFound {
+			t.Fatalf("expected ErrProjectNotFound; got %v", err)
+		}
+	})
+}
+
+func TestParity_Templates(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		tpl, err := s.InsertTemplate(ctx, "Onboarding", []domain.TaskBlueprint{
+			{Title: "Kickoff call", Priority: "high"},
+			{Title: "Provision accounts", Description: "SSO and billing"},
+		})
+		if err != nil || len(tpl.Tasks) != 2 {
+			t.Fatalf("InsertTemplate: %+v, %v", tpl, err)
+		}
+		empty, err := s.InsertTemplate(ctx, "Empty", nil)
+		if err != nil || empty.Tasks == nil {
+			t.Fatalf("expected an empty task list; got %+v, %v", empty, err)
+		}
+
+		got, err := s.GetTemplate(ctx, tpl.ID)
+		if err != nil || got.Name != "Onboarding" || got.Tasks[1].Description != "SSO and billing" {
+			t.Fatalf("GetTemplate: %+v, %v", got, err)
+		}
+		list, err := s.ListTemplates(ctx)
+		if err != nil || len(list) != 2 || list[0].Name != "Empty" {
+			t.Fatalf("ListTemplates: %+v, %v", list, err)
+		}
+
+		p, err := s.InstantiateTemplate(ctx, tpl.ID, "", "")
+		if err != nil || p.Name != "Onboarding" {
+			t.Fatalf("InstantiateTemplate: %+v, %v", p, err)
+		}
+		tasks, err := s.ListBoardTasks(ctx, p.ID)
+		if err != nil || len(tasks) != 2 || tasks[0].Title != "Kickoff call" || tasks[0].Priority != "high" ||
+			tasks[1].Priority != DefaultTaskPriority || tasks[1].Status != "todo" {
+			t.Fatalf("expected the blueprint tasks in order; got %+v, %v", tasks, err)
+		}
+		named, err := s.InstantiateTemplate(ctx, tpl.ID, "Acme onboarding", "")
+		if err != nil || named.Name != "Acme onboarding" || named.ID == p.ID {
+			t.Fatalf("InstantiateTemplate: %+v, %v", named, err)
+		}
+
+		if err := s.DeleteTemplate(ctx, tpl.ID); err != nil {
+			t.Fatalf("DeleteTemplate: %v", err)
+		}
+		if _, err := s.GetTemplate(ctx, tpl.ID); err != ErrTemplateNotFound {
+			t.Fatalf("expected ErrTemplateNotFound; got %v", err)
+		}
+		if err := s.DeleteTemplate(ctx, tpl.ID); err != ErrTemplateNotFound {
+			t.Fatalf("expected ErrTemplateNotFound; got %v", err)
+		}
+		if _, err := s.InstantiateTemplate(ctx, tpl.ID, "", ""); err != ErrTemplateNotFound {
+			t.Fatalf("expected ErrTemplateNotFound; got %v", err)
+		}
+		// Projects made from a template outlive it
+		if _, err := s.GetProject(ctx, p.ID); err != nil {
+			t.Fatalf("GetProject: %v", err)
+		}
+	})
+}
+
+func TestParity_ProjectMetadata(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		ada, err := s.InsertUser(ctx, "Ada", "ada@example.com")
+		if err != nil {
+			t.Fatalf("InsertUser: %v", err)
+		}
+		ops, err := s.InsertProject(ctx, NewProject{
+			Name:        "Operations",
+			Description: "Keeping the lights on",
+			OwnerID:     &ada.ID,
+			Color:       "#1f6feb",
+			Icon:        "wrench",
+			Key:         "OPS",
+		})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		got, err := s.GetProject(ctx, ops.ID)
+		if err != nil {
+			t.Fatalf("GetProject: %v", err)
+		}
+		if got.Description != "Keeping the lights on" || got.OwnerID == nil || *got.OwnerID != ada.ID ||
+			got.Color != "#1f6feb" || got.Icon != "wrench" || got.Key != "OPS" || got.UpdatedAt.IsZero() {
+			t.Fatalf("unexpected project: %+v", got)
+		}
+
+		if _, err := s.InsertProject(ctx, NewProject{Name: "Other ops", Key: "OPS"}); err != ErrProjectKeyTaken {
+			t.Fatalf("expected ErrProjectKeyTaken; got %v", err)
+		}
+		unknown := uuid.New()
+		if _, err := s.InsertProject(ctx, NewProject{Name: "Orphan", OwnerID: &unknown}); err != ErrUserNotFound {
+			t.Fatalf("expected ErrUserNotFound; got %v", err)
+		}
+
+		// Numbers count up per project and are not reused after a delete
+		first, err := s.InsertTask(ctx, ops.ID, NewTask{Title: "First"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		if err := s.DeleteTask(ctx, ops.ID, first.ID, SubtasksReparent); err != nil {
+			t.Fatalf("DeleteTask: %v", err)
+		}
+		second, err := s.InsertTask(ctx, ops.ID, NewTask{Title: "Second"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		if first.Number != 1 || first.Identifier != "OPS-1" || second.Number != 2 || second.Identifier != "OPS-2" {
+			t.Fatalf("unexpected numbering: %d %q, %d %q", first.Number, first.Identifier, second.Number, second.Identifier)
+		}
+
+		web, err := s.InsertProject(ctx, NewProject{Name: "Website"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		plain, err := s.InsertTask(ctx, web.ID, NewTask{Title: "Landing page"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		if plain.Number != 1 || plain.Identifier != "" {
+			t.Fatalf("expected number 1 without identifier; got %d %q", plain.Number, plain.Identifier)
+		}
+
+		// A moved task gets the next number in its new project
+		moved, _, err := s.TransferTask(ctx, ops.ID, second.ID, web.ID)
+		if err != nil {
+			t.Fatalf("TransferTask: %v", err)
+		}
+		if moved.Number != 2 {
+			t.Fatalf("expected moved task to be number 2; got %d", moved.Number)
+		}
+
+		key, owner := "WEB", uuid.Nil
+		updated, err := s.UpdateProject(ctx, web.ID, ProjectUpdate{Key: &key, OwnerID: &owner})
+		if err != nil {
+			t.Fatalf("UpdateProject: %v", err)
+		}
+		if updated.Key != "WEB" || updated.OwnerID != nil || updated.UpdatedAt.Before(updated.CreatedAt) {
+			t.Fatalf("unexpected project: %+v", updated)
+		}
+		task, err := s.GetTask(ctx, web.ID, moved.ID)
+		if err != nil {
+			t.Fatalf("GetTask: %v", err)
+		}
+		if task.Identifier != "WEB-2" {
+			t.Fatalf("expected WEB-2; got %q", task.Identifier)
+		}
+		taken := "OPS"
+		if _, err := s.UpdateProject(ctx, web.ID, ProjectUpdate{Key: &taken}); err != ErrProjectKeyTaken {
+			t.Fatalf("expected ErrProjectKeyTaken; got %v", err)
+		}
+
+		clone, err := s.CloneProject(ctx, ops.ID, ProjectClone{Name: "Operations 2"})
+		if err != nil {
+			t.Fatalf("CloneProject: %v", err)
+		}
+		if clone.Description != ops.Description || clone.Color != ops.Color || clone.Icon != ops.Icon || clone.Key != "" {
+			t.Fatalf("expected metadata without key on clone; got %+v", clone)
+		}
+	})
+}
+
+func TestParity_TaskNumbers(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		p, err := s.InsertProject(ctx, NewProject{Name: "Alpha", Key: "ALP"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+
+		// Concurrent inserts still get distinct numbers with no gaps
+		const n = 20
+		var wg sync.WaitGroup
+		errs := make(chan error, n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if _, err := s.InsertTask(ctx, p.ID, NewTask{Title: fmt.Sprintf("T%d", i)}); err != nil {
+					errs <- err
+				}
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Fatalf("InsertTask: %v", err)
+		}
+
+		seen := make(map[int64]bool, n)
+		for number := int64(1); number <= n; number++ {
+			task, err := s.GetTaskByNumber(ctx, p.ID, number)
+			if err != nil {
+				t.Fatalf("GetTaskByNumber(%d): %v", number, err)
+			}
+			if task.Number != number || task.Identifier != fmt.Sprintf("ALP-%d", number) || seen[number] {
+				t.Fatalf("unexpected task for number %d: %+v", number, task)
+			}
+			seen[number] = true
+		}
+
+		if _, err := s.GetTaskByNumber(ctx, p.ID, n+1); err != ErrTaskNotFound {
+			t.Fatalf("expected ErrTaskNotFound; got %v", err)
+		}
+		if _, err := s.GetTaskByNumber(ctx, uuid.New(), 1); err != ErrProjectNotFound {
+			t.Fatalf("expected ErrProjectNotFound; got %v", err)
+		}
+	})
+}
+
+func TestParity_APIKeys(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		ci, err := s.InsertAPIKey(ctx, NewAPIKey{Name: "ci", Prefix: "pmk_abc", Hash: []byte("hash-ci")})
+		if err != nil {
+			t.Fatalf("InsertAPIKey: %v", err)
+		}
+		if _, err := s.InsertAPIKey(ctx, NewAPIKey{Name: "ops", Prefix: "pmk_def", Hash: []byte("hash-ops"), Admin: true}); err != nil {
+			t.Fatalf("InsertAPIKey: %v", err)
+		}
+
+		got, err := s.GetAPIKeyByHash(ctx, []byte("hash-ci"))
+		if err != nil {
+			t.Fatalf("GetAPIKeyByHash: %v", err)
+		}
+		if got.ID != ci.ID || got.Name != "ci" || got.Prefix != "pmk_abc" || got.Admin || got.RevokedAt != nil {
+			t.Fatalf("unexpected key: %+v", got)
+		}
+		if _, err := s.GetAPIKeyByHash(ctx, []byte("nope")); err != ErrAPIKeyNotFound {
+			t.Fatalf("expected ErrAPIKeyNotFound; got %v", err)
+		}
+
+		keys, err := s.ListAPIKeys(ctx)
+		if err != nil {
+			t.Fatalf("ListAPIKeys: %v", err)
+		}
+		if len(keys) != 2 || keys[0].Name != "ci" || keys[1].Name != "ops" || !keys[1].Admin {
+			t.Fatalf("unexpected keys: %+v", keys)
+		}
+
+		revoked, err := s.RevokeAPIKey(ctx, ci.ID)
+		if err != nil || revoked.RevokedAt == nil {
+			t.Fatalf("RevokeAPIKey: %+v, %v", revoked, err)
+		}
+		again, err := s.RevokeAPIKey(ctx, ci.ID)
+		if err != nil || !again.RevokedAt.Equal(*revoked.RevokedAt) {
+			t.Fatalf("expected the first revocation time to stick; got %+v, %v", again, err)
+		}
+		if got, _ := s.GetAPIKeyByHash(ctx, []byte("hash-ci")); got.RevokedAt == nil {
+			t.Fatalf("expected revoked key; got %+v", got)
+		}
+		if _, err := s.RevokeAPIKey(ctx, uuid.New()); err != ErrAPIKeyNotFound {
+			t.Fatalf("expected ErrAPIKeyNotFound; got %v", err)
+		}
+	})
+}
+
+func TestParity_ProjectMembers(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		p, err := s.InsertProject(ctx, NewProject{Name: "Alpha", CreatedBy: "alice"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		other, err := s.InsertProject(ctx, NewProject{Name: "Beta", CreatedBy: "bob"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+
+		owner, err := s.GetProjectMember(ctx, p.ID, "alice")
+		if err != nil || owner.Role != domain.RoleOwner {
+			t.Fatalf("expected the creator to be owner; got %+v, %v", owner, err)
+		}
+		if _, err := s.GetProjectMember(ctx, p.ID, "bob"); err != ErrMemberNotFound {
+			t.Fatalf("expected ErrMemberNotFound; got %v", err)
+		}
+
+		if _, err := s.PutProjectMember(ctx, p.ID, "bob", domain.RoleViewer); err != nil {
+			t.Fatalf("PutProjectMember: %v", err)
+		}
+		editor, err := s.PutProjectMember(ctx, p.ID, "bob", domain.RoleEditor)
+		if err != nil || editor.Role != domain.RoleEditor {
+			t.Fatalf("PutProjectMember: %+v, %v", editor, err)
+		}
+		members, err := s.ListProjectMembers(ctx, p.ID)
+		if err != nil || len(members) != 2 || members[0].Subject != "alice" || members[1].Subject != "bob" {
+			t.Fatalf("ListProjectMembers: %+v, %v", members, err)
+		}
+
+		// The last owner can be neither demoted nor removed
+		if _, err := s.PutProjectMember(ctx, p.ID, "alice", domain.RoleEditor); err != ErrLastOwner {
+			t.Fatalf("expected ErrLastOwner; got %v", err)
+		}
+		if err := s.RemoveProjectMember(ctx, p.ID, "alice"); err != ErrLastOwner {
+			t.Fatalf("expected ErrLastOwner; got %v", err)
+		}
+		if err := s.RemoveProjectMember(ctx, p.ID, "bob"); err != nil {
+			t.Fatalf("RemoveProjectMember: %v", err)
+		}
+		if err := s.RemoveProjectMember(ctx, p.ID, "bob"); err != ErrMemberNotFound {
+			t.Fatalf("expected ErrMemberNotFound; got %v", err)
+		}
+		if _, err := s.PutProjectMember(ctx, uuid.New(), "bob", domain.RoleViewer); err != ErrProjectNotFound {
+			t.Fatalf("expected ErrProjectNotFound; got %v", err)
+		}
+
+		list, total, err := s.ListProjects(ctx, ListProjectsParams{Limit: 10, Member: "bob"})
+		if err != nil || total != 1 || len(list) != 1 || list[0].ID != other.ID {
+			t.Fatalf("expected only bob's project; got %+v, %d, %v", list, total, err)
+		}
+		if _, total, _ := s.ListProjects(ctx, ListProjectsParams{Limit: 10}); total != 2 {
+			t.Fatalf("expected every project without a member filter; got %d", total)
+		}
+
+		// Clones and instantiated templates belong to whoever created them
+		clone, err := s.CloneProject(ctx, p.ID, ProjectClone{Name: "Alpha (copy)", CreatedBy: "carol"})
+		if err != nil {
+			t.Fatalf("CloneProject: %v", err)
+		}
+		if m, err := s.GetProjectMember(ctx, clone.ID, "carol"); err != nil || m.Role != domain.RoleOwner {
+			t.Fatalf("expected carol to own the clone; got %+v, %v", m, err)
+		}
+		if _, err := s.GetProjectMember(ctx, clone.ID, "alice"); err != ErrMemberNotFound {
+			t.Fatalf("expected members not to be cloned; got %v", err)
+		}
+
+		// AuthorizeProject is the shared role check on top of either store
+		for _, tt := range []struct {
+			subject, want string
+			err           error
+		}{
+			{"alice", domain.RoleOwner, nil},
+			{"bob", domain.RoleViewer, ErrProjectNotFound},
+			{"carol", domain.RoleViewer, ErrProjectNotFound},
+		} {
+			if err := AuthorizeProject(ctx, s, p.ID, tt.subject, tt.want); err != tt.err {
+				t.Fatalf("AuthorizeProject(%s, %s): expected %v; got %v", tt.subject, tt.want, tt.err, err)
+			}
+		}
+		if _, err := s.PutProjectMember(ctx, p.ID, "bob", domain.RoleViewer); err != nil {
+			t.Fatalf("PutProjectMember: %v", err)
+		}
+		if err := AuthorizeProject(ctx, s, p.ID, "bob", domain.RoleEditor); err != ErrForbidden {
+			t.Fatalf("expected a viewer to be refused editing; got %v", err)
+		}
+		if err := AuthorizeProject(ctx, s, p.ID, "bob", domain.RoleViewer); err != nil {
+			t.Fatalf("expected a viewer to read; got %v", err)
+		}
+		if err := AuthorizeProject(ctx, s, uuid.New(), "bob", domain.RoleViewer); err != ErrProjectNotFound {
+			t.Fatalf("expected ErrProjectNotFound; got %v", err)
+		}
+	})
+}
+
+func TestParity_WorkspaceIsolation(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		orgA, err := s.InsertOrganization(ctx, "Acme")
+		if err != nil {
+			t.Fatalf("InsertOrganization: %v", err)
+		}
+		orgB, err := s.InsertOrganization(ctx, "Globex")
+		if err != nil {
+			t.Fatalf("InsertOrganization: %v", err)
+		}
+		ctxA, ctxB := WithWorkspace(ctx, orgA.ID), WithWorkspace(ctx, orgB.ID)
+
+		user, err := s.InsertUser(ctxA, "Ann", "ann@example.com")
+		if err != nil || user.WorkspaceID != orgA.ID {
+			t.Fatalf("InsertUser: %+v, %v", user, err)
+		}
+		p, err := s.InsertProject(ctxA, NewProject{Name: "Alpha", Key: "OPS", CreatedBy: "alice", OwnerID: &user.ID})
+		if err != nil || p.WorkspaceID != orgA.ID {
+			t.Fatalf("InsertProject: %+v, %v", p, err)
+		}
+		task, err := s.InsertTask(ctxA, p.ID, NewTask{Title: "T1", AssigneeID: &user.ID})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		label, err := s.InsertLabel(ctxA, p.ID, "bug", "")
+		if err != nil {
+			t.Fatalf("InsertLabel: %v", err)
+		}
+		tpl, err := s.InsertTemplate(ctxA, "Onboarding", nil)
+		if err != nil {
+			t.Fatalf("InsertTemplate: %v", err)
+		}
+
+		// Keys only need to be unique within a workspace
+		q, err := s.InsertProject(ctxB, NewProject{Name: "Beta", Key: "OPS"})
+		if err != nil || q.WorkspaceID != orgB.ID {
+			t.Fatalf("expected the key to be free in another workspace; got %+v, %v", q, err)
+		}
+		if _, err := s.InsertProject(ctxA, NewProject{Name: "Gamma", Key: "OPS"}); err != ErrProjectKeyTaken {
+			t.Fatalf("expected ErrProjectKeyTaken; got %v", err)
+		}
+		other, err := s.InsertTask(ctxB, q.ID, NewTask{Title: "T2"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+
+		// So do emails, and users cannot be referred to from another workspace
+		if _, err := s.InsertUser(ctxA, "Ann", "ann@example.com"); err != ErrEmailTaken {
+			t.Fatalf("expected ErrEmailTaken; got %v", err)
+		}
+		bUser, err := s.InsertUser(ctxB, "Ann", "ann@example.com")
+		if err != nil || bUser.WorkspaceID != orgB.ID {
+			t.Fatalf("expected the email to be free in another workspace; got %+v, %v", bUser, err)
+		}
+		if _, err := s.GetUser(ctxB, user.ID); err != ErrUserNotFound {
+			t.Fatalf("GetUser: expected ErrUserNotFound; got %v", err)
+		}
+		if _, _, err := s.ListUserTasks(ctxB, user.ID, ListUserTasksParams{Limit: 10}); err != ErrUserNotFound {
+			t.Fatalf("ListUserTasks: expected ErrUserNotFound; got %v", err)
+		}
+		if _, err := s.InsertProject(ctxB, NewProject{Name: "Delta", OwnerID: &user.ID}); err != ErrUserNotFound {
+			t.Fatalf("InsertProject: expected ErrUserNotFound for the owner; got %v", err)
+		}
+		if _, err := s.InsertTask(ctxB, q.ID, NewTask{Title: "T4", AssigneeID: &user.ID}); err != ErrUserNotFound {
+			t.Fatalf("InsertTask: expected ErrUserNotFound for the assignee; got %v", err)
+		}
+		if _, err := s.UpdateTask(ctxB, q.ID, other.ID, TaskUpdate{AssigneeID: &user.ID}); err != ErrUserNotFound {
+			t.Fatalf("UpdateTask: expected ErrUserNotFound for the assignee; got %v", err)
+		}
+		if _, err := s.InsertComment(ctxB, q.ID, other.ID, NewComment{AuthorID: user.ID, Body: "hi"}); err != ErrUserNotFound {
+			t.Fatalf("InsertComment: expected ErrUserNotFound for the author; got %v", err)
+		}
+		// Not even maintenance moves a task, and its assignee, across workspaces
+		if _, _, err := s.CopyTask(AllWorkspaces(ctx), p.ID, task.ID, q.ID); err != ErrTargetProjectNotFound {
+			t.Fatalf("CopyTask: expected ErrTargetProjectNotFound; got %v", err)
+		}
+
+		// Workspace B sees nothing of workspace A
+		if _, err := s.GetProject(ctxB, p.ID); err != ErrNotFound {
+			t.Fatalf("GetProject: expected ErrNotFound; got %v", err)
+		}
+		name := "Mine"
+		if _, err := s.UpdateProject(ctxB, p.ID, ProjectUpdate{Name: &name}); err != ErrNotFound {
+			t.Fatalf("UpdateProject: expected ErrNotFound; got %v", err)
+		}
+		if err := s.DeleteProject(ctxB, p.ID); err != ErrNotFound {
+			t.Fatalf("DeleteProject: expected ErrNotFound; got %v", err)
+		}
+		if _, err := s.GetTask(ctxB, p.ID, task.ID); err != ErrProjectNotFound {
+			t.Fatalf("GetTask: expected ErrProjectNotFound; got %v", err)
+		}
+		if _, err := s.InsertTask(ctxB, p.ID, NewTask{Title: "T3"}); err != ErrProjectNotFound {
+			t.Fatalf("InsertTask: expected ErrProjectNotFound; got %v", err)
+		}
+		if _, err := s.GetLabel(ctxB, p.ID, label.ID); err != ErrProjectNotFound {
+			t.Fatalf("GetLabel: expected ErrProjectNotFound; got %v", err)
+		}
+		if _, err := s.GetProjectMember(ctxB, p.ID, "alice"); err != ErrMemberNotFound {
+			t.Fatalf("GetProjectMember: expected ErrMemberNotFound; got %v", err)
+		}
+		if _, _, err := s.TransferTask(ctxB, q.ID, other.ID, p.ID); err != ErrTargetProjectNotFound {
+			t.Fatalf("TransferTask: expected ErrTargetProjectNotFound; got %v", err)
+		}
+		if _, err := s.GetTemplate(ctxB, tpl.ID); err != ErrTemplateNotFound {
+			t.Fatalf("GetTemplate: expected ErrTemplateNotFound; got %v", err)
+		}
+		if _, err := s.InstantiateTemplate(ctxB, tpl.ID, "", ""); err != ErrTemplateNotFound {
+			t.Fatalf("InstantiateTemplate: expected ErrTemplateNotFound; got %v", err)
+		}
+		if list, _ := s.ListTemplates(ctxB); len(list) != 0 {
+			t.Fatalf("ListTemplates: expected none; got %+v", list)
+		}
+		list, total, err := s.ListProjects(ctxB, ListProjectsParams{Limit: 10})
+		if err != nil || total != 1 || len(list) != 1 || list[0].ID != q.ID {
+			t.Fatalf("ListProjects: expected only Beta; got %+v, %d, %v", list, total, err)
+		}
+		if _, total, _ := s.ListUserTasks(ctxA, user.ID, ListUserTasksParams{Limit: 10}); total != 1 {
+			t.Fatalf("ListUserTasks: expected one task; got %d", total)
+		}
+
+		// An unscoped context is the default workspace, which holds neither
+		if _, total, _ := s.ListProjects(ctx, ListProjectsParams{Limit: 10}); total != 0 {
+			t.Fatalf("expected the default workspace to be empty; got %d", total)
+		}
+
+		// Maintenance sees every workspace
+		if err := s.DeleteProject(ctxA, p.ID); err != nil {
+			t.Fatalf("DeleteProject: %v", err)
+		}
+		if err := s.DeleteProject(ctxB, q.ID); err != nil {
+			t.Fatalf("DeleteProject: %v", err)
+		}
+		n, err := s.PurgeDeletedProjects(AllWorkspaces(ctx), time.Now().Add(time.Minute))
+		if err != nil || n != 2 {
+			t.Fatalf("PurgeDeletedProjects: expected 2; got %d, %v", n, err)
+		}
+	})
+}
+
+func TestParity_AuditLog(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		ctx = WithActor(ctx, "api-key:admin", "req-1")
+
+		p, err := s.InsertProject(ctx, NewProject{Name: "Alpha"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		task, err := s.InsertTask(ctx, p.ID, NewTask{Title: "T1"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		done := "done"
+		if _, err := s.UpdateTask(WithActor(ctx, "bob", "req-2"), p.ID, task.ID, TaskUpdate{Status: &done}); err != nil {
+			t.Fatalf("UpdateTask: %v", err)
+		}
+		// Nothing changes, so nothing is recorded
+		if _, err := s.UpdateTask(ctx, p.ID, task.ID, TaskUpdate{Status: &done}); err != nil {
+			t.Fatalf("UpdateTask: %v", err)
+		}
+		label, err := s.InsertLabel(ctx, p.ID, "bug", "")
+		if err != nil {
+			t.Fatalf("InsertLabel: %v", err)
+		}
+		if _, err := s.AttachLabel(ctx, p.ID, task.ID, label.ID); err != nil {
+			t.Fatalf("AttachLabel: %v", err)
+		}
+		if err := s.DeleteTask(ctx, p.ID, task.ID, SubtasksReparent); err != nil {
+			t.Fatalf("DeleteTask: %v", err)
+		}
+
+		entries, total, err := s.ListAuditEntries(ctx, ListAuditParams{Limit: 10, Entity: domain.AuditTask, EntityID: task.ID.String()})
+		if err != nil || total != 3 || len(entries) != 3 {
+			t.Fatalf("ListAuditEntries: expected 3 task entries; got %+v, %d, %v", entries, total, err)
+		}
+		del, upd, ins := entries[0], entries[1], entries[2]
+		if ins.Action != domain.AuditCreate || ins.Before != nil || ins.After["title"] != "T1" || ins.Actor != "api-key:admin" || ins.RequestID != "req-1" {
+			t.Fatalf("unexpected create entry: %+v", ins)
+		}
+		if upd.Action != domain.AuditUpdate || upd.Actor != "bob" || upd.RequestID != "req-2" ||
+			upd.Before["status"] != "todo" || upd.After["status"] != "done" {
+			t.Fatalf("unexpected update entry: %+v", upd)
+		}
+		if _, ok := upd.After["title"]; ok {
+			t.Fatalf("expected the update to leave out unchanged fields; got %+v", upd.After)
+		}
+		if del.Action != domain.AuditDelete || del.After != nil || del.Before["status"] != "done" {
+			t.Fatalf("unexpected delete entry: %+v", del)
+		}
+		if !(del.ID > upd.ID && upd.ID > ins.ID) || ins.WorkspaceID != domain.DefaultWorkspaceID {
+			t.Fatalf("unexpected ids or workspace: %+v", entries)
+		}
+
+		labels, _, err := s.ListAuditEntries(ctx, ListAuditParams{Limit: 10, Entity: domain.AuditTaskLabel})
+		if err != nil || len(labels) != 1 || labels[0].EntityID != task.ID.String() || labels[0].After["labelId"] != label.ID.String() {
+			t.Fatalf("unexpected label entries: %+v, %v", labels, err)
+		}
+
+		// project, task x3, label, task_label
+		page, total, err := s.ListAuditEntries(ctx, ListAuditParams{Limit: 2, Offset: 2})
+		if err != nil || total != 6 || len(page) != 2 || page[1].ID != upd.ID {
+			t.Fatalf("unexpected page: %+v, %d, %v", page, total, err)
+		}
+
+		// Other workspaces do not see the entries
+		org, err := s.InsertOrganization(ctx, "Acme")
+		if err != nil {
+			t.Fatalf("InsertOrganization: %v", err)
+		}
+		if _, total, _ := s.ListAuditEntries(WithWorkspace(ctx, org.ID), ListAuditParams{Limit: 10}); total != 0 {
+			t.Fatalf("expected no entries in another workspace; got %d", total)
+		}
+
+		if n, err := s.PurgeAuditEntries(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+			t.Fatalf("PurgeAuditEntries: expected 0; got %d, %v", n, err)
+		}
+		if n, err := s.PurgeAuditEntries(ctx, time.Now().Add(time.Minute)); err != nil || n != 7 {
+			t.Fatalf("PurgeAuditEntries: expected 7; got %d, %v", n, err)
+		}
+	})
+}
+
+func TestParity_AuditLogCoversCopies(t *testing.T) {
+	forEachStore(t, func(t *testing.T, ctx context.Context, s ProjectStore) {
+		history := func(entity, id string) []domain.AuditEntry {
+			t.Helper()
+			entries, _, err := s.ListAuditEntries(ctx, ListAuditParams{Limit: 100, Entity: entity, EntityID: id})
+			if err != nil {
+				t.Fatalf("ListAuditEntries: %v", err)
+			}
+			return entries
+		}
+
+		p, err := s.InsertProject(ctx, NewProject{Name: "Alpha", CreatedBy: "alice"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		if got := history(domain.AuditMember, p.ID.String()); len(got) != 1 || got[0].Action != domain.AuditCreate || got[0].After["subject"] != "alice" {
+			t.Fatalf("expected the owner to be recorded; got %+v", got)
+		}
+
+		user, err := s.InsertUser(ctx, "Ann", "ann@example.com")
+		if err != nil {
+			t.Fatalf("InsertUser: %v", err)
+		}
+		parent, err := s.InsertTask(ctx, p.ID, NewTask{Title: "Parent"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		child, err := s.InsertTask(ctx, p.ID, NewTask{Title: "Child", ParentTaskID: &parent.ID})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		other, err := s.InsertTask(ctx, p.ID, NewTask{Title: "Other"})
+		if err != nil {
+			t.Fatalf("InsertTask: %v", err)
+		}
+		label, err := s.InsertLabel(ctx, p.ID, "bug", "")
+		if err != nil {
+			t.Fatalf("InsertLabel: %v", err)
+		}
+		if _, err := s.AttachLabel(ctx, p.ID, child.ID, label.ID); err != nil {
+			t.Fatalf("AttachLabel: %v", err)
+		}
+		if _, err := s.AddTaskBlocker(ctx, p.ID, child.ID, other.ID); err != nil {
+			t.Fatalf("AddTaskBlocker: %v", err)
+		}
+		if _, err := s.InsertComment(ctx, p.ID, other.ID, NewComment{AuthorID: user.ID, Body: "hi"}); err != nil {
+			t.Fatalf("InsertComment: %v", err)
+		}
+
+		// Cloned tasks, labels and the new owner are recorded as creates
+		clone, err := s.CloneProject(ctx, p.ID, ProjectClone{Name: "Beta", CreatedBy: "bob", IncludeTasks: true})
+		if err != nil {
+			t.Fatalf("CloneProject: %v", err)
+		}
+		tasks, _, err := s.ListTasks(ctx, clone.ID, ListTasksParams{Limit: 10})
+		if err != nil || len(tasks) != 3 {
+			t.Fatalf("ListTasks: %+v, %v", tasks, err)
+		}
+		for _, task := range tasks {
+			got := history(domain.AuditTask, task.ID.String())
+			if len(got) != 1 || got[0].Action != domain.AuditCreate || got[0].After["title"] != task.Title {
+				t.Fatalf("expected a create for cloned task %q; got %+v", task.Title, got)
+			}
+		}
+		if got := history(domain.AuditMember, clone.ID.String()); len(got) != 1 || got[0].After["subject"] != "bob" {
+			t.Fatalf("expected the clone's owner to be recorded; got %+v", got)
+		}
+		if got := history(domain.AuditLabel, ""); len(got) != 2 {
+			t.Fatalf("expected the cloned label to be recorded; got %+v", got)
+		}
+
+		// Transferring records every moved task, the dropped dependency, the
+		// created label and the swapped attachment
+		target, err := s.InsertProject(ctx, NewProject{Name: "Gamma"})
+		if err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+		if _, _, err := s.TransferTask(ctx, p.ID, parent.ID, target.ID); err != nil {
+			t.Fatalf("TransferTask: %v", err)
+		}
+		for _, id := range []uuid.UUID{parent.ID, child.ID} {
+			got := history(domain.AuditTask, id.String())
+			if len(got) == 0 || got[0].Action != domain.AuditUpdate || got[0].Before["projectId"] != p.ID.String() || got[0].After["projectId"] != target.ID.String() {
+				t.Fatalf("expected the move of %s to be recorded; got %+v", id, got)
+			}
+		}
+		if got := history(domain.AuditTaskDependency, child.ID.String()); len(got) != 2 || got[0].Action != domain.AuditDelete || got[0].Before["blockerId"] != other.ID.String() {
+			t.Fatalf("expected the dropped dependency to be recorded; got %+v", got)
+		}
+		created := history(domain.AuditLabel, "")
+		if len(created) != 3 || created[0].After["projectId"] != target.ID.String() {
+			t.Fatalf("expected the carried label to be recorded; got %+v", created)
+		}
+		relabeled := history(domain.AuditTaskLabel, child.ID.String())
+		if len(relabeled) != 2 || relabeled[0].Action != domain.AuditUpdate ||
+			relabeled[0].Before["labelId"] != label.ID.String() || relabeled[0].After["labelId"] != created[0].EntityID {
+			t.Fatalf("expected the swapped attachment to be recorded; got %+v", relabeled)
+		}
+
+		// Copying records the copied comment and label attachment
+		copied, _, err := s.CopyTask(ctx, target.ID, child.ID, p.ID)
+		if err != nil {
+			t.Fatalf("CopyTask: %v", err)
+		}
+		if got := history(domain.AuditTaskLabel, copied.ID.String()); len(got) != 1 || got[0].After["labelId"] != label.ID.String() {
+			t.Fatalf("expected the copied attachment to be recorded; got %+v", got)
+		}
+		copiedOther, _, err := s.CopyTask(ctx, p.ID, other.ID, target.ID)
+		if err != nil {
+			t.Fatalf("CopyTask: %v", err)
+		}
+		comments := history(domain.AuditComment, "")
+		if len(comments) != 2 || comments[0].After["taskId"] != copiedOther.ID.String() {
+			t.Fatalf("expected the copied comment to be recorded; got %+v", comments)
+		}
+	})
+}
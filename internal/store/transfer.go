@@ -0,0 +1,12 @@
+package store
+
+import "github.com/linus5304/project-manager-api/internal/domain"
+
+// transferStatus is where a task in status lands in another project's
+// workflow: the same status if it has one, otherwise its first status.
+func transferStatus(workflow domain.Workflow, status string) domain.WorkflowStatus {
+	if st, ok := workflow.Status(status); ok {
+		return st
+	}
+	return workflow.Statuses[0]
+}
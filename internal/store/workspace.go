@@ -0,0 +1,44 @@
+package store
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/linus5304/project-manager-api/internal/domain"
+)
+
+type workspaceKey struct{}
+
+// allWorkspaces marks a context that may see every workspace.
+var allWorkspaces = uuid.Max
+
+// WithWorkspace scopes every store call made with the returned context to
+// one workspace: projects, templates, users and everything in them from
+// other workspaces behave as if they did not exist. Calls made with a context that
+// was never scoped use domain.DefaultWorkspaceID.
+func WithWorkspace(ctx context.Context, workspaceID uuid.UUID) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, workspaceID)
+}
+
+// AllWorkspaces lifts the workspace scope, for maintenance such as
+// PurgeDeletedProjects.
+func AllWorkspaces(ctx context.Context) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, allWorkspaces)
+}
+
+// Workspace returns the workspace ctx is scoped to.
+func Workspace(ctx context.Context) uuid.UUID {
+	if id, ok := ctx.Value(workspaceKey{}).(uuid.UUID); ok && id != allWorkspaces {
+		return id
+	}
+	return domain.DefaultWorkspaceID
+}
+
+// inWorkspace reports whether something in workspaceID is visible to ctx.
+func inWorkspace(ctx context.Context, workspaceID uuid.UUID) bool {
+	id, ok := ctx.Value(workspaceKey{}).(uuid.UUID)
+	if !ok {
+		id = domain.DefaultWorkspaceID
+	}
+	return id == allWorkspaces || id == workspaceID
+}